@@ -0,0 +1,171 @@
+// Package apperrors defines the error classes shared by the repository,
+// service and CLI layers. Concrete error types carry details for messages and
+// match their sentinel with errors.Is, also after being wrapped with %w.
+package apperrors
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrNotFound            = errors.New("not found")
+	ErrDuplicateName       = errors.New("duplicate name")
+	ErrValidation          = errors.New("validation failed")
+	ErrCategoryInUse       = errors.New("category in use")
+	ErrDatabaseUnavailable = errors.New("database unavailable")
+	ErrConflict            = errors.New("conflict")
+)
+
+// NotFoundError reports a missing entity, e.g. "item with ID 3 not found"
+type NotFoundError struct {
+	Entity string
+	ID     int
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("%s with ID %d not found", e.Entity, e.ID)
+}
+
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// RateNotFoundError reports a currency without an exchange rate on or before a date
+type RateNotFoundError struct {
+	Currency string
+	Date     time.Time
+}
+
+func (e *RateNotFoundError) Error() string {
+	return fmt.Sprintf("exchange rate for %s on or before %s not found", e.Currency, e.Date.Format("2006-01-02"))
+}
+
+func (e *RateNotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// DuplicateNameError reports a name that must be unique and is already
+// taken; TrashedID is the deleted record holding it, 0 when it is live
+type DuplicateNameError struct {
+	Entity    string
+	Name      string
+	TrashedID int
+}
+
+func (e *DuplicateNameError) Error() string {
+	if e.TrashedID != 0 {
+		return fmt.Sprintf("%s with name '%s' is in the trash: restore it with '%s restore --id %d' or purge it first", e.Entity, e.Name, e.Entity, e.TrashedID)
+	}
+	return fmt.Sprintf("%s with name '%s' already exists", e.Entity, e.Name)
+}
+
+func (e *DuplicateNameError) Is(target error) bool {
+	return target == ErrDuplicateName
+}
+
+// ValidationError reports invalid input for a field, e.g. "price must be greater than 0"
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func NewValidationError(field, message string) *ValidationError {
+	return &ValidationError{Field: field, Message: message}
+}
+
+func (e *ValidationError) Error() string {
+	return e.Field + " " + e.Message
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// CategoryInUseError reports a category delete blocked by the items still
+// referencing it; InTrash means all of them are deleted but not purged yet
+type CategoryInUseError struct {
+	ID      int
+	InTrash bool
+}
+
+func (e *CategoryInUseError) Error() string {
+	if e.InTrash {
+		return fmt.Sprintf("category with ID %d is still used by items in the trash", e.ID)
+	}
+	return fmt.Sprintf("category with ID %d is still used by items", e.ID)
+}
+
+func (e *CategoryInUseError) Is(target error) bool {
+	return target == ErrCategoryInUse
+}
+
+// PeriodLockedError reports a change to a journal month that is already posted
+type PeriodLockedError struct {
+	Month    string
+	PostedAt time.Time
+}
+
+func (e *PeriodLockedError) Error() string {
+	if e.PostedAt.IsZero() {
+		return fmt.Sprintf("journal of %s is already posted and locked", e.Month)
+	}
+	return fmt.Sprintf("journal of %s was posted on %s and is locked", e.Month, e.PostedAt.Format("2006-01-02 15:04:05"))
+}
+
+func (e *PeriodLockedError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+// AlreadyDisposedError reports a change to an item that was already sold, scrapped or donated
+type AlreadyDisposedError struct {
+	ID         int
+	DisposedAt time.Time
+}
+
+func (e *AlreadyDisposedError) Error() string {
+	return fmt.Sprintf("item with ID %d was already disposed on %s", e.ID, e.DisposedAt.Format("2006-01-02"))
+}
+
+func (e *AlreadyDisposedError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+// VersionConflictError reports an update of an item or category that was
+// changed by someone else since it was read. Expected is the version the
+// update was based on, 0 when it was based on the time of the last update.
+type VersionConflictError struct {
+	Entity   string
+	ID       int
+	Expected int
+	Actual   int
+}
+
+func (e *VersionConflictError) Error() string {
+	if e.Expected == 0 {
+		return fmt.Sprintf("%s with ID %d was changed since it was read, it is at version %d", e.Entity, e.ID, e.Actual)
+	}
+	return fmt.Sprintf("%s with ID %d was changed since it was read: expected version %d, it is at version %d", e.Entity, e.ID, e.Expected, e.Actual)
+}
+
+func (e *VersionConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+// DatabaseUnavailableError wraps a connection failure, keeping the driver error
+type DatabaseUnavailableError struct {
+	Err error
+}
+
+func (e *DatabaseUnavailableError) Error() string {
+	return "database unavailable: " + e.Err.Error()
+}
+
+func (e *DatabaseUnavailableError) Unwrap() error {
+	return e.Err
+}
+
+func (e *DatabaseUnavailableError) Is(target error) bool {
+	return target == ErrDatabaseUnavailable
+}
@@ -0,0 +1,48 @@
+package apperrors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestErrorsSurviveWrapping(t *testing.T) {
+	tests := []struct {
+		err      error
+		sentinel error
+		message  string
+	}{
+		{&NotFoundError{Entity: "item", ID: 3}, ErrNotFound, "item with ID 3 not found"},
+		{&RateNotFoundError{Currency: "USD", Date: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)}, ErrNotFound, "exchange rate for USD on or before 2024-06-01 not found"},
+		{&DuplicateNameError{Entity: "category", Name: "Elektronik"}, ErrDuplicateName, "category with name 'Elektronik' already exists"},
+		{NewValidationError("price", "must be greater than 0"), ErrValidation, "price must be greater than 0"},
+		{&CategoryInUseError{ID: 2}, ErrCategoryInUse, "category with ID 2 is still used by items"},
+		{&CategoryInUseError{ID: 2, InTrash: true}, ErrCategoryInUse, "category with ID 2 is still used by items in the trash"},
+		{&VersionConflictError{Entity: "item", ID: 3, Expected: 2, Actual: 4}, ErrConflict, "item with ID 3 was changed since it was read: expected version 2, it is at version 4"},
+		{&VersionConflictError{Entity: "category", ID: 2, Actual: 3}, ErrConflict, "category with ID 2 was changed since it was read, it is at version 3"},
+		{&DatabaseUnavailableError{Err: errors.New("connection refused")}, ErrDatabaseUnavailable, "database unavailable: connection refused"},
+		{&PeriodLockedError{Month: "2026-09", PostedAt: time.Date(2026, 10, 2, 8, 0, 0, 0, time.UTC)}, ErrConflict, "journal of 2026-09 was posted on 2026-10-02 08:00:00 and is locked"},
+		{&AlreadyDisposedError{ID: 3, DisposedAt: time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)}, ErrConflict, "item with ID 3 was already disposed on 2026-03-31"},
+	}
+
+	for _, tt := range tests {
+		wrapped := fmt.Errorf("failed to do something: %w", fmt.Errorf("error querying: %w", tt.err))
+		if !errors.Is(wrapped, tt.sentinel) {
+			t.Errorf("expected %q to match %v", wrapped, tt.sentinel)
+		}
+		if tt.err.Error() != tt.message {
+			t.Errorf("expected message %q, got %q", tt.message, tt.err.Error())
+		}
+		for _, other := range []error{ErrNotFound, ErrDuplicateName, ErrValidation, ErrCategoryInUse, ErrDatabaseUnavailable, ErrConflict} {
+			if other != tt.sentinel && errors.Is(wrapped, other) {
+				t.Errorf("expected %q not to match %v", wrapped, other)
+			}
+		}
+	}
+
+	var validationErr *ValidationError
+	if err := fmt.Errorf("invalid category ID: %w", NewValidationError("ID", "must be greater than 0")); !errors.As(err, &validationErr) || validationErr.Field != "ID" {
+		t.Errorf("expected ValidationError for field 'ID', got %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"mini_project3/repository"
+
+	"github.com/spf13/cobra"
+)
+
+// auditSource is who runs this process and with which command line, as
+// recorded in the audit log: INVENTORY_ACTOR, else the operating system user
+func auditSource() repository.AuditSource {
+	actor := os.Getenv("INVENTORY_ACTOR")
+	if actor == "" {
+		if u, err := user.Current(); err == nil {
+			actor = u.Username
+		}
+	}
+	return repository.AuditSource{Actor: actor, Command: commandLine(os.Args)}
+}
+
+// commandLine joins args back into a command line, quoting arguments with
+// spaces and masking the value of --db-url, which may hold a password
+func commandLine(args []string) string {
+	parts := make([]string, 0, len(args))
+	mask := false
+	for i, arg := range args {
+		switch {
+		case i == 0:
+			arg = filepath.Base(arg)
+		case mask:
+			arg, mask = "***", false
+		case arg == "--db-url":
+			mask = true
+		case strings.HasPrefix(arg, "--db-url="):
+			arg = "--db-url=***"
+		}
+		if arg == "" || strings.ContainsAny(arg, " \t\n\"'") {
+			arg = strconv.Quote(arg)
+		}
+		parts = append(parts, arg)
+	}
+	return strings.Join(parts, " ")
+}
+
+// ==================== AUDIT COMMANDS ====================
+
+var auditCmd = &cobra.Command{
+	Use:               "audit",
+	Short:             "Lihat log perubahan barang dan kategori",
+	PersistentPreRunE: setupApp,
+}
+
+var auditListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Tampilkan perubahan barang dan kategori",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entity, _ := cmd.Flags().GetString("entity")
+		id, _ := cmd.Flags().GetInt("id")
+		since, _ := cmd.Flags().GetString("since")
+		_, err := auditHandler.ListAudit(cmd.Context(), entity, id, since)
+		return err
+	},
+}
+
+var itemHistoryCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Tampilkan riwayat perubahan barang",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, _ := cmd.Flags().GetInt("id")
+		_, err := auditHandler.ItemHistory(cmd.Context(), id)
+		return err
+	},
+}
+
+func init() {
+	auditCmd.AddCommand(auditListCmd)
+	itemCmd.AddCommand(itemHistoryCmd)
+
+	auditListCmd.Flags().String("entity", "", "Only list changes of this entity: item or category")
+	auditListCmd.Flags().IntP("id", "i", 0, "Only list changes of the item or category with this ID (requires --entity)")
+	auditListCmd.Flags().String("since", "", "Only list changes made since this date (YYYY-MM-DD) or this long ago (7d, 12h)")
+
+	itemHistoryCmd.Flags().IntP("id", "i", 0, "Item ID")
+	itemHistoryCmd.MarkFlagRequired("id")
+}
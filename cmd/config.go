@@ -0,0 +1,215 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"mini_project3/config"
+	"mini_project3/output"
+
+	"github.com/spf13/cobra"
+)
+
+// ==================== CONFIG COMMANDS ====================
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Kelola profil koneksi database",
+}
+
+// openConfigFile resolves and reads the config file selected by --config
+func openConfigFile(cmd *cobra.Command) (string, *config.File, error) {
+	path, _ := cmd.Flags().GetString("config")
+	path, err := config.ResolvePath(path)
+	if err != nil {
+		return "", nil, err
+	}
+
+	file, err := config.ReadFile(path)
+	if err != nil {
+		return "", nil, err
+	}
+	return path, file, nil
+}
+
+// configProfile is one row of 'config list' in a non-table format
+type configProfile struct {
+	Name    string `json:"name"`
+	Current bool   `json:"current"`
+	Target  string `json:"target"`
+}
+
+// configSetting is one row of 'config show' in a non-table format
+type configSetting struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Tampilkan semua profil",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, file, err := openConfigFile(cmd)
+		if err != nil {
+			return err
+		}
+
+		profiles := make([]configProfile, 0, len(file.Profiles))
+		for _, name := range file.ProfileNames() {
+			profile, _ := file.Resolve(name)
+			target := profile.URL
+			if target == "" && profile.Driver == config.DriverSQLite {
+				target = "sqlite:" + profile.SQLitePath()
+			} else if target == "" {
+				target = fmt.Sprintf("%s:%d/%s", profile.Host, profile.Port, profile.DBName)
+			}
+			profiles = append(profiles, configProfile{Name: name, Current: name == file.CurrentProfile, Target: target})
+		}
+
+		w := cmd.OutOrStdout()
+		if outputFormat != output.Table {
+			return output.Write(w, outputFormat, profiles)
+		}
+
+		fmt.Fprintf(w, "File konfigurasi: %s\n\n", path)
+		if len(profiles) == 0 {
+			fmt.Fprintln(w, "No profiles found.")
+			return nil
+		}
+		for _, profile := range profiles {
+			marker := " "
+			if profile.Current {
+				marker = "*"
+			}
+			fmt.Fprintf(w, "%s %-15s %s\n", marker, profile.Name, profile.Target)
+		}
+		return nil
+	},
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Tampilkan konfigurasi efektif (file, env dan flag)",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+
+		profile, _ := cmd.Flags().GetString("profile")
+		if profile == "" {
+			if _, file, err := openConfigFile(cmd); err == nil {
+				profile = file.CurrentProfile
+			}
+		}
+		if profile == "" {
+			profile = "(default)"
+		}
+
+		settings := make([]configSetting, 0, len(config.Keys()))
+		for _, key := range config.Keys() {
+			value := cfg.Get(key)
+			if key == "password" && value != "" {
+				value = "********"
+			}
+			settings = append(settings, configSetting{Key: key, Value: value})
+		}
+
+		w := cmd.OutOrStdout()
+		if outputFormat != output.Table {
+			return output.Write(w, outputFormat, settings)
+		}
+
+		fmt.Fprintf(w, "\n=== Konfigurasi: %s ===\n", profile)
+		for _, setting := range settings {
+			fmt.Fprintf(w, "%-18s: %s\n", setting.Key, setting.Value)
+		}
+		return nil
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Ubah setting pada profil aktif (atau --profile)",
+	Long:  "Ubah setting pada profil aktif (atau --profile). Key yang valid: " + strings.Join(config.Keys(), ", "),
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, file, err := openConfigFile(cmd)
+		if err != nil {
+			return err
+		}
+
+		name, _ := cmd.Flags().GetString("profile")
+		if name == "" {
+			name = file.CurrentProfile
+		}
+
+		if err := file.Set(name, args[0], args[1]); err != nil {
+			return err
+		}
+		if err := file.Save(path); err != nil {
+			return err
+		}
+
+		if name == "" {
+			name = "(default)"
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "\n✓ %s pada profil %s berhasil diperbarui\n", args[0], name)
+		return nil
+	},
+}
+
+var configUseCmd = &cobra.Command{
+	Use:   "use <profile>",
+	Short: "Jadikan profil sebagai profil aktif",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, file, err := openConfigFile(cmd)
+		if err != nil {
+			return err
+		}
+
+		if _, ok := file.Profiles[args[0]]; !ok {
+			return fmt.Errorf("profile '%s' not found", args[0])
+		}
+
+		file.CurrentProfile = args[0]
+		if err := file.Save(path); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "\n✓ Profil aktif sekarang: %s\n", args[0])
+		return nil
+	},
+}
+
+var configTestCmd = &cobra.Command{
+	Use:   "test",
+	Short: "Uji koneksi ke database",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return fmt.Errorf("invalid configuration: %w", err)
+		}
+
+		info, err := config.CheckConnection(cmd.Context(), cfg)
+		if err != nil {
+			return err
+		}
+
+		w := cmd.OutOrStdout()
+		fmt.Fprintf(w, "\n✓ Koneksi berhasil\n")
+		fmt.Fprintf(w, "Server      : %s\n", info.ServerVersion)
+		fmt.Fprintf(w, "Latensi     : %s\n", info.Latency.Round(time.Millisecond))
+		return nil
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configListCmd)
+	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configUseCmd)
+	configCmd.AddCommand(configTestCmd)
+}
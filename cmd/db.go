@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"mini_project3/database"
+	"mini_project3/repository"
+
+	"github.com/spf13/cobra"
+)
+
+// ==================== DB COMMANDS ====================
+
+var dbCmd = &cobra.Command{
+	Use:               "db",
+	Short:             "Kelola skema database",
+	PersistentPreRunE: connectDB,
+}
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Jalankan migrasi skema database",
+}
+
+func newMigrator() (*database.Migrator, error) {
+	return database.NewMigrator(db, appConfig.Driver)
+}
+
+var migrateUpCmd = &cobra.Command{
+	Use:   "up",
+	Short: "Terapkan migrasi yang belum dijalankan",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		steps, _ := cmd.Flags().GetInt("steps")
+		migrator, err := newMigrator()
+		if err != nil {
+			return err
+		}
+		applied, err := migrator.Up(cmd.Context(), steps)
+		w := cmd.OutOrStdout()
+		for _, m := range applied {
+			fmt.Fprintf(w, "✓ Migrasi %06d_%s diterapkan\n", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Fprintln(w, "Skema database sudah terbaru.")
+		}
+		return nil
+	},
+}
+
+var migrateDownCmd = &cobra.Command{
+	Use:   "down",
+	Short: "Batalkan migrasi terakhir",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		steps, _ := cmd.Flags().GetInt("steps")
+		migrator, err := newMigrator()
+		if err != nil {
+			return err
+		}
+		reverted, err := migrator.Down(cmd.Context(), steps)
+		w := cmd.OutOrStdout()
+		for _, m := range reverted {
+			fmt.Fprintf(w, "✓ Migrasi %06d_%s dibatalkan\n", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(reverted) == 0 {
+			fmt.Fprintln(w, "Tidak ada migrasi yang bisa dibatalkan.")
+		}
+		return nil
+	},
+}
+
+var migrateStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Tampilkan status setiap migrasi",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		migrator, err := newMigrator()
+		if err != nil {
+			return err
+		}
+		statuses, err := migrator.Status(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 3, ' ', tabwriter.TabIndent)
+		fmt.Fprintln(w, "Versi\tNama\tStatus\tDiterapkan")
+		fmt.Fprintln(w, "---\t---\t---\t---")
+		for _, s := range statuses {
+			status, appliedAt := "pending", "-"
+			if s.Applied {
+				status, appliedAt = "applied", s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%06d\t%s\t%s\t%s\n", s.Version, s.Name, status, appliedAt)
+		}
+		return w.Flush()
+	},
+}
+
+var migrateRedoCmd = &cobra.Command{
+	Use:   "redo",
+	Short: "Batalkan lalu terapkan ulang migrasi terakhir",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		migrator, err := newMigrator()
+		if err != nil {
+			return err
+		}
+		m, err := migrator.Redo(cmd.Context())
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "✓ Migrasi %06d_%s diterapkan ulang\n", m.Version, m.Name)
+		return nil
+	},
+}
+
+var seedCmd = &cobra.Command{
+	Use:   "seed",
+	Short: "Isi database dengan data contoh (minimal, demo, large)",
+	Long: `Isi database dengan data contoh (minimal, demo, large). Kategori yang
+namanya sudah ada dipakai ulang, dan barang yang sudah ada (nama, kategori
+dan tanggal beli sama) dilewati, sehingga seed yang diulang tidak
+menggandakan data.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		set, _ := cmd.Flags().GetString("set")
+		count, _ := cmd.Flags().GetInt("count")
+		seed, _ := cmd.Flags().GetInt64("seed")
+		reset, _ := cmd.Flags().GetBool("reset")
+
+		fixture, err := database.FixtureByName(set, count, seed)
+		if err != nil {
+			return err
+		}
+
+		result, err := database.Seed(cmd.Context(), db, appConfig.Driver, fixture, reset, repository.AuditRecord)
+		if err != nil {
+			return err
+		}
+
+		w := cmd.OutOrStdout()
+		fmt.Fprintf(w, "\n✓ Data '%s' berhasil ditambahkan: %d kategori, %d barang\n", fixture.Name, result.Categories, result.Items)
+		if result.Skipped > 0 {
+			fmt.Fprintf(w, "%d barang sudah ada dan dilewati\n", result.Skipped)
+		}
+		return nil
+	},
+}
+
+func init() {
+	dbCmd.AddCommand(seedCmd)
+	dbCmd.AddCommand(migrateCmd)
+	migrateCmd.AddCommand(migrateUpCmd)
+	migrateCmd.AddCommand(migrateDownCmd)
+	migrateCmd.AddCommand(migrateStatusCmd)
+	migrateCmd.AddCommand(migrateRedoCmd)
+
+	migrateUpCmd.Flags().IntP("steps", "n", 0, "Number of migrations to apply (default: all)")
+	migrateDownCmd.Flags().IntP("steps", "n", 1, "Number of migrations to roll back")
+
+	seedCmd.Flags().StringP("set", "s", "demo", "Fixture set: "+strings.Join(database.FixtureNames, ", "))
+	seedCmd.Flags().IntP("count", "c", 1000, "Number of synthetic items (large set only)")
+	seedCmd.Flags().Int64("seed", 1, "Random seed for reproducible synthetic items (large set only)")
+	seedCmd.Flags().Bool("reset", false, "Delete all items and categories before seeding; their ids are not reused")
+}
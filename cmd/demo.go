@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"mini_project3/database"
+	"mini_project3/models"
+	"mini_project3/money"
+	"mini_project3/repository"
+	"mini_project3/service"
+)
+
+type demoRepositories struct {
+	categories    *repository.MemoryCategoryRepository
+	items         *repository.MemoryItemRepository
+	exchangeRates *repository.MemoryExchangeRateRepository
+	journals      *repository.MemoryJournalRepository
+	audit         *repository.MemoryAuditRepository
+	tx            *repository.MemoryTxManager
+}
+
+// demoExchangeRates lets --demo reports use --currency USD or SGD
+var demoExchangeRates = []models.ExchangeRate{
+	{Currency: "USD", Date: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Rate: money.MustParseRate("15400")},
+	{Currency: "USD", Date: time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), Rate: money.MustParseRate("16350")},
+	{Currency: "SGD", Date: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Rate: money.MustParseRate("11650")},
+	{Currency: "SGD", Date: time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), Rate: money.MustParseRate("12050")},
+}
+
+// newDemoRepositories returns in-memory repositories preloaded with the demo
+// fixture. Changes only live as long as the process.
+func newDemoRepositories(ctx context.Context) (*demoRepositories, error) {
+	store := repository.NewMemoryStore()
+	repos := &demoRepositories{
+		categories:    repository.NewMemoryCategoryRepository(store),
+		items:         repository.NewMemoryItemRepository(store),
+		exchangeRates: repository.NewMemoryExchangeRateRepository(store),
+		journals:      repository.NewMemoryJournalRepository(store),
+		audit:         repository.NewMemoryAuditRepository(store),
+		tx:            repository.NewMemoryTxManager(store),
+	}
+	// the sample data is not the user's doing
+	ctx = repository.WithAuditSource(ctx, repository.AuditSource{Actor: "demo"})
+
+	fixture := database.DemoFixture()
+	categoryIDs := map[string]int{}
+	for _, cat := range fixture.Categories {
+		cat := cat
+		if err := repos.categories.Create(ctx, &cat); err != nil {
+			return nil, err
+		}
+		categoryIDs[cat.Name] = cat.ID
+	}
+	for _, item := range fixture.Items {
+		item := models.Item{
+			Name:               item.Name,
+			CategoryID:         categoryIDs[item.CategoryName],
+			Price:              item.Price,
+			Currency:           service.BaseCurrency,
+			PurchaseDate:       item.PurchaseDate,
+			DepreciationPolicy: item.DepreciationPolicy,
+		}
+		if err := repos.items.Create(ctx, &item); err != nil {
+			return nil, err
+		}
+	}
+	for _, rate := range demoExchangeRates {
+		rate := rate
+		if err := repos.exchangeRates.Set(ctx, &rate); err != nil {
+			return nil, err
+		}
+	}
+
+	fmt.Fprintln(os.Stderr, "Mode demo: data contoh di memori, perubahan tidak disimpan")
+	return repos, nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"strings"
+
+	"mini_project3/models"
+	"mini_project3/money"
+	"mini_project3/service"
+
+	"github.com/spf13/cobra"
+)
+
+// addPolicyFlags adds the depreciation policy flags of item and category create/update;
+// inheritedFrom names where an omitted value comes from
+func addPolicyFlags(cmd *cobra.Command, inheritedFrom string) {
+	cmd.Flags().String("method", "", "Depreciation method: "+strings.Join(service.DepreciationMethods, ", ")+" (default: "+inheritedFrom+")")
+	cmd.Flags().Int("life", 0, "Useful life in months, required by straight-line, double-declining and sum-of-years-digits")
+	cmd.Flags().String("rate", "", "Yearly rate of declining-balance in percent (e.g. 25)")
+	cmd.Flags().String("salvage", "", "Residual value the book value stops at: an amount in the price currency (e.g. 500000) or a percentage of the price (e.g. 10%)")
+	cmd.Flags().String("tax-group", "", "Fiscal asset group: "+strings.Join(service.TaxGroupCodes(), ", ")+"; items inherit the category's, no group means no fiscal book")
+	cmd.Flags().String("tax-method", "", "Fiscal method: "+strings.Join(service.TaxMethods, ", ")+"; buildings only straight-line (default: straight-line)")
+}
+
+// policyFlags reads the flags added by addPolicyFlags
+func policyFlags(cmd *cobra.Command) (models.DepreciationPolicy, error) {
+	method, _ := cmd.Flags().GetString("method")
+	life, _ := cmd.Flags().GetInt("life")
+	rateStr, _ := cmd.Flags().GetString("rate")
+	salvageStr, _ := cmd.Flags().GetString("salvage")
+	taxGroup, _ := cmd.Flags().GetString("tax-group")
+	taxMethod, _ := cmd.Flags().GetString("tax-method")
+
+	policy := models.DepreciationPolicy{
+		Method:           strings.ToLower(strings.TrimSpace(method)),
+		UsefulLifeMonths: life,
+		TaxGroup:         strings.ToLower(strings.TrimSpace(taxGroup)),
+		TaxMethod:        strings.ToLower(strings.TrimSpace(taxMethod)),
+	}
+	if rateStr != "" {
+		rate, err := parsePercent("rate", rateStr)
+		if err != nil {
+			return models.DepreciationPolicy{}, err
+		}
+		policy.RatePercent = rate
+	}
+
+	salvageStr = strings.TrimSpace(salvageStr)
+	if strings.HasSuffix(salvageStr, "%") {
+		percent, err := parsePercent("salvage", salvageStr)
+		if err != nil {
+			return models.DepreciationPolicy{}, err
+		}
+		policy.SalvagePercent = percent
+	} else if salvageStr != "" {
+		amount, err := parseMoney("salvage", salvageStr)
+		if err != nil {
+			return models.DepreciationPolicy{}, err
+		}
+		policy.SalvageValue = amount
+	}
+	return policy, nil
+}
+
+// policyPatchFlags reads the flags added by addPolicyFlags that are given on
+// the command line, for an update that keeps the other fields; an empty
+// value such as --method "" makes the field inherited again
+func policyPatchFlags(cmd *cobra.Command) (service.PolicyPatch, error) {
+	policy, err := policyFlags(cmd)
+	if err != nil {
+		return service.PolicyPatch{}, err
+	}
+
+	var patch service.PolicyPatch
+	flags := cmd.Flags()
+	if flags.Changed("method") {
+		patch.Method = &policy.Method
+	}
+	if flags.Changed("life") {
+		patch.UsefulLifeMonths = &policy.UsefulLifeMonths
+	}
+	if flags.Changed("rate") {
+		patch.RatePercent = &policy.RatePercent
+	}
+	if flags.Changed("salvage") {
+		patch.SalvageValue, patch.SalvagePercent = &policy.SalvageValue, &policy.SalvagePercent
+	}
+	if flags.Changed("tax-group") {
+		patch.TaxGroup = &policy.TaxGroup
+	}
+	if flags.Changed("tax-method") {
+		patch.TaxMethod = &policy.TaxMethod
+	}
+	return patch, nil
+}
+
+// parsePercent parses a percentage flag value such as 25 or 12.5%
+func parsePercent(flag, value string) (money.Rate, error) {
+	return parseRate(flag, strings.TrimSuffix(strings.TrimSpace(value), "%"))
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"mini_project3/apperrors"
+	"mini_project3/money"
+	"mini_project3/output"
+	"mini_project3/repository"
+
+	"github.com/spf13/cobra"
+)
+
+// Exit codes of the inventory command, documented in README.md. Scripts
+// branch on these, so existing values must never be renumbered.
+const (
+	exitOK                  = 0
+	exitError               = 1 // any error not listed below
+	exitUsage               = 2 // unknown command or flag, missing required flag, bad flag value
+	exitValidation          = 3
+	exitNotFound            = 4
+	exitDuplicateName       = 5
+	exitCategoryInUse       = 6
+	exitDatabaseUnavailable = 7
+	exitTimeout             = 8   // --timeout expired
+	exitConflict            = 9   // e.g. posting a journal month that is already posted
+	exitInterrupted         = 130 // Ctrl-C or SIGTERM, as conventional for SIGINT
+)
+
+// cancelTimeout releases the --timeout context set up by startCommand
+var cancelTimeout context.CancelFunc
+
+// commandStarted is set once the flags and arguments are valid, so errors
+// returned before that are usage errors
+var commandStarted bool
+
+// startCommand is the first persistent hook of every command. Cobra checks
+// required flags only after the hooks, which would connect to the database
+// first and report a missing flag as a general error. It also parses
+// --output, --locale and --as-of and applies --timeout to the context passed down to
+// every query, along with the audit source of every change.
+func startCommand(cmd *cobra.Command, args []string) error {
+	if err := cmd.ValidateRequiredFlags(); err != nil {
+		return err
+	}
+	if err := cmd.ValidateFlagGroups(); err != nil {
+		return err
+	}
+
+	format, _ := cmd.Flags().GetString("output")
+	var err error
+	if outputFormat, err = output.ParseFormat(format); err != nil {
+		return err
+	}
+
+	tag, _ := cmd.Flags().GetString("locale")
+	locale, ok := money.LookupLocale(tag)
+	if !ok {
+		return apperrors.NewValidationError("--locale", fmt.Sprintf("must be id-ID or en-US, got '%s'", tag))
+	}
+	outputLocale = locale
+
+	if value, _ := cmd.Flags().GetString("as-of"); value != "" {
+		if asOfDate, err = parseDate("as-of", value); err != nil {
+			return err
+		}
+	}
+
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	if timeout < 0 {
+		return fmt.Errorf("invalid argument %q for \"--timeout\" flag: must not be negative", timeout)
+	}
+	if timeout > 0 {
+		var ctx context.Context
+		ctx, cancelTimeout = context.WithTimeout(cmd.Context(), timeout)
+		cmd.SetContext(ctx)
+	}
+
+	cmd.SetContext(repository.WithAuditSource(cmd.Context(), auditSource()))
+	commandStarted = true
+	return nil
+}
+
+// exitCode maps an error returned by rootCmd.Execute to the process exit code.
+// ctx is the context of the executed command: once it is done, the error is
+// whatever the interrupted query returned, so the context decides the code.
+func exitCode(ctx context.Context, err error) int {
+	switch {
+	case err == nil:
+		return exitOK
+	case !commandStarted:
+		return exitUsage
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return exitTimeout
+	case errors.Is(ctx.Err(), context.Canceled):
+		return exitInterrupted
+	case errors.Is(err, apperrors.ErrValidation):
+		return exitValidation
+	case errors.Is(err, apperrors.ErrNotFound):
+		return exitNotFound
+	case errors.Is(err, apperrors.ErrDuplicateName):
+		return exitDuplicateName
+	case errors.Is(err, apperrors.ErrCategoryInUse):
+		return exitCategoryInUse
+	case errors.Is(err, apperrors.ErrConflict):
+		return exitConflict
+	case errors.Is(err, apperrors.ErrDatabaseUnavailable):
+		return exitDatabaseUnavailable
+	}
+	return exitError
+}
+
+// parseDate parses a YYYY-MM-DD flag value, reporting failures as a validation error on flag
+func parseDate(flag, value string) (time.Time, error) {
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, apperrors.NewValidationError("--"+flag, "must be a date in YYYY-MM-DD format, got '"+value+"'")
+	}
+	return t, nil
+}
+
+// parseTimestamp parses a flag value in the YYYY-MM-DD HH:MM:SS format of the
+// get commands or in RFC 3339 as in their JSON output, reporting failures as
+// a validation error on flag
+func parseTimestamp(flag, value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.ParseInLocation("2006-01-02 15:04:05", value, time.Local); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, apperrors.NewValidationError("--"+flag, "must be a time in YYYY-MM-DD HH:MM:SS or RFC 3339 format, got '"+value+"'")
+	}
+	return t, nil
+}
+
+// parseMoney parses a decimal amount flag value, reporting failures as a validation error on flag
+func parseMoney(flag, value string) (money.Money, error) {
+	m, err := money.Parse(value)
+	if err != nil {
+		return money.Zero, apperrors.NewValidationError("--"+flag, "must be an amount with at most 2 decimal places, got '"+value+"'")
+	}
+	return m, nil
+}
+
+// parseRate parses an exchange rate flag value, reporting failures as a validation error on flag
+func parseRate(flag, value string) (money.Rate, error) {
+	r, err := money.ParseRate(value)
+	if err != nil {
+		return money.Rate{}, apperrors.NewValidationError("--"+flag, "must be a positive rate with at most 6 decimal places, got '"+value+"'")
+	}
+	return r, nil
+}
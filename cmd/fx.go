@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// ==================== FX COMMANDS ====================
+
+var fxCmd = &cobra.Command{
+	Use:               "fx",
+	Short:             "Kelola kurs mata uang asing",
+	PersistentPreRunE: setupApp,
+}
+
+var fxSetCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Simpan kurs harian sebuah mata uang",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		currency, _ := cmd.Flags().GetString("currency")
+		dateStr, _ := cmd.Flags().GetString("date")
+		rateStr, _ := cmd.Flags().GetString("rate")
+
+		date, err := parseDate("date", dateStr)
+		if err != nil {
+			return err
+		}
+		rate, err := parseRate("rate", rateStr)
+		if err != nil {
+			return err
+		}
+
+		_, err = fxHandler.SetRate(cmd.Context(), currency, date, rate)
+		return err
+	},
+}
+
+var fxListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Tampilkan kurs yang tersimpan",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		currency, _ := cmd.Flags().GetString("currency")
+		_, err := fxHandler.ListRates(cmd.Context(), currency)
+		return err
+	},
+}
+
+var fxImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Impor kurs harian dari file CSV (kolom date,currency,rate)",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, _ := cmd.Flags().GetString("file")
+		if path == "-" {
+			_, err := fxHandler.ImportRates(cmd.Context(), cmd.InOrStdin())
+			return err
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open rates file: %w", err)
+		}
+		defer f.Close()
+
+		_, err = fxHandler.ImportRates(cmd.Context(), f)
+		return err
+	},
+}
+
+func init() {
+	fxCmd.AddCommand(fxSetCmd)
+	fxCmd.AddCommand(fxListCmd)
+	fxCmd.AddCommand(fxImportCmd)
+
+	fxSetCmd.Flags().StringP("currency", "c", "", "ISO 4217 currency code (e.g. USD)")
+	fxSetCmd.Flags().StringP("date", "d", "", "Date the rate is valid from (YYYY-MM-DD)")
+	fxSetCmd.Flags().StringP("rate", "r", "", "Value of 1 unit of the currency in IDR, up to 6 decimal places")
+	fxSetCmd.MarkFlagRequired("currency")
+	fxSetCmd.MarkFlagRequired("date")
+	fxSetCmd.MarkFlagRequired("rate")
+
+	fxListCmd.Flags().StringP("currency", "c", "", "Only show rates of this currency")
+
+	fxImportCmd.Flags().StringP("file", "f", "", "CSV file with a date,currency,rate header, or - for stdin")
+	fxImportCmd.MarkFlagRequired("file")
+}
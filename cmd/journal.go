@@ -0,0 +1,61 @@
+package main
+
+import (
+	"mini_project3/models"
+	"mini_project3/service"
+
+	"github.com/spf13/cobra"
+)
+
+// addAccountFlags adds the journal account flags of category create/update
+func addAccountFlags(cmd *cobra.Command) {
+	cmd.Flags().String("expense-account", "", "Ledger account debited with the monthly depreciation (default: "+service.DefaultJournalAccounts.ExpenseAccount+")")
+	cmd.Flags().String("accumulated-account", "", "Ledger account of the accumulated depreciation, credited monthly (default: "+service.DefaultJournalAccounts.AccumulatedAccount+")")
+}
+
+// accountFlags reads the flags added by addAccountFlags
+func accountFlags(cmd *cobra.Command) models.JournalAccounts {
+	expense, _ := cmd.Flags().GetString("expense-account")
+	accumulated, _ := cmd.Flags().GetString("accumulated-account")
+	return models.JournalAccounts{ExpenseAccount: expense, AccumulatedAccount: accumulated}
+}
+
+// accountPatchFlags sets the accounts of patch whose flags, added by
+// addAccountFlags, are given on the command line
+func accountPatchFlags(cmd *cobra.Command, patch *service.CategoryPatch) {
+	accounts := accountFlags(cmd)
+	if cmd.Flags().Changed("expense-account") {
+		patch.ExpenseAccount = &accounts.ExpenseAccount
+	}
+	if cmd.Flags().Changed("accumulated-account") {
+		patch.AccumulatedAccount = &accounts.AccumulatedAccount
+	}
+}
+
+// ==================== JOURNAL COMMANDS ====================
+
+var reportJournalCmd = &cobra.Command{
+	Use:   "journal",
+	Short: "Tampilkan atau posting jurnal penyusutan bulanan per kategori",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		month, _ := cmd.Flags().GetString("month")
+		currency, _ := cmd.Flags().GetString("currency")
+		importFormat, _ := cmd.Flags().GetString("import-format")
+		if post, _ := cmd.Flags().GetBool("post"); post {
+			_, err := journalHandler.PostJournal(cmd.Context(), month, currency)
+			return err
+		}
+		_, err := journalHandler.ShowJournal(cmd.Context(), month, currency, importFormat)
+		return err
+	},
+}
+
+func init() {
+	reportCmd.AddCommand(reportJournalCmd)
+
+	reportJournalCmd.Flags().String("month", "", "Month of the journal in YYYY-MM format")
+	reportJournalCmd.Flags().Bool("post", false, "Post the journal and lock the month against recalculation")
+	reportJournalCmd.Flags().String("import-format", "", "Write the journal as an accounting import file instead: iif (QuickBooks)")
+	reportJournalCmd.MarkFlagRequired("month")
+	reportJournalCmd.MarkFlagsMutuallyExclusive("post", "import-format")
+}
@@ -1,56 +1,56 @@
 package main
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
-	"log"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 	"time"
 
 	"mini_project3/config"
 	"mini_project3/handler"
+	"mini_project3/money"
+	"mini_project3/output"
 	"mini_project3/repository"
 	"mini_project3/service"
+	"mini_project3/spreadsheet"
 
 	"github.com/spf13/cobra"
 )
 
 var (
+	appConfig       config.Config
+	db              *sql.DB
 	categoryHandler *handler.CategoryHandler
 	itemHandler     *handler.ItemHandler
+	fxHandler       *handler.FXHandler
+	journalHandler  *handler.JournalHandler
+	trashHandler    *handler.TrashHandler
+	auditHandler    *handler.AuditHandler
+	outputFormat    output.Format
+	outputLocale    money.Locale
+	// asOfDate is the date of --as-of, zero to report as of today
+	asOfDate time.Time
 )
 
 func main() {
-	// Initialize database
-	cfg := config.Config{
-		Host:     "localhost",
-		Port:     5432,
-		User:     "postgres",
-		Password: "clau",
-		DBName:   "inventory_office",
+	// Ctrl-C or SIGTERM cancels the context and with it any in-flight query
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	cmd, err := rootCmd.ExecuteContextC(ctx)
+	code := exitCode(cmd.Context(), err)
+	stop()
+	if cancelTimeout != nil {
+		cancelTimeout()
 	}
-
-	db, err := config.NewDatabase(cfg)
-	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+	if db != nil {
+		db.Close()
 	}
-	defer db.Close()
-
-	// Initialize repositories
-	categoryRepo := repository.NewCategoryRepository(db)
-	itemRepo := repository.NewItemRepository(db)
-
-	// Initialize services
-	categoryService := service.NewCategoryServiceWithRepo(categoryRepo)
-	itemService := service.NewItemServiceWithRepo(itemRepo, categoryRepo)
-
-	// Initialize handlers
-	categoryHandler = handler.NewCategoryHandler(categoryService)
-	itemHandler = handler.NewItemHandler(itemService)
-
-	// Execute root command
-	if err := rootCmd.Execute(); err != nil {
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(code)
 	}
 }
 
@@ -58,80 +58,268 @@ var rootCmd = &cobra.Command{
 	Use:   "inventory",
 	Short: "Sistem Inventaris Barang Kantor",
 	Long:  `Aplikasi CLI untuk mengelola inventaris barang kantor dengan fitur kategori, barang, dan laporan depresiasi.`,
+
+	SilenceUsage:  true,
+	SilenceErrors: true,
+
+	PersistentPreRunE: startCommand,
 }
 
 func init() {
+	// Run the root hook as well as the category/item/report/db hooks
+	cobra.EnableTraverseRunHooks = true
+
 	rootCmd.AddCommand(categoryCmd)
 	rootCmd.AddCommand(itemCmd)
 	rootCmd.AddCommand(reportCmd)
+	rootCmd.AddCommand(fxCmd)
+	rootCmd.AddCommand(trashCmd)
+	rootCmd.AddCommand(auditCmd)
+	rootCmd.AddCommand(configCmd)
+	rootCmd.AddCommand(dbCmd)
+
+	addGlobalFlags(rootCmd)
+}
+
+// addGlobalFlags adds the flags of every command to cmd; they take
+// precedence over INVENTORY_* variables and the config file
+func addGlobalFlags(cmd *cobra.Command) {
+	flags := cmd.PersistentFlags()
+	flags.String("config", "", "Config file (default $XDG_CONFIG_HOME/inventory/config.yaml)")
+	flags.StringP("output", "o", string(output.Table), "Output format of list, get and report commands: table, json, yaml, csv or tsv")
+	flags.String("locale", money.Indonesian.Tag, "Locale of amounts in table output: id-ID or en-US")
+	flags.String("as-of", "", "Report book values, days used and replacements as of this date (YYYY-MM-DD, default today)")
+	flags.Duration("timeout", 30*time.Second, "Abort the command when it takes longer than this (0 disables)")
+	flags.Bool("demo", false, "Use a temporary in-memory inventory with sample data instead of a database")
+	flags.String("profile", "", "Connection profile from the config file (default: current profile)")
+	flags.String("driver", "", "Storage backend: postgres (default) or sqlite")
+	flags.String("db-path", "", "SQLite database file (default $XDG_DATA_HOME/inventory/inventory.db)")
+	flags.String("db-host", "", "Database host")
+	flags.Int("db-port", 0, "Database port")
+	flags.String("db-user", "", "Database user")
+	flags.String("db-name", "", "Database name")
+	flags.String("db-sslmode", "", "Database sslmode (disable, require, verify-full, ...)")
+	flags.String("db-url", "", "Full database URL/DSN, overrides the other --db-* flags")
+}
+
+// loadConfig resolves the database configuration from file, environment and flags
+func loadConfig(cmd *cobra.Command) (config.Config, error) {
+	flags := cmd.Flags()
+	path, _ := flags.GetString("config")
+	profile, _ := flags.GetString("profile")
+
+	cfg, err := config.Load(path, profile)
+	if err != nil {
+		return cfg, err
+	}
+
+	if flags.Changed("driver") {
+		cfg.Driver, _ = flags.GetString("driver")
+	}
+	if flags.Changed("db-path") {
+		cfg.Path, _ = flags.GetString("db-path")
+	}
+	if flags.Changed("db-host") {
+		cfg.Host, _ = flags.GetString("db-host")
+	}
+	if flags.Changed("db-port") {
+		cfg.Port, _ = flags.GetInt("db-port")
+	}
+	if flags.Changed("db-user") {
+		cfg.User, _ = flags.GetString("db-user")
+	}
+	if flags.Changed("db-name") {
+		cfg.DBName, _ = flags.GetString("db-name")
+	}
+	if flags.Changed("db-sslmode") {
+		cfg.SSLMode, _ = flags.GetString("db-sslmode")
+	}
+	if flags.Changed("db-url") {
+		cfg.URL, _ = flags.GetString("db-url")
+	} else {
+		// a connection field flag replaces a url from the file or environment
+		for _, name := range []string{"db-host", "db-port", "db-user", "db-name", "db-sslmode"} {
+			if flags.Changed(name) {
+				cfg.URL = ""
+			}
+		}
+	}
+
+	return cfg, cfg.Validate()
+}
+
+// connectDB opens the database connection used by the command
+func connectDB(cmd *cobra.Command, args []string) error {
+	if demo, _ := cmd.Flags().GetBool("demo"); demo {
+		return fmt.Errorf("'%s' needs a real database and is not available with --demo", cmd.CommandPath())
+	}
+
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	db, err = config.NewDatabase(cmd.Context(), cfg)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	appConfig = cfg
+	return nil
+}
+
+// setupApp connects to the database (or builds the --demo store) and wires repositories, services and handlers
+func setupApp(cmd *cobra.Command, args []string) error {
+	var (
+		categoryRepo     service.CategoryRepositoryInterface
+		itemRepo         service.ItemRepositoryInterface
+		exchangeRateRepo service.ExchangeRateRepositoryInterface
+		journalRepo      service.JournalRepositoryInterface
+		auditRepo        service.AuditRepositoryInterface
+		txManager        service.TxManager
+	)
+
+	// Initialize repositories
+	if demo, _ := cmd.Flags().GetBool("demo"); demo {
+		repos, err := newDemoRepositories(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("failed to load demo data: %w", err)
+		}
+		categoryRepo, itemRepo, exchangeRateRepo, journalRepo = repos.categories, repos.items, repos.exchangeRates, repos.journals
+		auditRepo = repos.audit
+		txManager = service.NewMemoryTxManager(repos.tx)
+	} else {
+		if err := connectDB(cmd, args); err != nil {
+			return err
+		}
+		categoryRepo = repository.NewCategoryRepositoryWithDriver(db, appConfig.Driver)
+		itemRepo = repository.NewItemRepositoryWithDriver(db, appConfig.Driver)
+		exchangeRateRepo = repository.NewExchangeRateRepositoryWithDriver(db, appConfig.Driver)
+		journalRepo = repository.NewJournalRepository(db)
+		auditRepo = repository.NewAuditRepository(db)
+		txManager = service.NewSQLTxManager(repository.NewTxManager(db, appConfig.Driver))
+	}
+
+	// Initialize services
+	categoryService := service.NewCategoryService(categoryRepo)
+	categoryService.SetTxManager(txManager)
+	fxService := service.NewFXService(exchangeRateRepo)
+	fxService.SetTxManager(txManager)
+	itemService := service.NewItemService(itemRepo, categoryRepo)
+	itemService.SetTxManager(txManager)
+	itemService.SetConverter(fxService)
+	if !asOfDate.IsZero() {
+		itemService.SetClock(func() time.Time { return asOfDate })
+	}
+	journalService := service.NewJournalService(itemService, journalRepo)
+	trashService := service.NewTrashService(itemRepo, categoryRepo)
+	trashService.SetTxManager(txManager)
+	auditService := service.NewAuditService(auditRepo)
+
+	// Initialize handlers
+	categoryHandler = handler.NewCategoryHandler(categoryService, cmd.OutOrStdout(), outputFormat)
+	itemHandler = handler.NewItemHandler(itemService, cmd.OutOrStdout(), outputFormat)
+	itemHandler.SetLocale(outputLocale)
+	fxHandler = handler.NewFXHandler(fxService, cmd.OutOrStdout(), outputFormat)
+	journalHandler = handler.NewJournalHandler(journalService, cmd.OutOrStdout(), outputFormat)
+	journalHandler.SetLocale(outputLocale)
+	trashHandler = handler.NewTrashHandler(trashService, cmd.OutOrStdout(), outputFormat)
+	auditHandler = handler.NewAuditHandler(auditService, cmd.OutOrStdout(), outputFormat)
+
+	return nil
 }
 
 // ==================== CATEGORY COMMANDS ====================
 
 var categoryCmd = &cobra.Command{
-	Use:   "category",
-	Short: "Kelola kategori barang",
+	Use:               "category",
+	Short:             "Kelola kategori barang",
+	PersistentPreRunE: setupApp,
 }
 
 var categoryListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "Tampilkan semua kategori",
-	Run: func(cmd *cobra.Command, args []string) {
-		if err := categoryHandler.ListCategories(); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
-		}
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, err := categoryHandler.ListCategories(cmd.Context())
+		return err
 	},
 }
 
 var categoryGetCmd = &cobra.Command{
 	Use:   "get",
 	Short: "Tampilkan detail kategori berdasarkan ID",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		id, _ := cmd.Flags().GetInt("id")
-		if err := categoryHandler.GetCategory(id); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
-		}
+		_, err := categoryHandler.GetCategory(cmd.Context(), id)
+		return err
 	},
 }
 
 var categoryCreateCmd = &cobra.Command{
 	Use:   "create",
 	Short: "Tambah kategori baru",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		name, _ := cmd.Flags().GetString("name")
 		desc, _ := cmd.Flags().GetString("description")
-		if err := categoryHandler.CreateCategory(name, desc); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+		policy, err := policyFlags(cmd)
+		if err != nil {
+			return err
 		}
+		_, err = categoryHandler.CreateCategory(cmd.Context(), name, desc, policy, accountFlags(cmd))
+		return err
 	},
 }
 
 var categoryUpdateCmd = &cobra.Command{
 	Use:   "update",
-	Short: "Update kategori",
-	Run: func(cmd *cobra.Command, args []string) {
+	Short: "Update kategori (hanya field yang diberikan)",
+	RunE: func(cmd *cobra.Command, args []string) error {
 		id, _ := cmd.Flags().GetInt("id")
-		name, _ := cmd.Flags().GetString("name")
-		desc, _ := cmd.Flags().GetString("description")
-		if err := categoryHandler.UpdateCategory(id, name, desc); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+		flags := cmd.Flags()
+
+		var patch service.CategoryPatch
+		if flags.Changed("name") {
+			name, _ := flags.GetString("name")
+			patch.Name = &name
+		}
+		if flags.Changed("description") {
+			desc, _ := flags.GetString("description")
+			patch.Description = &desc
 		}
+		if clearDescription, _ := flags.GetBool("clear-description"); clearDescription {
+			empty := ""
+			patch.Description = &empty
+		}
+		policy, err := policyPatchFlags(cmd)
+		if err != nil {
+			return err
+		}
+		patch.Policy = policy
+		accountPatchFlags(cmd, &patch)
+
+		pre, err := preconditionFlags(cmd)
+		if err != nil {
+			return err
+		}
+		return categoryHandler.UpdateCategory(cmd.Context(), id, patch, pre)
 	},
 }
 
 var categoryDeleteCmd = &cobra.Command{
 	Use:   "delete",
-	Short: "Hapus kategori",
-	Run: func(cmd *cobra.Command, args []string) {
+	Short: "Hapus kategori (pindahkan ke tong sampah)",
+	RunE: func(cmd *cobra.Command, args []string) error {
 		id, _ := cmd.Flags().GetInt("id")
-		if err := categoryHandler.DeleteCategory(id); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
-		}
+		return categoryHandler.DeleteCategory(cmd.Context(), id)
+	},
+}
+
+var categoryRestoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Pulihkan kategori dari tong sampah",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, _ := cmd.Flags().GetInt("id")
+		return categoryHandler.RestoreCategory(cmd.Context(), id)
 	},
 }
 
@@ -141,6 +329,7 @@ func init() {
 	categoryCmd.AddCommand(categoryCreateCmd)
 	categoryCmd.AddCommand(categoryUpdateCmd)
 	categoryCmd.AddCommand(categoryDeleteCmd)
+	categoryCmd.AddCommand(categoryRestoreCmd)
 
 	// Flags for category commands
 	categoryGetCmd.Flags().IntP("id", "i", 0, "Category ID")
@@ -148,125 +337,219 @@ func init() {
 
 	categoryCreateCmd.Flags().StringP("name", "n", "", "Category name")
 	categoryCreateCmd.Flags().StringP("description", "d", "", "Category description")
+	addPolicyFlags(categoryCreateCmd, "declining-balance 20%")
+	addAccountFlags(categoryCreateCmd)
 	categoryCreateCmd.MarkFlagRequired("name")
 
 	categoryUpdateCmd.Flags().IntP("id", "i", 0, "Category ID")
 	categoryUpdateCmd.Flags().StringP("name", "n", "", "Category name")
 	categoryUpdateCmd.Flags().StringP("description", "d", "", "Category description")
+	addPolicyFlags(categoryUpdateCmd, "declining-balance 20%")
+	addAccountFlags(categoryUpdateCmd)
+	addPreconditionFlags(categoryUpdateCmd, "category")
+	categoryUpdateCmd.Flags().Bool("clear-description", false, "Clear the category description")
 	categoryUpdateCmd.MarkFlagRequired("id")
-	categoryUpdateCmd.MarkFlagRequired("name")
+	categoryUpdateCmd.MarkFlagsMutuallyExclusive("description", "clear-description")
 
 	categoryDeleteCmd.Flags().IntP("id", "i", 0, "Category ID")
 	categoryDeleteCmd.MarkFlagRequired("id")
+
+	categoryRestoreCmd.Flags().IntP("id", "i", 0, "Category ID")
+	categoryRestoreCmd.MarkFlagRequired("id")
 }
 
 // ==================== ITEM COMMANDS ====================
 
 var itemCmd = &cobra.Command{
-	Use:   "item",
-	Short: "Kelola barang inventaris",
+	Use:               "item",
+	Short:             "Kelola barang inventaris",
+	PersistentPreRunE: setupApp,
 }
 
 var itemListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "Tampilkan semua barang",
-	Run: func(cmd *cobra.Command, args []string) {
-		if err := itemHandler.ListItems(); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
-		}
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, err := itemHandler.ListItems(cmd.Context())
+		return err
 	},
 }
 
 var itemGetCmd = &cobra.Command{
 	Use:   "get",
 	Short: "Tampilkan detail barang berdasarkan ID",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		id, _ := cmd.Flags().GetInt("id")
-		if err := itemHandler.GetItem(id); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
-		}
+		_, err := itemHandler.GetItem(cmd.Context(), id)
+		return err
 	},
 }
 
 var itemCreateCmd = &cobra.Command{
 	Use:   "create",
 	Short: "Tambah barang baru",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		name, _ := cmd.Flags().GetString("name")
 		categoryID, _ := cmd.Flags().GetInt("category")
-		price, _ := cmd.Flags().GetFloat64("price")
+		priceStr, _ := cmd.Flags().GetString("price")
+		currency, _ := cmd.Flags().GetString("currency")
 		dateStr, _ := cmd.Flags().GetString("date")
 
-		purchaseDate, err := time.Parse("2006-01-02", dateStr)
+		price, err := parseMoney("price", priceStr)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: invalid date format (use YYYY-MM-DD): %v\n", err)
-			os.Exit(1)
+			return err
 		}
-
-		if err := itemHandler.CreateItem(name, categoryID, price, purchaseDate); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+		purchaseDate, err := parseDate("date", dateStr)
+		if err != nil {
+			return err
+		}
+		policy, err := policyFlags(cmd)
+		if err != nil {
+			return err
 		}
+
+		_, err = itemHandler.CreateItem(cmd.Context(), name, categoryID, price, currency, purchaseDate, policy)
+		return err
 	},
 }
 
 var itemUpdateCmd = &cobra.Command{
 	Use:   "update",
-	Short: "Update barang",
-	Run: func(cmd *cobra.Command, args []string) {
+	Short: "Update barang (hanya field yang diberikan)",
+	RunE: func(cmd *cobra.Command, args []string) error {
 		id, _ := cmd.Flags().GetInt("id")
-		name, _ := cmd.Flags().GetString("name")
-		categoryID, _ := cmd.Flags().GetInt("category")
-		price, _ := cmd.Flags().GetFloat64("price")
-		dateStr, _ := cmd.Flags().GetString("date")
+		flags := cmd.Flags()
 
-		purchaseDate, err := time.Parse("2006-01-02", dateStr)
+		var patch service.ItemPatch
+		if flags.Changed("name") {
+			name, _ := flags.GetString("name")
+			patch.Name = &name
+		}
+		if flags.Changed("category") {
+			categoryID, _ := flags.GetInt("category")
+			patch.CategoryID = &categoryID
+		}
+		if flags.Changed("price") {
+			priceStr, _ := flags.GetString("price")
+			price, err := parseMoney("price", priceStr)
+			if err != nil {
+				return err
+			}
+			patch.Price = &price
+		}
+		if flags.Changed("currency") {
+			currency, _ := flags.GetString("currency")
+			patch.Currency = &currency
+		}
+		if flags.Changed("date") {
+			dateStr, _ := flags.GetString("date")
+			purchaseDate, err := parseDate("date", dateStr)
+			if err != nil {
+				return err
+			}
+			patch.PurchaseDate = &purchaseDate
+		}
+		policy, err := policyPatchFlags(cmd)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: invalid date format (use YYYY-MM-DD): %v\n", err)
-			os.Exit(1)
+			return err
 		}
+		patch.Policy = policy
 
-		if err := itemHandler.UpdateItem(id, name, categoryID, price, purchaseDate); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+		pre, err := preconditionFlags(cmd)
+		if err != nil {
+			return err
 		}
+		return itemHandler.UpdateItem(cmd.Context(), id, patch, pre)
 	},
 }
 
 var itemDeleteCmd = &cobra.Command{
 	Use:   "delete",
-	Short: "Hapus barang",
-	Run: func(cmd *cobra.Command, args []string) {
+	Short: "Hapus barang (pindahkan ke tong sampah)",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, _ := cmd.Flags().GetInt("id")
+		return itemHandler.DeleteItem(cmd.Context(), id)
+	},
+}
+
+var itemRestoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Pulihkan barang dari tong sampah",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, _ := cmd.Flags().GetInt("id")
+		return itemHandler.RestoreItem(cmd.Context(), id)
+	},
+}
+
+var itemDisposeCmd = &cobra.Command{
+	Use:   "dispose",
+	Short: "Catat penjualan, pembuangan atau sumbangan barang",
+	RunE: func(cmd *cobra.Command, args []string) error {
 		id, _ := cmd.Flags().GetInt("id")
-		if err := itemHandler.DeleteItem(id); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+		dateStr, _ := cmd.Flags().GetString("date")
+		method, _ := cmd.Flags().GetString("method")
+		proceedsStr, _ := cmd.Flags().GetString("proceeds")
+
+		date, err := parseDate("date", dateStr)
+		if err != nil {
+			return err
+		}
+		proceeds, err := parseMoney("proceeds", proceedsStr)
+		if err != nil {
+			return err
+		}
+
+		_, err = itemHandler.DisposeItem(cmd.Context(), id, date, method, proceeds)
+		return err
+	},
+}
+
+var itemImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Impor barang dari file CSV atau XLSX, satu barang per baris",
+	Long: `Impor barang dari file CSV atau sheet pertama file XLSX. Baris pertama adalah
+header; kolom dicari berdasarkan nama field (` + strings.Join(service.ImportFields, ", ") + `)
+atau dipetakan dengan --map. Kategori dicari berdasarkan nama. Setiap baris
+divalidasi seperti item create; secara default satu baris tidak valid
+membatalkan seluruh impor.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, _ := cmd.Flags().GetString("file")
+		columns, _ := cmd.Flags().GetStringToString("map")
+		createCategories, _ := cmd.Flags().GetBool("create-categories")
+		skipInvalid, _ := cmd.Flags().GetBool("skip-invalid")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		rows, err := spreadsheet.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open items file: %w", err)
 		}
+
+		_, err = itemHandler.ImportItems(cmd.Context(), rows, service.ImportOptions{
+			Columns:          columns,
+			CreateCategories: createCategories,
+			SkipInvalid:      skipInvalid,
+			DryRun:           dryRun,
+		})
+		return err
 	},
 }
 
 var itemSearchCmd = &cobra.Command{
 	Use:   "search",
 	Short: "Cari barang berdasarkan nama",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		keyword, _ := cmd.Flags().GetString("keyword")
-		if err := itemHandler.SearchItems(keyword); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
-		}
+		_, err := itemHandler.SearchItems(cmd.Context(), keyword)
+		return err
 	},
 }
 
 var itemReplacementCmd = &cobra.Command{
 	Use:   "replacement",
 	Short: "Tampilkan barang yang perlu diganti (> 100 hari)",
-	Run: func(cmd *cobra.Command, args []string) {
-		if err := itemHandler.ListItemsNeedReplacement(); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
-		}
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, err := itemHandler.ListItemsNeedReplacement(cmd.Context())
+		return err
 	},
 }
 
@@ -276,6 +559,9 @@ func init() {
 	itemCmd.AddCommand(itemCreateCmd)
 	itemCmd.AddCommand(itemUpdateCmd)
 	itemCmd.AddCommand(itemDeleteCmd)
+	itemCmd.AddCommand(itemRestoreCmd)
+	itemCmd.AddCommand(itemDisposeCmd)
+	itemCmd.AddCommand(itemImportCmd)
 	itemCmd.AddCommand(itemSearchCmd)
 	itemCmd.AddCommand(itemReplacementCmd)
 
@@ -285,8 +571,10 @@ func init() {
 
 	itemCreateCmd.Flags().StringP("name", "n", "", "Item name")
 	itemCreateCmd.Flags().IntP("category", "c", 0, "Category ID")
-	itemCreateCmd.Flags().Float64P("price", "p", 0, "Item price")
+	itemCreateCmd.Flags().StringP("price", "p", "", "Item price, up to 2 decimal places (e.g. 1500000.50)")
+	itemCreateCmd.Flags().String("currency", service.BaseCurrency, "ISO 4217 currency of the price (e.g. USD)")
 	itemCreateCmd.Flags().StringP("date", "d", "", "Purchase date (YYYY-MM-DD)")
+	addPolicyFlags(itemCreateCmd, "the category's")
 	itemCreateCmd.MarkFlagRequired("name")
 	itemCreateCmd.MarkFlagRequired("category")
 	itemCreateCmd.MarkFlagRequired("price")
@@ -295,17 +583,34 @@ func init() {
 	itemUpdateCmd.Flags().IntP("id", "i", 0, "Item ID")
 	itemUpdateCmd.Flags().StringP("name", "n", "", "Item name")
 	itemUpdateCmd.Flags().IntP("category", "c", 0, "Category ID")
-	itemUpdateCmd.Flags().Float64P("price", "p", 0, "Item price")
+	itemUpdateCmd.Flags().StringP("price", "p", "", "Item price, up to 2 decimal places (e.g. 1500000.50)")
+	itemUpdateCmd.Flags().String("currency", service.BaseCurrency, "ISO 4217 currency of the price (e.g. USD)")
 	itemUpdateCmd.Flags().StringP("date", "d", "", "Purchase date (YYYY-MM-DD)")
+	addPolicyFlags(itemUpdateCmd, "the category's")
+	addPreconditionFlags(itemUpdateCmd, "item")
 	itemUpdateCmd.MarkFlagRequired("id")
-	itemUpdateCmd.MarkFlagRequired("name")
-	itemUpdateCmd.MarkFlagRequired("category")
-	itemUpdateCmd.MarkFlagRequired("price")
-	itemUpdateCmd.MarkFlagRequired("date")
 
 	itemDeleteCmd.Flags().IntP("id", "i", 0, "Item ID")
 	itemDeleteCmd.MarkFlagRequired("id")
 
+	itemRestoreCmd.Flags().IntP("id", "i", 0, "Item ID")
+	itemRestoreCmd.MarkFlagRequired("id")
+
+	itemDisposeCmd.Flags().IntP("id", "i", 0, "Item ID")
+	itemDisposeCmd.Flags().StringP("date", "d", "", "Disposal date (YYYY-MM-DD); depreciation stops on this date")
+	itemDisposeCmd.Flags().String("method", "", "How the item left: "+strings.Join(service.DisposalMethods, ", "))
+	itemDisposeCmd.Flags().String("proceeds", "0", "Amount received in the currency of the price, up to 2 decimal places")
+	itemDisposeCmd.MarkFlagRequired("id")
+	itemDisposeCmd.MarkFlagRequired("date")
+	itemDisposeCmd.MarkFlagRequired("method")
+
+	itemImportCmd.Flags().StringP("file", "f", "", "CSV or XLSX file with a header row")
+	itemImportCmd.Flags().StringToString("map", nil, "Column of a field when its header differs from the field name, e.g. name=\"Nama Barang\",price=Harga")
+	itemImportCmd.Flags().Bool("create-categories", false, "Create the categories the file names that do not exist yet")
+	itemImportCmd.Flags().Bool("skip-invalid", false, "Import the valid rows and skip the invalid ones, instead of importing nothing")
+	itemImportCmd.Flags().Bool("dry-run", false, "Validate every row and print the report without storing anything")
+	itemImportCmd.MarkFlagRequired("file")
+
 	itemSearchCmd.Flags().StringP("keyword", "k", "", "Search keyword")
 	itemSearchCmd.MarkFlagRequired("keyword")
 }
@@ -313,37 +618,77 @@ func init() {
 // ==================== REPORT COMMANDS ====================
 
 var reportCmd = &cobra.Command{
-	Use:   "report",
-	Short: "Laporan investasi dan depresiasi",
+	Use:               "report",
+	Short:             "Laporan investasi dan depresiasi",
+	PersistentPreRunE: setupApp,
 }
 
 var reportTotalCmd = &cobra.Command{
 	Use:   "total",
 	Short: "Tampilkan total investasi dan depresiasi",
-	Run: func(cmd *cobra.Command, args []string) {
-		if err := itemHandler.ShowTotalInvestment(); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
-		}
+	RunE: func(cmd *cobra.Command, args []string) error {
+		currency, _ := cmd.Flags().GetString("currency")
+		_, err := itemHandler.ShowTotalInvestment(cmd.Context(), currency)
+		return err
 	},
 }
 
 var reportItemCmd = &cobra.Command{
 	Use:   "item",
 	Short: "Tampilkan laporan depresiasi barang tertentu",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		id, _ := cmd.Flags().GetInt("id")
-		if err := itemHandler.ShowItemDepreciation(id); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
-		}
+		currency, _ := cmd.Flags().GetString("currency")
+		_, err := itemHandler.ShowItemDepreciation(cmd.Context(), id, currency)
+		return err
+	},
+}
+
+var reportFiscalCmd = &cobra.Command{
+	Use:   "fiscal",
+	Short: "Bandingkan nilai buku komersial dan fiskal per barang",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		currency, _ := cmd.Flags().GetString("currency")
+		_, err := itemHandler.ShowFiscalReport(cmd.Context(), currency)
+		return err
+	},
+}
+
+var reportDisposalsCmd = &cobra.Command{
+	Use:   "disposals",
+	Short: "Tampilkan barang yang dilepas beserta laba/rugi pelepasannya",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		currency, _ := cmd.Flags().GetString("currency")
+		_, err := itemHandler.ShowDisposalReport(cmd.Context(), currency)
+		return err
+	},
+}
+
+var reportScheduleCmd = &cobra.Command{
+	Use:   "schedule",
+	Short: "Tampilkan jadwal depresiasi barang per bulan atau per tahun",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, _ := cmd.Flags().GetInt("id")
+		currency, _ := cmd.Flags().GetString("currency")
+		period, _ := cmd.Flags().GetString("period")
+		_, err := itemHandler.ShowDepreciationSchedule(cmd.Context(), id, currency, period)
+		return err
 	},
 }
 
 func init() {
 	reportCmd.AddCommand(reportTotalCmd)
 	reportCmd.AddCommand(reportItemCmd)
+	reportCmd.AddCommand(reportFiscalCmd)
+	reportCmd.AddCommand(reportScheduleCmd)
+	reportCmd.AddCommand(reportDisposalsCmd)
+
+	reportCmd.PersistentFlags().String("currency", service.BaseCurrency, "Reporting currency; amounts are converted at the rate of each purchase date")
 
 	reportItemCmd.Flags().IntP("id", "i", 0, "Item ID")
 	reportItemCmd.MarkFlagRequired("id")
+
+	reportScheduleCmd.Flags().IntP("id", "i", 0, "Item ID")
+	reportScheduleCmd.Flags().String("period", service.PeriodYear, "Length of a schedule row: month or year")
+	reportScheduleCmd.MarkFlagRequired("id")
 }
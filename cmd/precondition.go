@@ -0,0 +1,28 @@
+package main
+
+import (
+	"mini_project3/service"
+
+	"github.com/spf13/cobra"
+)
+
+// addPreconditionFlags adds the flags that make an update of entity fail
+// when someone else changed it since it was read
+func addPreconditionFlags(cmd *cobra.Command, entity string) {
+	cmd.Flags().Int("if-version", 0, "Only update while the "+entity+" is still at this version, as shown by get")
+	cmd.Flags().String("expect-updated-at", "", "Only update while the "+entity+" was last updated at this time, as shown by get (YYYY-MM-DD HH:MM:SS or RFC 3339)")
+}
+
+// preconditionFlags reads the flags added by addPreconditionFlags
+func preconditionFlags(cmd *cobra.Command) (service.Precondition, error) {
+	version, _ := cmd.Flags().GetInt("if-version")
+	pre := service.Precondition{Version: version}
+	if value, _ := cmd.Flags().GetString("expect-updated-at"); value != "" {
+		updatedAt, err := parseTimestamp("expect-updated-at", value)
+		if err != nil {
+			return pre, err
+		}
+		pre.UpdatedAt = updatedAt
+	}
+	return pre, nil
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// ==================== TRASH COMMANDS ====================
+
+var trashCmd = &cobra.Command{
+	Use:               "trash",
+	Short:             "Kelola barang dan kategori yang dihapus",
+	PersistentPreRunE: setupApp,
+}
+
+var trashListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Tampilkan barang dan kategori di tong sampah",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, err := trashHandler.ListTrash(cmd.Context())
+		return err
+	},
+}
+
+var trashPurgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Hapus permanen isi tong sampah yang sudah lama",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		olderThan, _ := cmd.Flags().GetString("older-than")
+		_, err := trashHandler.PurgeTrash(cmd.Context(), olderThan)
+		return err
+	},
+}
+
+func init() {
+	trashCmd.AddCommand(trashListCmd)
+	trashCmd.AddCommand(trashPurgeCmd)
+
+	trashPurgeCmd.Flags().String("older-than", "", "Only purge what was deleted longer ago than this, in days (90d) or hours (12h)")
+	trashPurgeCmd.MarkFlagRequired("older-than")
+}
@@ -1,36 +1,143 @@
 package config
 
 import (
+    "context"
     "database/sql"
     "fmt"
     "log"
+    "os"
+    "path/filepath"
+    "strings"
+    "time"
+
+    "mini_project3/apperrors"
 
     _ "github.com/lib/pq"
 )
 
+// Supported values of Config.Driver, also the names passed to sql.Open
+const (
+    DriverPostgres = "postgres"
+    DriverSQLite   = "sqlite"
+)
+
 type Config struct {
-    Host     string
-    Port     int
-    User     string
-    Password string
-    DBName   string
+    // Driver selects the storage backend: postgres (default) or sqlite
+    Driver string `yaml:"driver,omitempty" toml:"driver,omitempty"`
+    // Path is the database file used by the sqlite driver
+    Path string `yaml:"path,omitempty" toml:"path,omitempty"`
+
+    Host     string `yaml:"host,omitempty" toml:"host,omitempty"`
+    Port     int    `yaml:"port,omitempty" toml:"port,omitzero"`
+    User     string `yaml:"user,omitempty" toml:"user,omitempty"`
+    Password string `yaml:"password,omitempty" toml:"password,omitempty"`
+    DBName   string `yaml:"dbname,omitempty" toml:"dbname,omitempty"`
+    SSLMode  string `yaml:"sslmode,omitempty" toml:"sslmode,omitempty"`
+
+    // URL is a full connection string (postgres://... or key=value DSN).
+    // When set it takes precedence over the individual fields above.
+    URL string `yaml:"url,omitempty" toml:"url,omitempty"`
+
+    MaxOpenConns    int           `yaml:"max_open_conns,omitempty" toml:"max_open_conns,omitzero"`
+    MaxIdleConns    int           `yaml:"max_idle_conns,omitempty" toml:"max_idle_conns,omitzero"`
+    ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime,omitempty" toml:"conn_max_lifetime,omitzero"`
 }
 
-func NewDatabase(cfg Config) (*sql.DB, error) {
-    dsn := fmt.Sprintf(
-        "host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
-        cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.DBName,
+// DSN returns the connection string passed to the selected driver
+func (c Config) DSN() string {
+    if c.URL != "" {
+        return c.URL
+    }
+    if c.Driver == DriverSQLite {
+        return sqliteDSN(c.SQLitePath())
+    }
+
+    parts := []string{
+        "host=" + quoteDSNValue(c.Host),
+        fmt.Sprintf("port=%d", c.Port),
+        "user=" + quoteDSNValue(c.User),
+    }
+    if c.Password != "" {
+        parts = append(parts, "password="+quoteDSNValue(c.Password))
+    }
+    parts = append(parts,
+        "dbname="+quoteDSNValue(c.DBName),
+        "sslmode="+quoteDSNValue(c.SSLMode),
     )
+    return strings.Join(parts, " ")
+}
+
+// quoteDSNValue quotes a key=value DSN value when it contains spaces, quotes or backslashes
+func quoteDSNValue(value string) string {
+    if value != "" && !strings.ContainsAny(value, ` '\`) {
+        return value
+    }
+    value = strings.ReplaceAll(value, `\`, `\\`)
+    value = strings.ReplaceAll(value, `'`, `\'`)
+    return "'" + value + "'"
+}
+
+func NewDatabase(ctx context.Context, cfg Config) (*sql.DB, error) {
+    if cfg.Driver == DriverSQLite && cfg.URL == "" {
+        if err := os.MkdirAll(filepath.Dir(cfg.SQLitePath()), 0o700); err != nil {
+            return nil, fmt.Errorf("error creating database directory: %w", err)
+        }
+    }
 
-    db, err := sql.Open("postgres", dsn)
+    db, err := sql.Open(cfg.Driver, cfg.DSN())
     if err != nil {
         return nil, fmt.Errorf("error opening database: %w", err)
     }
 
-    if err := db.Ping(); err != nil {
-        return nil, fmt.Errorf("error connecting to database: %w", err)
+    db.SetMaxOpenConns(cfg.MaxOpenConns)
+    db.SetMaxIdleConns(cfg.MaxIdleConns)
+    db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+
+    // lib/pq honours ctx while dialing but not during the startup handshake,
+    // so a server that accepts the connection and never answers would block
+    // the ping past the deadline
+    pinged := make(chan error, 1)
+    go func() { pinged <- db.PingContext(ctx) }()
+    select {
+    case err = <-pinged:
+    case <-ctx.Done():
+        err = ctx.Err()
+    }
+    if err != nil {
+        db.Close()
+        return nil, fmt.Errorf("error connecting to database: %w", &apperrors.DatabaseUnavailableError{Err: err})
     }
 
     log.Println("Database connected successfully")
     return db, nil
-}
\ No newline at end of file
+}
+
+// ConnectionInfo describes a successful connection made by CheckConnection
+type ConnectionInfo struct {
+    ServerVersion string
+    Latency       time.Duration
+}
+
+// CheckConnection connects with NewDatabase and reports the server product and version
+// and the time taken to connect and ping
+func CheckConnection(ctx context.Context, cfg Config) (*ConnectionInfo, error) {
+    start := time.Now()
+    db, err := NewDatabase(ctx, cfg)
+    if err != nil {
+        return nil, err
+    }
+    defer db.Close()
+    latency := time.Since(start)
+
+    product, query := "PostgreSQL", "SHOW server_version"
+    if cfg.Driver == DriverSQLite {
+        product, query = "SQLite", "SELECT sqlite_version()"
+    }
+
+    var version string
+    if err := db.QueryRowContext(ctx, query).Scan(&version); err != nil {
+        return nil, fmt.Errorf("error querying server version: %w", err)
+    }
+
+    return &ConnectionInfo{ServerVersion: product + " " + version, Latency: latency}, nil
+}
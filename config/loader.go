@@ -0,0 +1,468 @@
+package config
+
+import (
+    "errors"
+    "fmt"
+    "os"
+    "path/filepath"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/BurntSushi/toml"
+    "gopkg.in/yaml.v3"
+)
+
+// EnvPrefix is the prefix of every environment variable read by Load
+const EnvPrefix = "INVENTORY_"
+
+// File is the layout of the config file: base settings shared by every
+// profile, the named profiles and the profile selected by `config use`
+type File struct {
+    Config         `yaml:",inline"`
+    CurrentProfile string            `yaml:"current_profile,omitempty" toml:"current_profile,omitempty"`
+    Profiles       map[string]Config `yaml:"profiles,omitempty" toml:"profiles,omitempty"`
+
+    // present holds the keys written in the file or set with Set, per
+    // profile and "" for the base settings, so that a written zero value
+    // such as `sslmode: ""` overrides the base settings and the defaults
+    present map[string]map[string]bool
+}
+
+// field describes one configurable setting, its config key and environment variable
+type field struct {
+    key string
+    env string
+}
+
+var fields = []field{
+    {"driver", "DB_DRIVER"},
+    {"path", "DB_PATH"},
+    {"host", "DB_HOST"},
+    {"port", "DB_PORT"},
+    {"user", "DB_USER"},
+    {"password", "DB_PASSWORD"},
+    {"dbname", "DB_NAME"},
+    {"sslmode", "DB_SSLMODE"},
+    {"url", "DB_URL"},
+    {"max_open_conns", "DB_MAX_OPEN_CONNS"},
+    {"max_idle_conns", "DB_MAX_IDLE_CONNS"},
+    {"conn_max_lifetime", "DB_CONN_MAX_LIFETIME"},
+}
+
+// URLFields are the settings a url replaces. Setting one of them with a higher
+// precedence than the url, such as INVENTORY_DB_HOST over a url in the file,
+// drops the url, so that the more explicit setting is not silently ignored.
+var URLFields = []string{"host", "port", "user", "dbname", "sslmode"}
+
+// Keys returns the setting names accepted by Set and Get
+func Keys() []string {
+    keys := make([]string, len(fields))
+    for i, f := range fields {
+        keys[i] = f.key
+    }
+    return keys
+}
+
+// DefaultConfig returns the settings used when nothing else is configured
+func DefaultConfig() Config {
+    return Config{
+        Driver:          DriverPostgres,
+        Host:            "localhost",
+        Port:            5432,
+        User:            "postgres",
+        DBName:          "inventory_office",
+        SSLMode:         "disable",
+        MaxOpenConns:    10,
+        MaxIdleConns:    5,
+        ConnMaxLifetime: 30 * time.Minute,
+    }
+}
+
+// DefaultConfigDir returns the XDG config directory of the application ($XDG_CONFIG_HOME/inventory)
+func DefaultConfigDir() (string, error) {
+    dir, err := os.UserConfigDir()
+    if err != nil {
+        return "", fmt.Errorf("error locating config directory: %w", err)
+    }
+    return filepath.Join(dir, "inventory"), nil
+}
+
+// ResolvePath returns the config file to use: the given path, INVENTORY_CONFIG,
+// the first existing config.yaml, config.yml or config.toml in the config
+// directory, or config.yaml there when none exists yet
+func ResolvePath(path string) (string, error) {
+    if path != "" {
+        return path, nil
+    }
+    if env := os.Getenv(EnvPrefix + "CONFIG"); env != "" {
+        return env, nil
+    }
+
+    dir, err := DefaultConfigDir()
+    if err != nil {
+        return "", err
+    }
+    for _, name := range []string{"config.yaml", "config.yml", "config.toml"} {
+        candidate := filepath.Join(dir, name)
+        if _, err := os.Stat(candidate); err == nil {
+            return candidate, nil
+        }
+    }
+    return filepath.Join(dir, "config.yaml"), nil
+}
+
+// Load builds the configuration from the defaults, the config file (base
+// settings, then the selected profile) and the INVENTORY_* environment
+// variables, in increasing order of precedence. An empty profile means
+// INVENTORY_PROFILE or the current_profile stored in the file.
+func Load(path, profile string) (Config, error) {
+    path, err := ResolvePath(path)
+    if err != nil {
+        return DefaultConfig(), err
+    }
+
+    file, err := ReadFile(path)
+    if err != nil {
+        return DefaultConfig(), err
+    }
+
+    if profile == "" {
+        profile = os.Getenv(EnvPrefix + "PROFILE")
+    }
+
+    cfg, err := file.Resolve(profile)
+    if err != nil {
+        return cfg, err
+    }
+
+    if err := ApplyEnv(&cfg, os.LookupEnv); err != nil {
+        return cfg, err
+    }
+
+    return cfg, nil
+}
+
+// ReadFile decodes a YAML or TOML file (chosen by extension). A missing file
+// yields an empty File so that `config set` can create it.
+func ReadFile(path string) (*File, error) {
+    file := &File{}
+
+    data, err := os.ReadFile(path)
+    if err != nil {
+        if errors.Is(err, os.ErrNotExist) {
+            return file, nil
+        }
+        return nil, fmt.Errorf("error reading config file: %w", err)
+    }
+
+    switch strings.ToLower(filepath.Ext(path)) {
+    case ".yaml", ".yml":
+        err = yaml.Unmarshal(data, file)
+    case ".toml":
+        err = toml.Unmarshal(data, file)
+    default:
+        return nil, fmt.Errorf("unsupported config file format: %s", path)
+    }
+    if err != nil {
+        return nil, fmt.Errorf("error parsing config file %s: %w", path, err)
+    }
+
+    var raw map[string]interface{}
+    if strings.ToLower(filepath.Ext(path)) == ".toml" {
+        err = toml.Unmarshal(data, &raw)
+    } else {
+        err = yaml.Unmarshal(data, &raw)
+    }
+    if err != nil {
+        return nil, fmt.Errorf("error parsing config file %s: %w", path, err)
+    }
+    file.markPresent("", raw)
+    if profiles, ok := raw["profiles"].(map[string]interface{}); ok {
+        for name, profile := range profiles {
+            if settings, ok := profile.(map[string]interface{}); ok {
+                file.markPresent(name, settings)
+            }
+        }
+    }
+    return file, nil
+}
+
+// markPresent records the setting keys of raw as written for profile
+func (f *File) markPresent(profile string, raw map[string]interface{}) {
+    for _, field := range fields {
+        if _, ok := raw[field.key]; ok {
+            f.markKey(profile, field.key)
+        }
+    }
+}
+
+func (f *File) markKey(profile, key string) {
+    if f.present == nil {
+        f.present = map[string]map[string]bool{}
+    }
+    if f.present[profile] == nil {
+        f.present[profile] = map[string]bool{}
+    }
+    f.present[profile][key] = true
+}
+
+// Set assigns a setting of profile, or of the base settings when profile is
+// empty. The value is kept even when it is zero, like a value written in the file.
+func (f *File) Set(profile, key, value string) error {
+    if profile == "" {
+        if err := f.Config.Set(key, value); err != nil {
+            return err
+        }
+    } else {
+        if f.Profiles == nil {
+            f.Profiles = map[string]Config{}
+        }
+        cfg := f.Profiles[profile]
+        if err := cfg.Set(key, value); err != nil {
+            return err
+        }
+        f.Profiles[profile] = cfg
+    }
+    f.markKey(profile, key)
+    return nil
+}
+
+// Save writes the file back in the format given by the extension of path
+func (f *File) Save(path string) error {
+    var (
+        data []byte
+        err  error
+    )
+
+    switch strings.ToLower(filepath.Ext(path)) {
+    case ".yaml", ".yml":
+        data, err = yaml.Marshal(f.document())
+    case ".toml":
+        var sb strings.Builder
+        err = toml.NewEncoder(&sb).Encode(f.document())
+        data = []byte(sb.String())
+    default:
+        return fmt.Errorf("unsupported config file format: %s", path)
+    }
+    if err != nil {
+        return fmt.Errorf("error encoding config file: %w", err)
+    }
+
+    if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+        return fmt.Errorf("error creating config directory: %w", err)
+    }
+    if err := os.WriteFile(path, data, 0o600); err != nil {
+        return fmt.Errorf("error writing config file: %w", err)
+    }
+    return nil
+}
+
+// document returns the contents of the file as written by Save: the settings
+// that are set or present, so that written zero values survive a save
+func (f *File) document() map[string]interface{} {
+    doc := settingsOf(f.Config, f.present[""])
+    if f.CurrentProfile != "" {
+        doc["current_profile"] = f.CurrentProfile
+    }
+    if len(f.Profiles) > 0 {
+        profiles := map[string]interface{}{}
+        for name, profile := range f.Profiles {
+            profiles[name] = settingsOf(profile, f.present[name])
+        }
+        doc["profiles"] = profiles
+    }
+    return doc
+}
+
+func settingsOf(cfg Config, present map[string]bool) map[string]interface{} {
+    settings := map[string]interface{}{}
+    for _, f := range fields {
+        value := cfg.Get(f.key)
+        if !present[f.key] && isZero(value) {
+            continue
+        }
+        switch f.key {
+        case "port", "max_open_conns", "max_idle_conns":
+            settings[f.key], _ = strconv.Atoi(value)
+        default:
+            settings[f.key] = value
+        }
+    }
+    return settings
+}
+
+// isZero reports whether a value returned by Config.Get is the zero value of its setting
+func isZero(value string) bool {
+    return value == "" || value == "0" || value == "0s"
+}
+
+// ProfileNames returns the profile names in alphabetical order
+func (f *File) ProfileNames() []string {
+    names := make([]string, 0, len(f.Profiles))
+    for name := range f.Profiles {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    return names
+}
+
+// Resolve returns the defaults overlaid with the base settings and the given
+// profile (or the current profile when name is empty)
+func (f *File) Resolve(name string) (Config, error) {
+    cfg := DefaultConfig()
+    merge(&cfg, f.Config, f.present[""])
+
+    if name == "" {
+        name = f.CurrentProfile
+    }
+    if name == "" {
+        return cfg, nil
+    }
+
+    profile, ok := f.Profiles[name]
+    if !ok {
+        return cfg, fmt.Errorf("profile '%s' not found", name)
+    }
+    merge(&cfg, profile, f.present[name])
+    return cfg, nil
+}
+
+// merge copies the settings of src that are present or non-zero into dst
+func merge(dst *Config, src Config, present map[string]bool) {
+    for _, f := range fields {
+        if value := src.Get(f.key); present[f.key] || !isZero(value) {
+            dst.Set(f.key, value)
+        }
+    }
+}
+
+// ApplyEnv overrides cfg with the INVENTORY_DB_* variables returned by lookup.
+// A variable of URLFields drops the url of the file unless INVENTORY_DB_URL is set too.
+func ApplyEnv(cfg *Config, lookup func(string) (string, bool)) error {
+    set := map[string]bool{}
+    for _, f := range fields {
+        value, ok := lookup(EnvPrefix + f.env)
+        if !ok {
+            continue
+        }
+        if err := cfg.Set(f.key, value); err != nil {
+            return fmt.Errorf("invalid %s%s: %w", EnvPrefix, f.env, err)
+        }
+        set[f.key] = true
+    }
+    if !set["url"] {
+        for _, key := range URLFields {
+            if set[key] {
+                cfg.URL = ""
+            }
+        }
+    }
+    return nil
+}
+
+// Set assigns a setting by its config key, parsing numbers and durations
+func (c *Config) Set(key, value string) error {
+    switch key {
+    case "driver":
+        c.Driver = value
+    case "path":
+        c.Path = value
+    case "host":
+        c.Host = value
+    case "user":
+        c.User = value
+    case "password":
+        c.Password = value
+    case "dbname":
+        c.DBName = value
+    case "sslmode":
+        c.SSLMode = value
+    case "url":
+        c.URL = value
+    case "port", "max_open_conns", "max_idle_conns":
+        n, err := strconv.Atoi(value)
+        if err != nil {
+            return fmt.Errorf("%s must be a number: %w", key, err)
+        }
+        switch key {
+        case "port":
+            c.Port = n
+        case "max_open_conns":
+            c.MaxOpenConns = n
+        default:
+            c.MaxIdleConns = n
+        }
+    case "conn_max_lifetime":
+        d, err := time.ParseDuration(value)
+        if err != nil {
+            return fmt.Errorf("%s must be a duration: %w", key, err)
+        }
+        c.ConnMaxLifetime = d
+    default:
+        return fmt.Errorf("unknown config key '%s' (valid: %s)", key, strings.Join(Keys(), ", "))
+    }
+    return nil
+}
+
+// Get returns a setting by its config key formatted as a string
+func (c Config) Get(key string) string {
+    switch key {
+    case "driver":
+        return c.Driver
+    case "path":
+        return c.Path
+    case "host":
+        return c.Host
+    case "port":
+        return strconv.Itoa(c.Port)
+    case "user":
+        return c.User
+    case "password":
+        return c.Password
+    case "dbname":
+        return c.DBName
+    case "sslmode":
+        return c.SSLMode
+    case "url":
+        return c.URL
+    case "max_open_conns":
+        return strconv.Itoa(c.MaxOpenConns)
+    case "max_idle_conns":
+        return strconv.Itoa(c.MaxIdleConns)
+    case "conn_max_lifetime":
+        return c.ConnMaxLifetime.String()
+    }
+    return ""
+}
+
+// Validate checks the settings that the driver would otherwise reject late
+func (c Config) Validate() error {
+    switch c.Driver {
+    case DriverPostgres:
+    case DriverSQLite:
+        return nil
+    default:
+        return fmt.Errorf("invalid driver: %s (valid: %s, %s)", c.Driver, DriverPostgres, DriverSQLite)
+    }
+
+    if c.URL != "" {
+        return nil
+    }
+    if c.Host == "" {
+        return errors.New("database host cannot be empty")
+    }
+    if c.Port <= 0 || c.Port > 65535 {
+        return fmt.Errorf("invalid database port: %d", c.Port)
+    }
+    if c.DBName == "" {
+        return errors.New("database name cannot be empty")
+    }
+    switch c.SSLMode {
+    case "disable", "allow", "prefer", "require", "verify-ca", "verify-full":
+    default:
+        return fmt.Errorf("invalid sslmode: %s", c.SSLMode)
+    }
+    return nil
+}
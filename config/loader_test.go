@@ -0,0 +1,248 @@
+package config
+
+import (
+    "os"
+    "path/filepath"
+    "testing"
+    "time"
+)
+
+func TestReadFile_YAML(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "config.yaml")
+    content := "host: db.local\nport: 6543\nsslmode: require\nconn_max_lifetime: 5m\n"
+    if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+        t.Fatal(err)
+    }
+
+    file, err := ReadFile(path)
+    if err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+    cfg, err := file.Resolve("")
+    if err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+
+    if cfg.Host != "db.local" || cfg.Port != 6543 || cfg.SSLMode != "require" {
+        t.Errorf("unexpected config: %+v", cfg)
+    }
+    if cfg.ConnMaxLifetime != 5*time.Minute {
+        t.Errorf("expected conn_max_lifetime 5m, got %s", cfg.ConnMaxLifetime)
+    }
+    if cfg.User != "postgres" {
+        t.Errorf("expected default user to be kept, got '%s'", cfg.User)
+    }
+}
+
+func TestReadFile_TOML(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "config.toml")
+    content := "user = \"inventory\"\ndbname = \"inv\"\nmax_open_conns = 3\n"
+    if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+        t.Fatal(err)
+    }
+
+    file, err := ReadFile(path)
+    if err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+    cfg, _ := file.Resolve("")
+
+    if cfg.User != "inventory" || cfg.DBName != "inv" || cfg.MaxOpenConns != 3 {
+        t.Errorf("unexpected config: %+v", cfg)
+    }
+}
+
+func TestReadFile_Missing(t *testing.T) {
+    file, err := ReadFile(filepath.Join(t.TempDir(), "config.yaml"))
+    if err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+    if len(file.Profiles) != 0 {
+        t.Errorf("expected empty file, got %+v", file)
+    }
+}
+
+func TestFile_Resolve_Profiles(t *testing.T) {
+    file := &File{
+        Config:         Config{User: "inventory", Password: "base"},
+        CurrentProfile: "dev",
+        Profiles: map[string]Config{
+            "dev":     {Host: "localhost", DBName: "inventory_dev"},
+            "staging": {Host: "staging.local", Password: "staging"},
+        },
+    }
+
+    cfg, err := file.Resolve("")
+    if err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+    if cfg.DBName != "inventory_dev" || cfg.User != "inventory" || cfg.Password != "base" {
+        t.Errorf("unexpected dev config: %+v", cfg)
+    }
+
+    cfg, _ = file.Resolve("staging")
+    if cfg.Host != "staging.local" || cfg.Password != "staging" || cfg.DBName != "inventory_office" {
+        t.Errorf("unexpected staging config: %+v", cfg)
+    }
+
+    if _, err := file.Resolve("prod"); err == nil {
+        t.Error("expected error for unknown profile")
+    }
+}
+
+func TestFile_Resolve_WrittenZeroValues(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "config.yaml")
+    content := "sslmode: require\nmax_open_conns: 20\nprofiles:\n  local:\n    sslmode: \"\"\n    max_open_conns: 0\n  staging:\n    host: staging.local\n"
+    if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+        t.Fatal(err)
+    }
+    file, err := ReadFile(path)
+    if err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+
+    local, _ := file.Resolve("local")
+    if local.SSLMode != "" || local.MaxOpenConns != 0 {
+        t.Errorf("expected the written zero values to override the base settings, got %+v", local)
+    }
+    staging, _ := file.Resolve("staging")
+    if staging.SSLMode != "require" || staging.MaxOpenConns != 20 {
+        t.Errorf("expected staging to keep the base settings, got %+v", staging)
+    }
+
+    // a zero value set with Set survives a save
+    for _, name := range []string{"config.yaml", "config.toml"} {
+        path := filepath.Join(t.TempDir(), name)
+        file := &File{Config: Config{SSLMode: "require"}}
+        if err := file.Set("local", "sslmode", ""); err != nil {
+            t.Fatalf("%s: unexpected error: %s", name, err)
+        }
+        if err := file.Save(path); err != nil {
+            t.Fatalf("%s: unexpected error: %s", name, err)
+        }
+        loaded, err := ReadFile(path)
+        if err != nil {
+            t.Fatalf("%s: unexpected error: %s", name, err)
+        }
+        if local, _ := loaded.Resolve("local"); local.SSLMode != "" {
+            t.Errorf("%s: expected the saved empty sslmode to override require, got %q", name, local.SSLMode)
+        }
+    }
+}
+
+func TestFile_SaveRoundTrip(t *testing.T) {
+    for _, name := range []string{"config.yaml", "config.toml"} {
+        path := filepath.Join(t.TempDir(), name)
+        file := &File{
+            CurrentProfile: "dev",
+            Profiles: map[string]Config{
+                "dev": {Host: "localhost", Port: 5433, ConnMaxLifetime: time.Minute},
+            },
+        }
+        if err := file.Save(path); err != nil {
+            t.Fatalf("%s: unexpected error: %s", name, err)
+        }
+
+        loaded, err := ReadFile(path)
+        if err != nil {
+            t.Fatalf("%s: unexpected error: %s", name, err)
+        }
+        dev := loaded.Profiles["dev"]
+        if loaded.CurrentProfile != "dev" || dev.Port != 5433 || dev.ConnMaxLifetime != time.Minute {
+            t.Errorf("%s: unexpected round trip result: %+v", name, loaded)
+        }
+    }
+}
+
+func TestApplyEnv(t *testing.T) {
+    env := map[string]string{
+        "INVENTORY_DB_HOST":     "env-host",
+        "INVENTORY_DB_PORT":     "5433",
+        "INVENTORY_DB_PASSWORD": "secret",
+    }
+    lookup := func(key string) (string, bool) {
+        v, ok := env[key]
+        return v, ok
+    }
+
+    cfg := DefaultConfig()
+    if err := ApplyEnv(&cfg, lookup); err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+
+    if cfg.Host != "env-host" || cfg.Port != 5433 || cfg.Password != "secret" {
+        t.Errorf("unexpected config: %+v", cfg)
+    }
+
+    env["INVENTORY_DB_PORT"] = "abc"
+    if err := ApplyEnv(&cfg, lookup); err == nil {
+        t.Error("expected error for invalid port")
+    }
+}
+
+func TestApplyEnv_FieldDropsFileURL(t *testing.T) {
+    env := map[string]string{"INVENTORY_DB_HOST": "env-host"}
+    lookup := func(key string) (string, bool) {
+        v, ok := env[key]
+        return v, ok
+    }
+
+    cfg := DefaultConfig()
+    cfg.URL = "postgres://u:p@file-host/db"
+    if err := ApplyEnv(&cfg, lookup); err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+    if cfg.URL != "" || cfg.Host != "env-host" {
+        t.Errorf("expected INVENTORY_DB_HOST to replace the url of the file, got %+v", cfg)
+    }
+
+    // a url set next to the field still wins
+    env["INVENTORY_DB_URL"] = "postgres://u:p@env-url/db"
+    if err := ApplyEnv(&cfg, lookup); err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+    if cfg.DSN() != env["INVENTORY_DB_URL"] {
+        t.Errorf("expected INVENTORY_DB_URL as DSN, got %q", cfg.DSN())
+    }
+}
+
+func TestConfig_Set_UnknownKey(t *testing.T) {
+    cfg := DefaultConfig()
+    if err := cfg.Set("colour", "blue"); err == nil {
+        t.Error("expected error for unknown key")
+    }
+}
+
+func TestConfig_DSN(t *testing.T) {
+    cfg := DefaultConfig()
+    cfg.Password = "my pass"
+
+    expected := "host=localhost port=5432 user=postgres password='my pass' dbname=inventory_office sslmode=disable"
+    if dsn := cfg.DSN(); dsn != expected {
+        t.Errorf("expected DSN %q, got %q", expected, dsn)
+    }
+
+    cfg.URL = "postgres://u:p@example.com/db?sslmode=require"
+    if dsn := cfg.DSN(); dsn != cfg.URL {
+        t.Errorf("expected URL to be used as DSN, got %q", dsn)
+    }
+}
+
+func TestConfig_SQLite(t *testing.T) {
+    cfg := DefaultConfig()
+    cfg.Driver = DriverSQLite
+    cfg.Path = "/tmp/inventory.db"
+
+    if err := cfg.Validate(); err != nil {
+        t.Errorf("unexpected error: %s", err)
+    }
+    expected := "file:/tmp/inventory.db?_pragma=foreign_keys%281%29&_pragma=busy_timeout%285000%29&_time_format=sqlite&_txlock=immediate"
+    if dsn := cfg.DSN(); dsn != expected {
+        t.Errorf("expected DSN %q, got %q", expected, dsn)
+    }
+
+    cfg.Driver = "mysql"
+    if err := cfg.Validate(); err == nil {
+        t.Error("expected error for unsupported driver")
+    }
+}
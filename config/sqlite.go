@@ -0,0 +1,61 @@
+package config
+
+import (
+    "database/sql/driver"
+    "net/url"
+    "os"
+    "path/filepath"
+    "strings"
+
+    "modernc.org/sqlite"
+)
+
+func init() {
+    // SQLite's built-in lower() only folds ASCII; replace it with Go's Unicode
+    // folding so LOWER(name) LIKE LOWER($1) searches behave as on PostgreSQL
+    sqlite.MustRegisterDeterministicScalarFunction("lower", 1,
+        func(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+            switch v := args[0].(type) {
+            case string:
+                return strings.ToLower(v), nil
+            case []byte:
+                return strings.ToLower(string(v)), nil
+            }
+            return args[0], nil
+        })
+}
+
+// DefaultSQLitePath returns $XDG_DATA_HOME/inventory/inventory.db
+// (~/.local/share/inventory/inventory.db when XDG_DATA_HOME is unset)
+func DefaultSQLitePath() string {
+    dir := os.Getenv("XDG_DATA_HOME")
+    if dir == "" {
+        home, err := os.UserHomeDir()
+        if err != nil {
+            return "inventory.db"
+        }
+        dir = filepath.Join(home, ".local", "share")
+    }
+    return filepath.Join(dir, "inventory", "inventory.db")
+}
+
+// SQLitePath returns the configured database file or the default location
+func (c Config) SQLitePath() string {
+    if c.Path != "" {
+        return c.Path
+    }
+    return DefaultSQLitePath()
+}
+
+// sqliteDSN enables foreign keys (needed for ON DELETE RESTRICT), waits on
+// locks instead of failing, stores times in a format SQLite's date
+// functions understand, and begins transactions IMMEDIATE so a unit of work
+// holds the write lock from its first read and cannot deadlock upgrading it
+func sqliteDSN(path string) string {
+    params := url.Values{}
+    params.Add("_pragma", "foreign_keys(1)")
+    params.Add("_pragma", "busy_timeout(5000)")
+    params.Set("_time_format", "sqlite")
+    params.Set("_txlock", "immediate")
+    return "file:" + path + "?" + params.Encode()
+}
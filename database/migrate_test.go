@@ -0,0 +1,128 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"mini_project3/config"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"m/000002_add_b.up.sql":   {Data: []byte("CREATE TABLE b ()")},
+		"m/000002_add_b.down.sql": {Data: []byte("DROP TABLE b")},
+		"m/000001_add_a.up.sql":   {Data: []byte("CREATE TABLE a ()")},
+		"m/000001_add_a.down.sql": {Data: []byte("DROP TABLE a")},
+	}
+
+	migrations, err := LoadMigrations(fsys, "m")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(migrations) != 2 {
+		t.Fatalf("expected 2 migrations, got %d", len(migrations))
+	}
+	if migrations[0].Version != 1 || migrations[0].Name != "add_a" || migrations[1].Version != 2 {
+		t.Errorf("unexpected migrations order: %+v", migrations)
+	}
+}
+
+func TestLoadMigrations_MissingDown(t *testing.T) {
+	fsys := fstest.MapFS{
+		"m/000001_add_a.up.sql": {Data: []byte("CREATE TABLE a ()")},
+	}
+
+	if _, err := LoadMigrations(fsys, "m"); err == nil {
+		t.Error("expected error for migration without down file")
+	}
+}
+
+func TestLoadMigrations_Embedded(t *testing.T) {
+	postgres, err := LoadMigrations(migrationFS, "migrations/postgres")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	sqlite, err := LoadMigrations(migrationFS, "migrations/sqlite")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(postgres) != len(sqlite) {
+		t.Fatalf("expected the same number of postgres and sqlite migrations, got %d and %d", len(postgres), len(sqlite))
+	}
+	for i := range postgres {
+		if postgres[i].Version != int64(i+1) {
+			t.Errorf("expected migration version %d, got %d", i+1, postgres[i].Version)
+		}
+		if postgres[i].Version != sqlite[i].Version || postgres[i].Name != sqlite[i].Name {
+			t.Errorf("postgres migration %d_%s has no sqlite counterpart", postgres[i].Version, postgres[i].Name)
+		}
+	}
+}
+
+func newTestMigrator(t *testing.T) (*Migrator, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrator := &Migrator{db: db, driver: config.DriverPostgres, migrations: []Migration{
+		{Version: 1, Name: "add_a", Up: "CREATE TABLE a", Down: "DROP TABLE a"},
+		{Version: 2, Name: "add_b", Up: "CREATE TABLE b", Down: "DROP TABLE b"},
+	}}
+	return migrator, mock
+}
+
+func TestMigrator_Up(t *testing.T) {
+	migrator, mock := newTestMigrator(t)
+
+	mock.ExpectExec("SELECT pg_advisory_lock").WithArgs(advisoryLockKey).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(1, time.Now()))
+	mock.ExpectBegin()
+	mock.ExpectExec("CREATE TABLE b").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO schema_migrations").WithArgs(int64(2), "add_b").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectExec("SELECT pg_advisory_unlock").WithArgs(advisoryLockKey).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	applied, err := migrator.Up(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(applied) != 1 || applied[0].Version != 2 {
+		t.Errorf("expected migration 2 to be applied, got %+v", applied)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestMigrator_Down_RollsBackOnError(t *testing.T) {
+	migrator, mock := newTestMigrator(t)
+
+	mock.ExpectExec("SELECT pg_advisory_lock").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(1, time.Now()).AddRow(2, time.Now()))
+	mock.ExpectBegin()
+	mock.ExpectExec("DROP TABLE b").WillReturnError(sqlmock.ErrCancelled)
+	mock.ExpectRollback()
+	mock.ExpectExec("SELECT pg_advisory_unlock").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if _, err := migrator.Down(context.Background(), 1); err == nil {
+		t.Error("expected error when down script fails")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
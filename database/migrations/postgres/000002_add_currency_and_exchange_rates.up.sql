@@ -0,0 +1,11 @@
+ALTER TABLE items ADD COLUMN currency VARCHAR(3) NOT NULL DEFAULT 'IDR';
+
+-- rate is the value of one unit of currency in IDR, valid from rate_date
+-- until the next rate of the same currency
+CREATE TABLE exchange_rates (
+    currency VARCHAR(3) NOT NULL,
+    rate_date DATE NOT NULL,
+    rate DECIMAL(18, 6) NOT NULL CHECK (rate > 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (currency, rate_date)
+);
@@ -0,0 +1,7 @@
+ALTER TABLE items DROP COLUMN IF EXISTS depreciation_rate;
+ALTER TABLE items DROP COLUMN IF EXISTS useful_life_months;
+ALTER TABLE items DROP COLUMN IF EXISTS depreciation_method;
+
+ALTER TABLE categories DROP COLUMN IF EXISTS depreciation_rate;
+ALTER TABLE categories DROP COLUMN IF EXISTS useful_life_months;
+ALTER TABLE categories DROP COLUMN IF EXISTS depreciation_method;
@@ -0,0 +1,10 @@
+-- An empty method, a zero useful life or a zero rate is inherited: an item
+-- falls back to its category, a category to declining balance at 20% a year.
+-- depreciation_rate is a percentage per year.
+ALTER TABLE categories ADD COLUMN depreciation_method VARCHAR(30) NOT NULL DEFAULT '';
+ALTER TABLE categories ADD COLUMN useful_life_months INTEGER NOT NULL DEFAULT 0 CHECK (useful_life_months >= 0);
+ALTER TABLE categories ADD COLUMN depreciation_rate DECIMAL(9, 6) NOT NULL DEFAULT 0 CHECK (depreciation_rate >= 0 AND depreciation_rate <= 100);
+
+ALTER TABLE items ADD COLUMN depreciation_method VARCHAR(30) NOT NULL DEFAULT '';
+ALTER TABLE items ADD COLUMN useful_life_months INTEGER NOT NULL DEFAULT 0 CHECK (useful_life_months >= 0);
+ALTER TABLE items ADD COLUMN depreciation_rate DECIMAL(9, 6) NOT NULL DEFAULT 0 CHECK (depreciation_rate >= 0 AND depreciation_rate <= 100);
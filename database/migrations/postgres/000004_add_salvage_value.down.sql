@@ -0,0 +1,5 @@
+ALTER TABLE items DROP COLUMN IF EXISTS salvage_percent;
+ALTER TABLE items DROP COLUMN IF EXISTS salvage_value;
+
+ALTER TABLE categories DROP COLUMN IF EXISTS salvage_percent;
+ALTER TABLE categories DROP COLUMN IF EXISTS salvage_value;
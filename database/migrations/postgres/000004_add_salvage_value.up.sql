@@ -0,0 +1,8 @@
+-- The residual value an asset keeps at the end of its useful life, either an
+-- amount in the currency of the item price or a percentage of the price.
+-- At most one of the two is set; both zero is inherited like 000003.
+ALTER TABLE categories ADD COLUMN salvage_value DECIMAL(15, 2) NOT NULL DEFAULT 0 CHECK (salvage_value >= 0);
+ALTER TABLE categories ADD COLUMN salvage_percent DECIMAL(9, 6) NOT NULL DEFAULT 0 CHECK (salvage_percent >= 0 AND salvage_percent < 100);
+
+ALTER TABLE items ADD COLUMN salvage_value DECIMAL(15, 2) NOT NULL DEFAULT 0 CHECK (salvage_value >= 0);
+ALTER TABLE items ADD COLUMN salvage_percent DECIMAL(9, 6) NOT NULL DEFAULT 0 CHECK (salvage_percent >= 0 AND salvage_percent < 100);
@@ -0,0 +1,5 @@
+ALTER TABLE items DROP COLUMN IF EXISTS tax_method;
+ALTER TABLE items DROP COLUMN IF EXISTS tax_group;
+
+ALTER TABLE categories DROP COLUMN IF EXISTS tax_method;
+ALTER TABLE categories DROP COLUMN IF EXISTS tax_group;
@@ -0,0 +1,8 @@
+-- Maps items and categories to an asset group of the Indonesian fiscal book
+-- (kelompok-1 .. kelompok-4, bangunan-permanen, bangunan-tidak-permanen) and
+-- its straight-line or declining-balance method. Empty values are inherited.
+ALTER TABLE categories ADD COLUMN tax_group VARCHAR(30) NOT NULL DEFAULT '';
+ALTER TABLE categories ADD COLUMN tax_method VARCHAR(30) NOT NULL DEFAULT '';
+
+ALTER TABLE items ADD COLUMN tax_group VARCHAR(30) NOT NULL DEFAULT '';
+ALTER TABLE items ADD COLUMN tax_method VARCHAR(30) NOT NULL DEFAULT '';
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS journal_lines;
+DROP TABLE IF EXISTS journal_periods;
+
+ALTER TABLE categories DROP COLUMN IF EXISTS accumulated_account;
+ALTER TABLE categories DROP COLUMN IF EXISTS expense_account;
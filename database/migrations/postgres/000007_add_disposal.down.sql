@@ -0,0 +1,3 @@
+ALTER TABLE items DROP COLUMN IF EXISTS disposal_proceeds;
+ALTER TABLE items DROP COLUMN IF EXISTS disposal_method;
+ALTER TABLE items DROP COLUMN IF EXISTS disposed_at;
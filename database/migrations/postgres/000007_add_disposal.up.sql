@@ -0,0 +1,6 @@
+-- A disposed item stays in the inventory for its history and the disposals
+-- report: disposed_at is the date it was sold, scrapped or donated (NULL while
+-- in use), its depreciation stops on that date. Proceeds are in the item currency.
+ALTER TABLE items ADD COLUMN disposed_at DATE;
+ALTER TABLE items ADD COLUMN disposal_method VARCHAR(20) NOT NULL DEFAULT '';
+ALTER TABLE items ADD COLUMN disposal_proceeds DECIMAL(15, 2) NOT NULL DEFAULT 0;
@@ -0,0 +1,9 @@
+-- Rows still in the trash would become live again, so they are removed
+DELETE FROM items WHERE deleted_at IS NOT NULL;
+DELETE FROM categories WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_items_deleted_at;
+DROP INDEX IF EXISTS idx_categories_deleted_at;
+
+ALTER TABLE items DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE categories DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,29 @@
+-- Every create, update, dispose, delete, restore and purge of an item or
+-- category appends a row in the same transaction as the change. before_data
+-- and after_data hold the record as JSON, NULL where it is not live (before a
+-- create or restore, after a delete or purge). There is no foreign key, so the
+-- history of a purged record stays.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id SERIAL PRIMARY KEY,
+    at TIMESTAMP NOT NULL,
+    actor VARCHAR(100) NOT NULL,
+    command TEXT NOT NULL DEFAULT '',
+    entity VARCHAR(20) NOT NULL,
+    entity_id INTEGER NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    before_data JSONB,
+    after_data JSONB
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_entity ON audit_log(entity, entity_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_at ON audit_log(at);
+
+-- The log is append-only
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_append_only BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
@@ -0,0 +1,5 @@
+-- Every update of an item or category increments its version, so an update
+-- based on an older read of the row can be refused instead of silently
+-- overwriting the change made in the meantime.
+ALTER TABLE categories ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE items ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
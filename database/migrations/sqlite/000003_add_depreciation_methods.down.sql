@@ -0,0 +1,7 @@
+ALTER TABLE items DROP COLUMN depreciation_rate;
+ALTER TABLE items DROP COLUMN useful_life_months;
+ALTER TABLE items DROP COLUMN depreciation_method;
+
+ALTER TABLE categories DROP COLUMN depreciation_rate;
+ALTER TABLE categories DROP COLUMN useful_life_months;
+ALTER TABLE categories DROP COLUMN depreciation_method;
@@ -0,0 +1,5 @@
+ALTER TABLE items DROP COLUMN salvage_percent;
+ALTER TABLE items DROP COLUMN salvage_value;
+
+ALTER TABLE categories DROP COLUMN salvage_percent;
+ALTER TABLE categories DROP COLUMN salvage_value;
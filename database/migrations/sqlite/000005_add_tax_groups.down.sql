@@ -0,0 +1,5 @@
+ALTER TABLE items DROP COLUMN tax_method;
+ALTER TABLE items DROP COLUMN tax_group;
+
+ALTER TABLE categories DROP COLUMN tax_method;
+ALTER TABLE categories DROP COLUMN tax_group;
@@ -0,0 +1,26 @@
+-- General ledger accounts a category books its monthly depreciation to:
+-- debit expense_account, credit accumulated_account. Empty uses the default.
+ALTER TABLE categories ADD COLUMN expense_account VARCHAR(30) NOT NULL DEFAULT '';
+ALTER TABLE categories ADD COLUMN accumulated_account VARCHAR(30) NOT NULL DEFAULT '';
+
+-- A posted month (YYYY-MM) is locked: its journal is read back from
+-- journal_lines instead of being recalculated
+CREATE TABLE journal_periods (
+    month VARCHAR(7) PRIMARY KEY,
+    currency VARCHAR(3) NOT NULL,
+    posted_at TIMESTAMP NOT NULL
+);
+
+-- category_name and account are copied so the posted journal does not change
+-- when a category is renamed, remapped or deleted
+CREATE TABLE journal_lines (
+    month VARCHAR(7) NOT NULL REFERENCES journal_periods(month) ON DELETE CASCADE,
+    line_no INTEGER NOT NULL,
+    category_id INTEGER NOT NULL,
+    category_name VARCHAR(100) NOT NULL,
+    account VARCHAR(30) NOT NULL,
+    description VARCHAR(255) NOT NULL,
+    debit DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    credit DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    PRIMARY KEY (month, line_no)
+);
@@ -0,0 +1,3 @@
+ALTER TABLE items DROP COLUMN disposal_proceeds;
+ALTER TABLE items DROP COLUMN disposal_method;
+ALTER TABLE items DROP COLUMN disposed_at;
@@ -0,0 +1,9 @@
+-- Deleting an item or category moves it to the trash: deleted_at is set
+-- (NULL while live) and every query leaves the row out until it is restored
+-- or purged. A category in the trash keeps its name, so restoring it never
+-- collides with a category created in the meantime.
+ALTER TABLE categories ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE items ADD COLUMN deleted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_categories_deleted_at ON categories(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_items_deleted_at ON items(deleted_at);
@@ -0,0 +1,2 @@
+ALTER TABLE items DROP COLUMN version;
+ALTER TABLE categories DROP COLUMN version;
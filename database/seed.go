@@ -0,0 +1,268 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"mini_project3/config"
+	"mini_project3/models"
+	"mini_project3/money"
+)
+
+// Fixture is a named set of categories and items. Items refer to their
+// category by CategoryName, which Seed resolves to an ID.
+type Fixture struct {
+	Name       string
+	Categories []models.Category
+	Items      []models.Item
+}
+
+// FixtureNames lists the fixture sets accepted by FixtureByName
+var FixtureNames = []string{"minimal", "demo", "large"}
+
+// FixtureByName returns a fixture set; count and seed are only used by "large"
+func FixtureByName(name string, count int, seed int64) (Fixture, error) {
+	switch name {
+	case "minimal":
+		return MinimalFixture(), nil
+	case "demo":
+		return DemoFixture(), nil
+	case "large":
+		if count <= 0 {
+			return Fixture{}, fmt.Errorf("count must be greater than 0")
+		}
+		return LargeFixture(count, seed), nil
+	}
+	return Fixture{}, fmt.Errorf("unknown fixture set '%s' (valid: minimal, demo, large)", name)
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// MinimalFixture contains only the standard categories
+func MinimalFixture() Fixture {
+	return Fixture{
+		Name: "minimal",
+		Categories: []models.Category{
+			{Name: "Elektronik", Description: "Peralatan elektronik kantor", DepreciationPolicy: models.DepreciationPolicy{TaxGroup: "kelompok-1"}},
+			{Name: "Furniture", Description: "Mebel dan perabotan kantor", DepreciationPolicy: models.DepreciationPolicy{Method: "straight-line", UsefulLifeMonths: 96, SalvagePercent: money.MustParseRate("10"), TaxGroup: "kelompok-2"}},
+			{Name: "Alat Tulis", Description: "Perlengkapan tulis menulis", DepreciationPolicy: models.DepreciationPolicy{TaxGroup: "kelompok-1"}},
+		},
+	}
+}
+
+// DemoFixture contains the standard categories and a handful of items
+func DemoFixture() Fixture {
+	f := MinimalFixture()
+	f.Name = "demo"
+	f.Items = []models.Item{
+		{Name: "Laptop Dell XPS 13", CategoryName: "Elektronik", Price: money.FromInt(15000000), PurchaseDate: date(2024, 6, 1)},
+		{Name: "Monitor LG 24 inch", CategoryName: "Elektronik", Price: money.FromInt(2500000), PurchaseDate: date(2024, 7, 15)},
+		{Name: "Meja Kerja", CategoryName: "Furniture", Price: money.FromInt(1500000), PurchaseDate: date(2024, 5, 10)},
+		{Name: "Kursi Ergonomis", CategoryName: "Furniture", Price: money.FromInt(2000000), PurchaseDate: date(2024, 5, 10)},
+		{Name: "Printer HP LaserJet", CategoryName: "Elektronik", Price: money.FromInt(3500000), PurchaseDate: date(2024, 8, 1)},
+	}
+	return f
+}
+
+// syntheticCatalog maps each large-fixture category to item names and a price range in Rupiah
+var syntheticCatalog = []struct {
+	category models.Category
+	names    []string
+	minPrice int
+	maxPrice int
+}{
+	{models.Category{Name: "Elektronik", Description: "Peralatan elektronik kantor", DepreciationPolicy: models.DepreciationPolicy{TaxGroup: "kelompok-1"}},
+		[]string{"Laptop", "Monitor", "Printer", "Proyektor", "Scanner", "Tablet"}, 1_000_000, 25_000_000},
+	{models.Category{Name: "Furniture", Description: "Mebel dan perabotan kantor", DepreciationPolicy: models.DepreciationPolicy{Method: "straight-line", UsefulLifeMonths: 96, SalvagePercent: money.MustParseRate("10"), TaxGroup: "kelompok-2"}},
+		[]string{"Meja Kerja", "Kursi", "Lemari Arsip", "Rak Buku", "Sofa Tamu"}, 500_000, 8_000_000},
+	{models.Category{Name: "Alat Tulis", Description: "Perlengkapan tulis menulis", DepreciationPolicy: models.DepreciationPolicy{TaxGroup: "kelompok-1"}},
+		[]string{"Papan Tulis", "Mesin Laminasi", "Penghancur Kertas", "Stapler Besar"}, 100_000, 3_000_000},
+	{models.Category{Name: "Jaringan", Description: "Perangkat jaringan dan server", DepreciationPolicy: models.DepreciationPolicy{TaxGroup: "kelompok-1"}},
+		[]string{"Router", "Switch", "Access Point", "Server Rack", "UPS"}, 750_000, 40_000_000},
+	{models.Category{Name: "Kendaraan", Description: "Kendaraan operasional kantor", DepreciationPolicy: models.DepreciationPolicy{TaxGroup: "kelompok-2", TaxMethod: "declining-balance"}},
+		[]string{"Motor Operasional", "Mobil Operasional", "Sepeda Listrik"}, 8_000_000, 350_000_000},
+}
+
+// LargeFixture generates count pseudo-random items from seed. The same count
+// and seed always produce the same items, with purchase dates spread over
+// 2019-2025 independent of the current date.
+func LargeFixture(count int, seed int64) Fixture {
+	rng := rand.New(rand.NewSource(seed))
+	start := date(2019, 1, 1)
+	days := int(date(2025, 12, 31).Sub(start).Hours() / 24)
+
+	f := Fixture{Name: "large"}
+	for _, entry := range syntheticCatalog {
+		f.Categories = append(f.Categories, entry.category)
+	}
+
+	f.Items = make([]models.Item, count)
+	for i := range f.Items {
+		entry := syntheticCatalog[rng.Intn(len(syntheticCatalog))]
+		name := entry.names[rng.Intn(len(entry.names))]
+		// Harga dibulatkan ke ribuan rupiah
+		price := (entry.minPrice + rng.Intn(entry.maxPrice-entry.minPrice+1)) / 1000 * 1000
+
+		f.Items[i] = models.Item{
+			Name:         fmt.Sprintf("%s #%05d", name, i+1),
+			CategoryName: entry.category.Name,
+			Price:        money.FromInt(int64(price)),
+			PurchaseDate: start.AddDate(0, 0, rng.Intn(days+1)),
+		}
+	}
+	return f
+}
+
+// SeedResult counts the rows inserted by Seed and the items it skipped
+type SeedResult struct {
+	Categories int
+	Items      int
+	Skipped    int
+}
+
+// AuditFunc writes the audit entry of action on entity id within tx, see
+// repository.AuditRecord
+type AuditFunc func(ctx context.Context, tx *sql.Tx, entity string, id int, action string) error
+
+// Seed inserts a fixture in a single transaction. Categories that already
+// exist (by name) are reused, unless they are in the trash, and items that already exist (by name,
+// category and purchase date) are skipped, so seeding the same fixture again
+// adds nothing; when reset is true all items and categories are removed first.
+// Like the repositories it records every row it creates or removes with audit.
+func Seed(ctx context.Context, db *sql.DB, driver string, f Fixture, reset bool, audit AuditFunc) (*SeedResult, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// the ids keep counting up after a reset: the audit log outlives the rows
+	// and keys their history on the id, which a new row must not inherit
+	if reset {
+		if err := auditReset(ctx, tx, audit); err != nil {
+			return nil, err
+		}
+		statements := []string{`TRUNCATE items, categories`}
+		if driver == config.DriverSQLite {
+			statements = []string{
+				`DELETE FROM items`,
+				`DELETE FROM categories`,
+			}
+		}
+		for _, stmt := range statements {
+			if _, err := tx.ExecContext(ctx, stmt); err != nil {
+				return nil, fmt.Errorf("error resetting data: %w", err)
+			}
+		}
+	}
+
+	result := &SeedResult{}
+	categoryIDs := map[string]int{}
+	for _, cat := range f.Categories {
+		res, err := tx.ExecContext(ctx,
+			`INSERT INTO categories (name, description, depreciation_method, useful_life_months, depreciation_rate, salvage_value, salvage_percent,
+				tax_group, tax_method)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) ON CONFLICT (name) DO NOTHING`,
+			cat.Name, cat.Description, cat.Method, cat.UsefulLifeMonths, cat.RatePercent, cat.SalvageValue, cat.SalvagePercent,
+			cat.TaxGroup, cat.TaxMethod)
+		if err != nil {
+			return nil, fmt.Errorf("error seeding category '%s': %w", cat.Name, err)
+		}
+		created, _ := res.RowsAffected()
+
+		// the name stays taken while the category is in the trash, where
+		// seeded items would not be listed under it
+		var id int
+		var live bool
+		if err := tx.QueryRowContext(ctx, `SELECT id, deleted_at IS NULL FROM categories WHERE name = $1`, cat.Name).Scan(&id, &live); err != nil {
+			return nil, fmt.Errorf("error resolving category '%s': %w", cat.Name, err)
+		}
+		if !live {
+			return nil, fmt.Errorf("category '%s' is in the trash: restore it with 'category restore --id %d' or purge it before seeding", cat.Name, id)
+		}
+		if created > 0 {
+			if err := audit(ctx, tx, models.AuditCategory, id, models.AuditCreate); err != nil {
+				return nil, err
+			}
+			result.Categories++
+		}
+		categoryIDs[cat.Name] = id
+	}
+
+	if len(f.Items) > 0 {
+		exists, err := tx.PrepareContext(ctx, `SELECT EXISTS (SELECT 1 FROM items WHERE name = $1 AND category_id = $2 AND purchase_date = $3 AND deleted_at IS NULL)`)
+		if err != nil {
+			return nil, fmt.Errorf("error preparing item lookup: %w", err)
+		}
+		defer exists.Close()
+		stmt, err := tx.PrepareContext(ctx, `INSERT INTO items (name, category_id, price, purchase_date) VALUES ($1, $2, $3, $4) RETURNING id`)
+		if err != nil {
+			return nil, fmt.Errorf("error preparing item insert: %w", err)
+		}
+		defer stmt.Close()
+
+		for _, item := range f.Items {
+			categoryID, ok := categoryIDs[item.CategoryName]
+			if !ok {
+				return nil, fmt.Errorf("item '%s' refers to unknown category '%s'", item.Name, item.CategoryName)
+			}
+			var found bool
+			if err := exists.QueryRowContext(ctx, item.Name, categoryID, item.PurchaseDate).Scan(&found); err != nil {
+				return nil, fmt.Errorf("error looking up item '%s': %w", item.Name, err)
+			}
+			if found {
+				result.Skipped++
+				continue
+			}
+			var id int
+			if err := stmt.QueryRowContext(ctx, item.Name, categoryID, item.Price, item.PurchaseDate).Scan(&id); err != nil {
+				return nil, fmt.Errorf("error seeding item '%s': %w", item.Name, err)
+			}
+			if err := audit(ctx, tx, models.AuditItem, id, models.AuditCreate); err != nil {
+				return nil, err
+			}
+			result.Items++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing seed data: %w", err)
+	}
+	return result, nil
+}
+
+// auditReset records the purge of every item and category a reset removes,
+// including those in the trash
+func auditReset(ctx context.Context, tx *sql.Tx, audit AuditFunc) error {
+	for _, table := range []struct{ name, entity string }{{"items", models.AuditItem}, {"categories", models.AuditCategory}} {
+		rows, err := tx.QueryContext(ctx, `SELECT id FROM `+table.name+` ORDER BY id`)
+		if err != nil {
+			return fmt.Errorf("error resetting data: %w", err)
+		}
+		var ids []int
+		for rows.Next() {
+			var id int
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return fmt.Errorf("error resetting data: %w", err)
+			}
+			ids = append(ids, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("error resetting data: %w", err)
+		}
+
+		for _, id := range ids {
+			if err := audit(ctx, tx, table.entity, id, models.AuditPurge); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"strings"
+	"testing"
+
+	"mini_project3/config"
+	"mini_project3/models"
+	"mini_project3/money"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestLargeFixture_Deterministic(t *testing.T) {
+	a := LargeFixture(50, 42)
+	b := LargeFixture(50, 42)
+
+	if len(a.Items) != 50 {
+		t.Fatalf("expected 50 items, got %d", len(a.Items))
+	}
+	if !reflect.DeepEqual(a, b) {
+		t.Error("expected the same seed to produce the same fixture")
+	}
+
+	c := LargeFixture(50, 43)
+	if reflect.DeepEqual(a.Items, c.Items) {
+		t.Error("expected a different seed to produce different items")
+	}
+
+	categories := map[string]bool{}
+	for _, cat := range a.Categories {
+		categories[cat.Name] = true
+	}
+	for _, item := range a.Items {
+		if !categories[item.CategoryName] {
+			t.Errorf("item '%s' has unknown category '%s'", item.Name, item.CategoryName)
+		}
+		if item.Price.Cmp(money.Zero) <= 0 {
+			t.Errorf("item '%s' has invalid price %s", item.Name, item.Price)
+		}
+	}
+}
+
+func TestFixtureByName(t *testing.T) {
+	f, err := FixtureByName("demo", 0, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(f.Items) != 5 || len(f.Categories) != 3 {
+		t.Errorf("unexpected demo fixture: %d categories, %d items", len(f.Categories), len(f.Items))
+	}
+
+	if _, err := FixtureByName("large", 0, 1); err == nil {
+		t.Error("expected error for large fixture without count")
+	}
+	if _, err := FixtureByName("huge", 10, 1); err == nil {
+		t.Error("expected error for unknown fixture")
+	}
+}
+
+// auditCall is one change Seed passed to its AuditFunc
+type auditCall struct {
+	entity string
+	id     int
+	action string
+}
+
+// recordAudit returns an AuditFunc that appends its calls to calls
+func recordAudit(calls *[]auditCall) AuditFunc {
+	return func(ctx context.Context, tx *sql.Tx, entity string, id int, action string) error {
+		*calls = append(*calls, auditCall{entity, id, action})
+		return nil
+	}
+}
+
+func TestSeed(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	f := DemoFixture()
+	f.Categories = f.Categories[:1]
+	f.Items = f.Items[:2]
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO categories").WithArgs("Elektronik", "Peralatan elektronik kantor", "", 0, money.Rate{}, money.Zero, money.Rate{}, "kelompok-1", "").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("SELECT id, deleted_at IS NULL FROM categories").WithArgs("Elektronik").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "live"}).AddRow(7, true))
+	lookup := mock.ExpectPrepare("SELECT EXISTS")
+	prep := mock.ExpectPrepare("INSERT INTO items")
+	lookup.ExpectQuery().WithArgs("Laptop Dell XPS 13", 7, f.Items[0].PurchaseDate).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	prep.ExpectQuery().WithArgs("Laptop Dell XPS 13", 7, money.FromInt(15000000), f.Items[0].PurchaseDate).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11))
+	// the monitor was seeded before and is skipped
+	lookup.ExpectQuery().WithArgs("Monitor LG 24 inch", 7, f.Items[1].PurchaseDate).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectCommit()
+
+	var calls []auditCall
+	result, err := Seed(context.Background(), db, config.DriverPostgres, f, false, recordAudit(&calls))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if result.Categories != 1 || result.Items != 1 || result.Skipped != 1 {
+		t.Errorf("unexpected seed result: %+v", result)
+	}
+	expected := []auditCall{{models.AuditCategory, 7, models.AuditCreate}, {models.AuditItem, 11, models.AuditCreate}}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("expected the created rows in the audit log, got %+v", calls)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestSeed_TrashedCategory(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	f := DemoFixture()
+	f.Categories = f.Categories[:1]
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO categories").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT id, deleted_at IS NULL FROM categories").WithArgs("Elektronik").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "live"}).AddRow(7, false))
+	mock.ExpectRollback()
+
+	var calls []auditCall
+	_, err = Seed(context.Background(), db, config.DriverPostgres, f, false, recordAudit(&calls))
+	if err == nil || !strings.Contains(err.Error(), "category 'Elektronik' is in the trash") {
+		t.Errorf("expected an error about the trashed category, got %v", err)
+	}
+	if len(calls) != 0 {
+		t.Errorf("expected nothing in the audit log, got %+v", calls)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestSeed_ResetKeepsIDs(t *testing.T) {
+	for _, tt := range []struct {
+		driver     string
+		statements []string
+	}{
+		{config.DriverPostgres, []string{"TRUNCATE items, categories"}},
+		{config.DriverSQLite, []string{"DELETE FROM items", "DELETE FROM categories"}},
+	} {
+		t.Run(tt.driver, func(t *testing.T) {
+			// matched exactly, so the ids are not restarted behind the statements
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			defer db.Close()
+
+			mock.ExpectBegin()
+			mock.ExpectQuery("SELECT id FROM items ORDER BY id").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+			mock.ExpectQuery("SELECT id FROM categories ORDER BY id").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			for _, stmt := range tt.statements {
+				mock.ExpectExec(stmt).WillReturnResult(sqlmock.NewResult(0, 0))
+			}
+			mock.ExpectCommit()
+
+			var calls []auditCall
+			if _, err := Seed(context.Background(), db, tt.driver, Fixture{Name: "empty"}, true, recordAudit(&calls)); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			// the removed rows are recorded as purged before they are deleted
+			expected := []auditCall{{models.AuditItem, 3, models.AuditPurge}, {models.AuditCategory, 1, models.AuditPurge}}
+			if !reflect.DeepEqual(calls, expected) {
+				t.Errorf("expected the removed rows in the audit log, got %+v", calls)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
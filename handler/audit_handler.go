@@ -0,0 +1,122 @@
+package handler
+
+import (
+    "context"
+    "fmt"
+    "io"
+    "strings"
+    "text/tabwriter"
+
+    "mini_project3/models"
+    "mini_project3/output"
+    "mini_project3/service"
+)
+
+// auditActions names the actions of the audit log in the table format
+var auditActions = map[string]string{
+    models.AuditCreate:  "Dibuat",
+    models.AuditUpdate:  "Diubah",
+    models.AuditDispose: "Dilepas",
+    models.AuditDelete:  "Dihapus",
+    models.AuditRestore: "Dipulihkan",
+    models.AuditPurge:   "Dihapus permanen",
+}
+
+// auditEntities names the entities of the audit log in the table format
+var auditEntities = map[string]string{
+    models.AuditItem:     "Barang",
+    models.AuditCategory: "Kategori",
+}
+
+type AuditHandler struct {
+    service *service.AuditService
+    w       io.Writer
+    format  output.Format
+}
+
+// NewAuditHandler creates AuditHandler writing to w; entries are listed in format
+func NewAuditHandler(service *service.AuditService, w io.Writer, format output.Format) *AuditHandler {
+    return &AuditHandler{service: service, w: w, format: format}
+}
+
+// ListAudit shows the changes of entity id since since, see service.AuditService.List
+func (h *AuditHandler) ListAudit(ctx context.Context, entity string, id int, since string) ([]models.AuditEntry, error) {
+    entries, err := h.service.List(ctx, entity, id, since)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get audit log: %w", err)
+    }
+    if h.format != output.Table {
+        return entries, output.Write(h.w, h.format, entries)
+    }
+
+    if len(entries) == 0 {
+        fmt.Fprintln(h.w, "Tidak ada perubahan yang tercatat.")
+        return entries, nil
+    }
+
+    w := tabwriter.NewWriter(h.w, 0, 0, 3, ' ', tabwriter.TabIndent)
+    fmt.Fprintln(w, "Waktu\tPengguna\tData\tAksi\tPerubahan")
+    fmt.Fprintln(w, "---\t---\t---\t---\t---")
+    for _, entry := range entries {
+        fmt.Fprintf(w, "%s\t%s\t%s #%d\t%s\t%s\n",
+            entry.At.Format("2006-01-02 15:04"),
+            entry.Actor,
+            auditEntities[entry.Entity],
+            entry.EntityID,
+            auditActions[entry.Action],
+            formatChanges(entry.Changes))
+    }
+
+    return entries, w.Flush()
+}
+
+// ItemHistory shows every recorded change of item id with the command that made it
+func (h *AuditHandler) ItemHistory(ctx context.Context, id int) ([]models.AuditEntry, error) {
+    entries, err := h.service.History(ctx, id)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get item history: %w", err)
+    }
+    if h.format != output.Table {
+        return entries, output.Write(h.w, h.format, entries)
+    }
+
+    if len(entries) == 0 {
+        fmt.Fprintf(h.w, "Belum ada riwayat untuk barang dengan ID %d.\n", id)
+        return entries, nil
+    }
+
+    fmt.Fprintf(h.w, "\n=== Riwayat Barang ID %d ===\n\n", id)
+    w := tabwriter.NewWriter(h.w, 0, 0, 3, ' ', tabwriter.TabIndent)
+    fmt.Fprintln(w, "Waktu\tPengguna\tAksi\tPerubahan\tPerintah")
+    fmt.Fprintln(w, "---\t---\t---\t---\t---")
+    for _, entry := range entries {
+        fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+            entry.At.Format("2006-01-02 15:04"),
+            entry.Actor,
+            auditActions[entry.Action],
+            formatChanges(entry.Changes),
+            entry.Command)
+    }
+
+    return entries, w.Flush()
+}
+
+// formatChanges writes changes on one line as field: before → after
+func formatChanges(changes []models.FieldChange) string {
+    if len(changes) == 0 {
+        return "-"
+    }
+    parts := make([]string, len(changes))
+    for i, change := range changes {
+        parts[i] = fmt.Sprintf("%s: %s → %s", change.Field, valueOrDash(change.Before), valueOrDash(change.After))
+    }
+    return strings.Join(parts, "; ")
+}
+
+// valueOrDash shows an empty value as -
+func valueOrDash(value string) string {
+    if value == "" {
+        return "-"
+    }
+    return value
+}
\ No newline at end of file
@@ -1,33 +1,42 @@
 package handler
 
 import (
+    "context"
     "fmt"
-    "os"
+    "io"
     "text/tabwriter"
 
+    "mini_project3/models"
+    "mini_project3/output"
     "mini_project3/service"
 )
 
 type CategoryHandler struct {
     service *service.CategoryService
+    w       io.Writer
+    format  output.Format
 }
 
-func NewCategoryHandler(service *service.CategoryService) *CategoryHandler {
-    return &CategoryHandler{service: service}
+// NewCategoryHandler creates CategoryHandler writing to w; list and get commands are printed in format
+func NewCategoryHandler(service *service.CategoryService, w io.Writer, format output.Format) *CategoryHandler {
+    return &CategoryHandler{service: service, w: w, format: format}
 }
 
-func (h *CategoryHandler) ListCategories() error {
-    categories, err := h.service.GetAll()
+func (h *CategoryHandler) ListCategories(ctx context.Context) ([]models.Category, error) {
+    categories, err := h.service.GetAll(ctx)
     if err != nil {
-        return fmt.Errorf("failed to get categories: %w", err)
+        return nil, fmt.Errorf("failed to get categories: %w", err)
+    }
+    if h.format != output.Table {
+        return categories, output.Write(h.w, h.format, categories)
     }
 
     if len(categories) == 0 {
-        fmt.Println("No categories found.")
-        return nil
+        fmt.Fprintln(h.w, "No categories found.")
+        return categories, nil
     }
 
-    w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', tabwriter.TabIndent)
+    w := tabwriter.NewWriter(h.w, 0, 0, 3, ' ', tabwriter.TabIndent)
     fmt.Fprintln(w, "ID\tNama\tDeskripsi\tDibuat")
     fmt.Fprintln(w, "---\t---\t---\t---")
 
@@ -39,50 +48,65 @@ func (h *CategoryHandler) ListCategories() error {
             cat.CreatedAt.Format("2006-01-02 15:04"))
     }
 
-    w.Flush()
-    return nil
+    return categories, w.Flush()
 }
 
-func (h *CategoryHandler) GetCategory(id int) error {
-    cat, err := h.service.GetByID(id)
+func (h *CategoryHandler) GetCategory(ctx context.Context, id int) (*models.Category, error) {
+    cat, err := h.service.GetByID(ctx, id)
     if err != nil {
-        return fmt.Errorf("failed to get category: %w", err)
+        return nil, fmt.Errorf("failed to get category: %w", err)
+    }
+    if h.format != output.Table {
+        return cat, output.Write(h.w, h.format, cat)
     }
 
-    fmt.Printf("\n=== Detail Kategori ===\n")
-    fmt.Printf("ID          : %d\n", cat.ID)
-    fmt.Printf("Nama        : %s\n", cat.Name)
-    fmt.Printf("Deskripsi   : %s\n", cat.Description)
-    fmt.Printf("Dibuat      : %s\n", cat.CreatedAt.Format("2006-01-02 15:04:05"))
-    fmt.Printf("Diperbarui  : %s\n", cat.UpdatedAt.Format("2006-01-02 15:04:05"))
-
-    return nil
+    fmt.Fprintf(h.w, "\n=== Detail Kategori ===\n")
+    fmt.Fprintf(h.w, "ID          : %d\n", cat.ID)
+    fmt.Fprintf(h.w, "Nama        : %s\n", cat.Name)
+    fmt.Fprintf(h.w, "Deskripsi   : %s\n", cat.Description)
+    fmt.Fprintf(h.w, "Depresiasi  : %s\n", policyText(cat.DepreciationPolicy, "bawaan ("+policyText(service.DefaultDepreciationPolicy, "")+")"))
+    fmt.Fprintf(h.w, "Akun Jurnal : %s\n", accountText(cat.JournalAccounts))
+    fmt.Fprintf(h.w, "Dibuat      : %s\n", cat.CreatedAt.Format("2006-01-02 15:04:05"))
+    fmt.Fprintf(h.w, "Diperbarui  : %s\n", cat.UpdatedAt.Format("2006-01-02 15:04:05"))
+    fmt.Fprintf(h.w, "Versi       : %d\n", cat.Version)
+
+    return cat, nil
 }
 
-func (h *CategoryHandler) CreateCategory(name, description string) error {
-    cat, err := h.service.Create(name, description)
+func (h *CategoryHandler) CreateCategory(ctx context.Context, name, description string, policy models.DepreciationPolicy, accounts models.JournalAccounts) (*models.Category, error) {
+    cat, err := h.service.Create(ctx, name, description, policy, accounts)
     if err != nil {
-        return fmt.Errorf("failed to create category: %w", err)
+        return nil, fmt.Errorf("failed to create category: %w", err)
     }
 
-    fmt.Printf("\n✓ Kategori berhasil ditambahkan dengan ID: %d\n", cat.ID)
-    return nil
+    fmt.Fprintf(h.w, "\n✓ Kategori berhasil ditambahkan dengan ID: %d\n", cat.ID)
+    return cat, nil
 }
 
-func (h *CategoryHandler) UpdateCategory(id int, name, description string) error {
-    if err := h.service.Update(id, name, description); err != nil {
+func (h *CategoryHandler) UpdateCategory(ctx context.Context, id int, patch service.CategoryPatch, pre service.Precondition) error {
+    if err := h.service.Patch(ctx, id, patch, pre); err != nil {
         return fmt.Errorf("failed to update category: %w", err)
     }
 
-    fmt.Printf("\n✓ Kategori dengan ID %d berhasil diperbarui\n", id)
+    fmt.Fprintf(h.w, "\n✓ Kategori dengan ID %d berhasil diperbarui\n", id)
     return nil
 }
 
-func (h *CategoryHandler) DeleteCategory(id int) error {
-    if err := h.service.Delete(id); err != nil {
+func (h *CategoryHandler) DeleteCategory(ctx context.Context, id int) error {
+    if err := h.service.Delete(ctx, id); err != nil {
         return fmt.Errorf("failed to delete category: %w", err)
     }
 
-    fmt.Printf("\n✓ Kategori dengan ID %d berhasil dihapus\n", id)
+    fmt.Fprintf(h.w, "\n✓ Kategori dengan ID %d berhasil dihapus\n", id)
+    fmt.Fprintf(h.w, "Kategori dipindahkan ke tong sampah, pulihkan dengan 'category restore --id %d'\n", id)
+    return nil
+}
+
+func (h *CategoryHandler) RestoreCategory(ctx context.Context, id int) error {
+    if err := h.service.Restore(ctx, id); err != nil {
+        return fmt.Errorf("failed to restore category: %w", err)
+    }
+
+    fmt.Fprintf(h.w, "\n✓ Kategori dengan ID %d berhasil dipulihkan\n", id)
     return nil
 }
\ No newline at end of file
@@ -0,0 +1,78 @@
+package handler
+
+import (
+    "context"
+    "fmt"
+    "io"
+    "text/tabwriter"
+    "time"
+
+    "mini_project3/models"
+    "mini_project3/money"
+    "mini_project3/output"
+    "mini_project3/service"
+)
+
+type FXHandler struct {
+    service *service.FXService
+    w       io.Writer
+    format  output.Format
+}
+
+// NewFXHandler creates FXHandler writing to w; the list command is printed in format
+func NewFXHandler(service *service.FXService, w io.Writer, format output.Format) *FXHandler {
+    return &FXHandler{service: service, w: w, format: format}
+}
+
+func (h *FXHandler) SetRate(ctx context.Context, currency string, date time.Time, rate money.Rate) (*models.ExchangeRate, error) {
+    exchangeRate, err := h.service.Set(ctx, currency, date, rate)
+    if err != nil {
+        return nil, fmt.Errorf("failed to set exchange rate: %w", err)
+    }
+
+    fmt.Fprintf(h.w, "\n✓ Kurs %s tanggal %s disimpan: 1 %s = %s %s\n",
+        exchangeRate.Currency,
+        exchangeRate.Date.Format("2006-01-02"),
+        exchangeRate.Currency,
+        exchangeRate.Rate,
+        service.BaseCurrency)
+    return exchangeRate, nil
+}
+
+func (h *FXHandler) ListRates(ctx context.Context, currency string) ([]models.ExchangeRate, error) {
+    rates, err := h.service.GetAll(ctx, currency)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get exchange rates: %w", err)
+    }
+    if h.format != output.Table {
+        return rates, output.Write(h.w, h.format, rates)
+    }
+
+    if len(rates) == 0 {
+        fmt.Fprintln(h.w, "Belum ada kurs. Tambahkan dengan 'inventory fx set' atau 'inventory fx import'.")
+        return rates, nil
+    }
+
+    w := tabwriter.NewWriter(h.w, 0, 0, 3, ' ', tabwriter.TabIndent)
+    fmt.Fprintf(w, "Mata Uang\tTanggal\tKurs (%s)\n", service.BaseCurrency)
+    fmt.Fprintln(w, "---\t---\t---")
+
+    for _, rate := range rates {
+        fmt.Fprintf(w, "%s\t%s\t%s\n",
+            rate.Currency,
+            rate.Date.Format("2006-01-02"),
+            rate.Rate)
+    }
+
+    return rates, w.Flush()
+}
+
+func (h *FXHandler) ImportRates(ctx context.Context, r io.Reader) ([]models.ExchangeRate, error) {
+    rates, err := h.service.Import(ctx, r)
+    if err != nil {
+        return nil, fmt.Errorf("failed to import exchange rates: %w", err)
+    }
+
+    fmt.Fprintf(h.w, "\n✓ %d kurs berhasil diimpor\n", len(rates))
+    return rates, nil
+}
\ No newline at end of file
@@ -0,0 +1,715 @@
+package handler
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "errors"
+    "flag"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+
+    "mini_project3/apperrors"
+    "mini_project3/models"
+    "mini_project3/money"
+    "mini_project3/output"
+    "mini_project3/service"
+    "mini_project3/spreadsheet"
+)
+
+// Run `go test ./handler -update` to rewrite testdata/*.golden after an intended output change
+var update = flag.Bool("update", false, "update golden files")
+
+// fixedNow is the clock of every golden test, so days used and depreciation never change
+var fixedNow = time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+
+func date(year int, month time.Month, day int) time.Time {
+    return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// stubCategoryRepo, stubItemRepo and stubExchangeRateRepo serve fixed rows with stable timestamps
+type stubCategoryRepo struct {
+    categories []models.Category
+    trash      []models.TrashEntry
+}
+
+func (r *stubCategoryRepo) GetAll(ctx context.Context) ([]models.Category, error) {
+    return r.categories, nil
+}
+
+func (r *stubCategoryRepo) GetByID(ctx context.Context, id int) (*models.Category, error) {
+    for _, cat := range r.categories {
+        if cat.ID == id {
+            return &cat, nil
+        }
+    }
+    return nil, &apperrors.NotFoundError{Entity: "category", ID: id}
+}
+
+func (r *stubCategoryRepo) Create(ctx context.Context, cat *models.Category) error {
+    cat.ID = len(r.categories) + 1
+    r.categories = append(r.categories, *cat)
+    return nil
+}
+
+func (r *stubCategoryRepo) Update(ctx context.Context, cat *models.Category) error {
+    _, err := r.GetByID(ctx, cat.ID)
+    return err
+}
+
+func (r *stubCategoryRepo) Delete(ctx context.Context, id int) error {
+    _, err := r.GetByID(ctx, id)
+    return err
+}
+
+func (r *stubCategoryRepo) CheckNameExists(ctx context.Context, name string, excludeID int) (bool, error) {
+    return false, nil
+}
+
+func (r *stubCategoryRepo) GetDeleted(ctx context.Context) ([]models.TrashEntry, error) {
+    return r.trash, nil
+}
+
+func (r *stubCategoryRepo) Restore(ctx context.Context, id int) error {
+    return restoreStub(r.trash, "deleted category", id)
+}
+
+func (r *stubCategoryRepo) Purge(ctx context.Context, before time.Time) (int, error) {
+    return purgeStub(r.trash, before), nil
+}
+
+// restoreStub and purgeStub look up a stub trash without changing it
+func restoreStub(trash []models.TrashEntry, entity string, id int) error {
+    for _, entry := range trash {
+        if entry.ID == id {
+            return nil
+        }
+    }
+    return &apperrors.NotFoundError{Entity: entity, ID: id}
+}
+
+func purgeStub(trash []models.TrashEntry, before time.Time) int {
+    purged := 0
+    for _, entry := range trash {
+        if entry.DeletedAt.Before(before) {
+            purged++
+        }
+    }
+    return purged
+}
+
+type stubItemRepo struct {
+    items []models.Item
+    trash []models.TrashEntry
+}
+
+func (r *stubItemRepo) GetAll(ctx context.Context) ([]models.Item, error) {
+    return r.items, nil
+}
+
+func (r *stubItemRepo) GetByID(ctx context.Context, id int) (*models.Item, error) {
+    for _, item := range r.items {
+        if item.ID == id {
+            return &item, nil
+        }
+    }
+    return nil, &apperrors.NotFoundError{Entity: "item", ID: id}
+}
+
+func (r *stubItemRepo) Create(ctx context.Context, item *models.Item) error {
+    item.ID = len(r.items) + 1
+    r.items = append(r.items, *item)
+    return nil
+}
+
+func (r *stubItemRepo) Update(ctx context.Context, item *models.Item) error {
+    _, err := r.GetByID(ctx, item.ID)
+    return err
+}
+
+func (r *stubItemRepo) Dispose(ctx context.Context, id int, disposal models.Disposal) error {
+    _, err := r.GetByID(ctx, id)
+    return err
+}
+
+func (r *stubItemRepo) Delete(ctx context.Context, id int) error {
+    _, err := r.GetByID(ctx, id)
+    return err
+}
+
+func (r *stubItemRepo) GetDeleted(ctx context.Context) ([]models.TrashEntry, error) {
+    return r.trash, nil
+}
+
+func (r *stubItemRepo) Restore(ctx context.Context, id int) error {
+    return restoreStub(r.trash, "deleted item", id)
+}
+
+func (r *stubItemRepo) Purge(ctx context.Context, before time.Time) (int, error) {
+    return purgeStub(r.trash, before), nil
+}
+
+func (r *stubItemRepo) Search(ctx context.Context, keyword string) ([]models.Item, error) {
+    var items []models.Item
+    for _, item := range r.items {
+        if strings.Contains(strings.ToLower(item.Name), strings.ToLower(keyword)) {
+            items = append(items, item)
+        }
+    }
+    return items, nil
+}
+
+func (r *stubItemRepo) GetItemsNeedReplacement(ctx context.Context, days int, asOf time.Time) ([]models.Item, error) {
+    var items []models.Item
+    for _, item := range r.items {
+        if asOf.Sub(item.PurchaseDate).Hours()/24 > float64(days) && !item.DisposedBy(asOf) {
+            items = append(items, item)
+        }
+    }
+    return items, nil
+}
+
+type stubExchangeRateRepo struct {
+    rates []models.ExchangeRate
+}
+
+func (r *stubExchangeRateRepo) Set(ctx context.Context, rate *models.ExchangeRate) error {
+    rate.CreatedAt = fixedNow
+    return nil
+}
+
+func (r *stubExchangeRateRepo) GetAll(ctx context.Context, currency string) ([]models.ExchangeRate, error) {
+    var rates []models.ExchangeRate
+    for _, rate := range r.rates {
+        if currency == "" || rate.Currency == currency {
+            rates = append(rates, rate)
+        }
+    }
+    return rates, nil
+}
+
+func (r *stubExchangeRateRepo) GetOnOrBefore(ctx context.Context, currency string, date time.Time) (*models.ExchangeRate, error) {
+    var found *models.ExchangeRate
+    for i, rate := range r.rates {
+        if rate.Currency == currency && !rate.Date.After(date) {
+            found = &r.rates[i]
+        }
+    }
+    if found == nil {
+        return nil, &apperrors.RateNotFoundError{Currency: currency, Date: date}
+    }
+    return found, nil
+}
+
+// stubJournalRepo keeps posted journals in memory and posts them at fixedNow
+type stubJournalRepo struct {
+    journals map[string]models.Journal
+}
+
+func (r *stubJournalRepo) Get(ctx context.Context, month string) (*models.Journal, error) {
+    journal, ok := r.journals[month]
+    if !ok {
+        return nil, nil
+    }
+    journal.Lines = append([]models.JournalLine(nil), journal.Lines...)
+    return &journal, nil
+}
+
+func (r *stubJournalRepo) Post(ctx context.Context, journal *models.Journal) error {
+    if _, ok := r.journals[journal.Month]; ok {
+        return &apperrors.PeriodLockedError{Month: journal.Month}
+    }
+    postedAt := fixedNow
+    journal.PostedAt = &postedAt
+    r.journals[journal.Month] = *journal
+    return nil
+}
+
+func sampleData() (*stubCategoryRepo, *stubItemRepo) {
+    created := time.Date(2025, 1, 2, 9, 30, 0, 0, time.UTC)
+    updated := time.Date(2025, 3, 4, 16, 45, 10, 0, time.UTC)
+    categories := &stubCategoryRepo{categories: []models.Category{
+        {ID: 1, Name: "Elektronik", Description: "Peralatan elektronik kantor", CreatedAt: created, UpdatedAt: updated, Version: 2,
+            DepreciationPolicy: models.DepreciationPolicy{TaxGroup: "kelompok-1"}},
+        {ID: 2, Name: "Furniture", Description: "Mebel dan perabotan kantor", CreatedAt: created, UpdatedAt: created, Version: 1,
+            DepreciationPolicy: models.DepreciationPolicy{Method: service.MethodStraightLine, UsefulLifeMonths: 96, SalvagePercent: money.MustParseRate("10")},
+            JournalAccounts:    models.JournalAccounts{ExpenseAccount: "6-1200", AccumulatedAccount: "1-2920"}},
+    }}
+    items := &stubItemRepo{items: []models.Item{
+        {ID: 1, Name: "Laptop Dell XPS 13", CategoryID: 1, CategoryName: "Elektronik", Price: money.FromInt(15000000), Currency: "IDR", PurchaseDate: date(2024, 6, 1), CreatedAt: created, UpdatedAt: updated, Version: 2},
+        {ID: 2, Name: "Monitor LG 24 inch", CategoryID: 1, CategoryName: "Elektronik", Price: money.MustParse("150.75"), Currency: "USD", PurchaseDate: date(2025, 12, 20), CreatedAt: created, UpdatedAt: created, Version: 1,
+            DepreciationPolicy: models.DepreciationPolicy{Method: service.MethodDoubleDeclining, UsefulLifeMonths: 48, SalvageValue: money.FromInt(15), TaxMethod: service.MethodDecliningBalance}},
+        {ID: 3, Name: "Meja Kerja", CategoryID: 2, CategoryName: "Furniture", Price: money.FromInt(1500000), Currency: "IDR", PurchaseDate: date(2023, 5, 10), CreatedAt: created, UpdatedAt: created, Version: 1},
+    }}
+    return categories, items
+}
+
+// sampleRates are the rates of the golden tests, sorted by currency and date
+// like the real repositories return them
+var sampleRates = []models.ExchangeRate{
+    {Currency: "SGD", Date: date(2025, 12, 1), Rate: money.MustParseRate("12880.123456"), CreatedAt: fixedNow},
+    {Currency: "USD", Date: date(2023, 1, 1), Rate: money.MustParseRate("14850"), CreatedAt: fixedNow},
+    {Currency: "USD", Date: date(2024, 1, 1), Rate: money.MustParseRate("15400"), CreatedAt: fixedNow},
+    {Currency: "USD", Date: date(2025, 12, 1), Rate: money.MustParseRate("16650.5"), CreatedAt: fixedNow},
+}
+
+func newTestHandlers(categoryRepo *stubCategoryRepo, itemRepo *stubItemRepo, format output.Format, withRates bool) (*CategoryHandler, *ItemHandler, *FXHandler, *bytes.Buffer) {
+    var buf bytes.Buffer
+    rateRepo := &stubExchangeRateRepo{}
+    if withRates {
+        rateRepo.rates = sampleRates
+    }
+
+    fxService := service.NewFXService(rateRepo)
+    itemService := service.NewItemService(itemRepo, categoryRepo)
+    itemService.SetClock(func() time.Time { return fixedNow })
+    itemService.SetConverter(fxService)
+    return NewCategoryHandler(service.NewCategoryService(categoryRepo), &buf, format),
+        NewItemHandler(itemService, &buf, format),
+        NewFXHandler(fxService, &buf, format),
+        &buf
+}
+
+// assertGolden compares got with testdata/<name>.golden
+func assertGolden(t *testing.T, name string, got []byte) {
+    t.Helper()
+    path := filepath.Join("testdata", name+".golden")
+    if *update {
+        if err := os.WriteFile(path, got, 0o644); err != nil {
+            t.Fatal(err)
+        }
+    }
+
+    expected, err := os.ReadFile(path)
+    if err != nil {
+        t.Fatalf("error reading golden file (run with -update to create it): %s", err)
+    }
+    if !bytes.Equal(got, expected) {
+        t.Errorf("output differs from %s\n--- got ---\n%s\n--- expected ---\n%s", path, got, expected)
+    }
+}
+
+func TestHandlers_Golden(t *testing.T) {
+    ctx := context.Background()
+    purchaseDate := date(2025, 1, 1)
+
+    tests := []struct {
+        name   string
+        format output.Format
+        empty  bool
+        run    func(c *CategoryHandler, i *ItemHandler, f *FXHandler) error
+    }{
+        {"category_list", output.Table, false, func(c *CategoryHandler, i *ItemHandler, f *FXHandler) error { _, err := c.ListCategories(ctx); return err }},
+        {"category_list_empty", output.Table, true, func(c *CategoryHandler, i *ItemHandler, f *FXHandler) error { _, err := c.ListCategories(ctx); return err }},
+        {"category_get", output.Table, false, func(c *CategoryHandler, i *ItemHandler, f *FXHandler) error { _, err := c.GetCategory(ctx, 1); return err }},
+        {"category_get_policy", output.Table, false, func(c *CategoryHandler, i *ItemHandler, f *FXHandler) error { _, err := c.GetCategory(ctx, 2); return err }},
+        {"category_create", output.Table, false, func(c *CategoryHandler, i *ItemHandler, f *FXHandler) error { _, err := c.CreateCategory(ctx, "Jaringan", "", models.DepreciationPolicy{}, models.JournalAccounts{}); return err }},
+        {"category_update", output.Table, false, func(c *CategoryHandler, i *ItemHandler, f *FXHandler) error {
+            name := "Mebel"
+            return c.UpdateCategory(ctx, 2, service.CategoryPatch{Name: &name}, service.Precondition{})
+        }},
+        {"category_delete", output.Table, false, func(c *CategoryHandler, i *ItemHandler, f *FXHandler) error { return c.DeleteCategory(ctx, 2) }},
+        {"item_list", output.Table, false, func(c *CategoryHandler, i *ItemHandler, f *FXHandler) error { _, err := i.ListItems(ctx); return err }},
+        {"item_list_empty", output.Table, true, func(c *CategoryHandler, i *ItemHandler, f *FXHandler) error { _, err := i.ListItems(ctx); return err }},
+        {"item_get", output.Table, false, func(c *CategoryHandler, i *ItemHandler, f *FXHandler) error { _, err := i.GetItem(ctx, 1); return err }},
+        {"item_get_policy", output.Table, false, func(c *CategoryHandler, i *ItemHandler, f *FXHandler) error { _, err := i.GetItem(ctx, 2); return err }},
+        {"item_create", output.Table, false, func(c *CategoryHandler, i *ItemHandler, f *FXHandler) error {
+            _, err := i.CreateItem(ctx, "Printer", 1, money.FromInt(3500000), "IDR", purchaseDate, models.DepreciationPolicy{})
+            return err
+        }},
+        {"item_update", output.Table, false, func(c *CategoryHandler, i *ItemHandler, f *FXHandler) error {
+            name, price := "Monitor LG 27 inch", money.FromInt(3000000)
+            return i.UpdateItem(ctx, 2, service.ItemPatch{Name: &name, Price: &price}, service.Precondition{})
+        }},
+        {"item_delete", output.Table, false, func(c *CategoryHandler, i *ItemHandler, f *FXHandler) error { return i.DeleteItem(ctx, 3) }},
+        {"item_search", output.Table, false, func(c *CategoryHandler, i *ItemHandler, f *FXHandler) error { _, err := i.SearchItems(ctx, "LAPTOP"); return err }},
+        {"item_search_empty", output.Table, false, func(c *CategoryHandler, i *ItemHandler, f *FXHandler) error { _, err := i.SearchItems(ctx, "proyektor"); return err }},
+        {"item_replacement", output.Table, false, func(c *CategoryHandler, i *ItemHandler, f *FXHandler) error { _, err := i.ListItemsNeedReplacement(ctx); return err }},
+        {"item_replacement_empty", output.Table, true, func(c *CategoryHandler, i *ItemHandler, f *FXHandler) error { _, err := i.ListItemsNeedReplacement(ctx); return err }},
+        {"report_total", output.Table, false, func(c *CategoryHandler, i *ItemHandler, f *FXHandler) error { _, err := i.ShowTotalInvestment(ctx, "IDR"); return err }},
+        {"report_item", output.Table, false, func(c *CategoryHandler, i *ItemHandler, f *FXHandler) error { _, err := i.ShowItemDepreciation(ctx, 1, "IDR"); return err }},
+        {"report_total_usd", output.Table, false, func(c *CategoryHandler, i *ItemHandler, f *FXHandler) error { _, err := i.ShowTotalInvestment(ctx, "usd"); return err }},
+        {"report_item_straight_line", output.Table, false, func(c *CategoryHandler, i *ItemHandler, f *FXHandler) error { _, err := i.ShowItemDepreciation(ctx, 3, "IDR"); return err }},
+        {"report_total_json", output.JSON, false, func(c *CategoryHandler, i *ItemHandler, f *FXHandler) error { _, err := i.ShowTotalInvestment(ctx, "IDR"); return err }},
+        {"report_item_usd", output.Table, false, func(c *CategoryHandler, i *ItemHandler, f *FXHandler) error { _, err := i.ShowItemDepreciation(ctx, 1, "USD"); return err }},
+        {"report_item_foreign", output.Table, false, func(c *CategoryHandler, i *ItemHandler, f *FXHandler) error { _, err := i.ShowItemDepreciation(ctx, 2, "IDR"); return err }},
+        {"report_item_en", output.Table, false, func(c *CategoryHandler, i *ItemHandler, f *FXHandler) error {
+            i.SetLocale(money.English)
+            _, err := i.ShowItemDepreciation(ctx, 2, "SGD")
+            return err
+        }},
+        {"fx_list", output.Table, false, func(c *CategoryHandler, i *ItemHandler, f *FXHandler) error { _, err := f.ListRates(ctx, ""); return err }},
+        {"fx_list_empty", output.Table, true, func(c *CategoryHandler, i *ItemHandler, f *FXHandler) error { _, err := f.ListRates(ctx, "eur"); return err }},
+        {"fx_set", output.Table, false, func(c *CategoryHandler, i *ItemHandler, f *FXHandler) error {
+            _, err := f.SetRate(ctx, "eur", date(2026, 1, 2), money.MustParseRate("18250.75"))
+            return err
+        }},
+        {"fx_import", output.Table, false, func(c *CategoryHandler, i *ItemHandler, f *FXHandler) error {
+            _, err := f.ImportRates(ctx, strings.NewReader("date,currency,rate\n2026-01-02,EUR,18250.75\n2026-01-02,JPY,108.5\n"))
+            return err
+        }},
+        {"fx_list_csv", output.CSV, false, func(c *CategoryHandler, i *ItemHandler, f *FXHandler) error { _, err := f.ListRates(ctx, "USD"); return err }},
+        {"item_list_json", output.JSON, false, func(c *CategoryHandler, i *ItemHandler, f *FXHandler) error { _, err := i.ListItems(ctx); return err }},
+        {"category_get_yaml", output.YAML, false, func(c *CategoryHandler, i *ItemHandler, f *FXHandler) error { _, err := c.GetCategory(ctx, 1); return err }},
+        {"report_total_csv", output.CSV, false, func(c *CategoryHandler, i *ItemHandler, f *FXHandler) error { _, err := i.ShowTotalInvestment(ctx, "IDR"); return err }},
+        {"report_item_tsv", output.TSV, false, func(c *CategoryHandler, i *ItemHandler, f *FXHandler) error { _, err := i.ShowItemDepreciation(ctx, 3, "IDR"); return err }},
+        {"report_fiscal", output.Table, false, func(c *CategoryHandler, i *ItemHandler, f *FXHandler) error { _, err := i.ShowFiscalReport(ctx, "IDR"); return err }},
+        {"report_fiscal_empty", output.Table, true, func(c *CategoryHandler, i *ItemHandler, f *FXHandler) error { _, err := i.ShowFiscalReport(ctx, "IDR"); return err }},
+        {"report_schedule", output.Table, false, func(c *CategoryHandler, i *ItemHandler, f *FXHandler) error {
+            _, err := i.ShowDepreciationSchedule(ctx, 3, "IDR", service.PeriodYear)
+            return err
+        }},
+        {"report_schedule_csv", output.CSV, false, func(c *CategoryHandler, i *ItemHandler, f *FXHandler) error {
+            _, err := i.ShowDepreciationSchedule(ctx, 2, "IDR", service.PeriodYear)
+            return err
+        }},
+        {"report_fiscal_csv", output.CSV, false, func(c *CategoryHandler, i *ItemHandler, f *FXHandler) error { _, err := i.ShowFiscalReport(ctx, "IDR"); return err }},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            categoryRepo, itemRepo := sampleData()
+            if tt.empty {
+                categoryRepo.categories, itemRepo.items = nil, nil
+            }
+            categoryHandler, itemHandler, fxHandler, buf := newTestHandlers(categoryRepo, itemRepo, tt.format, !tt.empty)
+
+            if err := tt.run(categoryHandler, itemHandler, fxHandler); err != nil {
+                t.Fatalf("unexpected error: %s", err)
+            }
+            assertGolden(t, tt.name, buf.Bytes())
+        })
+    }
+}
+
+func TestItemHandler_ReturnsRenderedData(t *testing.T) {
+    categoryRepo, itemRepo := sampleData()
+    _, itemHandler, _, _ := newTestHandlers(categoryRepo, itemRepo, output.Table, true)
+
+    items, err := itemHandler.ListItemsNeedReplacement(context.Background())
+    if err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+    if len(items) != 2 || items[0].ID != 1 || items[1].ID != 3 {
+        t.Errorf("expected items 1 and 3, got %+v", items)
+    }
+
+    summary, err := itemHandler.ShowTotalInvestment(context.Background(), "IDR")
+    if err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+    // 150.75 USD at the 2025-12-01 rate of 16650.5 is Rp 2.510.062,875, rounded half to even
+    if summary.TotalOriginal != money.MustParse("19010062.88") {
+        t.Errorf("expected total original 19010062.88, got %v", summary.TotalOriginal)
+    }
+}
+
+func TestItemHandler_AsOf(t *testing.T) {
+    ctx := context.Background()
+    tests := []struct {
+        name string
+        run  func(i *ItemHandler) error
+    }{
+        {"item_list_as_of", func(i *ItemHandler) error { _, err := i.ListItems(ctx); return err }},
+        {"item_replacement_as_of", func(i *ItemHandler) error { _, err := i.ListItemsNeedReplacement(ctx); return err }},
+        {"report_total_as_of", func(i *ItemHandler) error { _, err := i.ShowTotalInvestment(ctx, "IDR"); return err }},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            categoryRepo, itemRepo := sampleData()
+            // before the monitor was bought, so no USD rate is needed
+            _, itemHandler, _, buf := newTestHandlers(categoryRepo, itemRepo, output.Table, false)
+            itemHandler.service.SetClock(func() time.Time { return date(2025, 12, 1) })
+
+            if err := tt.run(itemHandler); err != nil {
+                t.Fatalf("unexpected error: %s", err)
+            }
+            assertGolden(t, tt.name, buf.Bytes())
+        })
+    }
+}
+
+func TestItemHandler_Disposal(t *testing.T) {
+    ctx := context.Background()
+    tests := []struct {
+        name   string
+        format output.Format
+        run    func(i *ItemHandler) error
+    }{
+        {"item_dispose", output.Table, func(i *ItemHandler) error {
+            _, err := i.DisposeItem(ctx, 1, date(2025, 12, 31), "sold", money.FromInt(9000000))
+            return err
+        }},
+        {"item_get_disposed", output.Table, func(i *ItemHandler) error { _, err := i.GetItem(ctx, 3); return err }},
+        {"item_list_disposed", output.Table, func(i *ItemHandler) error { _, err := i.ListItems(ctx); return err }},
+        {"report_disposals", output.Table, func(i *ItemHandler) error { _, err := i.ShowDisposalReport(ctx, "IDR"); return err }},
+        {"report_disposals_csv", output.CSV, func(i *ItemHandler) error { _, err := i.ShowDisposalReport(ctx, "IDR"); return err }},
+        {"report_schedule_disposed", output.Table, func(i *ItemHandler) error {
+            _, err := i.ShowDepreciationSchedule(ctx, 3, "IDR", service.PeriodYear)
+            return err
+        }},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            categoryRepo, itemRepo := sampleData()
+            disposedAt := date(2025, 6, 30)
+            itemRepo.items[2].Disposal = models.Disposal{DisposedAt: &disposedAt, DisposalMethod: service.DisposalScrapped, DisposalProceeds: money.FromInt(250000)}
+            _, itemHandler, _, buf := newTestHandlers(categoryRepo, itemRepo, tt.format, true)
+
+            if err := tt.run(itemHandler); err != nil {
+                t.Fatalf("unexpected error: %s", err)
+            }
+            assertGolden(t, tt.name, buf.Bytes())
+        })
+    }
+}
+
+func TestItemHandler_Import(t *testing.T) {
+    ctx := context.Background()
+    file := "Nama Barang,category,price,currency,purchase_date,method,life\n" +
+        "Printer Epson L3210,elektronik,3500000,,2025-01-10,,\n" +
+        "Kursi Kantor,Furniture,-750000,,2025-02-01,,\n" +
+        "Router Mikrotik,Jaringan,1250000,,2025-03-05,straight-line,60\n" +
+        "Proyektor,Elektronik,899.99,USD,05/04/2025,,\n"
+    columns := map[string]string{"name": "Nama Barang"}
+
+    tests := []struct {
+        name    string
+        format  output.Format
+        opts    service.ImportOptions
+        wantErr bool
+    }{
+        {"item_import", output.Table, service.ImportOptions{Columns: columns, CreateCategories: true, SkipInvalid: true}, false},
+        {"item_import_dry_run", output.Table, service.ImportOptions{Columns: columns, DryRun: true}, true},
+        {"item_import_invalid", output.Table, service.ImportOptions{Columns: columns, CreateCategories: true}, true},
+        {"item_import_csv", output.CSV, service.ImportOptions{Columns: columns, CreateCategories: true, SkipInvalid: true}, false},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            rows, err := spreadsheet.ReadCSV(strings.NewReader(file))
+            if err != nil {
+                t.Fatalf("unexpected error: %s", err)
+            }
+            categoryRepo, itemRepo := sampleData()
+            _, itemHandler, _, buf := newTestHandlers(categoryRepo, itemRepo, tt.format, true)
+
+            report, err := itemHandler.ImportItems(ctx, rows, tt.opts)
+            if tt.wantErr != (err != nil) {
+                t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+            }
+            if err != nil && !errors.Is(err, apperrors.ErrValidation) {
+                t.Errorf("expected a validation error, got %v", err)
+            }
+            if report == nil {
+                t.Fatal("expected the report of every row")
+            }
+            assertGolden(t, tt.name, buf.Bytes())
+        })
+    }
+}
+
+func TestTrashHandler_Golden(t *testing.T) {
+    ctx := context.Background()
+    tests := []struct {
+        name   string
+        format output.Format
+        empty  bool
+        run    func(tr *TrashHandler, c *CategoryHandler, i *ItemHandler) error
+    }{
+        {"trash_list", output.Table, false, func(tr *TrashHandler, c *CategoryHandler, i *ItemHandler) error { _, err := tr.ListTrash(ctx); return err }},
+        {"trash_list_empty", output.Table, true, func(tr *TrashHandler, c *CategoryHandler, i *ItemHandler) error { _, err := tr.ListTrash(ctx); return err }},
+        {"trash_list_json", output.JSON, false, func(tr *TrashHandler, c *CategoryHandler, i *ItemHandler) error { _, err := tr.ListTrash(ctx); return err }},
+        {"trash_purge", output.Table, false, func(tr *TrashHandler, c *CategoryHandler, i *ItemHandler) error { _, err := tr.PurgeTrash(ctx, "90d"); return err }},
+        {"item_restore", output.Table, false, func(tr *TrashHandler, c *CategoryHandler, i *ItemHandler) error { return i.RestoreItem(ctx, 4) }},
+        {"category_restore", output.Table, false, func(tr *TrashHandler, c *CategoryHandler, i *ItemHandler) error { return c.RestoreCategory(ctx, 3) }},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            categoryRepo, itemRepo := sampleData()
+            if !tt.empty {
+                itemRepo.trash = []models.TrashEntry{
+                    {Entity: models.TrashItem, ID: 4, Name: "Kursi Lipat", DeletedAt: time.Date(2025, 9, 1, 8, 15, 0, 0, time.UTC)},
+                    {Entity: models.TrashItem, ID: 5, Name: "Printer Canon", DeletedAt: time.Date(2026, 1, 10, 14, 0, 0, 0, time.UTC)},
+                }
+                categoryRepo.trash = []models.TrashEntry{
+                    {Entity: models.TrashCategory, ID: 3, Name: "Dapur", DeletedAt: time.Date(2025, 10, 20, 10, 30, 0, 0, time.UTC)},
+                }
+            }
+            categoryHandler, itemHandler, _, buf := newTestHandlers(categoryRepo, itemRepo, tt.format, true)
+            trashService := service.NewTrashService(itemRepo, categoryRepo)
+            trashService.SetClock(func() time.Time { return fixedNow })
+            trashHandler := NewTrashHandler(trashService, buf, tt.format)
+
+            if err := tt.run(trashHandler, categoryHandler, itemHandler); err != nil {
+                t.Fatalf("unexpected error: %s", err)
+            }
+            assertGolden(t, tt.name, buf.Bytes())
+        })
+    }
+}
+
+type stubAuditRepo struct {
+    entries []models.AuditEntry
+}
+
+func (r *stubAuditRepo) List(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, error) {
+    var entries []models.AuditEntry
+    for _, entry := range r.entries {
+        if (filter.Entity == "" || entry.Entity == filter.Entity) && (filter.EntityID == 0 || entry.EntityID == filter.EntityID) && !entry.At.Before(filter.Since) {
+            entries = append(entries, entry)
+        }
+    }
+    return entries, nil
+}
+
+func TestAuditHandler_Golden(t *testing.T) {
+    ctx := context.Background()
+    at := func(day, hour int) time.Time { return time.Date(2026, 1, day, hour, 0, 0, 0, time.UTC) }
+    laptop := `{"id":1,"name":"Laptop","category_id":1,"category_name":"Elektronik","price":15000000.00,"purchase_date":"2025-01-01T00:00:00Z","updated_at":"2026-01-02T09:00:00Z"}`
+    repo := &stubAuditRepo{entries: []models.AuditEntry{
+        {ID: 1, At: at(2, 9), Actor: "budi", Command: "inventory category create --name Elektronik", Entity: models.AuditCategory, EntityID: 1, Action: models.AuditCreate,
+            After: json.RawMessage(`{"id":1,"name":"Elektronik"}`)},
+        {ID: 2, At: at(2, 9), Actor: "budi", Command: `inventory item create --name Laptop --category 1 --price 15000000 --date 2025-01-01`, Entity: models.AuditItem, EntityID: 1, Action: models.AuditCreate,
+            After: json.RawMessage(laptop)},
+        {ID: 3, At: at(10, 14), Actor: "sari", Command: `inventory item update --id 1 --name "Laptop Dell" --price 14500000`, Entity: models.AuditItem, EntityID: 1, Action: models.AuditUpdate,
+            Before: json.RawMessage(laptop),
+            After:  json.RawMessage(`{"id":1,"name":"Laptop Dell","category_id":1,"category_name":"Elektronik","price":14500000.00,"purchase_date":"2025-01-01T00:00:00Z","updated_at":"2026-01-10T14:00:00Z"}`)},
+        {ID: 4, At: at(12, 8), Actor: "sari", Command: "inventory item delete --id 1", Entity: models.AuditItem, EntityID: 1, Action: models.AuditDelete,
+            Before: json.RawMessage(laptop)},
+    }}
+
+    tests := []struct {
+        name   string
+        format output.Format
+        run    func(a *AuditHandler) error
+    }{
+        {"audit_list", output.Table, func(a *AuditHandler) error { _, err := a.ListAudit(ctx, "", 0, ""); return err }},
+        {"audit_list_since", output.Table, func(a *AuditHandler) error { _, err := a.ListAudit(ctx, "item", 1, "7d"); return err }},
+        {"audit_list_empty", output.Table, func(a *AuditHandler) error { _, err := a.ListAudit(ctx, "category", 2, ""); return err }},
+        {"item_history", output.Table, func(a *AuditHandler) error { _, err := a.ItemHistory(ctx, 1); return err }},
+        {"item_history_json", output.JSON, func(a *AuditHandler) error { _, err := a.ItemHistory(ctx, 1); return err }},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            var buf bytes.Buffer
+            auditService := service.NewAuditService(repo)
+            auditService.SetClock(func() time.Time { return fixedNow })
+
+            if err := tt.run(NewAuditHandler(auditService, &buf, tt.format)); err != nil {
+                t.Fatalf("unexpected error: %s", err)
+            }
+            assertGolden(t, tt.name, buf.Bytes())
+        })
+    }
+}
+
+func TestJournalHandler_Golden(t *testing.T) {
+    ctx := context.Background()
+    tests := []struct {
+        name   string
+        format output.Format
+        run    func(j *JournalHandler) error
+    }{
+        {"report_journal", output.Table, func(j *JournalHandler) error { _, err := j.ShowJournal(ctx, "2025-12", "IDR", ""); return err }},
+        {"report_journal_empty", output.Table, func(j *JournalHandler) error { _, err := j.ShowJournal(ctx, "2020-01", "IDR", ""); return err }},
+        {"report_journal_csv", output.CSV, func(j *JournalHandler) error { _, err := j.ShowJournal(ctx, "2025-12", "IDR", ""); return err }},
+        {"report_journal_iif", output.Table, func(j *JournalHandler) error { _, err := j.ShowJournal(ctx, "2025-12", "IDR", "IIF"); return err }},
+        {"report_journal_post", output.Table, func(j *JournalHandler) error { _, err := j.PostJournal(ctx, "2025-12", "IDR"); return err }},
+        {"report_journal_posted", output.Table, func(j *JournalHandler) error {
+            if _, err := j.service.PostJournal(ctx, "2025-11", "IDR"); err != nil {
+                return err
+            }
+            _, err := j.ShowJournal(ctx, "2025-11", "IDR", "")
+            return err
+        }},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            categoryRepo, itemRepo := sampleData()
+            _, itemHandler, _, buf := newTestHandlers(categoryRepo, itemRepo, tt.format, true)
+            journalService := service.NewJournalService(itemHandler.service, &stubJournalRepo{journals: map[string]models.Journal{}})
+            journalService.SetClock(func() time.Time { return fixedNow })
+            journalHandler := NewJournalHandler(journalService, buf, tt.format)
+
+            if err := tt.run(journalHandler); err != nil {
+                t.Fatalf("unexpected error: %s", err)
+            }
+            assertGolden(t, tt.name, buf.Bytes())
+        })
+    }
+}
+
+// failOnceWriter fails the first write and accepts the others
+type failOnceWriter struct {
+    failed bool
+    buf    bytes.Buffer
+}
+
+func (w *failOnceWriter) Write(p []byte) (int, error) {
+    if !w.failed {
+        w.failed = true
+        return 0, errors.New("disk full")
+    }
+    return w.buf.Write(p)
+}
+
+func TestWriteIIF_ReportsWriteError(t *testing.T) {
+    journal := &models.Journal{Month: "2025-12", Date: date(2025, 12, 31), Lines: []models.JournalLine{
+        {Account: "6-1100", Description: "Penyusutan Elektronik 2025-12", Debit: money.FromInt(100000)},
+        {Account: "1-2900", Description: "Penyusutan Elektronik 2025-12", Credit: money.FromInt(100000)},
+    }}
+
+    w := &failOnceWriter{}
+    if err := writeIIF(w, journal); err == nil {
+        t.Errorf("expected the failed write to be reported, wrote %q", w.buf.String())
+    }
+}
+
+func TestJournalHandler_RejectsUnknownImportFormat(t *testing.T) {
+    categoryRepo, itemRepo := sampleData()
+    _, itemHandler, _, buf := newTestHandlers(categoryRepo, itemRepo, output.Table, true)
+    journalHandler := NewJournalHandler(service.NewJournalService(itemHandler.service, &stubJournalRepo{journals: map[string]models.Journal{}}), buf, output.Table)
+
+    if _, err := journalHandler.ShowJournal(context.Background(), "2025-12", "IDR", "qbo"); !errors.Is(err, apperrors.ErrValidation) {
+        t.Errorf("expected validation error for an unknown import format, got %v", err)
+    }
+    if buf.Len() != 0 {
+        t.Errorf("expected no output on error, got %q", buf.String())
+    }
+}
+
+func TestItemHandler_ErrorWritesNothing(t *testing.T) {
+    categoryRepo, itemRepo := sampleData()
+    _, itemHandler, _, buf := newTestHandlers(categoryRepo, itemRepo, output.Table, true)
+
+    if _, err := itemHandler.GetItem(context.Background(), 99); err == nil {
+        t.Error("expected error for missing item")
+    }
+    if buf.Len() != 0 {
+        t.Errorf("expected no output on error, got %q", buf.String())
+    }
+}
+
+
+func TestItemHandler_MissingRate(t *testing.T) {
+    categoryRepo, itemRepo := sampleData()
+    _, itemHandler, _, buf := newTestHandlers(categoryRepo, itemRepo, output.Table, false)
+
+    _, err := itemHandler.ShowTotalInvestment(context.Background(), "IDR")
+    var rateErr *apperrors.RateNotFoundError
+    if !errors.As(err, &rateErr) || rateErr.Currency != "USD" {
+        t.Errorf("expected RateNotFoundError for USD, got %v", err)
+    }
+    if buf.Len() != 0 {
+        t.Errorf("expected no output on error, got %q", buf.String())
+    }
+}
\ No newline at end of file
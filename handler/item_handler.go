@@ -1,234 +1,580 @@
 package handler
 
 import (
+    "context"
     "fmt"
-    "os"
+    "io"
+    "math"
+    "strconv"
+    "strings"
     "text/tabwriter"
     "time"
 
+    "mini_project3/models"
+    "mini_project3/money"
+    "mini_project3/output"
     "mini_project3/service"
+    "mini_project3/spreadsheet"
 )
 
 type ItemHandler struct {
     service *service.ItemService
+    w       io.Writer
+    format  output.Format
+    locale  money.Locale
 }
 
-func NewItemHandler(service *service.ItemService) *ItemHandler {
-    return &ItemHandler{service: service}
+// NewItemHandler creates ItemHandler writing to w; list, get and report commands are printed in format
+func NewItemHandler(service *service.ItemService, w io.Writer, format output.Format) *ItemHandler {
+    return &ItemHandler{service: service, w: w, format: format, locale: money.Indonesian}
 }
 
-func (h *ItemHandler) ListItems() error {
-    items, err := h.service.GetAll()
-    if err != nil {
-        return fmt.Errorf("failed to get items: %w", err)
-    }
-
-    if len(items) == 0 {
-        fmt.Println("No items found.")
-        return nil
-    }
+// SetLocale changes how amounts are written in the table format, money.Indonesian by default
+func (h *ItemHandler) SetLocale(locale money.Locale) {
+    h.locale = locale
+}
 
-    w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', tabwriter.TabIndent)
+// printItemsTable prints items with the days used since purchase
+func (h *ItemHandler) printItemsTable(items []models.Item) error {
+    w := tabwriter.NewWriter(h.w, 0, 0, 3, ' ', tabwriter.TabIndent)
     fmt.Fprintln(w, "ID\tNama\tKategori\tHarga\tTgl Beli\tHari Digunakan")
     fmt.Fprintln(w, "---\t---\t---\t---\t---\t---")
 
     for _, item := range items {
-        daysUsed := int(time.Since(item.PurchaseDate).Hours() / 24)
-        fmt.Fprintf(w, "%d\t%s\t%s\tRp %.2f\t%s\t%d hari\n",
+        fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n",
             item.ID,
             item.Name,
             item.CategoryName,
-            item.Price,
+            h.locale.Format(item.Price, item.Currency),
             item.PurchaseDate.Format("2006-01-02"),
-            daysUsed)
+            h.daysUsed(item))
     }
 
-    w.Flush()
-    return nil
+    return w.Flush()
 }
 
-func (h *ItemHandler) GetItem(id int) error {
-    item, err := h.service.GetByID(id)
+// daysUsed writes the days item has been used on the as-of date, or that it
+// was not yet bought then
+func (h *ItemHandler) daysUsed(item models.Item) string {
+    if !h.service.Purchased(item) {
+        return "belum dibeli"
+    }
+    if h.service.Disposed(item) {
+        return fmt.Sprintf("%d hari (dilepas)", h.service.DaysUsed(item))
+    }
+    return fmt.Sprintf("%d hari", h.service.DaysUsed(item))
+}
+
+// disposalMethods names the disposal methods in the table format
+var disposalMethods = map[string]string{
+    service.DisposalSold:     "dijual",
+    service.DisposalScrapped: "dibuang",
+    service.DisposalDonated:  "disumbangkan",
+}
+
+// gainLoss writes a gain or loss on disposal with its direction
+func (h *ItemHandler) gainLoss(amount money.Money, currency string) string {
+    switch {
+    case amount.IsNegative():
+        return h.locale.Format(amount, currency) + " (rugi)"
+    case amount.IsZero():
+        return h.locale.Format(amount, currency)
+    }
+    return h.locale.Format(amount, currency) + " (laba)"
+}
+
+// policyText lists the fields a depreciation policy sets, or inherited when it sets none
+func policyText(p models.DepreciationPolicy, inherited string) string {
+    var parts []string
+    if p.Method != "" {
+        parts = append(parts, p.Method)
+    }
+    if p.UsefulLifeMonths > 0 {
+        parts = append(parts, fmt.Sprintf("umur manfaat %d bulan", p.UsefulLifeMonths))
+    }
+    if !p.RatePercent.IsZero() {
+        parts = append(parts, fmt.Sprintf("rate %s%% per tahun", p.RatePercent))
+    }
+    if !p.SalvagePercent.IsZero() {
+        parts = append(parts, fmt.Sprintf("nilai residu %s%%", p.SalvagePercent))
+    } else if !p.SalvageValue.IsZero() {
+        parts = append(parts, fmt.Sprintf("nilai residu %s", p.SalvageValue))
+    }
+    if p.TaxGroup != "" && p.TaxMethod != "" {
+        parts = append(parts, fmt.Sprintf("fiskal %s (%s)", p.TaxGroup, p.TaxMethod))
+    } else if p.TaxGroup != "" {
+        parts = append(parts, "fiskal "+p.TaxGroup)
+    } else if p.TaxMethod != "" {
+        parts = append(parts, "fiskal "+p.TaxMethod)
+    }
+    if len(parts) == 0 {
+        return inherited
+    }
+    return strings.Join(parts, ", ")
+}
+
+func (h *ItemHandler) ListItems(ctx context.Context) ([]models.Item, error) {
+    items, err := h.service.GetAll(ctx)
     if err != nil {
-        return fmt.Errorf("failed to get item: %w", err)
+        return nil, fmt.Errorf("failed to get items: %w", err)
+    }
+    if h.format != output.Table {
+        return items, output.Write(h.w, h.format, items)
     }
 
-    daysUsed := int(time.Since(item.PurchaseDate).Hours() / 24)
+    if len(items) == 0 {
+        fmt.Fprintln(h.w, "No items found.")
+        return items, nil
+    }
 
-    fmt.Printf("\n=== Detail Barang ===\n")
-    fmt.Printf("ID              : %d\n", item.ID)
-    fmt.Printf("Nama            : %s\n", item.Name)
-    fmt.Printf("Kategori        : %s (ID: %d)\n", item.CategoryName, item.CategoryID)
-    fmt.Printf("Harga           : Rp %.2f\n", item.Price)
-    fmt.Printf("Tgl Beli        : %s\n", item.PurchaseDate.Format("2006-01-02"))
-    fmt.Printf("Hari Digunakan  : %d hari\n", daysUsed)
-    fmt.Printf("Dibuat          : %s\n", item.CreatedAt.Format("2006-01-02 15:04:05"))
-    fmt.Printf("Diperbarui      : %s\n", item.UpdatedAt.Format("2006-01-02 15:04:05"))
+    return items, h.printItemsTable(items)
+}
 
-    return nil
+func (h *ItemHandler) GetItem(ctx context.Context, id int) (*models.Item, error) {
+    item, err := h.service.GetByID(ctx, id)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get item: %w", err)
+    }
+    if h.format != output.Table {
+        return item, output.Write(h.w, h.format, item)
+    }
+
+    fmt.Fprintf(h.w, "\n=== Detail Barang ===\n")
+    fmt.Fprintf(h.w, "ID              : %d\n", item.ID)
+    fmt.Fprintf(h.w, "Nama            : %s\n", item.Name)
+    fmt.Fprintf(h.w, "Kategori        : %s (ID: %d)\n", item.CategoryName, item.CategoryID)
+    fmt.Fprintf(h.w, "Harga           : %s\n", h.locale.Format(item.Price, item.Currency))
+    fmt.Fprintf(h.w, "Tgl Beli        : %s\n", item.PurchaseDate.Format("2006-01-02"))
+    fmt.Fprintf(h.w, "Hari Digunakan  : %s\n", h.daysUsed(*item))
+    fmt.Fprintf(h.w, "Depresiasi      : %s\n", policyText(item.DepreciationPolicy, "mengikuti kategori"))
+    if item.DisposedAt != nil {
+        fmt.Fprintf(h.w, "Dilepas         : %s, %s, hasil %s\n", item.DisposedAt.Format("2006-01-02"),
+            disposalMethods[item.DisposalMethod], h.locale.Format(item.DisposalProceeds, item.Currency))
+    }
+    fmt.Fprintf(h.w, "Dibuat          : %s\n", item.CreatedAt.Format("2006-01-02 15:04:05"))
+    fmt.Fprintf(h.w, "Diperbarui      : %s\n", item.UpdatedAt.Format("2006-01-02 15:04:05"))
+    fmt.Fprintf(h.w, "Versi           : %d\n", item.Version)
+
+    return item, nil
 }
 
-func (h *ItemHandler) CreateItem(name string, categoryID int, price float64, purchaseDate time.Time) error {
-    item, err := h.service.Create(name, categoryID, price, purchaseDate)
+func (h *ItemHandler) CreateItem(ctx context.Context, name string, categoryID int, price money.Money, currency string, purchaseDate time.Time, policy models.DepreciationPolicy) (*models.Item, error) {
+    item, err := h.service.Create(ctx, name, categoryID, price, currency, purchaseDate, policy)
     if err != nil {
-        return fmt.Errorf("failed to create item: %w", err)
+        return nil, fmt.Errorf("failed to create item: %w", err)
     }
 
-    fmt.Printf("\n✓ Barang berhasil ditambahkan dengan ID: %d\n", item.ID)
-    return nil
+    fmt.Fprintf(h.w, "\n✓ Barang berhasil ditambahkan dengan ID: %d\n", item.ID)
+    return item, nil
 }
 
-func (h *ItemHandler) UpdateItem(id int, name string, categoryID int, price float64, purchaseDate time.Time) error {
-    if err := h.service.Update(id, name, categoryID, price, purchaseDate); err != nil {
+func (h *ItemHandler) UpdateItem(ctx context.Context, id int, patch service.ItemPatch, pre service.Precondition) error {
+    if err := h.service.Patch(ctx, id, patch, pre); err != nil {
         return fmt.Errorf("failed to update item: %w", err)
     }
 
-    fmt.Printf("\n✓ Barang dengan ID %d berhasil diperbarui\n", id)
+    fmt.Fprintf(h.w, "\n✓ Barang dengan ID %d berhasil diperbarui\n", id)
     return nil
 }
 
-func (h *ItemHandler) DeleteItem(id int) error {
-    if err := h.service.Delete(id); err != nil {
+func (h *ItemHandler) DeleteItem(ctx context.Context, id int) error {
+    if err := h.service.Delete(ctx, id); err != nil {
         return fmt.Errorf("failed to delete item: %w", err)
     }
 
-    fmt.Printf("\n✓ Barang dengan ID %d berhasil dihapus\n", id)
+    fmt.Fprintf(h.w, "\n✓ Barang dengan ID %d berhasil dihapus\n", id)
+    fmt.Fprintf(h.w, "Barang dipindahkan ke tong sampah, pulihkan dengan 'item restore --id %d'\n", id)
     return nil
 }
 
-func (h *ItemHandler) SearchItems(keyword string) error {
-    items, err := h.service.Search(keyword)
-    if err != nil {
-        return fmt.Errorf("failed to search items: %w", err)
+func (h *ItemHandler) RestoreItem(ctx context.Context, id int) error {
+    if err := h.service.Restore(ctx, id); err != nil {
+        return fmt.Errorf("failed to restore item: %w", err)
     }
 
-    if len(items) == 0 {
-        fmt.Printf("Tidak ada barang ditemukan dengan kata kunci '%s'\n", keyword)
-        return nil
-    }
+    fmt.Fprintf(h.w, "\n✓ Barang dengan ID %d berhasil dipulihkan\n", id)
+    return nil
+}
 
-    fmt.Printf("\nHasil pencarian untuk '%s':\n\n", keyword)
+// importStatuses names the outcomes of import rows in the table format
+var importStatuses = map[string]string{
+    models.ImportImported: "Diimpor",
+    models.ImportValid:    "Valid",
+    models.ImportInvalid:  "Tidak valid",
+    models.ImportSkipped:  "Dilewati",
+}
 
-    w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', tabwriter.TabIndent)
-    fmt.Fprintln(w, "ID\tNama\tKategori\tHarga\tTgl Beli\tHari Digunakan")
-    fmt.Fprintln(w, "---\t---\t---\t---\t---\t---")
+// ImportItems imports the items of rows and prints the outcome of every row.
+// When invalid rows stop the import the report is still printed.
+func (h *ItemHandler) ImportItems(ctx context.Context, rows []spreadsheet.Row, opts service.ImportOptions) (*models.ImportReport, error) {
+    report, err := h.service.Import(ctx, rows, opts)
+    if report == nil {
+        return nil, fmt.Errorf("failed to import items: %w", err)
+    }
+    if werr := h.printImportReport(report, err == nil); werr != nil {
+        return report, werr
+    }
+    if err != nil {
+        return report, fmt.Errorf("failed to import items: %w", err)
+    }
+    return report, nil
+}
 
-    for _, item := range items {
-        daysUsed := int(time.Since(item.PurchaseDate).Hours() / 24)
-        fmt.Fprintf(w, "%d\t%s\t%s\tRp %.2f\t%s\t%d hari\n",
-            item.ID,
-            item.Name,
-            item.CategoryName,
-            item.Price,
-            item.PurchaseDate.Format("2006-01-02"),
-            daysUsed)
+// printImportReport writes report in the output format: json and yaml the
+// whole report, csv and tsv one record per file row; the table ends with a
+// summary unless an import that was not a dry run failed
+func (h *ItemHandler) printImportReport(report *models.ImportReport, succeeded bool) error {
+    switch h.format {
+    case output.Table:
+    case output.CSV, output.TSV:
+        return output.Write(h.w, h.format, report.Rows)
+    default:
+        return output.Write(h.w, h.format, report)
+    }
+
+    w := tabwriter.NewWriter(h.w, 0, 0, 3, ' ', tabwriter.TabIndent)
+    fmt.Fprintln(w, "Baris\tNama\tKategori\tStatus\tKeterangan")
+    fmt.Fprintln(w, "---\t---\t---\t---\t---")
+    for _, row := range report.Rows {
+        note := row.Error
+        if row.ItemID != 0 {
+            note = fmt.Sprintf("ID %d", row.ItemID)
+        }
+        fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", row.Line, row.Name, row.Category, importStatuses[row.Status], note)
+    }
+    if err := w.Flush(); err != nil {
+        return err
     }
 
-    w.Flush()
+    if len(report.NewCategories) > 0 {
+        fmt.Fprintf(h.w, "\nKategori baru: %s\n", strings.Join(report.NewCategories, ", "))
+    }
+    if report.DryRun {
+        fmt.Fprintf(h.w, "\nDry run: %d barang akan diimpor, %d baris tidak valid; tidak ada yang disimpan\n", report.Imported, report.Invalid)
+    } else if succeeded {
+        fmt.Fprintf(h.w, "\n✓ %d barang berhasil diimpor, %d baris dilewati\n", report.Imported, report.Invalid)
+    }
     return nil
 }
 
-func (h *ItemHandler) ListItemsNeedReplacement() error {
-    items, err := h.service.GetItemsNeedReplacement()
+func (h *ItemHandler) SearchItems(ctx context.Context, keyword string) ([]models.Item, error) {
+    items, err := h.service.Search(ctx, keyword)
     if err != nil {
-        return fmt.Errorf("failed to get items need replacement: %w", err)
+        return nil, fmt.Errorf("failed to search items: %w", err)
+    }
+    if h.format != output.Table {
+        return items, output.Write(h.w, h.format, items)
     }
 
     if len(items) == 0 {
-        fmt.Println("Tidak ada barang yang perlu diganti (> 100 hari)")
-        return nil
+        fmt.Fprintf(h.w, "Tidak ada barang ditemukan dengan kata kunci '%s'\n", keyword)
+        return items, nil
     }
 
-    fmt.Printf("\n=== Barang yang Perlu Diganti (> 100 hari) ===\n\n")
+    fmt.Fprintf(h.w, "\nHasil pencarian untuk '%s':\n\n", keyword)
+    return items, h.printItemsTable(items)
+}
 
-    w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', tabwriter.TabIndent)
-    fmt.Fprintln(w, "ID\tNama\tKategori\tHarga\tTgl Beli\tHari Digunakan")
-    fmt.Fprintln(w, "---\t---\t---\t---\t---\t---")
+func (h *ItemHandler) ListItemsNeedReplacement(ctx context.Context) ([]models.Item, error) {
+    items, err := h.service.GetItemsNeedReplacement(ctx)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get items need replacement: %w", err)
+    }
+    if h.format != output.Table {
+        return items, output.Write(h.w, h.format, items)
+    }
 
-    for _, item := range items {
-        daysUsed := int(time.Since(item.PurchaseDate).Hours() / 24)
-        fmt.Fprintf(w, "%d\t%s\t%s\tRp %.2f\t%s\t%d hari\n",
-            item.ID,
-            item.Name,
-            item.CategoryName,
-            item.Price,
-            item.PurchaseDate.Format("2006-01-02"),
-            daysUsed)
+    if len(items) == 0 {
+        fmt.Fprintf(h.w, "Tidak ada barang yang perlu diganti (> 100 hari per %s)\n", h.service.AsOf().Format("2006-01-02"))
+        return items, nil
     }
 
-    w.Flush()
-    
-    fmt.Printf("\nTotal: %d barang perlu diganti\n", len(items))
-    return nil
+    fmt.Fprintf(h.w, "\n=== Barang yang Perlu Diganti (> 100 hari per %s) ===\n\n", h.service.AsOf().Format("2006-01-02"))
+    if err := h.printItemsTable(items); err != nil {
+        return items, err
+    }
+
+    fmt.Fprintf(h.w, "\nTotal: %d barang perlu diganti\n", len(items))
+    return items, nil
 }
 
-func (h *ItemHandler) ShowTotalInvestment() error {
-    totalOriginal, totalCurrent, err := h.service.GetTotalInvestment()
+// ShowTotalInvestment reports the total investment of all items in currency
+func (h *ItemHandler) ShowTotalInvestment(ctx context.Context, currency string) (*models.InvestmentSummary, error) {
+    summary, err := h.service.GetInvestmentSummary(ctx, currency)
     if err != nil {
-        return fmt.Errorf("failed to calculate total investment: %w", err)
+        return nil, fmt.Errorf("failed to calculate total investment: %w", err)
     }
-
-    totalDepreciation := totalOriginal - totalCurrent
-    percentageDepreciation := 0.0
-    if totalOriginal > 0 {
-        percentageDepreciation = (totalDepreciation / totalOriginal) * 100
+    if h.format != output.Table {
+        return summary, output.Write(h.w, h.format, summary)
     }
 
-    fmt.Printf("\n=== Laporan Total Investasi ===\n")
-    fmt.Printf("Total Investasi Awal    : Rp %s\n", formatCurrency(totalOriginal))
-    fmt.Printf("Total Nilai Sekarang    : Rp %s\n", formatCurrency(totalCurrent))
-    fmt.Printf("Total Depresiasi        : Rp %s\n", formatCurrency(totalDepreciation))
-    fmt.Printf("Persentase Depresiasi   : %.2f%%\n", percentageDepreciation)
-    fmt.Printf("\nMetode Depresiasi: Saldo Menurun 20%% per tahun\n")
+    currency = summary.Currency
+    fmt.Fprintf(h.w, "\n=== Laporan Total Investasi ===\n")
+    fmt.Fprintf(h.w, "Per Tanggal             : %s\n", h.service.AsOf().Format("2006-01-02"))
+    fmt.Fprintf(h.w, "Total Investasi Awal    : %s\n", h.locale.Format(summary.TotalOriginal, currency))
+    fmt.Fprintf(h.w, "Total Nilai Sekarang    : %s\n", h.locale.Format(summary.TotalCurrent, currency))
+    fmt.Fprintf(h.w, "Total Depresiasi        : %s\n", h.locale.Format(summary.TotalDepreciation, currency))
+    fmt.Fprintf(h.w, "Persentase Depresiasi   : %.2f%%\n", summary.DepreciationPercentage)
+
+    switch len(summary.Methods) {
+    case 0:
+    case 1:
+        fmt.Fprintf(h.w, "\nMetode Depresiasi: %s\n", summary.Methods[0].Description)
+    default:
+        fmt.Fprintf(h.w, "\nMetode Depresiasi:\n")
+        for _, method := range summary.Methods {
+            fmt.Fprintf(h.w, "- %s (%d barang)\n", method.Description, method.Items)
+            fmt.Fprintf(h.w, "  Investasi Awal %s, Nilai Sekarang %s\n",
+                h.locale.Format(method.TotalOriginal, currency),
+                h.locale.Format(method.TotalCurrent, currency))
+            fmt.Fprintf(h.w, "  Formula: %s\n", method.Formula)
+        }
+    }
+    if currency != service.BaseCurrency {
+        fmt.Fprintf(h.w, "Mata Uang Laporan: %s, dikonversi dengan kurs tanggal beli\n", currency)
+    }
 
-    return nil
+    return summary, nil
 }
 
-func (h *ItemHandler) ShowItemDepreciation(id int) error {
-    dep, err := h.service.GetItemDepreciation(id)
+// ShowItemDepreciation reports the depreciation of one item in currency
+func (h *ItemHandler) ShowItemDepreciation(ctx context.Context, id int, currency string) (*models.ItemDepreciation, error) {
+    dep, err := h.service.GetItemDepreciation(ctx, id, currency)
     if err != nil {
-        return fmt.Errorf("failed to calculate item depreciation: %w", err)
+        return nil, fmt.Errorf("failed to calculate item depreciation: %w", err)
+    }
+    if h.format != output.Table {
+        return dep, output.Write(h.w, h.format, dep)
     }
 
     yearsUsed := float64(dep.DaysUsed) / 365.0
-    percentageDepreciation := 0.0
-    if dep.Price > 0 {
-        percentageDepreciation = (dep.DepreciationValue / dep.Price) * 100
-    }
-
-    fmt.Printf("\n=== Laporan Depresiasi Barang ===\n")
-    fmt.Printf("ID                  : %d\n", dep.ID)
-    fmt.Printf("Nama                : %s\n", dep.Name)
-    fmt.Printf("Kategori            : %s\n", dep.CategoryName)
-    fmt.Printf("Harga Awal          : Rp %s\n", formatCurrency(dep.Price))
-    fmt.Printf("Tanggal Beli        : %s\n", dep.PurchaseDate.Format("2006-01-02"))
-    fmt.Printf("Hari Digunakan      : %d hari (%.2f tahun)\n", dep.DaysUsed, yearsUsed)
-    fmt.Printf("Rate Depresiasi     : %.0f%% per tahun\n", dep.DepreciationRate*100)
-    fmt.Printf("Nilai Sekarang      : Rp %s\n", formatCurrency(dep.CurrentValue))
-    fmt.Printf("Total Depresiasi    : Rp %s\n", formatCurrency(dep.DepreciationValue))
-    fmt.Printf("Persentase Depresiasi: %.2f%%\n", percentageDepreciation)
-    fmt.Printf("\nMetode: Saldo Menurun (Declining Balance Method)\n")
-    fmt.Printf("Formula: Nilai Sekarang = Harga Awal × (1 - 0.20)^tahun\n")
+    percentageDepreciation := dep.DepreciationValue.Ratio(dep.PurchaseValue) * 100
+
+    fmt.Fprintf(h.w, "\n=== Laporan Depresiasi Barang ===\n")
+    fmt.Fprintf(h.w, "Per Tanggal         : %s\n", h.service.AsOf().Format("2006-01-02"))
+    fmt.Fprintf(h.w, "ID                  : %d\n", dep.ID)
+    fmt.Fprintf(h.w, "Nama                : %s\n", dep.Name)
+    fmt.Fprintf(h.w, "Kategori            : %s\n", dep.CategoryName)
+    fmt.Fprintf(h.w, "Harga Awal          : %s\n", h.locale.Format(dep.Price, dep.Currency))
+    if dep.ReportCurrency != dep.Currency {
+        fmt.Fprintf(h.w, "Nilai Perolehan     : %s (kurs %s)\n", h.locale.Format(dep.PurchaseValue, dep.ReportCurrency), dep.PurchaseDate.Format("2006-01-02"))
+    }
+    fmt.Fprintf(h.w, "Tanggal Beli        : %s\n", dep.PurchaseDate.Format("2006-01-02"))
+    if h.service.Disposed(dep.Item) {
+        fmt.Fprintf(h.w, "Tanggal Dilepas     : %s (%s), depresiasi berhenti\n", dep.DisposedAt.Format("2006-01-02"), disposalMethods[dep.DisposalMethod])
+    }
+    fmt.Fprintf(h.w, "Hari Digunakan      : %d hari (%.2f tahun)\n", dep.DaysUsed, yearsUsed)
+    fmt.Fprintf(h.w, "Rate Depresiasi     : %s%% per tahun\n", strconv.FormatFloat(math.Round(dep.DepreciationRate*10000)/100, 'f', -1, 64))
+    if !dep.ResidualValue.IsZero() {
+        fmt.Fprintf(h.w, "Nilai Residu        : %s\n", h.locale.Format(dep.ResidualValue, dep.ReportCurrency))
+    }
+    fmt.Fprintf(h.w, "Nilai Sekarang      : %s\n", h.locale.Format(dep.CurrentValue, dep.ReportCurrency))
+    fmt.Fprintf(h.w, "Total Depresiasi    : %s\n", h.locale.Format(dep.DepreciationValue, dep.ReportCurrency))
+    fmt.Fprintf(h.w, "Persentase Depresiasi: %.2f%%\n", percentageDepreciation)
+    fmt.Fprintf(h.w, "\nMetode: %s\n", dep.MethodDescription)
+    fmt.Fprintf(h.w, "Formula: %s\n", dep.Formula)
+
+    if fiscal := dep.Fiscal; fiscal != nil {
+        fmt.Fprintf(h.w, "\n=== Buku Fiskal ===\n")
+        fmt.Fprintf(h.w, "Kelompok            : %s\n", fiscal.TaxGroupName)
+        fmt.Fprintf(h.w, "Nilai Buku Komersial: %s\n", h.locale.Format(dep.CurrentValue, dep.ReportCurrency))
+        fmt.Fprintf(h.w, "Nilai Buku Fiskal   : %s\n", h.locale.Format(fiscal.CurrentValue, dep.ReportCurrency))
+        fmt.Fprintf(h.w, "Penyusutan Fiskal   : %s\n", h.locale.Format(fiscal.DepreciationValue, dep.ReportCurrency))
+        fmt.Fprintf(h.w, "Beda Waktu          : %s\n", h.timingDifference(fiscal.TimingDifference, dep.ReportCurrency))
+        fmt.Fprintf(h.w, "\nMetode: %s\n", fiscal.MethodDescription)
+        fmt.Fprintf(h.w, "Formula: %s\n", fiscal.Formula)
+    }
 
-    return nil
+    return dep, nil
 }
 
-// formatCurrency memformat angka menjadi format mata uang Indonesia
-func formatCurrency(amount float64) string {
-    // Format dengan pemisah ribuan
-    intPart := int64(amount)
-    decPart := int((amount - float64(intPart)) * 100)
-    
-    // Konversi ke string dengan pemisah
-    result := ""
-    intStr := fmt.Sprintf("%d", intPart)
-    
-    for i, digit := range intStr {
-        if i > 0 && (len(intStr)-i)%3 == 0 {
-            result += "."
+// timingDifference writes a timing difference with the direction of its fiscal correction
+func (h *ItemHandler) timingDifference(diff money.Money, currency string) string {
+    switch {
+    case diff.IsNegative():
+        return h.locale.Format(diff, currency) + " (koreksi fiskal negatif)"
+    case diff.IsZero():
+        return h.locale.Format(diff, currency)
+    }
+    return h.locale.Format(diff, currency) + " (koreksi fiskal positif)"
+}
+
+// ShowFiscalReport puts the commercial and fiscal book value of the items with a
+// tax group side by side in currency. csv and tsv write one row per item.
+func (h *ItemHandler) ShowFiscalReport(ctx context.Context, currency string) (*models.FiscalReport, error) {
+    report, err := h.service.GetFiscalReport(ctx, currency)
+    if err != nil {
+        return nil, fmt.Errorf("failed to calculate fiscal report: %w", err)
+    }
+    switch h.format {
+    case output.Table:
+    case output.CSV, output.TSV:
+        return report, output.Write(h.w, h.format, report.Items)
+    default:
+        return report, output.Write(h.w, h.format, report)
+    }
+
+    currency = report.Currency
+    fmt.Fprintf(h.w, "\n=== Laporan Buku Komersial dan Fiskal ===\n")
+    fmt.Fprintf(h.w, "Per Tanggal: %s\n", h.service.AsOf().Format("2006-01-02"))
+    if len(report.Items) == 0 {
+        fmt.Fprintln(h.w, "Tidak ada barang dengan kelompok fiskal")
+    } else {
+        w := tabwriter.NewWriter(h.w, 0, 0, 3, ' ', tabwriter.TabIndent)
+        fmt.Fprintln(w, "ID\tNama\tKelompok\tMetode Fiskal\tHarga Awal\tNilai Buku Komersial\tNilai Buku Fiskal\tBeda Waktu")
+        fmt.Fprintln(w, "---\t---\t---\t---\t---\t---\t---\t---")
+        for _, line := range report.Items {
+            fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+                line.ID,
+                line.Name,
+                line.TaxGroup,
+                line.TaxMethod,
+                h.locale.Format(line.PurchaseValue, currency),
+                h.locale.Format(line.CommercialValue, currency),
+                h.locale.Format(line.FiscalValue, currency),
+                h.locale.Format(line.TimingDifference, currency))
+        }
+        if err := w.Flush(); err != nil {
+            return nil, err
         }
-        result += string(digit)
     }
-    
-    return fmt.Sprintf("%s,%02d", result, decPart)
+
+    fmt.Fprintf(h.w, "\nTotal Harga Awal          : %s\n", h.locale.Format(report.TotalPurchase, currency))
+    fmt.Fprintf(h.w, "Total Nilai Buku Komersial: %s\n", h.locale.Format(report.TotalCommercial, currency))
+    fmt.Fprintf(h.w, "Total Nilai Buku Fiskal   : %s\n", h.locale.Format(report.TotalFiscal, currency))
+    fmt.Fprintf(h.w, "Total Beda Waktu          : %s\n", h.timingDifference(report.TotalTimingDifference, currency))
+    if report.UnmappedItems > 0 {
+        fmt.Fprintf(h.w, "%d barang belum memiliki kelompok fiskal dan tidak dihitung\n", report.UnmappedItems)
+    }
+    if currency != service.BaseCurrency {
+        fmt.Fprintf(h.w, "Mata Uang Laporan: %s, dikonversi dengan kurs tanggal beli\n", currency)
+    }
+
+    return report, nil
+}
+
+// ShowDepreciationSchedule prints the book value of one item per month or year
+// in currency. csv and tsv write one row per period.
+func (h *ItemHandler) ShowDepreciationSchedule(ctx context.Context, id int, currency, period string) (*models.DepreciationSchedule, error) {
+    schedule, err := h.service.GetDepreciationSchedule(ctx, id, currency, period)
+    if err != nil {
+        return nil, fmt.Errorf("failed to calculate depreciation schedule: %w", err)
+    }
+    switch h.format {
+    case output.Table:
+    case output.CSV, output.TSV:
+        return schedule, output.Write(h.w, h.format, schedule.Rows)
+    default:
+        return schedule, output.Write(h.w, h.format, schedule)
+    }
+
+    currency = schedule.Currency
+    periodName := "Tahunan"
+    if schedule.Period == service.PeriodMonth {
+        periodName = "Bulanan"
+    }
+    fmt.Fprintf(h.w, "\n=== Jadwal Depresiasi Barang ===\n")
+    fmt.Fprintf(h.w, "ID            : %d\n", schedule.ID)
+    fmt.Fprintf(h.w, "Nama          : %s\n", schedule.Name)
+    fmt.Fprintf(h.w, "Tanggal Beli  : %s\n", schedule.PurchaseDate.Format("2006-01-02"))
+    fmt.Fprintf(h.w, "Harga Awal    : %s\n", h.locale.Format(schedule.PurchaseValue, currency))
+    if !schedule.ResidualValue.IsZero() {
+        fmt.Fprintf(h.w, "Nilai Residu  : %s\n", h.locale.Format(schedule.ResidualValue, currency))
+    }
+    fmt.Fprintf(h.w, "Metode        : %s\n", schedule.MethodDescription)
+    fmt.Fprintf(h.w, "Periode       : %s\n\n", periodName)
+
+    w := tabwriter.NewWriter(h.w, 0, 0, 3, ' ', tabwriter.TabIndent)
+    fmt.Fprintln(w, "Periode\tNilai Buku Awal\tDepresiasi\tAkumulasi Depresiasi\tNilai Buku Akhir")
+    fmt.Fprintln(w, "---\t---\t---\t---\t---")
+    for _, row := range schedule.Rows {
+        fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+            row.Period,
+            h.locale.Format(row.OpeningValue, currency),
+            h.locale.Format(row.Depreciation, currency),
+            h.locale.Format(row.AccumulatedDepreciation, currency),
+            h.locale.Format(row.ClosingValue, currency))
+    }
+    if err := w.Flush(); err != nil {
+        return nil, err
+    }
+
+    if schedule.Truncated {
+        fmt.Fprintf(h.w, "\nJadwal dihentikan setelah %d periode; nilai buku saldo menurun tidak pernah mencapai nilai residu\n", len(schedule.Rows))
+    }
+    if schedule.DisposedAt != nil {
+        fmt.Fprintf(h.w, "\nBarang dilepas pada %s; nilai buku akhir periode terakhir adalah nilai buku pada tanggal itu\n", schedule.DisposedAt.Format("2006-01-02"))
+    }
+    if currency != service.BaseCurrency {
+        fmt.Fprintf(h.w, "Mata Uang Laporan: %s, dikonversi dengan kurs tanggal beli\n", currency)
+    }
+
+    return schedule, nil
+}
+
+// DisposeItem records the sale, scrapping or donation of an item and prints
+// the gain or loss against its book value on that date
+func (h *ItemHandler) DisposeItem(ctx context.Context, id int, date time.Time, method string, proceeds money.Money) (*models.DisposalLine, error) {
+    line, err := h.service.Dispose(ctx, id, date, method, proceeds)
+    if err != nil {
+        return nil, fmt.Errorf("failed to dispose item: %w", err)
+    }
+
+    fmt.Fprintf(h.w, "\n✓ Barang dengan ID %d %s pada %s\n", line.ID, disposalMethods[line.DisposalMethod], line.DisposedAt.Format("2006-01-02"))
+    fmt.Fprintf(h.w, "Nilai Buku      : %s\n", h.locale.Format(line.BookValue, line.Currency))
+    fmt.Fprintf(h.w, "Hasil Pelepasan : %s\n", h.locale.Format(line.Proceeds, line.Currency))
+    fmt.Fprintf(h.w, "Laba/Rugi       : %s\n", h.gainLoss(line.GainLoss, line.Currency))
+    return line, nil
+}
+
+// ShowDisposalReport lists the items disposed by the as-of date with the gain
+// or loss of each in currency. csv and tsv write one row per item.
+func (h *ItemHandler) ShowDisposalReport(ctx context.Context, currency string) (*models.DisposalReport, error) {
+    report, err := h.service.GetDisposalReport(ctx, currency)
+    if err != nil {
+        return nil, fmt.Errorf("failed to calculate disposal report: %w", err)
+    }
+    switch h.format {
+    case output.Table:
+    case output.CSV, output.TSV:
+        return report, output.Write(h.w, h.format, report.Items)
+    default:
+        return report, output.Write(h.w, h.format, report)
+    }
+
+    currency = report.Currency
+    fmt.Fprintf(h.w, "\n=== Laporan Pelepasan Barang ===\n")
+    fmt.Fprintf(h.w, "Per Tanggal: %s\n", h.service.AsOf().Format("2006-01-02"))
+    if len(report.Items) == 0 {
+        fmt.Fprintln(h.w, "Tidak ada barang yang dilepas")
+        return report, nil
+    }
+
+    w := tabwriter.NewWriter(h.w, 0, 0, 3, ' ', tabwriter.TabIndent)
+    fmt.Fprintln(w, "ID\tNama\tTgl Dilepas\tCara\tHarga Awal\tNilai Buku\tHasil\tLaba/Rugi")
+    fmt.Fprintln(w, "---\t---\t---\t---\t---\t---\t---\t---")
+    for _, line := range report.Items {
+        fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+            line.ID,
+            line.Name,
+            line.DisposedAt.Format("2006-01-02"),
+            disposalMethods[line.DisposalMethod],
+            h.locale.Format(line.PurchaseValue, currency),
+            h.locale.Format(line.BookValue, currency),
+            h.locale.Format(line.Proceeds, currency),
+            h.locale.Format(line.GainLoss, currency))
+    }
+    if err := w.Flush(); err != nil {
+        return nil, err
+    }
+
+    fmt.Fprintf(h.w, "\nTotal Harga Awal : %s\n", h.locale.Format(report.TotalPurchase, currency))
+    fmt.Fprintf(h.w, "Total Nilai Buku : %s\n", h.locale.Format(report.TotalBookValue, currency))
+    fmt.Fprintf(h.w, "Total Hasil      : %s\n", h.locale.Format(report.TotalProceeds, currency))
+    fmt.Fprintf(h.w, "Total Laba/Rugi  : %s\n", h.gainLoss(report.TotalGainLoss, currency))
+    if currency != service.BaseCurrency {
+        fmt.Fprintf(h.w, "Mata Uang Laporan: %s, harga dengan kurs tanggal beli, hasil dengan kurs tanggal dilepas\n", currency)
+    }
+
+    return report, nil
 }
\ No newline at end of file
@@ -0,0 +1,148 @@
+package handler
+
+import (
+    "bufio"
+    "context"
+    "fmt"
+    "io"
+    "strings"
+    "text/tabwriter"
+
+    "mini_project3/apperrors"
+    "mini_project3/models"
+    "mini_project3/money"
+    "mini_project3/output"
+    "mini_project3/service"
+)
+
+// ImportFormatIIF writes a journal as an Intuit Interchange Format file that QuickBooks imports
+const ImportFormatIIF = "iif"
+
+type JournalHandler struct {
+    service *service.JournalService
+    w       io.Writer
+    format  output.Format
+    locale  money.Locale
+}
+
+// NewJournalHandler creates JournalHandler writing to w; the journal is printed in format
+func NewJournalHandler(service *service.JournalService, w io.Writer, format output.Format) *JournalHandler {
+    return &JournalHandler{service: service, w: w, format: format, locale: money.Indonesian}
+}
+
+// SetLocale changes how amounts are written in the table format, money.Indonesian by default
+func (h *JournalHandler) SetLocale(locale money.Locale) {
+    h.locale = locale
+}
+
+// accountText describes the journal accounts of a category for its detail
+func accountText(accounts models.JournalAccounts) string {
+    expense, accumulated := accounts.ExpenseAccount, accounts.AccumulatedAccount
+    if expense == "" {
+        expense = service.DefaultJournalAccounts.ExpenseAccount + " (bawaan)"
+    }
+    if accumulated == "" {
+        accumulated = service.DefaultJournalAccounts.AccumulatedAccount + " (bawaan)"
+    }
+    return fmt.Sprintf("beban %s, akumulasi %s", expense, accumulated)
+}
+
+// ShowJournal prints the depreciation journal of month in currency. csv and
+// tsv write one row per journal line; importFormat iif writes the journal as
+// a file to import into the accounting software instead.
+func (h *JournalHandler) ShowJournal(ctx context.Context, month, currency, importFormat string) (*models.Journal, error) {
+    importFormat = strings.ToLower(strings.TrimSpace(importFormat))
+    if importFormat != "" && importFormat != ImportFormatIIF {
+        return nil, apperrors.NewValidationError("import format", fmt.Sprintf("must be %s, got '%s'", ImportFormatIIF, importFormat))
+    }
+
+    journal, err := h.service.GetJournal(ctx, month, currency)
+    if err != nil {
+        return nil, fmt.Errorf("failed to calculate depreciation journal: %w", err)
+    }
+    if importFormat == ImportFormatIIF {
+        return journal, writeIIF(h.w, journal)
+    }
+    switch h.format {
+    case output.Table:
+    case output.CSV, output.TSV:
+        return journal, output.Write(h.w, h.format, journal.Lines)
+    default:
+        return journal, output.Write(h.w, h.format, journal)
+    }
+
+    fmt.Fprintf(h.w, "\n=== Jurnal Penyusutan %s ===\n", journal.Month)
+    fmt.Fprintf(h.w, "Tanggal  : %s\n", journal.Date.Format("2006-01-02"))
+    if journal.Posted {
+        fmt.Fprintf(h.w, "Status   : diposting %s, terkunci\n", journal.PostedAt.Format("2006-01-02 15:04:05"))
+    } else {
+        fmt.Fprintf(h.w, "Status   : belum diposting\n")
+    }
+
+    if len(journal.Lines) == 0 {
+        fmt.Fprintf(h.w, "\nTidak ada penyusutan pada %s\n", journal.Month)
+        return journal, nil
+    }
+
+    fmt.Fprintln(h.w)
+    w := tabwriter.NewWriter(h.w, 0, 0, 3, ' ', tabwriter.TabIndent)
+    fmt.Fprintln(w, "Akun\tKategori\tKeterangan\tDebit\tKredit")
+    fmt.Fprintln(w, "---\t---\t---\t---\t---")
+    for _, line := range journal.Lines {
+        debit, credit := "", ""
+        if !line.Debit.IsZero() {
+            debit = h.locale.Format(line.Debit, journal.Currency)
+        }
+        if !line.Credit.IsZero() {
+            credit = h.locale.Format(line.Credit, journal.Currency)
+        }
+        fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", line.Account, line.CategoryName, line.Description, debit, credit)
+    }
+    fmt.Fprintf(w, "Total\t\t\t%s\t%s\n", h.locale.Format(journal.TotalDebit, journal.Currency), h.locale.Format(journal.TotalCredit, journal.Currency))
+    if err := w.Flush(); err != nil {
+        return nil, err
+    }
+
+    if !journal.Difference.IsZero() {
+        fmt.Fprintf(h.w, "\n⚠ Perhitungan ulang hari ini berbeda %s dari jurnal yang diposting; bukukan selisihnya di bulan berjalan\n", h.locale.Format(journal.Difference, journal.Currency))
+    }
+    if journal.Currency != service.BaseCurrency {
+        fmt.Fprintf(h.w, "Mata Uang Laporan: %s, dikonversi dengan kurs tanggal beli\n", journal.Currency)
+    }
+    return journal, nil
+}
+
+// writeIIF writes journal as one general journal transaction per category:
+// a TRNS line debiting the expense account and an SPL line crediting the
+// accumulated depreciation account. Lines are buffered so that a failed write
+// is reported by Flush instead of leaving a truncated file unnoticed.
+func writeIIF(w io.Writer, journal *models.Journal) error {
+    b := bufio.NewWriter(w)
+    header := "TRNSTYPE\tDATE\tACCNT\tAMOUNT\tDOCNUM\tMEMO"
+    fmt.Fprintf(b, "!TRNS\t%s\n!SPL\t%s\n!ENDTRNS\n", header, header)
+
+    date := journal.Date.Format("01/02/2006")
+    docNum := "DEP-" + journal.Month
+    for _, line := range journal.Lines {
+        if !line.Debit.IsZero() {
+            fmt.Fprintf(b, "TRNS\tGENERAL JOURNAL\t%s\t%s\t%s\t%s\t%s\n", date, line.Account, line.Debit, docNum, line.Description)
+            continue
+        }
+        fmt.Fprintf(b, "SPL\tGENERAL JOURNAL\t%s\t%s\t%s\t%s\t%s\n", date, line.Account, line.Credit.Neg(), docNum, line.Description)
+        fmt.Fprintln(b, "ENDTRNS")
+    }
+    return b.Flush()
+}
+
+// PostJournal posts the depreciation journal of month in currency and locks
+// the month, so a later change to the items no longer changes it
+func (h *JournalHandler) PostJournal(ctx context.Context, month, currency string) (*models.Journal, error) {
+    journal, err := h.service.PostJournal(ctx, month, currency)
+    if err != nil {
+        return nil, fmt.Errorf("failed to post depreciation journal: %w", err)
+    }
+
+    fmt.Fprintf(h.w, "\n✓ Jurnal penyusutan %s berhasil diposting dan dikunci: %d baris, total %s\n",
+        journal.Month, len(journal.Lines), h.locale.Format(journal.TotalDebit, journal.Currency))
+    return journal, nil
+}
\ No newline at end of file
@@ -0,0 +1,69 @@
+package handler
+
+import (
+    "context"
+    "fmt"
+    "io"
+    "text/tabwriter"
+
+    "mini_project3/models"
+    "mini_project3/output"
+    "mini_project3/service"
+)
+
+// trashEntities names the entities of the trash in the table format
+var trashEntities = map[string]string{
+    models.TrashItem:     "Barang",
+    models.TrashCategory: "Kategori",
+}
+
+type TrashHandler struct {
+    service *service.TrashService
+    w       io.Writer
+    format  output.Format
+}
+
+// NewTrashHandler creates TrashHandler writing to w; the trash is listed in format
+func NewTrashHandler(service *service.TrashService, w io.Writer, format output.Format) *TrashHandler {
+    return &TrashHandler{service: service, w: w, format: format}
+}
+
+func (h *TrashHandler) ListTrash(ctx context.Context) ([]models.TrashEntry, error) {
+    entries, err := h.service.List(ctx)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get trash: %w", err)
+    }
+    if h.format != output.Table {
+        return entries, output.Write(h.w, h.format, entries)
+    }
+
+    if len(entries) == 0 {
+        fmt.Fprintln(h.w, "Tong sampah kosong.")
+        return entries, nil
+    }
+
+    w := tabwriter.NewWriter(h.w, 0, 0, 3, ' ', tabwriter.TabIndent)
+    fmt.Fprintln(w, "Jenis\tID\tNama\tDihapus")
+    fmt.Fprintln(w, "---\t---\t---\t---")
+    for _, entry := range entries {
+        fmt.Fprintf(w, "%s\t%d\t%s\t%s\n",
+            trashEntities[entry.Entity],
+            entry.ID,
+            entry.Name,
+            entry.DeletedAt.Format("2006-01-02 15:04"))
+    }
+
+    return entries, w.Flush()
+}
+
+// PurgeTrash permanently deletes what was moved to the trash more than olderThan ago
+func (h *TrashHandler) PurgeTrash(ctx context.Context, olderThan string) (*models.PurgeResult, error) {
+    result, err := h.service.Purge(ctx, olderThan)
+    if err != nil {
+        return nil, fmt.Errorf("failed to purge trash: %w", err)
+    }
+
+    fmt.Fprintf(h.w, "\n✓ %d barang dan %d kategori yang dihapus sebelum %s dihapus permanen\n",
+        result.Items, result.Categories, result.Before.Format("2006-01-02 15:04"))
+    return result, nil
+}
\ No newline at end of file
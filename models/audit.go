@@ -0,0 +1,53 @@
+package models
+
+import (
+    "encoding/json"
+    "time"
+)
+
+// Entities recorded in the audit log
+const (
+    AuditItem     = "item"
+    AuditCategory = "category"
+)
+
+// Changes recorded in the audit log
+const (
+    AuditCreate  = "create"
+    AuditUpdate  = "update"
+    AuditDispose = "dispose"
+    AuditDelete  = "delete"
+    AuditRestore = "restore"
+    AuditPurge   = "purge"
+)
+
+// AuditEntry is one change of an item or category. Before and After hold the
+// record as JSON, null where it is not live: before a create or restore and
+// after a delete or purge. Changes lists the fields that differ between the two.
+type AuditEntry struct {
+    ID       int             `json:"id"`
+    At       time.Time       `json:"at"`
+    Actor    string          `json:"actor"`
+    Command  string          `json:"command"`
+    Entity   string          `json:"entity"`
+    EntityID int             `json:"entity_id"`
+    Action   string          `json:"action"`
+    Before   json.RawMessage `json:"before"`
+    After    json.RawMessage `json:"after"`
+    Changes  []FieldChange   `json:"changes"`
+}
+
+// FieldChange is a field of a record with its JSON value before and after a change
+type FieldChange struct {
+    Field  string `json:"field"`
+    Before string `json:"before"`
+    After  string `json:"after"`
+}
+
+// AuditFilter selects audit entries: Entity and EntityID when set, and the
+// changes made at or after Since when it is not zero
+type AuditFilter struct {
+    Entity   string
+    EntityID int
+    Since    time.Time
+}
\ No newline at end of file
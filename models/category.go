@@ -6,6 +6,11 @@ type Category struct {
     ID          int       `json:"id"`
     Name        string    `json:"name"`
     Description string    `json:"description"`
-    CreatedAt   time.Time `json:"created_at"`
-    UpdatedAt   time.Time `json:"updated_at"`
+    DepreciationPolicy
+    JournalAccounts
+    CreatedAt time.Time `json:"created_at"`
+    UpdatedAt time.Time `json:"updated_at"`
+    // Version is incremented by every update; an update with Version set
+    // only applies while the stored category is still at that version
+    Version int `json:"version"`
 }
\ No newline at end of file
@@ -0,0 +1,27 @@
+package models
+
+import "mini_project3/money"
+
+// DepreciationPolicy selects how an item or the items of a category
+// depreciate. Zero fields are inherited: an item falls back to its category
+// and a category to declining balance at 20% per year.
+type DepreciationPolicy struct {
+    Method           string `json:"depreciation_method"`
+    UsefulLifeMonths int    `json:"useful_life_months"`
+    // RatePercent is the yearly rate of the declining balance method, e.g. 25 for 25%
+    RatePercent money.Rate `json:"depreciation_rate_percent"`
+    // SalvageValue and SalvagePercent are the residual value the book value
+    // stops at, as an amount in the currency of the item price or as a
+    // percentage of the price. At most one is set; both are inherited together.
+    SalvageValue   money.Money `json:"salvage_value"`
+    SalvagePercent money.Rate  `json:"salvage_percent"`
+    // TaxGroup maps the asset to a group of the fiscal book, e.g. kelompok-2,
+    // and TaxMethod selects its straight-line or declining-balance rate
+    TaxGroup  string `json:"tax_group"`
+    TaxMethod string `json:"tax_method"`
+}
+
+// HasSalvage reports whether the policy sets a residual value of its own
+func (p DepreciationPolicy) HasSalvage() bool {
+    return !p.SalvageValue.IsZero() || !p.SalvagePercent.IsZero()
+}
\ No newline at end of file
@@ -0,0 +1,49 @@
+package models
+
+import (
+    "time"
+
+    "mini_project3/money"
+)
+
+// Disposal records how an item left the inventory. DisposedAt is nil while
+// the item is in use; its depreciation stops on that date.
+type Disposal struct {
+    DisposedAt     *time.Time `json:"disposed_at"`
+    DisposalMethod string     `json:"disposal_method"`
+    // DisposalProceeds is what the item was sold or scrapped for, in the currency of its price
+    DisposalProceeds money.Money `json:"disposal_proceeds"`
+}
+
+// DisposedBy reports whether the item was disposed on or before day
+func (d Disposal) DisposedBy(day time.Time) bool {
+    return d.DisposedAt != nil && !d.DisposedAt.After(day)
+}
+
+// DisposalLine reports a disposed item in Currency: the proceeds converted
+// at the rate of the disposal date minus its book value on that date
+type DisposalLine struct {
+    ID                      int         `json:"id"`
+    Name                    string      `json:"name"`
+    CategoryName            string      `json:"category_name"`
+    PurchaseDate            time.Time   `json:"purchase_date"`
+    DisposedAt              time.Time   `json:"disposed_at"`
+    DisposalMethod          string      `json:"disposal_method"`
+    Currency                string      `json:"currency"`
+    PurchaseValue           money.Money `json:"purchase_value"`
+    AccumulatedDepreciation money.Money `json:"accumulated_depreciation"`
+    BookValue               money.Money `json:"book_value"`
+    Proceeds                money.Money `json:"proceeds"`
+    // GainLoss is positive for a gain and negative for a loss on disposal
+    GainLoss money.Money `json:"gain_loss"`
+}
+
+// DisposalReport lists the items disposed by the as-of date with their totals in Currency
+type DisposalReport struct {
+    Currency       string         `json:"currency"`
+    Items          []DisposalLine `json:"items"`
+    TotalPurchase  money.Money    `json:"total_purchase"`
+    TotalBookValue money.Money    `json:"total_book_value"`
+    TotalProceeds  money.Money    `json:"total_proceeds"`
+    TotalGainLoss  money.Money    `json:"total_gain_loss"`
+}
\ No newline at end of file
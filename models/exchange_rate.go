@@ -0,0 +1,15 @@
+package models
+
+import (
+    "time"
+
+    "mini_project3/money"
+)
+
+// ExchangeRate is the value of one unit of Currency in the base currency (IDR) on Date
+type ExchangeRate struct {
+    Currency  string     `json:"currency"`
+    Date      time.Time  `json:"date"`
+    Rate      money.Rate `json:"rate"`
+    CreatedAt time.Time  `json:"created_at"`
+}
\ No newline at end of file
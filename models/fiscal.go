@@ -0,0 +1,45 @@
+package models
+
+import "mini_project3/money"
+
+// FiscalDepreciation is the fiscal book of an item, depreciated by the
+// prescribed life and rate of its tax group in the same currency as the
+// commercial ItemDepreciation it belongs to
+type FiscalDepreciation struct {
+    TaxGroup          string      `json:"tax_group"`
+    TaxGroupName      string      `json:"tax_group_name"`
+    Method            string      `json:"method"`
+    MethodDescription string      `json:"method_description"`
+    Formula           string      `json:"formula"`
+    CurrentValue      money.Money `json:"current_value"`
+    DepreciationValue money.Money `json:"depreciation_value"`
+    // TimingDifference is the commercial less the fiscal accumulated
+    // depreciation, a positive fiscal correction when above zero
+    TimingDifference money.Money `json:"timing_difference"`
+}
+
+// FiscalLine compares the commercial and fiscal book value of one item
+type FiscalLine struct {
+    ID               int         `json:"id"`
+    Name             string      `json:"name"`
+    CategoryName     string      `json:"category_name"`
+    TaxGroup         string      `json:"tax_group"`
+    TaxMethod        string      `json:"tax_method"`
+    PurchaseValue    money.Money `json:"purchase_value"`
+    CommercialValue  money.Money `json:"commercial_value"`
+    FiscalValue      money.Money `json:"fiscal_value"`
+    TimingDifference money.Money `json:"timing_difference"`
+}
+
+// FiscalReport puts the commercial and fiscal book of every item mapped to a
+// tax group side by side, in Currency
+type FiscalReport struct {
+    Currency              string       `json:"currency"`
+    Items                 []FiscalLine `json:"items"`
+    TotalPurchase         money.Money  `json:"total_purchase"`
+    TotalCommercial       money.Money  `json:"total_commercial"`
+    TotalFiscal           money.Money  `json:"total_fiscal"`
+    TotalTimingDifference money.Money  `json:"total_timing_difference"`
+    // UnmappedItems counts the items without a tax group, left out of the report
+    UnmappedItems int `json:"unmapped_items"`
+}
\ No newline at end of file
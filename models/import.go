@@ -0,0 +1,30 @@
+package models
+
+// Outcomes of a row of an item import
+const (
+    ImportImported = "imported" // the item was created
+    ImportValid    = "valid"    // a dry run would create the item
+    ImportInvalid  = "invalid"  // the row failed validation and nothing was imported
+    ImportSkipped  = "skipped"  // the row failed validation and the others were imported
+)
+
+// ImportRow is the outcome of one row of an item import file
+type ImportRow struct {
+    Line     int    `json:"line"`
+    Name     string `json:"name"`
+    Category string `json:"category"`
+    Status   string `json:"status"`
+    ItemID   int    `json:"item_id"`
+    Error    string `json:"error"`
+}
+
+// ImportReport lists the outcome of every row of an item import. Imported
+// and NewCategories count the items and name the categories it created, or
+// would create on a dry run.
+type ImportReport struct {
+    DryRun        bool        `json:"dry_run"`
+    Rows          []ImportRow `json:"rows"`
+    Imported      int         `json:"imported"`
+    Invalid       int         `json:"invalid"`
+    NewCategories []string    `json:"new_categories"`
+}
\ No newline at end of file
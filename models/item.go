@@ -1,22 +1,45 @@
 package models
 
-import "time"
+import (
+    "time"
+
+    "mini_project3/money"
+)
 
 type Item struct {
-    ID           int       `json:"id"`
-    Name         string    `json:"name"`
-    CategoryID   int       `json:"category_id"`
-    CategoryName string    `json:"category_name"`
-    Price        float64   `json:"price"`
-    PurchaseDate time.Time `json:"purchase_date"`
-    CreatedAt    time.Time `json:"created_at"`
-    UpdatedAt    time.Time `json:"updated_at"`
+    ID           int         `json:"id"`
+    Name         string      `json:"name"`
+    CategoryID   int         `json:"category_id"`
+    CategoryName string      `json:"category_name"`
+    Price        money.Money `json:"price"`
+    Currency     string      `json:"currency"`
+    PurchaseDate time.Time   `json:"purchase_date"`
+    DepreciationPolicy
+    Disposal
+    CreatedAt time.Time `json:"created_at"`
+    UpdatedAt time.Time `json:"updated_at"`
+    // Version is incremented by every update; an update with Version set
+    // only applies while the stored item is still at that version
+    Version int `json:"version"`
 }
 
+// ItemDepreciation reports an item in ReportCurrency: PurchaseValue is the
+// price converted at the rate of the purchase date, CurrentValue and
+// DepreciationValue are derived from it by Method, the effective method
+// after the item and category policies are merged. CurrentValue never drops
+// below ResidualValue, the salvage value of the policy in ReportCurrency.
 type ItemDepreciation struct {
     Item
-    DaysUsed          int     `json:"days_used"`
-    DepreciationRate  float64 `json:"depreciation_rate"`
-    CurrentValue      float64 `json:"current_value"`
-    DepreciationValue float64 `json:"depreciation_value"`
+    DaysUsed          int         `json:"days_used"`
+    Method            string      `json:"method"`
+    MethodDescription string      `json:"method_description"`
+    Formula           string      `json:"formula"`
+    DepreciationRate  float64     `json:"depreciation_rate"`
+    ReportCurrency    string      `json:"report_currency"`
+    PurchaseValue     money.Money `json:"purchase_value"`
+    ResidualValue     money.Money `json:"residual_value"`
+    CurrentValue      money.Money `json:"current_value"`
+    DepreciationValue money.Money `json:"depreciation_value"`
+    // Fiscal is the fiscal book of the item, nil when it has no tax group
+    Fiscal *FiscalDepreciation `json:"fiscal,omitempty"`
 }
\ No newline at end of file
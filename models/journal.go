@@ -0,0 +1,42 @@
+package models
+
+import (
+    "time"
+
+    "mini_project3/money"
+)
+
+// JournalAccounts are the general ledger accounts a category books its
+// monthly depreciation to; empty accounts use the default of the journal
+type JournalAccounts struct {
+    ExpenseAccount     string `json:"expense_account"`
+    AccumulatedAccount string `json:"accumulated_account"`
+}
+
+// Journal is the depreciation journal of one month: per category a debit to
+// its expense account and a credit to its accumulated depreciation account
+type Journal struct {
+    Month       string        `json:"month"`
+    Date        time.Time     `json:"date"`
+    Currency    string        `json:"currency"`
+    Lines       []JournalLine `json:"lines"`
+    TotalDebit  money.Money   `json:"total_debit"`
+    TotalCredit money.Money   `json:"total_credit"`
+    Posted      bool          `json:"posted"`
+    PostedAt    *time.Time    `json:"posted_at,omitempty"`
+    // Difference is the depreciation of a posted month recalculated today
+    // minus the posted one, e.g. after a backdated purchase
+    Difference money.Money `json:"difference"`
+}
+
+// JournalLine is one debit or credit of a Journal
+type JournalLine struct {
+    Month        string      `json:"month"`
+    Date         time.Time   `json:"date"`
+    CategoryID   int         `json:"category_id"`
+    CategoryName string      `json:"category_name"`
+    Account      string      `json:"account"`
+    Description  string      `json:"description"`
+    Debit        money.Money `json:"debit"`
+    Credit       money.Money `json:"credit"`
+}
\ No newline at end of file
@@ -0,0 +1,62 @@
+package models
+
+import (
+    "time"
+
+    "mini_project3/money"
+)
+
+// InvestmentSummary is the result of the total investment report, in Currency
+type InvestmentSummary struct {
+    Currency               string          `json:"currency"`
+    TotalOriginal          money.Money     `json:"total_original"`
+    TotalCurrent           money.Money     `json:"total_current"`
+    TotalDepreciation      money.Money     `json:"total_depreciation"`
+    DepreciationPercentage float64         `json:"depreciation_percentage"`
+    Methods                []MethodSummary `json:"methods,omitempty"`
+}
+
+// MethodSummary totals the items depreciated with one method and its parameters
+type MethodSummary struct {
+    Method            string      `json:"method"`
+    Description       string      `json:"description"`
+    Formula           string      `json:"formula"`
+    Items             int         `json:"items"`
+    TotalOriginal     money.Money `json:"total_original"`
+    TotalCurrent      money.Money `json:"total_current"`
+    TotalDepreciation money.Money `json:"total_depreciation"`
+}
+
+// DepreciationSchedule lists the book value of one item per calendar month or
+// year, from its purchase date to the end of its useful life, in Currency
+type DepreciationSchedule struct {
+    ID                int           `json:"id"`
+    Name              string        `json:"name"`
+    Period            string        `json:"period"`
+    Method            string        `json:"method"`
+    MethodDescription string        `json:"method_description"`
+    Currency          string        `json:"currency"`
+    PurchaseDate      time.Time     `json:"purchase_date"`
+    PurchaseValue     money.Money   `json:"purchase_value"`
+    ResidualValue     money.Money   `json:"residual_value"`
+    Rows              []ScheduleRow `json:"rows"`
+    // Truncated is set when the book value never reaches ResidualValue and
+    // the schedule stops after a fixed number of years
+    Truncated bool `json:"truncated"`
+    // DisposedAt ends the schedule in the period the item was disposed in
+    DisposedAt *time.Time `json:"disposed_at,omitempty"`
+}
+
+// ScheduleRow is one period of a DepreciationSchedule. The first period
+// starts on the purchase date; ClosingValue is the book value at the end of
+// the day End, as CalculateDepreciation reports it with that day as clock.
+type ScheduleRow struct {
+    Period                  string      `json:"period"`
+    Start                   time.Time   `json:"start"`
+    End                     time.Time   `json:"end"`
+    DaysUsed                int         `json:"days_used"`
+    OpeningValue            money.Money `json:"opening_value"`
+    Depreciation            money.Money `json:"depreciation"`
+    AccumulatedDepreciation money.Money `json:"accumulated_depreciation"`
+    ClosingValue            money.Money `json:"closing_value"`
+}
\ No newline at end of file
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Entities that can be moved to the trash
+const (
+    TrashItem     = "item"
+    TrashCategory = "category"
+)
+
+// TrashEntry is a deleted item or category that can still be restored
+type TrashEntry struct {
+    Entity    string    `json:"entity"`
+    ID        int       `json:"id"`
+    Name      string    `json:"name"`
+    DeletedAt time.Time `json:"deleted_at"`
+}
+
+// PurgeResult counts the records deleted permanently because they were
+// moved to the trash before Before
+type PurgeResult struct {
+    Before     time.Time `json:"before"`
+    Items      int       `json:"items"`
+    Categories int       `json:"categories"`
+}
\ No newline at end of file
@@ -0,0 +1,89 @@
+package money
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Locale describes how amounts are written for readers of one language:
+// the thousands and decimal separators and the symbol of each currency.
+type Locale struct {
+	Tag     string
+	Group   string
+	Decimal string
+	Symbols map[string]string
+	// SymbolSpace puts a space between the symbol and the number, "Rp 1.000,00"
+	SymbolSpace bool
+}
+
+// Indonesian is the default locale of the CLI: Rp 1.234.567,89
+var Indonesian = Locale{
+	Tag:         "id-ID",
+	Group:       ".",
+	Decimal:     ",",
+	Symbols:     map[string]string{"IDR": "Rp", "USD": "US$", "SGD": "S$", "EUR": "€", "JPY": "¥", "GBP": "£", "AUD": "AU$"},
+	SymbolSpace: true,
+}
+
+// English writes amounts the US way: Rp1,234,567.89, $1,234,567.89
+var English = Locale{
+	Tag:     "en-US",
+	Group:   ",",
+	Decimal: ".",
+	Symbols: map[string]string{"IDR": "Rp", "USD": "$", "SGD": "S$", "EUR": "€", "JPY": "¥", "GBP": "£", "AUD": "A$"},
+}
+
+// Locales lists the supported locales, default first
+var Locales = []Locale{Indonesian, English}
+
+// LookupLocale returns the locale for a tag such as "id-ID", "en" or "en_US.UTF-8"
+func LookupLocale(tag string) (Locale, bool) {
+	tag = strings.ToLower(strings.ReplaceAll(strings.SplitN(tag, ".", 2)[0], "_", "-"))
+	for _, l := range Locales {
+		full := strings.ToLower(l.Tag)
+		if tag == full || tag == strings.SplitN(full, "-", 2)[0] {
+			return l, true
+		}
+	}
+	return Locale{}, false
+}
+
+// Number writes m with the locale separators and no symbol, e.g. -1.234.567,89
+func (l Locale) Number(m Money) string {
+	cents := m.cents
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+
+	digits := fmt.Sprintf("%d", cents/100)
+	var sb strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			sb.WriteString(l.Group)
+		}
+		sb.WriteRune(digit)
+	}
+	return fmt.Sprintf("%s%s%s%02d", sign, sb.String(), l.Decimal, cents%100)
+}
+
+// Format writes m in currency with its symbol, e.g. "Rp 1.234.567,89" or
+// "-US$ 12,50". A currency without a known symbol is written with its code.
+func (l Locale) Format(m Money, currency string) string {
+	symbol, ok := l.Symbols[currency]
+	if !ok {
+		symbol = currency
+	}
+	separator := ""
+	if l.SymbolSpace || !ok {
+		separator = " "
+	}
+
+	number := l.Number(m)
+	sign := ""
+	if strings.HasPrefix(number, "-") {
+		sign, number = "-", number[1:]
+	}
+	return sign + symbol + separator + number
+}
@@ -0,0 +1,51 @@
+package money
+
+import "testing"
+
+func TestLocale_Format(t *testing.T) {
+	tests := []struct {
+		locale   Locale
+		amount   Money
+		currency string
+		expected string
+	}{
+		{Indonesian, FromInt(15000000), "IDR", "Rp 15.000.000,00"},
+		{Indonesian, MustParse("2500000.75"), "IDR", "Rp 2.500.000,75"},
+		{Indonesian, FromInt(100), "IDR", "Rp 100,00"},
+		{Indonesian, Zero, "IDR", "Rp 0,00"},
+		{Indonesian, MustParse("-1234.5"), "IDR", "-Rp 1.234,50"},
+		{Indonesian, MustParse("150.75"), "USD", "US$ 150,75"},
+		{Indonesian, FromInt(1000), "CHF", "CHF 1.000,00"},
+		{English, MustParse("1234567.89"), "USD", "$1,234,567.89"},
+		{English, FromInt(15000000), "IDR", "Rp15,000,000.00"},
+		{English, MustParse("-0.05"), "SGD", "-S$0.05"},
+		{English, FromInt(1000), "CHF", "CHF 1,000.00"},
+	}
+	for _, tt := range tests {
+		if got := tt.locale.Format(tt.amount, tt.currency); got != tt.expected {
+			t.Errorf("%s Format(%s, %s) = %q; expected %q", tt.locale.Tag, tt.amount, tt.currency, got, tt.expected)
+		}
+	}
+}
+
+func TestLookupLocale(t *testing.T) {
+	tests := map[string]string{
+		"id-ID":       "id-ID",
+		"id":          "id-ID",
+		"en":          "en-US",
+		"EN-us":       "en-US",
+		"en_US.UTF-8": "en-US",
+	}
+	for tag, expected := range tests {
+		l, ok := LookupLocale(tag)
+		if !ok || l.Tag != expected {
+			t.Errorf("LookupLocale(%q) = %q, %v; expected %q", tag, l.Tag, ok, expected)
+		}
+	}
+
+	for _, tag := range []string{"", "fr-FR", "english"} {
+		if _, ok := LookupLocale(tag); ok {
+			t.Errorf("expected no locale for %q", tag)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+// Package money holds Money, the exact decimal amount used for prices,
+// book values and report totals. An amount is a whole number of cents
+// (sen), matching the DECIMAL(15,2) price column, so adding and
+// subtracting is exact. Multiplying by a rate is the only operation that
+// rounds, and it always rounds half to even to the cent.
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Money is an amount of currency in cents. The zero value is zero.
+type Money struct {
+	cents int64
+}
+
+// Zero is the zero amount
+var Zero = Money{}
+
+// FromCents returns the amount of cents as Money
+func FromCents(cents int64) Money {
+	return Money{cents: cents}
+}
+
+// FromInt returns a whole amount, e.g. FromInt(1500000) is Rp 1.500.000,00
+func FromInt(units int64) Money {
+	return Money{cents: units * 100}
+}
+
+// Parse reads a plain decimal amount such as "1500000", "-12.5" or
+// "2500000.75". More than two decimal places is an error rather than a
+// silent rounding, because the amount could not be stored as entered.
+func Parse(s string) (Money, error) {
+	text := strings.TrimSpace(s)
+	negative := strings.HasPrefix(text, "-")
+	text = strings.TrimPrefix(strings.TrimPrefix(text, "-"), "+")
+
+	whole, frac, hasDot := strings.Cut(text, ".")
+	if whole == "" && frac == "" || !digitsOnly(whole) || !digitsOnly(frac) || hasDot && frac == "" {
+		return Zero, fmt.Errorf("'%s' is not a decimal amount", s)
+	}
+	if len(frac) > 2 {
+		return Zero, fmt.Errorf("'%s' has more than 2 decimal places", s)
+	}
+
+	cents, err := strconv.ParseInt(whole+(frac + "00")[:2], 10, 64)
+	if err != nil {
+		return Zero, fmt.Errorf("'%s' is out of range", s)
+	}
+	if negative {
+		cents = -cents
+	}
+	return Money{cents: cents}, nil
+}
+
+// MustParse is Parse for constants in fixtures and tests; it panics on invalid input
+func MustParse(s string) Money {
+	m, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+func digitsOnly(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Cents returns the amount in cents
+func (m Money) Cents() int64 {
+	return m.cents
+}
+
+func (m Money) Add(other Money) Money {
+	return Money{cents: m.cents + other.cents}
+}
+
+func (m Money) Sub(other Money) Money {
+	return Money{cents: m.cents - other.cents}
+}
+
+func (m Money) Neg() Money {
+	return Money{cents: -m.cents}
+}
+
+func (m Money) IsZero() bool {
+	return m.cents == 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.cents < 0
+}
+
+// Cmp returns -1, 0 or +1 as m is less than, equal to or greater than other
+func (m Money) Cmp(other Money) int {
+	switch {
+	case m.cents < other.cents:
+		return -1
+	case m.cents > other.cents:
+		return 1
+	}
+	return 0
+}
+
+// Mul returns m × factor rounded half to even to the cent
+func (m Money) Mul(factor *big.Rat) Money {
+	product := new(big.Rat).Mul(new(big.Rat).SetInt64(m.cents), factor)
+	return Money{cents: roundHalfEven(product)}
+}
+
+// MulFrac returns m × num / den rounded half to even to the cent
+func (m Money) MulFrac(num, den int64) Money {
+	return m.Mul(big.NewRat(num, den))
+}
+
+// MulFloat returns m × factor rounded half to even to the cent. The factor
+// is taken at its exact binary value, so the result is deterministic, but
+// prefer Mul or MulFrac when the factor is a decimal rate.
+func (m Money) MulFloat(factor float64) Money {
+	return m.Mul(new(big.Rat).SetFloat64(factor))
+}
+
+// Ratio returns m / other as a float for percentages; it is 0 when other is zero
+func (m Money) Ratio(other Money) float64 {
+	if other.cents == 0 {
+		return 0
+	}
+	return float64(m.cents) / float64(other.cents)
+}
+
+func roundHalfEven(r *big.Rat) int64 {
+	quo, rem := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+	// Compare twice the remainder with the denominator to decide the rounding
+	twice := new(big.Int).Abs(rem)
+	twice.Lsh(twice, 1)
+	switch twice.Cmp(r.Denom()) {
+	case 1:
+		quo.Add(quo, big.NewInt(int64(r.Sign())))
+	case 0:
+		if quo.Bit(0) == 1 {
+			quo.Add(quo, big.NewInt(int64(r.Sign())))
+		}
+	}
+	return quo.Int64()
+}
+
+// String returns the plain decimal form with two places, e.g. "-1500000.50"
+func (m Money) String() string {
+	sign := ""
+	cents := m.cents
+	if cents < 0 {
+		sign = "-"
+	}
+	units, frac := cents/100, cents%100
+	if units < 0 {
+		units = -units
+	}
+	if frac < 0 {
+		frac = -frac
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, units, frac)
+}
+
+// MarshalJSON writes the amount as a JSON number with two decimal places
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+func (m *Money) UnmarshalJSON(data []byte) error {
+	parsed, err := Parse(strings.Trim(string(data), `"`))
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// Value stores the amount as its decimal text so DECIMAL columns keep it exact
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+// Scan reads a DECIMAL column: PostgreSQL returns the decimal text, SQLite
+// an integer or a float depending on how the value was stored
+func (m *Money) Scan(src interface{}) error {
+	var (
+		parsed Money
+		err    error
+	)
+	switch v := src.(type) {
+	case []byte:
+		parsed, err = Parse(string(v))
+	case string:
+		parsed, err = Parse(v)
+	case int64:
+		parsed = FromInt(v)
+	case float64:
+		// A DECIMAL(15,2) value read back as a float is the nearest float to
+		// the stored decimal, so formatting it with two places recovers it
+		parsed, err = Parse(strconv.FormatFloat(v, 'f', 2, 64))
+	case nil:
+		err = errors.New("cannot scan NULL into Money")
+	default:
+		err = fmt.Errorf("cannot scan %T into Money", src)
+	}
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
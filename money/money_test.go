@@ -0,0 +1,122 @@
+package money
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input string
+		cents int64
+	}{
+		{"1500000", 150000000},
+		{"1500000.5", 150000050},
+		{"2500000.75", 250000075},
+		{"-12.05", -1205},
+		{"+0.01", 1},
+		{" 7 ", 700},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.input)
+		if err != nil || got.Cents() != tt.cents {
+			t.Errorf("Parse(%q) = %d, %v; expected %d", tt.input, got.Cents(), err, tt.cents)
+		}
+	}
+
+	for _, input := range []string{"", "-", "1.", "1.234", "1,5", "abc", "1e6", "99999999999999999999"} {
+		if _, err := Parse(input); err == nil {
+			t.Errorf("expected error for %q", input)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := map[Money]string{
+		FromCents(150000050): "1500000.50",
+		FromCents(-5):        "-0.05",
+		FromCents(-12345):    "-123.45",
+		Zero:                 "0.00",
+	}
+	for m, expected := range tests {
+		if got := m.String(); got != expected {
+			t.Errorf("expected %s, got %s", expected, got)
+		}
+	}
+}
+
+func TestMul_HalfEven(t *testing.T) {
+	half := big.NewRat(1, 2)
+	tests := []struct {
+		cents, expected int64
+	}{
+		{1, 0},   // 0.5 → 0
+		{3, 2},   // 1.5 → 2
+		{5, 2},   // 2.5 → 2
+		{7, 4},   // 3.5 → 4
+		{-5, -2}, // -2.5 → -2
+		{-7, -4}, // -3.5 → -4
+	}
+	for _, tt := range tests {
+		if got := FromCents(tt.cents).Mul(half).Cents(); got != tt.expected {
+			t.Errorf("%d × 1/2: expected %d, got %d", tt.cents, tt.expected, got)
+		}
+	}
+
+	if got := FromCents(100005).MulFrac(20, 100); got != FromCents(20001) {
+		t.Errorf("expected 200.01, got %s", got)
+	}
+	if got := FromCents(80004).MulFrac(20, 100); got != FromCents(16001) {
+		t.Errorf("expected 160.01, got %s", got)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	data, err := json.Marshal(struct {
+		Price Money `json:"price"`
+	}{MustParse("1500000.5")})
+	if err != nil || string(data) != `{"price":1500000.50}` {
+		t.Fatalf("unexpected json %s (%v)", data, err)
+	}
+
+	var decoded struct {
+		Price Money `json:"price"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded.Price != MustParse("1500000.50") {
+		t.Errorf("unexpected round trip %s (%v)", decoded.Price, err)
+	}
+}
+
+func TestScan(t *testing.T) {
+	tests := []struct {
+		src      interface{}
+		expected Money
+	}{
+		{[]byte("1500000.50"), MustParse("1500000.50")},
+		{"12.00", FromInt(12)},
+		{int64(15000000), FromInt(15000000)},
+		{1500000.5, MustParse("1500000.50")},
+		{0.1 + 0.2, MustParse("0.30")},
+	}
+	for _, tt := range tests {
+		var m Money
+		if err := m.Scan(tt.src); err != nil {
+			t.Errorf("Scan(%v): unexpected error %s", tt.src, err)
+			continue
+		}
+		if m != tt.expected {
+			t.Errorf("Scan(%v): expected %s, got %s", tt.src, tt.expected, m)
+		}
+	}
+
+	var m Money
+	if err := m.Scan(nil); err == nil {
+		t.Error("expected error scanning NULL")
+	}
+
+	value, err := MustParse("-3.5").Value()
+	if err != nil || value != "-3.50" {
+		t.Errorf("unexpected driver value %v (%v)", value, err)
+	}
+}
@@ -0,0 +1,111 @@
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// rateScale is the number of rate units per 1, matching the DECIMAL(18,6) rate column
+const rateScale = 1_000_000
+
+// Rate is an exact exchange rate with six decimal places: how many units of
+// the base currency one unit of another currency buys. It also holds the
+// depreciation percentages of items and categories.
+type Rate struct {
+	micros int64
+}
+
+// ParseRate reads a plain decimal rate such as "16250.5" with at most six decimal places
+func ParseRate(s string) (Rate, error) {
+	text := strings.TrimSpace(s)
+	whole, frac, hasDot := strings.Cut(text, ".")
+	if whole == "" && frac == "" || !digitsOnly(whole) || !digitsOnly(frac) || hasDot && frac == "" {
+		return Rate{}, fmt.Errorf("'%s' is not a decimal rate", s)
+	}
+	if len(frac) > 6 {
+		return Rate{}, fmt.Errorf("'%s' has more than 6 decimal places", s)
+	}
+
+	micros, err := strconv.ParseInt(whole+(frac + "000000")[:6], 10, 64)
+	if err != nil {
+		return Rate{}, fmt.Errorf("'%s' is out of range", s)
+	}
+	return Rate{micros: micros}, nil
+}
+
+// MustParseRate is ParseRate for constants in fixtures and tests; it panics on invalid input
+func MustParseRate(s string) Rate {
+	r, err := ParseRate(s)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// RateOne is the rate of a currency to itself
+var RateOne = Rate{micros: rateScale}
+
+func (r Rate) IsZero() bool {
+	return r.micros == 0
+}
+
+// Rat returns the rate as an exact fraction
+func (r Rate) Rat() *big.Rat {
+	return big.NewRat(r.micros, rateScale)
+}
+
+// String returns the rate without trailing zeros, e.g. "16250.5"
+func (r Rate) String() string {
+	text := fmt.Sprintf("%d.%06d", r.micros/rateScale, r.micros%rateScale)
+	return strings.TrimSuffix(strings.TrimRight(text, "0"), ".")
+}
+
+// MarshalJSON writes the rate as a JSON number
+func (r Rate) MarshalJSON() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+func (r *Rate) UnmarshalJSON(data []byte) error {
+	parsed, err := ParseRate(strings.Trim(string(data), `"`))
+	if err != nil {
+		return err
+	}
+	*r = parsed
+	return nil
+}
+
+// Value stores the rate as its decimal text so the DECIMAL column keeps it exact
+func (r Rate) Value() (driver.Value, error) {
+	return r.String(), nil
+}
+
+// Scan reads a DECIMAL rate column, see Money.Scan
+func (r *Rate) Scan(src interface{}) error {
+	var (
+		parsed Rate
+		err    error
+	)
+	switch v := src.(type) {
+	case []byte:
+		parsed, err = ParseRate(string(v))
+	case string:
+		parsed, err = ParseRate(v)
+	case int64:
+		parsed = Rate{micros: v * rateScale}
+	case float64:
+		parsed, err = ParseRate(strconv.FormatFloat(v, 'f', 6, 64))
+	case nil:
+		err = errors.New("cannot scan NULL into Rate")
+	default:
+		err = fmt.Errorf("cannot scan %T into Rate", src)
+	}
+	if err != nil {
+		return err
+	}
+	*r = parsed
+	return nil
+}
@@ -0,0 +1,56 @@
+package money
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+)
+
+func TestParseRate(t *testing.T) {
+	tests := map[string]string{
+		"16250":        "16250",
+		"16250.5":      "16250.5",
+		"12880.123456": "12880.123456",
+		" 0.000001 ":   "0.000001",
+		"108.500":      "108.5",
+	}
+	for input, expected := range tests {
+		got, err := ParseRate(input)
+		if err != nil || got.String() != expected {
+			t.Errorf("ParseRate(%q) = %s, %v; expected %s", input, got, err, expected)
+		}
+	}
+
+	for _, input := range []string{"", ".", "1.", "-1", "+1", "1.1234567", "1,5", "abc", "99999999999999999999"} {
+		if _, err := ParseRate(input); err == nil {
+			t.Errorf("expected error for %q", input)
+		}
+	}
+}
+
+func TestRate_Rat(t *testing.T) {
+	if got := MustParseRate("16250.5").Rat(); got.Cmp(big.NewRat(32501, 2)) != 0 {
+		t.Errorf("expected 32501/2, got %s", got)
+	}
+	if RateOne.Rat().Cmp(big.NewRat(1, 1)) != 0 {
+		t.Errorf("expected RateOne to be 1, got %s", RateOne.Rat())
+	}
+}
+
+func TestRate_JSONAndScan(t *testing.T) {
+	data, err := json.Marshal(struct{ Rate Rate }{MustParseRate("16250.5")})
+	if err != nil || string(data) != `{"Rate":16250.5}` {
+		t.Fatalf("unexpected JSON %s, %v", data, err)
+	}
+	var decoded struct{ Rate Rate }
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded.Rate != MustParseRate("16250.5") {
+		t.Errorf("unexpected round trip %s, %v", decoded.Rate, err)
+	}
+
+	for _, src := range []interface{}{[]byte("16250.500000"), "16250.5", 16250.5} {
+		var r Rate
+		if err := r.Scan(src); err != nil || r != MustParseRate("16250.5") {
+			t.Errorf("Scan(%v) = %s, %v", src, r, err)
+		}
+	}
+}
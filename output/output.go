@@ -0,0 +1,181 @@
+// Package output renders command results in the machine-readable formats
+// selected with --output. Field names always come from the json tags of the
+// models, so json, yaml, csv and tsv share the same stable names and raw
+// numeric values; the human table format is left to the handlers.
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"mini_project3/apperrors"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Format string
+
+const (
+	Table Format = "table"
+	JSON  Format = "json"
+	YAML  Format = "yaml"
+	CSV   Format = "csv"
+	TSV   Format = "tsv"
+)
+
+// Formats lists the accepted --output values, default first
+var Formats = []Format{Table, JSON, YAML, CSV, TSV}
+
+// ParseFormat validates an --output value
+func ParseFormat(value string) (Format, error) {
+	for _, f := range Formats {
+		if string(f) == value {
+			return f, nil
+		}
+	}
+	names := make([]string, len(Formats))
+	for i, f := range Formats {
+		names[i] = string(f)
+	}
+	return "", apperrors.NewValidationError("--output", fmt.Sprintf("must be one of %s, got '%s'", strings.Join(names, ", "), value))
+}
+
+// Write renders v, a struct or a slice of structs, in a non-table format.
+// csv and tsv write one header row followed by one row per element.
+func Write(w io.Writer, f Format, v interface{}) error {
+	v = nonNilSlice(v)
+
+	switch f {
+	case JSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case YAML:
+		node, err := toNode(v)
+		if err != nil {
+			return err
+		}
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(node); err != nil {
+			return fmt.Errorf("error encoding yaml: %w", err)
+		}
+		return enc.Close()
+	case CSV, TSV:
+		return writeRecords(w, f, v)
+	}
+	return fmt.Errorf("output format '%s' cannot be written as data", f)
+}
+
+// nonNilSlice turns a nil slice into an empty one so json prints [] instead of null
+func nonNilSlice(v interface{}) interface{} {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Slice && rv.IsNil() {
+		return reflect.MakeSlice(rv.Type(), 0, 0).Interface()
+	}
+	return v
+}
+
+// toNode converts v to a YAML node through its JSON encoding, which keeps
+// the json tag names and struct field order
+func toNode(v interface{}) (*yaml.Node, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding json: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("error converting json: %w", err)
+	}
+	node := doc.Content[0]
+	blockStyle(node)
+	return node, nil
+}
+
+// blockStyle drops the flow and quoting styles that came from the JSON text;
+// the encoder still quotes strings that would otherwise read as another type
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+func writeRecords(w io.Writer, f Format, v interface{}) error {
+	node, err := toNode(v)
+	if err != nil {
+		return err
+	}
+
+	var records []*yaml.Node
+	switch node.Kind {
+	case yaml.SequenceNode:
+		records = node.Content
+	case yaml.MappingNode:
+		records = []*yaml.Node{node}
+	default:
+		return fmt.Errorf("cannot write %T as %s", v, f)
+	}
+
+	header, err := columns(v, records)
+	if err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	if f == TSV {
+		cw.Comma = '\t'
+	}
+	cw.Write(header)
+	for _, record := range records {
+		row := make([]string, 0, len(header))
+		for i := 0; i+1 < len(record.Content); i += 2 {
+			row = append(row, cell(record.Content[i+1]))
+		}
+		cw.Write(row)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// columns returns the field names of the first record, or of the element
+// type when the slice is empty so the header is still printed
+func columns(v interface{}, records []*yaml.Node) ([]string, error) {
+	if len(records) == 0 {
+		elem := reflect.TypeOf(v).Elem()
+		node, err := toNode(reflect.Zero(elem).Interface())
+		if err != nil {
+			return nil, err
+		}
+		records = []*yaml.Node{node}
+	}
+
+	var header []string
+	for i := 0; i < len(records[0].Content); i += 2 {
+		header = append(header, records[0].Content[i].Value)
+	}
+	return header, nil
+}
+
+// cell formats a field value: scalars as their raw text, nested values as JSON
+func cell(node *yaml.Node) string {
+	if node.Kind == yaml.ScalarNode {
+		if node.Tag == "!!null" {
+			return ""
+		}
+		return node.Value
+	}
+	var nested interface{}
+	if err := node.Decode(&nested); err != nil {
+		return ""
+	}
+	var buf bytes.Buffer
+	json.NewEncoder(&buf).Encode(nested)
+	return strings.TrimSpace(buf.String())
+}
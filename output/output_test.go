@@ -0,0 +1,102 @@
+package output
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"mini_project3/apperrors"
+	"mini_project3/models"
+	"mini_project3/money"
+)
+
+func sampleItems() []models.Item {
+	date := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	return []models.Item{
+		{ID: 1, Name: "Laptop, Dell", CategoryID: 1, CategoryName: "Elektronik", Price: money.MustParse("15000000.50"), Currency: "IDR", PurchaseDate: date, CreatedAt: date, UpdatedAt: date},
+		{ID: 2, Name: "Meja", CategoryID: 2, CategoryName: "Furniture", Price: money.FromInt(1500000), Currency: "IDR", PurchaseDate: date, CreatedAt: date, UpdatedAt: date},
+	}
+}
+
+func render(t *testing.T, f Format, v interface{}) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Write(&buf, f, v); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	return buf.String()
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, f := range Formats {
+		if got, err := ParseFormat(string(f)); err != nil || got != f {
+			t.Errorf("expected %s to parse, got %v (%v)", f, got, err)
+		}
+	}
+	if _, err := ParseFormat("xml"); !errors.Is(err, apperrors.ErrValidation) {
+		t.Errorf("expected validation error for xml, got %v", err)
+	}
+}
+
+func TestWrite_CSV(t *testing.T) {
+	expected := "id,name,category_id,category_name,price,currency,purchase_date,depreciation_method,useful_life_months,depreciation_rate_percent,salvage_value,salvage_percent,tax_group,tax_method,disposed_at,disposal_method,disposal_proceeds,created_at,updated_at,version\n" +
+		"1,\"Laptop, Dell\",1,Elektronik,15000000.50,IDR,2024-06-01T00:00:00Z,,0,0,0.00,0,,,,,0.00,2024-06-01T00:00:00Z,2024-06-01T00:00:00Z,0\n" +
+		"2,Meja,2,Furniture,1500000.00,IDR,2024-06-01T00:00:00Z,,0,0,0.00,0,,,,,0.00,2024-06-01T00:00:00Z,2024-06-01T00:00:00Z,0\n"
+	if got := render(t, CSV, sampleItems()); got != expected {
+		t.Errorf("unexpected csv:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestWrite_TSV_Embedded(t *testing.T) {
+	dep := models.ItemDepreciation{Item: sampleItems()[1], DaysUsed: 10, DepreciationRate: 0.2, ReportCurrency: "IDR", PurchaseValue: money.FromInt(1500000), CurrentValue: money.FromInt(1000), DepreciationValue: money.FromInt(500000)}
+	expected := "id\tname\tcategory_id\tcategory_name\tprice\tcurrency\tpurchase_date\tdepreciation_method\tuseful_life_months\tdepreciation_rate_percent\tsalvage_value\tsalvage_percent\ttax_group\ttax_method\tdisposed_at\tdisposal_method\tdisposal_proceeds\tcreated_at\tupdated_at\tversion\tdays_used\tmethod\tmethod_description\tformula\tdepreciation_rate\treport_currency\tpurchase_value\tresidual_value\tcurrent_value\tdepreciation_value\n" +
+		"2\tMeja\t2\tFurniture\t1500000.00\tIDR\t2024-06-01T00:00:00Z\t\t0\t0\t0.00\t0\t\t\t\t\t0.00\t2024-06-01T00:00:00Z\t2024-06-01T00:00:00Z\t0\t10\t\t\t\t0.2\tIDR\t1500000.00\t0.00\t1000.00\t500000.00\n"
+	if got := render(t, TSV, dep); got != expected {
+		t.Errorf("unexpected tsv:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestWrite_EmptyList(t *testing.T) {
+	var items []models.Category
+
+	if got := render(t, JSON, items); got != "[]\n" {
+		t.Errorf("expected empty json array, got %q", got)
+	}
+	if got := render(t, YAML, items); got != "[]\n" {
+		t.Errorf("expected empty yaml sequence, got %q", got)
+	}
+	if got := render(t, CSV, items); got != "id,name,description,depreciation_method,useful_life_months,depreciation_rate_percent,salvage_value,salvage_percent,tax_group,tax_method,expense_account,accumulated_account,created_at,updated_at,version\n" {
+		t.Errorf("expected csv header only, got %q", got)
+	}
+}
+
+func TestWrite_YAML(t *testing.T) {
+	cat := models.Category{ID: 7, Name: "123", Description: "Mebel: kantor", CreatedAt: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)}
+	expected := "id: 7\n" +
+		"name: \"123\"\n" +
+		"description: 'Mebel: kantor'\n" +
+		"depreciation_method: \"\"\n" +
+		"useful_life_months: 0\n" +
+		"depreciation_rate_percent: 0\n" +
+		"salvage_value: 0.00\n" +
+		"salvage_percent: 0\n" +
+		"tax_group: \"\"\n" +
+		"tax_method: \"\"\n" +
+		"expense_account: \"\"\n" +
+		"accumulated_account: \"\"\n" +
+		"created_at: \"2024-06-01T00:00:00Z\"\n" +
+		"updated_at: \"0001-01-01T00:00:00Z\"\n" +
+		"version: 0\n"
+	if got := render(t, YAML, cat); got != expected {
+		t.Errorf("unexpected yaml:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestWrite_JSON(t *testing.T) {
+	summary := models.InvestmentSummary{Currency: "IDR", TotalOriginal: money.FromInt(100), TotalCurrent: money.FromInt(80), TotalDepreciation: money.FromInt(20), DepreciationPercentage: 20}
+	expected := "{\n  \"currency\": \"IDR\",\n  \"total_original\": 100.00,\n  \"total_current\": 80.00,\n  \"total_depreciation\": 20.00,\n  \"depreciation_percentage\": 20\n}\n"
+	if got := render(t, JSON, summary); got != expected {
+		t.Errorf("unexpected json:\n%s\nexpected:\n%s", got, expected)
+	}
+}
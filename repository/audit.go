@@ -0,0 +1,174 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "strings"
+    "time"
+
+    "mini_project3/apperrors"
+    "mini_project3/models"
+)
+
+// AuditSource tells the audit log who made a change and with which command line
+type AuditSource struct {
+    Actor   string
+    Command string
+}
+
+type auditSourceKey struct{}
+
+// WithAuditSource returns a context whose changes are recorded in the audit log as made by source
+func WithAuditSource(ctx context.Context, source AuditSource) context.Context {
+    return context.WithValue(ctx, auditSourceKey{}, source)
+}
+
+// auditSourceOf returns the source set with WithAuditSource, with actor "unknown" when there is none
+func auditSourceOf(ctx context.Context) AuditSource {
+    source, _ := ctx.Value(auditSourceKey{}).(AuditSource)
+    if source.Actor == "" {
+        source.Actor = "unknown"
+    }
+    return source
+}
+
+// newAuditEntry records action on entity id by the source of ctx. before and
+// after are the record before and after the change, nil where it does not exist.
+// At is in UTC so that SQLite compares it with --since like PostgreSQL.
+func newAuditEntry(ctx context.Context, entity string, id int, action string, before, after interface{}) (models.AuditEntry, error) {
+    source := auditSourceOf(ctx)
+    entry := models.AuditEntry{At: time.Now().UTC(), Actor: source.Actor, Command: source.Command, Entity: entity, EntityID: id, Action: action}
+    var err error
+    if entry.Before, err = snapshot(before); err != nil {
+        return entry, err
+    }
+    if entry.After, err = snapshot(after); err != nil {
+        return entry, err
+    }
+    return entry, nil
+}
+
+// snapshot encodes record as JSON, or returns nil for a nil record
+func snapshot(record interface{}) (json.RawMessage, error) {
+    if record == nil {
+        return nil, nil
+    }
+    data, err := json.Marshal(record)
+    if err != nil {
+        return nil, fmt.Errorf("error encoding audit record: %w", err)
+    }
+    return data, nil
+}
+
+// nullJSON stores a missing snapshot as NULL
+func nullJSON(data json.RawMessage) sql.NullString {
+    return sql.NullString{String: string(data), Valid: data != nil}
+}
+
+// writeAudit appends the change of entity id to audit_log within tx, see newAuditEntry
+func writeAudit(ctx context.Context, tx *sql.Tx, entity string, id int, action string, before, after interface{}) error {
+    entry, err := newAuditEntry(ctx, entity, id, action, before, after)
+    if err != nil {
+        return err
+    }
+
+    query := `
+        INSERT INTO audit_log (at, actor, command, entity, entity_id, action, before_data, after_data)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+    `
+    _, err = tx.ExecContext(ctx, query, entry.At, entry.Actor, entry.Command, entry.Entity, entry.EntityID, entry.Action,
+        nullJSON(entry.Before), nullJSON(entry.After))
+    if err != nil {
+        return fmt.Errorf("error writing audit log: %w", dbError(err))
+    }
+    return nil
+}
+
+// AuditRecord writes the audit entry of action on entity id within tx for a
+// change made outside the repositories, such as database.Seed. The row read
+// within tx is the snapshot: after a create, or before a purge, so a purge
+// is recorded before the row is deleted.
+func AuditRecord(ctx context.Context, tx *sql.Tx, entity string, id int, action string) error {
+    var record interface{}
+    var err error
+    switch entity {
+    case models.AuditCategory:
+        record, err = categoryInTx(ctx, tx, id, false, "")
+        if errors.Is(err, apperrors.ErrNotFound) {
+            record, err = categoryInTx(ctx, tx, id, true, "")
+        }
+    case models.AuditItem:
+        record, err = itemInTx(ctx, tx, id, false)
+        if errors.Is(err, apperrors.ErrNotFound) {
+            record, err = itemInTx(ctx, tx, id, true)
+        }
+    default:
+        return fmt.Errorf("cannot audit unknown entity '%s'", entity)
+    }
+    if err != nil {
+        return err
+    }
+
+    if action == models.AuditPurge {
+        return writeAudit(ctx, tx, entity, id, action, record, nil)
+    }
+    return writeAudit(ctx, tx, entity, id, action, nil, record)
+}
+
+type AuditRepository struct {
+    db *sql.DB
+}
+
+func NewAuditRepository(db *sql.DB) *AuditRepository {
+    return &AuditRepository{db: db}
+}
+
+// List returns the entries matching filter, oldest first
+func (r *AuditRepository) List(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, error) {
+    conditions, args := []string{"at >= $1"}, []interface{}{filter.Since.UTC()}
+    if filter.Entity != "" {
+        args = append(args, filter.Entity)
+        conditions = append(conditions, fmt.Sprintf("entity = $%d", len(args)))
+    }
+    if filter.EntityID != 0 {
+        args = append(args, filter.EntityID)
+        conditions = append(conditions, fmt.Sprintf("entity_id = $%d", len(args)))
+    }
+
+    query := `
+        SELECT id, at, actor, command, entity, entity_id, action, before_data, after_data
+        FROM audit_log
+        WHERE ` + strings.Join(conditions, " AND ") + `
+        ORDER BY at, id
+    `
+    rows, err := r.db.QueryContext(ctx, query, args...)
+    if err != nil {
+        return nil, fmt.Errorf("error querying audit log: %w", dbError(err))
+    }
+    defer rows.Close()
+
+    var entries []models.AuditEntry
+    for rows.Next() {
+        var entry models.AuditEntry
+        var before, after sql.NullString
+        if err := rows.Scan(&entry.ID, &entry.At, &entry.Actor, &entry.Command, &entry.Entity, &entry.EntityID, &entry.Action, &before, &after); err != nil {
+            return nil, fmt.Errorf("error scanning audit entry: %w", err)
+        }
+        entry.At = entry.At.Local()
+        if before.Valid {
+            entry.Before = json.RawMessage(before.String)
+        }
+        if after.Valid {
+            entry.After = json.RawMessage(after.String)
+        }
+        entries = append(entries, entry)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("error querying audit log: %w", dbError(err))
+    }
+
+    return entries, nil
+}
\ No newline at end of file
@@ -0,0 +1,118 @@
+package repository
+
+import (
+    "context"
+    "errors"
+    "testing"
+    "time"
+
+    "github.com/DATA-DOG/go-sqlmock"
+    "mini_project3/models"
+)
+
+var categoryColumnNames = []string{"id", "name", "description", "depreciation_method", "useful_life_months", "depreciation_rate", "salvage_value", "salvage_percent", "tax_group", "tax_method", "expense_account", "accumulated_account", "created_at", "updated_at", "version"}
+
+// expectCategoryRow expects the category read for the audit log within the transaction
+func expectCategoryRow(mock sqlmock.Sqlmock, id int, name string) {
+    mock.ExpectQuery("SELECT id, name, description, .* FROM categories WHERE id = \\$1").
+        WithArgs(id).
+        WillReturnRows(sqlmock.NewRows(categoryColumnNames).AddRow(id, name, "", "", 0, "0", "0", "0", "", "", "", "", time.Now(), time.Now(), 1))
+}
+
+// expectAudit expects the audit_log row of action on entity id
+func expectAudit(mock sqlmock.Sqlmock, entity string, id int, action string) {
+    mock.ExpectExec("INSERT INTO audit_log \\(at, actor, command, entity, entity_id, action, before_data, after_data\\)").
+        WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), entity, id, action, sqlmock.AnyArg(), sqlmock.AnyArg()).
+        WillReturnResult(sqlmock.NewResult(1, 1))
+}
+
+func TestCategoryRepository_Update_WritesAuditInTransaction(t *testing.T) {
+    db, mock, err := sqlmock.New()
+    if err != nil {
+        t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+    }
+    defer db.Close()
+
+    repo := NewCategoryRepository(db)
+    ctx := WithAuditSource(context.Background(), AuditSource{Actor: "budi", Command: "inventory category update --id 1 --name Gadget"})
+
+    mock.ExpectBegin()
+    expectCategoryRow(mock, 1, "Elektronik")
+    mock.ExpectExec("UPDATE categories SET name").
+        WillReturnResult(sqlmock.NewResult(0, 1))
+    expectCategoryRow(mock, 1, "Gadget")
+    mock.ExpectExec("INSERT INTO audit_log").
+        WithArgs(sqlmock.AnyArg(), "budi", "inventory category update --id 1 --name Gadget", models.AuditCategory, 1, models.AuditUpdate,
+            sqlmock.AnyArg(), sqlmock.AnyArg()).
+        WillReturnResult(sqlmock.NewResult(1, 1))
+    mock.ExpectCommit()
+
+    if err := repo.Update(ctx, &models.Category{ID: 1, Name: "Gadget"}); err != nil {
+        t.Errorf("error was not expected: %s", err)
+    }
+
+    if err := mock.ExpectationsWereMet(); err != nil {
+        t.Errorf("there were unfulfilled expectations: %s", err)
+    }
+}
+
+func TestCategoryRepository_Update_RollsBackWhenAuditFails(t *testing.T) {
+    db, mock, err := sqlmock.New()
+    if err != nil {
+        t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+    }
+    defer db.Close()
+
+    repo := NewCategoryRepository(db)
+
+    auditErr := errors.New("disk full")
+    mock.ExpectBegin()
+    expectCategoryRow(mock, 1, "Elektronik")
+    mock.ExpectExec("UPDATE categories SET name").
+        WillReturnResult(sqlmock.NewResult(0, 1))
+    expectCategoryRow(mock, 1, "Gadget")
+    mock.ExpectExec("INSERT INTO audit_log").
+        WillReturnError(auditErr)
+    mock.ExpectRollback()
+
+    err = repo.Update(context.Background(), &models.Category{ID: 1, Name: "Gadget"})
+    if !errors.Is(err, auditErr) {
+        t.Errorf("expected the audit error, got %v", err)
+    }
+
+    if err := mock.ExpectationsWereMet(); err != nil {
+        t.Errorf("there were unfulfilled expectations: %s", err)
+    }
+}
+
+func TestAuditRepository_List(t *testing.T) {
+    db, mock, err := sqlmock.New()
+    if err != nil {
+        t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+    }
+    defer db.Close()
+
+    repo := NewAuditRepository(db)
+    since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+    rows := sqlmock.NewRows([]string{"id", "at", "actor", "command", "entity", "entity_id", "action", "before_data", "after_data"}).
+        AddRow(1, since, "budi", "inventory item create", "item", 3, "create", nil, `{"id":3,"name":"Laptop"}`).
+        AddRow(2, since.Add(time.Hour), "budi", "inventory item delete --id 3", "item", 3, "delete", `{"id":3,"name":"Laptop"}`, nil)
+
+    mock.ExpectQuery("SELECT id, at, actor, command, entity, entity_id, action, before_data, after_data FROM audit_log WHERE at >= \\$1 AND entity = \\$2 AND entity_id = \\$3 ORDER BY at, id").
+        WithArgs(since, "item", 3).
+        WillReturnRows(rows)
+
+    entries, err := repo.List(context.Background(), models.AuditFilter{Entity: "item", EntityID: 3, Since: since})
+    if err != nil {
+        t.Fatalf("error was not expected: %s", err)
+    }
+
+    if len(entries) != 2 || entries[0].Before != nil || string(entries[0].After) != `{"id":3,"name":"Laptop"}` || entries[1].After != nil {
+        t.Errorf("unexpected entries %+v", entries)
+    }
+
+    if err := mock.ExpectationsWereMet(); err != nil {
+        t.Errorf("there were unfulfilled expectations: %s", err)
+    }
+}
\ No newline at end of file
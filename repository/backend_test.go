@@ -0,0 +1,866 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "encoding/json"
+    "errors"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+
+    "mini_project3/apperrors"
+    "mini_project3/config"
+    "mini_project3/database"
+    "mini_project3/models"
+    "mini_project3/money"
+)
+
+// testBackend is a real database with the schema migrated and no rows
+type testBackend struct {
+    name   string
+    driver string
+    db     *sql.DB
+}
+
+// openTestBackends returns a fresh SQLite database and, when
+// INVENTORY_TEST_POSTGRES_URL is set, the PostgreSQL database it points to.
+// The PostgreSQL tables are truncated, so never point it at real data.
+func openTestBackends(t *testing.T) []testBackend {
+    t.Helper()
+
+    cfg := config.DefaultConfig()
+    cfg.Driver = config.DriverSQLite
+    cfg.Path = filepath.Join(t.TempDir(), "inventory.db")
+    backends := []testBackend{{name: "sqlite", driver: config.DriverSQLite, db: openTestDB(t, cfg)}}
+
+    if url := os.Getenv("INVENTORY_TEST_POSTGRES_URL"); url != "" {
+        cfg := config.DefaultConfig()
+        cfg.URL = url
+        db := openTestDB(t, cfg)
+        if _, err := db.Exec(`TRUNCATE items, categories, exchange_rates, journal_periods, journal_lines, audit_log RESTART IDENTITY`); err != nil {
+            t.Fatalf("error truncating postgres tables: %s", err)
+        }
+        backends = append(backends, testBackend{name: "postgres", driver: config.DriverPostgres, db: db})
+    }
+
+    return backends
+}
+
+func openTestDB(t *testing.T, cfg config.Config) *sql.DB {
+    t.Helper()
+
+    db, err := config.NewDatabase(context.Background(), cfg)
+    if err != nil {
+        t.Fatalf("error opening %s database: %s", cfg.Driver, err)
+    }
+    t.Cleanup(func() { db.Close() })
+
+    migrator, err := database.NewMigrator(db, cfg.Driver)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if _, err := migrator.Up(context.Background(), 0); err != nil {
+        t.Fatalf("error migrating %s database: %s", cfg.Driver, err)
+    }
+    return db
+}
+
+// categoryRepository and itemRepository are the methods shared by the SQL
+// and in-memory implementations (service.CategoryRepositoryInterface and
+// service.ItemRepositoryInterface, which cannot be imported from here)
+type categoryRepository interface {
+    GetAll(ctx context.Context) ([]models.Category, error)
+    GetByID(ctx context.Context, id int) (*models.Category, error)
+    Create(ctx context.Context, cat *models.Category) error
+    Update(ctx context.Context, cat *models.Category) error
+    Delete(ctx context.Context, id int) error
+    CheckNameExists(ctx context.Context, name string, excludeID int) (bool, error)
+    GetDeleted(ctx context.Context) ([]models.TrashEntry, error)
+    Restore(ctx context.Context, id int) error
+    Purge(ctx context.Context, before time.Time) (int, error)
+}
+
+type itemRepository interface {
+    GetAll(ctx context.Context) ([]models.Item, error)
+    GetByID(ctx context.Context, id int) (*models.Item, error)
+    Create(ctx context.Context, item *models.Item) error
+    Update(ctx context.Context, item *models.Item) error
+    Delete(ctx context.Context, id int) error
+    Search(ctx context.Context, keyword string) ([]models.Item, error)
+    GetItemsNeedReplacement(ctx context.Context, days int, asOf time.Time) ([]models.Item, error)
+    Dispose(ctx context.Context, id int, disposal models.Disposal) error
+    GetDeleted(ctx context.Context) ([]models.TrashEntry, error)
+    Restore(ctx context.Context, id int) error
+    Purge(ctx context.Context, before time.Time) (int, error)
+}
+
+type exchangeRateRepository interface {
+    Set(ctx context.Context, rate *models.ExchangeRate) error
+    GetAll(ctx context.Context, currency string) ([]models.ExchangeRate, error)
+    GetOnOrBefore(ctx context.Context, currency string, date time.Time) (*models.ExchangeRate, error)
+}
+
+type journalRepository interface {
+    Get(ctx context.Context, month string) (*models.Journal, error)
+    Post(ctx context.Context, journal *models.Journal) error
+}
+
+type auditRepository interface {
+    List(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, error)
+}
+
+// forEachBackend runs fn as a subtest against the in-memory store and every available database
+func forEachBackend(t *testing.T, fn func(t *testing.T, catRepo categoryRepository, itemRepo itemRepository)) {
+    t.Run("memory", func(t *testing.T) {
+        store := NewMemoryStore()
+        fn(t, NewMemoryCategoryRepository(store), NewMemoryItemRepository(store))
+    })
+
+    for _, b := range openTestBackends(t) {
+        t.Run(b.name, func(t *testing.T) {
+            fn(t, NewCategoryRepositoryWithDriver(b.db, b.driver), NewItemRepositoryWithDriver(b.db, b.driver))
+        })
+    }
+}
+
+func mustCreateCategory(t *testing.T, repo categoryRepository, name string) *models.Category {
+    t.Helper()
+    cat := &models.Category{Name: name, Description: name + " kantor"}
+    if err := repo.Create(context.Background(), cat); err != nil {
+        t.Fatalf("error creating category: %s", err)
+    }
+    return cat
+}
+
+func mustCreateItem(t *testing.T, repo itemRepository, name string, categoryID int, purchaseDate time.Time) *models.Item {
+    t.Helper()
+    item := &models.Item{Name: name, CategoryID: categoryID, Price: money.MustParse("1500000.50"), Currency: "IDR", PurchaseDate: purchaseDate}
+    if err := repo.Create(context.Background(), item); err != nil {
+        t.Fatalf("error creating item: %s", err)
+    }
+    return item
+}
+
+func TestBackend_CategoryCRUD(t *testing.T) {
+    forEachBackend(t, func(t *testing.T, catRepo categoryRepository, itemRepo itemRepository) {
+        cat := mustCreateCategory(t, catRepo, "Elektronik")
+        if cat.ID == 0 || cat.CreatedAt.IsZero() {
+            t.Errorf("expected ID and created_at to be returned, got %+v", cat)
+        }
+
+        cat.Name = "Elektronik Kantor"
+        cat.DepreciationPolicy = models.DepreciationPolicy{Method: "declining-balance", RatePercent: money.MustParseRate("12.5"), SalvagePercent: money.MustParseRate("7.5"),
+            TaxGroup: "kelompok-2", TaxMethod: "declining-balance"}
+        cat.JournalAccounts = models.JournalAccounts{ExpenseAccount: "6-1100", AccumulatedAccount: "1-2900"}
+        if err := catRepo.Update(context.Background(), cat); err != nil {
+            t.Fatalf("unexpected error: %s", err)
+        }
+
+        got, err := catRepo.GetByID(context.Background(), cat.ID)
+        if err != nil {
+            t.Fatalf("unexpected error: %s", err)
+        }
+        if got.Name != "Elektronik Kantor" {
+            t.Errorf("expected updated name, got '%s'", got.Name)
+        }
+        if got.DepreciationPolicy != cat.DepreciationPolicy {
+            t.Errorf("expected policy %+v, got %+v", cat.DepreciationPolicy, got.DepreciationPolicy)
+        }
+        if got.JournalAccounts != cat.JournalAccounts {
+            t.Errorf("expected accounts %+v, got %+v", cat.JournalAccounts, got.JournalAccounts)
+        }
+
+        exists, err := catRepo.CheckNameExists(context.Background(), "Elektronik Kantor", 0)
+        if err != nil || !exists {
+            t.Errorf("expected name to exist, got %v (%v)", exists, err)
+        }
+        exists, _ = catRepo.CheckNameExists(context.Background(), "Elektronik Kantor", cat.ID)
+        if exists {
+            t.Error("expected name check to exclude the category itself")
+        }
+
+        if err := catRepo.Delete(context.Background(), cat.ID); err != nil {
+            t.Fatalf("unexpected error: %s", err)
+        }
+        if _, err := catRepo.GetByID(context.Background(), cat.ID); !errors.Is(err, apperrors.ErrNotFound) {
+            t.Errorf("expected ErrNotFound for deleted category, got %v", err)
+        }
+        if err := catRepo.Delete(context.Background(), cat.ID); !errors.Is(err, apperrors.ErrNotFound) {
+            t.Errorf("expected ErrNotFound deleting a missing category, got %v", err)
+        }
+    })
+}
+
+func TestBackend_ItemCRUD(t *testing.T) {
+    forEachBackend(t, func(t *testing.T, catRepo categoryRepository, itemRepo itemRepository) {
+        cat := mustCreateCategory(t, catRepo, "Elektronik")
+        purchaseDate := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+        item := mustCreateItem(t, itemRepo, "Laptop", cat.ID, purchaseDate)
+
+        got, err := itemRepo.GetByID(context.Background(), item.ID)
+        if err != nil {
+            t.Fatalf("unexpected error: %s", err)
+        }
+        if got.CategoryName != "Elektronik" || got.Price != money.MustParse("1500000.50") || got.Currency != "IDR" {
+            t.Errorf("unexpected item: %+v", got)
+        }
+        if got.PurchaseDate.Format("2006-01-02") != "2024-06-01" {
+            t.Errorf("expected purchase date 2024-06-01, got %s", got.PurchaseDate)
+        }
+
+        got.Name = "Laptop Dell"
+        got.Currency = "USD"
+        got.DepreciationPolicy = models.DepreciationPolicy{Method: "straight-line", UsefulLifeMonths: 48, SalvageValue: money.MustParse("250000.50")}
+        if err := itemRepo.Update(context.Background(), got); err != nil {
+            t.Fatalf("unexpected error: %s", err)
+        }
+
+        items, err := itemRepo.GetAll(context.Background())
+        if err != nil {
+            t.Fatalf("unexpected error: %s", err)
+        }
+        if len(items) != 1 || items[0].Name != "Laptop Dell" || items[0].Currency != "USD" || items[0].DepreciationPolicy != got.DepreciationPolicy {
+            t.Errorf("unexpected items: %+v", items)
+        }
+
+        if err := itemRepo.Delete(context.Background(), item.ID); err != nil {
+            t.Fatalf("unexpected error: %s", err)
+        }
+        if err := itemRepo.Delete(context.Background(), item.ID); !errors.Is(err, apperrors.ErrNotFound) {
+            t.Errorf("expected ErrNotFound deleting a missing item, got %v", err)
+        }
+    })
+}
+
+func TestBackend_UpdateVersion(t *testing.T) {
+    forEachBackend(t, func(t *testing.T, catRepo categoryRepository, itemRepo itemRepository) {
+        ctx := context.Background()
+        cat := mustCreateCategory(t, catRepo, "Elektronik")
+        item := mustCreateItem(t, itemRepo, "Laptop", cat.ID, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+        if cat.Version != 1 || item.Version != 1 {
+            t.Fatalf("expected new rows at version 1, got category %d and item %d", cat.Version, item.Version)
+        }
+
+        // two admins read version 1; the first update wins, the second is refused
+        first, _ := itemRepo.GetByID(ctx, item.ID)
+        second, _ := itemRepo.GetByID(ctx, item.ID)
+        first.Name = "Laptop Dell"
+        if err := itemRepo.Update(ctx, first); err != nil {
+            t.Fatalf("unexpected error: %s", err)
+        }
+        second.Name = "Laptop HP"
+        var conflict *apperrors.VersionConflictError
+        if err := itemRepo.Update(ctx, second); !errors.As(err, &conflict) || conflict.Expected != 1 || conflict.Actual != 2 {
+            t.Fatalf("expected a version conflict from 1 to 2, got %v", err)
+        }
+        if got, _ := itemRepo.GetByID(ctx, item.ID); got.Name != "Laptop Dell" || got.Version != 2 {
+            t.Errorf("expected the first update at version 2, got %q at version %d", got.Name, got.Version)
+        }
+
+        // version 0 updates whatever version is stored
+        second.Version = 0
+        if err := itemRepo.Update(ctx, second); err != nil || second.Version != 3 {
+            t.Errorf("expected an unconditional update to version 3, got version %d (%v)", second.Version, err)
+        }
+
+        cat.Description = "Peralatan kantor"
+        if err := catRepo.Update(ctx, cat); err != nil || cat.Version != 2 {
+            t.Fatalf("expected the category at version 2, got version %d (%v)", cat.Version, err)
+        }
+        cat.Version = 1
+        if err := catRepo.Update(ctx, cat); !errors.Is(err, apperrors.ErrConflict) {
+            t.Errorf("expected ErrConflict updating a stale category, got %v", err)
+        }
+    })
+}
+
+func TestBackend_SearchIsCaseInsensitive(t *testing.T) {
+    forEachBackend(t, func(t *testing.T, catRepo categoryRepository, itemRepo itemRepository) {
+        cat := mustCreateCategory(t, catRepo, "Elektronik")
+        mustCreateItem(t, itemRepo, "Laptop Dell", cat.ID, time.Now())
+        mustCreateItem(t, itemRepo, "LAPTOP HP", cat.ID, time.Now())
+        mustCreateItem(t, itemRepo, "Écran Samsung", cat.ID, time.Now())
+        mustCreateItem(t, itemRepo, "Monitor", cat.ID, time.Now())
+
+        items, err := itemRepo.Search(context.Background(), "lApToP")
+        if err != nil {
+            t.Fatalf("unexpected error: %s", err)
+        }
+        if len(items) != 2 {
+            t.Errorf("expected 2 items, got %d", len(items))
+        }
+
+        items, _ = itemRepo.Search(context.Background(), "ÉCRAN")
+        if len(items) != 1 || !strings.HasPrefix(items[0].Name, "Écran") {
+            t.Errorf("expected non-ASCII search to match 'Écran Samsung', got %+v", items)
+        }
+    })
+}
+
+func TestBackend_GetItemsNeedReplacement(t *testing.T) {
+    forEachBackend(t, func(t *testing.T, catRepo categoryRepository, itemRepo itemRepository) {
+        cat := mustCreateCategory(t, catRepo, "Elektronik")
+        asOf := time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)
+        day := func(offset int) time.Time { return asOf.AddDate(0, 0, offset) }
+        mustCreateItem(t, itemRepo, "Exactly 100 days", cat.ID, day(-100))
+        mustCreateItem(t, itemRepo, "101 days", cat.ID, day(-101))
+        mustCreateItem(t, itemRepo, "200 days", cat.ID, day(-200))
+        mustCreateItem(t, itemRepo, "New", cat.ID, day(0))
+        mustCreateItem(t, itemRepo, "Bought later", cat.ID, day(150))
+
+        items, err := itemRepo.GetItemsNeedReplacement(context.Background(), 100, asOf)
+        if err != nil {
+            t.Fatalf("unexpected error: %s", err)
+        }
+        if len(items) != 2 {
+            t.Fatalf("expected 2 items, got %d: %+v", len(items), items)
+        }
+        if items[0].Name != "200 days" || items[1].Name != "101 days" {
+            t.Errorf("expected oldest purchase first, got '%s', '%s'", items[0].Name, items[1].Name)
+        }
+    })
+}
+
+func TestBackend_Dispose(t *testing.T) {
+    forEachBackend(t, func(t *testing.T, catRepo categoryRepository, itemRepo itemRepository) {
+        ctx := context.Background()
+        cat := mustCreateCategory(t, catRepo, "Elektronik")
+        asOf := time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)
+        sold := mustCreateItem(t, itemRepo, "Sold", cat.ID, asOf.AddDate(-1, 0, 0))
+        later := mustCreateItem(t, itemRepo, "Sold after as-of", cat.ID, asOf.AddDate(-1, 0, 0))
+
+        for _, tt := range []struct {
+            item *models.Item
+            date time.Time
+        }{{sold, asOf}, {later, asOf.AddDate(0, 0, 1)}} {
+            date := tt.date
+            disposal := models.Disposal{DisposedAt: &date, DisposalMethod: "sold", DisposalProceeds: money.MustParse("750000.25")}
+            if err := itemRepo.Dispose(ctx, tt.item.ID, disposal); err != nil {
+                t.Fatalf("unexpected error: %s", err)
+            }
+        }
+
+        got, err := itemRepo.GetByID(ctx, sold.ID)
+        if err != nil {
+            t.Fatalf("unexpected error: %s", err)
+        }
+        if got.DisposedAt == nil || got.DisposedAt.Format("2006-01-02") != "2025-12-31" || got.DisposalMethod != "sold" || got.DisposalProceeds != money.MustParse("750000.25") {
+            t.Errorf("unexpected disposal %+v", got.Disposal)
+        }
+        if got.Price != sold.Price || got.Name != "Sold" {
+            t.Errorf("expected the other fields to be kept, got %+v", got)
+        }
+
+        items, err := itemRepo.GetItemsNeedReplacement(ctx, 100, asOf)
+        if err != nil {
+            t.Fatalf("unexpected error: %s", err)
+        }
+        if len(items) != 1 || items[0].ID != later.ID {
+            t.Errorf("expected only the item disposed after the as-of date, got %+v", items)
+        }
+
+        if err := itemRepo.Dispose(ctx, 999, models.Disposal{DisposedAt: &asOf}); !errors.Is(err, apperrors.ErrNotFound) {
+            t.Errorf("expected ErrNotFound disposing a missing item, got %v", err)
+        }
+    })
+}
+
+func TestBackend_CategoryDeleteRestrict(t *testing.T) {
+    forEachBackend(t, func(t *testing.T, catRepo categoryRepository, itemRepo itemRepository) {
+        cat := mustCreateCategory(t, catRepo, "Elektronik")
+        mustCreateItem(t, itemRepo, "Laptop", cat.ID, time.Now())
+
+        if err := catRepo.Delete(context.Background(), cat.ID); !errors.Is(err, apperrors.ErrCategoryInUse) {
+            t.Errorf("expected ErrCategoryInUse deleting a category that still has items, got %v", err)
+        }
+        if _, err := catRepo.GetByID(context.Background(), cat.ID); err != nil {
+            t.Errorf("expected category to be kept, got %s", err)
+        }
+    })
+}
+
+func TestBackend_Trash(t *testing.T) {
+    forEachBackend(t, func(t *testing.T, catRepo categoryRepository, itemRepo itemRepository) {
+        ctx := context.Background()
+        cat := mustCreateCategory(t, catRepo, "Elektronik")
+        empty := mustCreateCategory(t, catRepo, "Furniture")
+        laptop := mustCreateItem(t, itemRepo, "Laptop", cat.ID, time.Now().AddDate(-1, 0, 0))
+        mustCreateItem(t, itemRepo, "Monitor", cat.ID, time.Now())
+
+        if err := itemRepo.Delete(ctx, laptop.ID); err != nil {
+            t.Fatalf("unexpected error: %s", err)
+        }
+        if _, err := itemRepo.GetByID(ctx, laptop.ID); !errors.Is(err, apperrors.ErrNotFound) {
+            t.Errorf("expected ErrNotFound for an item in the trash, got %v", err)
+        }
+        items, _ := itemRepo.GetAll(ctx)
+        found, _ := itemRepo.Search(ctx, "laptop")
+        old, _ := itemRepo.GetItemsNeedReplacement(ctx, 100, time.Now())
+        if len(items) != 1 || len(found) != 0 || len(old) != 0 {
+            t.Errorf("expected queries to leave out the item in the trash, got %d items, %d found, %d to replace", len(items), len(found), len(old))
+        }
+        laptop.Name = "Laptop Dell"
+        if err := itemRepo.Update(ctx, laptop); !errors.Is(err, apperrors.ErrNotFound) {
+            t.Errorf("expected ErrNotFound updating an item in the trash, got %v", err)
+        }
+
+        // the other item is live, and once deleted too, the category is still kept for the trash
+        var inUse *apperrors.CategoryInUseError
+        if err := catRepo.Delete(ctx, cat.ID); !errors.As(err, &inUse) || inUse.InTrash {
+            t.Errorf("expected CategoryInUseError for live items, got %v", err)
+        }
+        monitor := items[0]
+        if err := itemRepo.Delete(ctx, monitor.ID); err != nil {
+            t.Fatalf("unexpected error: %s", err)
+        }
+        if err := catRepo.Delete(ctx, cat.ID); !errors.As(err, &inUse) || !inUse.InTrash {
+            t.Errorf("expected CategoryInUseError for items in the trash, got %v", err)
+        }
+
+        if err := catRepo.Delete(ctx, empty.ID); err != nil {
+            t.Fatalf("unexpected error: %s", err)
+        }
+        categories, _ := catRepo.GetAll(ctx)
+        if len(categories) != 1 || categories[0].ID != cat.ID {
+            t.Errorf("expected GetAll to leave out the category in the trash, got %+v", categories)
+        }
+        if exists, _ := catRepo.CheckNameExists(ctx, "Furniture", 0); !exists {
+            t.Error("expected the name check to count the category in the trash, like the unique constraint")
+        }
+        if err := catRepo.Create(ctx, &models.Category{Name: "Furniture"}); !errors.Is(err, apperrors.ErrDuplicateName) {
+            t.Errorf("expected a category in the trash to keep its name, got %v", err)
+        }
+
+        deletedItems, err := itemRepo.GetDeleted(ctx)
+        if err != nil {
+            t.Fatalf("unexpected error: %s", err)
+        }
+        if len(deletedItems) != 2 || deletedItems[0].ID != laptop.ID || deletedItems[0].Name != "Laptop" || deletedItems[0].Entity != models.TrashItem || deletedItems[0].DeletedAt.IsZero() {
+            t.Errorf("unexpected items in the trash %+v", deletedItems)
+        }
+        deletedCategories, _ := catRepo.GetDeleted(ctx)
+        if len(deletedCategories) != 1 || deletedCategories[0].ID != empty.ID || deletedCategories[0].Entity != models.TrashCategory {
+            t.Errorf("unexpected categories in the trash %+v", deletedCategories)
+        }
+
+        if err := itemRepo.Restore(ctx, laptop.ID); err != nil {
+            t.Fatalf("unexpected error: %s", err)
+        }
+        if got, err := itemRepo.GetByID(ctx, laptop.ID); err != nil || got.Name != "Laptop" {
+            t.Errorf("expected the restored item, got %+v (%v)", got, err)
+        }
+        if err := itemRepo.Restore(ctx, laptop.ID); !errors.Is(err, apperrors.ErrNotFound) {
+            t.Errorf("expected ErrNotFound restoring an item that is not in the trash, got %v", err)
+        }
+        if err := catRepo.Restore(ctx, empty.ID); err != nil {
+            t.Fatalf("unexpected error: %s", err)
+        }
+        if err := catRepo.Delete(ctx, empty.ID); err != nil {
+            t.Fatalf("unexpected error: %s", err)
+        }
+
+        // nothing was deleted an hour ago; everything in the trash was deleted before now
+        if n, err := itemRepo.Purge(ctx, time.Now().Add(-time.Hour)); err != nil || n != 0 {
+            t.Errorf("expected nothing to purge, got %d (%v)", n, err)
+        }
+        before := time.Now().Add(time.Second)
+        if n, err := itemRepo.Purge(ctx, before); err != nil || n != 1 {
+            t.Errorf("expected 1 item purged, got %d (%v)", n, err)
+        }
+        if n, err := catRepo.Purge(ctx, before); err != nil || n != 1 {
+            t.Errorf("expected 1 category purged, got %d (%v)", n, err)
+        }
+        if deleted, _ := itemRepo.GetDeleted(ctx); len(deleted) != 0 {
+            t.Errorf("expected an empty trash, got %+v", deleted)
+        }
+        if err := catRepo.Create(ctx, &models.Category{Name: "Furniture"}); err != nil {
+            t.Errorf("expected the name of a purged category to be free, got %v", err)
+        }
+    })
+}
+
+func TestBackend_CategoryNameUnique(t *testing.T) {
+    forEachBackend(t, func(t *testing.T, catRepo categoryRepository, itemRepo itemRepository) {
+        mustCreateCategory(t, catRepo, "Elektronik")
+        other := mustCreateCategory(t, catRepo, "Furniture")
+
+        if err := catRepo.Create(context.Background(), &models.Category{Name: "Elektronik"}); !errors.Is(err, apperrors.ErrDuplicateName) {
+            t.Errorf("expected ErrDuplicateName creating a duplicate category name, got %v", err)
+        }
+
+        other.Name = "Elektronik"
+        if err := catRepo.Update(context.Background(), other); !errors.Is(err, apperrors.ErrDuplicateName) {
+            t.Errorf("expected ErrDuplicateName renaming a category to an existing name, got %v", err)
+        }
+    })
+}
+
+func TestBackend_ItemRequiresCategory(t *testing.T) {
+    forEachBackend(t, func(t *testing.T, catRepo categoryRepository, itemRepo itemRepository) {
+        item := &models.Item{Name: "Laptop", CategoryID: 99, Price: money.FromInt(1000), PurchaseDate: time.Now()}
+        var notFound *apperrors.NotFoundError
+        if err := itemRepo.Create(context.Background(), item); !errors.As(err, &notFound) || notFound.Entity != "category" {
+            t.Errorf("expected category NotFoundError creating an item with a missing category, got %v", err)
+        }
+    })
+}
+
+func TestBackend_CanceledContext(t *testing.T) {
+    forEachBackend(t, func(t *testing.T, catRepo categoryRepository, itemRepo itemRepository) {
+        ctx, cancel := context.WithCancel(context.Background())
+        cancel()
+
+        if _, err := itemRepo.GetAll(ctx); !errors.Is(err, context.Canceled) {
+            t.Errorf("expected context.Canceled, got %v", err)
+        }
+        if err := catRepo.Create(ctx, &models.Category{Name: "Elektronik"}); !errors.Is(err, context.Canceled) {
+            t.Errorf("expected context.Canceled, got %v", err)
+        }
+        if categories, _ := catRepo.GetAll(context.Background()); len(categories) != 0 {
+            t.Errorf("expected nothing to be created, got %+v", categories)
+        }
+    })
+}
+
+
+func TestBackend_ExchangeRates(t *testing.T) {
+    repos := map[string]exchangeRateRepository{"memory": NewMemoryExchangeRateRepository(NewMemoryStore())}
+    for _, b := range openTestBackends(t) {
+        repos[b.name] = NewExchangeRateRepositoryWithDriver(b.db, b.driver)
+    }
+
+    day := func(d int) time.Time { return time.Date(2024, 6, d, 0, 0, 0, 0, time.UTC) }
+    for name, repo := range repos {
+        t.Run(name, func(t *testing.T) {
+            ctx := context.Background()
+            for _, rate := range []models.ExchangeRate{
+                {Currency: "USD", Date: day(1), Rate: money.MustParseRate("16000")},
+                {Currency: "USD", Date: day(10), Rate: money.MustParseRate("16100.5")},
+                {Currency: "SGD", Date: day(1), Rate: money.MustParseRate("12000.123456")},
+                // Same day again replaces the first rate
+                {Currency: "USD", Date: day(1), Rate: money.MustParseRate("16050")},
+            } {
+                rate := rate
+                if err := repo.Set(ctx, &rate); err != nil {
+                    t.Fatalf("unexpected error: %s", err)
+                }
+            }
+
+            rates, err := repo.GetAll(ctx, "USD")
+            if err != nil {
+                t.Fatalf("unexpected error: %s", err)
+            }
+            if len(rates) != 2 || rates[0].Rate != money.MustParseRate("16050") || rates[1].Date.Format("2006-01-02") != "2024-06-10" {
+                t.Errorf("unexpected USD rates: %+v", rates)
+            }
+            if all, _ := repo.GetAll(ctx, ""); len(all) != 3 || all[0].Currency != "SGD" || all[0].Rate != money.MustParseRate("12000.123456") {
+                t.Errorf("unexpected rates: %+v", all)
+            }
+
+            tests := []struct {
+                date     time.Time
+                expected string
+            }{
+                {day(1), "16050"},
+                {day(9), "16050"},
+                {day(10), "16100.5"},
+                {day(30), "16100.5"},
+            }
+            for _, tt := range tests {
+                rate, err := repo.GetOnOrBefore(ctx, "USD", tt.date)
+                if err != nil || rate.Rate != money.MustParseRate(tt.expected) {
+                    t.Errorf("rate on %s: expected %s, got %+v (%v)", tt.date.Format("2006-01-02"), tt.expected, rate, err)
+                }
+            }
+
+            if _, err := repo.GetOnOrBefore(ctx, "USD", day(1).AddDate(0, 0, -1)); !errors.Is(err, apperrors.ErrNotFound) {
+                t.Errorf("expected ErrNotFound before the first rate, got %v", err)
+            }
+            if _, err := repo.GetOnOrBefore(ctx, "EUR", day(30)); !errors.Is(err, apperrors.ErrNotFound) {
+                t.Errorf("expected ErrNotFound for a currency without rates, got %v", err)
+            }
+        })
+    }
+}
+
+func TestBackend_Journal(t *testing.T) {
+    repos := map[string]journalRepository{"memory": NewMemoryJournalRepository(NewMemoryStore())}
+    for _, b := range openTestBackends(t) {
+        repos[b.name] = NewJournalRepository(b.db)
+    }
+
+    for name, repo := range repos {
+        t.Run(name, func(t *testing.T) {
+            ctx := context.Background()
+            if journal, err := repo.Get(ctx, "2025-11"); journal != nil || err != nil {
+                t.Fatalf("expected no journal before posting, got %+v (%v)", journal, err)
+            }
+
+            postedAt := time.Date(2025, 12, 2, 8, 0, 0, 0, time.UTC)
+            journal := &models.Journal{Month: "2025-11", Currency: "IDR", PostedAt: &postedAt, Lines: []models.JournalLine{
+                {CategoryID: 1, CategoryName: "Elektronik", Account: "6-1100", Description: "Penyusutan Elektronik 2025-11", Debit: money.MustParse("250000.25")},
+                {CategoryID: 1, CategoryName: "Elektronik", Account: "1-2900", Description: "Penyusutan Elektronik 2025-11", Credit: money.MustParse("250000.25")},
+            }}
+            if err := repo.Post(ctx, journal); err != nil {
+                t.Fatalf("unexpected error: %s", err)
+            }
+
+            got, err := repo.Get(ctx, "2025-11")
+            if err != nil {
+                t.Fatalf("unexpected error: %s", err)
+            }
+            if !got.Posted || got.Currency != "IDR" || !got.PostedAt.Equal(postedAt) || len(got.Lines) != 2 {
+                t.Fatalf("unexpected posted journal %+v", got)
+            }
+            if got.Lines[0].Debit != money.MustParse("250000.25") || got.Lines[1].Account != "1-2900" || got.Lines[1].Month != "2025-11" {
+                t.Errorf("unexpected journal lines %+v", got.Lines)
+            }
+
+            if err := repo.Post(ctx, journal); !errors.Is(err, apperrors.ErrConflict) {
+                t.Errorf("expected ErrConflict posting a month twice, got %v", err)
+            }
+        })
+    }
+}
+
+func TestBackend_AuditLog(t *testing.T) {
+    type repos struct {
+        categories categoryRepository
+        items      itemRepository
+        audit      auditRepository
+    }
+    store := NewMemoryStore()
+    backends := map[string]repos{"memory": {NewMemoryCategoryRepository(store), NewMemoryItemRepository(store), NewMemoryAuditRepository(store)}}
+    for _, b := range openTestBackends(t) {
+        backends[b.name] = repos{NewCategoryRepositoryWithDriver(b.db, b.driver), NewItemRepositoryWithDriver(b.db, b.driver), NewAuditRepository(b.db)}
+    }
+
+    for name, r := range backends {
+        t.Run(name, func(t *testing.T) {
+            ctx := WithAuditSource(context.Background(), AuditSource{Actor: "budi", Command: "inventory item update --id 1 --price 1200000"})
+            cat := mustCreateCategory(t, r.categories, "Elektronik")
+            item := mustCreateItem(t, r.items, "Laptop", cat.ID, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+
+            item.Price = money.FromInt(1200000)
+            if err := r.items.Update(ctx, item); err != nil {
+                t.Fatalf("unexpected error: %s", err)
+            }
+            if err := r.items.Delete(ctx, item.ID); err != nil {
+                t.Fatalf("unexpected error: %s", err)
+            }
+            if err := r.items.Restore(ctx, item.ID); err != nil {
+                t.Fatalf("unexpected error: %s", err)
+            }
+            if err := r.items.Delete(ctx, item.ID); err != nil {
+                t.Fatalf("unexpected error: %s", err)
+            }
+            if _, err := r.items.Purge(ctx, time.Now().Add(time.Hour)); err != nil {
+                t.Fatalf("unexpected error: %s", err)
+            }
+            // A failed change leaves no entry
+            if err := r.items.Update(ctx, item); !errors.Is(err, apperrors.ErrNotFound) {
+                t.Fatalf("expected ErrNotFound updating a purged item, got %v", err)
+            }
+
+            entries, err := r.audit.List(ctx, models.AuditFilter{Entity: models.AuditItem, EntityID: item.ID})
+            if err != nil {
+                t.Fatalf("unexpected error: %s", err)
+            }
+            var actions []string
+            for _, entry := range entries {
+                actions = append(actions, entry.Action)
+            }
+            if strings.Join(actions, " ") != "create update delete restore delete purge" {
+                t.Fatalf("unexpected actions %v", actions)
+            }
+
+            created, updated := entries[0], entries[1]
+            if created.Actor != "unknown" || created.Before != nil || created.After == nil {
+                t.Errorf("unexpected create entry %+v", created)
+            }
+            if updated.Actor != "budi" || updated.Command != "inventory item update --id 1 --price 1200000" {
+                t.Errorf("unexpected source of update entry %+v", updated)
+            }
+            var before, after models.Item
+            if err := json.Unmarshal(updated.Before, &before); err != nil {
+                t.Fatalf("unexpected error decoding before: %s", err)
+            }
+            if err := json.Unmarshal(updated.After, &after); err != nil {
+                t.Fatalf("unexpected error decoding after: %s", err)
+            }
+            if before.Price != money.MustParse("1500000.50") || after.Price != money.FromInt(1200000) || after.CategoryName != "Elektronik" {
+                t.Errorf("unexpected update snapshots before %+v after %+v", before, after)
+            }
+            if entries[2].After != nil || entries[3].Before != nil || entries[5].Before == nil || entries[5].After != nil {
+                t.Errorf("unexpected delete, restore or purge snapshots %+v", entries[2:])
+            }
+
+            if categories, _ := r.audit.List(ctx, models.AuditFilter{Entity: models.AuditCategory}); len(categories) != 1 || categories[0].EntityID != cat.ID {
+                t.Errorf("expected the category create only, got %+v", categories)
+            }
+            if later, _ := r.audit.List(ctx, models.AuditFilter{Since: time.Now().Add(time.Hour)}); len(later) != 0 {
+                t.Errorf("expected no entries since an hour from now, got %+v", later)
+            }
+        })
+    }
+}
+
+func TestBackend_UnitOfWork(t *testing.T) {
+    type backend struct {
+        categories categoryRepository
+        items      itemRepository
+        audit      auditRepository
+        withTx     func(ctx context.Context, fn func(categories categoryRepository, items itemRepository) error) error
+    }
+    store := NewMemoryStore()
+    memoryTx := NewMemoryTxManager(store)
+    backends := map[string]backend{"memory": {NewMemoryCategoryRepository(store), NewMemoryItemRepository(store), NewMemoryAuditRepository(store),
+        func(ctx context.Context, fn func(categories categoryRepository, items itemRepository) error) error {
+            return memoryTx.WithTx(ctx, func(categories *MemoryCategoryRepository, items *MemoryItemRepository, _ *MemoryExchangeRateRepository) error {
+                return fn(categories, items)
+            })
+        }}}
+    for _, b := range openTestBackends(t) {
+        sqlTx := NewTxManager(b.db, b.driver)
+        backends[b.name] = backend{NewCategoryRepositoryWithDriver(b.db, b.driver), NewItemRepositoryWithDriver(b.db, b.driver), NewAuditRepository(b.db),
+            func(ctx context.Context, fn func(categories categoryRepository, items itemRepository) error) error {
+                return sqlTx.WithTx(ctx, func(categories *CategoryRepository, items *ItemRepository, _ *ExchangeRateRepository) error {
+                    return fn(categories, items)
+                })
+            }}
+    }
+
+    for name, b := range backends {
+        t.Run(name, func(t *testing.T) {
+            ctx := context.Background()
+            kept := mustCreateCategory(t, b.categories, "Elektronik")
+            failed := errors.New("failed")
+
+            // A failing unit of work rolls back every change it made, with their audit entries
+            err := b.withTx(ctx, func(categories categoryRepository, items itemRepository) error {
+                cat := mustCreateCategory(t, categories, "Furniture")
+                mustCreateItem(t, items, "Meja", cat.ID, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+                if err := categories.Delete(ctx, kept.ID); err != nil {
+                    t.Fatalf("unexpected error: %s", err)
+                }
+                return failed
+            })
+            if !errors.Is(err, failed) {
+                t.Fatalf("expected the error of the unit of work, got %v", err)
+            }
+
+            categories, _ := b.categories.GetAll(ctx)
+            items, _ := b.items.GetAll(ctx)
+            if len(categories) != 1 || categories[0].ID != kept.ID || len(items) != 0 {
+                t.Fatalf("expected only %+v after the rollback, got %+v and %+v", kept, categories, items)
+            }
+            entries, _ := b.audit.List(ctx, models.AuditFilter{})
+            if len(entries) != 1 {
+                t.Errorf("expected only the audit entry of %s after the rollback, got %+v", kept.Name, entries)
+            }
+
+            // A successful one commits them all
+            err = b.withTx(ctx, func(categories categoryRepository, items itemRepository) error {
+                cat, err := categories.GetByID(ctx, kept.ID)
+                if err != nil {
+                    return err
+                }
+                mustCreateItem(t, items, "Laptop", cat.ID, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+                return nil
+            })
+            if err != nil {
+                t.Fatalf("unexpected error: %s", err)
+            }
+            if items, _ := b.items.GetAll(ctx); len(items) != 1 || items[0].Name != "Laptop" {
+                t.Errorf("expected the committed item, got %+v", items)
+            }
+        })
+    }
+}
+
+func TestBackend_Seed(t *testing.T) {
+    for _, b := range openTestBackends(t) {
+        t.Run(b.name, func(t *testing.T) {
+            ctx := context.Background()
+            fixture := database.DemoFixture()
+
+            first, err := database.Seed(ctx, b.db, b.driver, fixture, false, AuditRecord)
+            if err != nil {
+                t.Fatalf("unexpected error: %s", err)
+            }
+            // seeding again reuses the categories and skips the items
+            again, err := database.Seed(ctx, b.db, b.driver, fixture, false, AuditRecord)
+            if err != nil {
+                t.Fatalf("unexpected error: %s", err)
+            }
+            if first.Items != len(fixture.Items) || again.Categories != 0 || again.Items != 0 || again.Skipped != len(fixture.Items) {
+                t.Errorf("expected the second seed to skip every item, got %+v then %+v", first, again)
+            }
+            if items, _ := NewItemRepositoryWithDriver(b.db, b.driver).GetAll(ctx); len(items) != len(fixture.Items) {
+                t.Errorf("expected %d items, got %d", len(fixture.Items), len(items))
+            }
+            audit := NewAuditRepository(b.db)
+            created, _ := audit.List(ctx, models.AuditFilter{Entity: models.AuditItem})
+            if len(created) != len(fixture.Items) || created[0].Action != models.AuditCreate || created[0].After == nil {
+                t.Errorf("expected one create in the audit log per seeded item, got %+v", created)
+            }
+
+            // a category in the trash is neither reused nor created again
+            categories := NewCategoryRepositoryWithDriver(b.db, b.driver)
+            all, _ := categories.GetAll(ctx)
+            var trashed models.Category
+            for _, cat := range all {
+                if cat.Name == "Alat Tulis" {
+                    trashed = cat
+                }
+            }
+            if err := categories.Delete(ctx, trashed.ID); err != nil {
+                t.Fatalf("unexpected error: %s", err)
+            }
+            if _, err := database.Seed(ctx, b.db, b.driver, fixture, false, AuditRecord); err == nil || !strings.Contains(err.Error(), "is in the trash") {
+                t.Errorf("expected an error about the trashed category, got %v", err)
+            }
+            if err := categories.Restore(ctx, trashed.ID); err != nil {
+                t.Fatalf("unexpected error: %s", err)
+            }
+            if _, err := database.Seed(ctx, b.db, b.driver, fixture, false, AuditRecord); err != nil {
+                t.Errorf("unexpected error after restoring the category: %s", err)
+            }
+
+            // a reset does not hand the ids, and with them the audit history, of the removed rows to new ones
+            items := NewItemRepositoryWithDriver(b.db, b.driver)
+            before, _ := items.GetAll(ctx)
+            if _, err := database.Seed(ctx, b.db, b.driver, fixture, true, AuditRecord); err != nil {
+                t.Fatalf("unexpected error: %s", err)
+            }
+            after, _ := items.GetAll(ctx)
+            if len(after) != len(fixture.Items) {
+                t.Fatalf("expected %d items after the reset, got %d", len(fixture.Items), len(after))
+            }
+            for _, item := range after {
+                for _, old := range before {
+                    if item.ID <= old.ID {
+                        t.Fatalf("expected item %s to get an id above the removed %d, got %d", item.Name, old.ID, item.ID)
+                    }
+                }
+            }
+            // the removed rows end their history with a purge
+            for _, old := range before {
+                history, _ := audit.List(ctx, models.AuditFilter{Entity: models.AuditItem, EntityID: old.ID})
+                if len(history) == 0 || history[len(history)-1].Action != models.AuditPurge || history[len(history)-1].Before == nil {
+                    t.Errorf("expected the reset to purge item %d in the audit log, got %+v", old.ID, history)
+                }
+            }
+            // the removed Alat Tulis has the history of its deletion, which no new category may show
+            reseeded, _ := categories.GetAll(ctx)
+            for _, cat := range reseeded {
+                if history, _ := audit.List(ctx, models.AuditFilter{Entity: models.AuditCategory, EntityID: cat.ID}); len(history) != 1 || history[0].Action != models.AuditCreate {
+                    t.Errorf("expected only the seed in the history of the new category %s, got %+v", cat.Name, history)
+                }
+            }
+        })
+    }
+}
\ No newline at end of file
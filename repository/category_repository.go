@@ -1,33 +1,72 @@
 package repository
 
 import (
+    "context"
     "database/sql"
     "fmt"
     "time"
 
+    "mini_project3/apperrors"
+    "mini_project3/config"
     "mini_project3/models"
 )
 
+// CategoryRepository stores categories; every change is appended to
+// audit_log in the same transaction
 type CategoryRepository struct {
-    db *sql.DB
+    conn
 }
 
 func NewCategoryRepository(db *sql.DB) *CategoryRepository {
-    return &CategoryRepository{db: db}
+    return &CategoryRepository{conn{db: db, driver: config.DriverPostgres}}
 }
 
-func (r *CategoryRepository) GetAll() ([]models.Category, error) {
-    query := `SELECT id, name, description, created_at, updated_at FROM categories ORDER BY id`
-    rows, err := r.db.Query(query)
+// NewCategoryRepositoryWithDriver creates CategoryRepository for the given backend (config.DriverPostgres or config.DriverSQLite)
+func NewCategoryRepositoryWithDriver(db *sql.DB, driver string) *CategoryRepository {
+    return &CategoryRepository{conn{db: db, driver: driver}}
+}
+
+// categoryColumns is the select list read by scanCategory
+const categoryColumns = `id, name, description, depreciation_method, useful_life_months, depreciation_rate, salvage_value, salvage_percent,
+    tax_group, tax_method, expense_account, accumulated_account, created_at, updated_at, version`
+
+// scanCategory reads one row selected with categoryColumns
+func scanCategory(row interface{ Scan(...interface{}) error }, cat *models.Category) error {
+    return row.Scan(&cat.ID, &cat.Name, &cat.Description, &cat.Method, &cat.UsefulLifeMonths, &cat.RatePercent, &cat.SalvageValue, &cat.SalvagePercent,
+        &cat.TaxGroup, &cat.TaxMethod, &cat.ExpenseAccount, &cat.AccumulatedAccount, &cat.CreatedAt, &cat.UpdatedAt, &cat.Version)
+}
+
+// categoryInTx reads category id within tx for the audit log, from the trash
+// when trashed is set, and locks it with lock, see conn.forUpdate
+func categoryInTx(ctx context.Context, tx *sql.Tx, id int, trashed bool, lock string) (*models.Category, error) {
+    condition, entity := `deleted_at IS NULL`, "category"
+    if trashed {
+        condition, entity = `deleted_at IS NOT NULL`, "deleted category"
+    }
+    query := `SELECT ` + categoryColumns + ` FROM categories WHERE id = $1 AND ` + condition + lock
+    var cat models.Category
+    err := scanCategory(tx.QueryRowContext(ctx, query, id), &cat)
     if err != nil {
-        return nil, fmt.Errorf("error querying categories: %w", err)
+        if err == sql.ErrNoRows {
+            return nil, &apperrors.NotFoundError{Entity: entity, ID: id}
+        }
+        return nil, fmt.Errorf("error querying category: %w", dbError(err))
+    }
+    return &cat, nil
+}
+
+func (r *CategoryRepository) GetAll(ctx context.Context) ([]models.Category, error) {
+    query := `SELECT ` + categoryColumns + ` FROM categories WHERE deleted_at IS NULL ORDER BY id`
+    rows, err := r.dbtx().QueryContext(ctx, query)
+    if err != nil {
+        return nil, fmt.Errorf("error querying categories: %w", dbError(err))
     }
     defer rows.Close()
 
     var categories []models.Category
     for rows.Next() {
         var cat models.Category
-        if err := rows.Scan(&cat.ID, &cat.Name, &cat.Description, &cat.CreatedAt, &cat.UpdatedAt); err != nil {
+        if err := scanCategory(rows, &cat); err != nil {
             return nil, fmt.Errorf("error scanning category: %w", err)
         }
         categories = append(categories, cat)
@@ -36,70 +75,227 @@ func (r *CategoryRepository) GetAll() ([]models.Category, error) {
     return categories, nil
 }
 
-func (r *CategoryRepository) GetByID(id int) (*models.Category, error) {
-    query := `SELECT id, name, description, created_at, updated_at FROM categories WHERE id = $1`
+func (r *CategoryRepository) GetByID(ctx context.Context, id int) (*models.Category, error) {
+    query := `SELECT ` + categoryColumns + ` FROM categories WHERE id = $1 AND deleted_at IS NULL` + r.forShare()
     var cat models.Category
-    err := r.db.QueryRow(query, id).Scan(&cat.ID, &cat.Name, &cat.Description, &cat.CreatedAt, &cat.UpdatedAt)
+    err := scanCategory(r.dbtx().QueryRowContext(ctx, query, id), &cat)
     if err != nil {
         if err == sql.ErrNoRows {
-            return nil, fmt.Errorf("category with ID %d not found", id)
+            return nil, &apperrors.NotFoundError{Entity: "category", ID: id}
         }
-        return nil, fmt.Errorf("error querying category: %w", err)
+        return nil, fmt.Errorf("error querying category: %w", dbError(err))
     }
     return &cat, nil
 }
 
-func (r *CategoryRepository) Create(cat *models.Category) error {
-    query := `INSERT INTO categories (name, description, updated_at) VALUES ($1, $2, $3) RETURNING id, created_at`
-    err := r.db.QueryRow(query, cat.Name, cat.Description, time.Now()).Scan(&cat.ID, &cat.CreatedAt)
-    if err != nil {
-        return fmt.Errorf("error creating category: %w", err)
-    }
-    return nil
+func (r *CategoryRepository) Create(ctx context.Context, cat *models.Category) error {
+    query := `
+        INSERT INTO categories (name, description, depreciation_method, useful_life_months, depreciation_rate, salvage_value, salvage_percent,
+            tax_group, tax_method, expense_account, accumulated_account, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id, created_at
+    `
+    return r.withTx(ctx, func(tx *sql.Tx) error {
+        err := tx.QueryRowContext(ctx, query, cat.Name, cat.Description, cat.Method, cat.UsefulLifeMonths, cat.RatePercent,
+            cat.SalvageValue, cat.SalvagePercent, cat.TaxGroup, cat.TaxMethod, cat.ExpenseAccount, cat.AccumulatedAccount, time.Now()).Scan(&cat.ID, &cat.CreatedAt)
+        if err != nil {
+            if isUniqueViolation(err) {
+                return fmt.Errorf("error creating category: %w", &apperrors.DuplicateNameError{Entity: "category", Name: cat.Name})
+            }
+            return fmt.Errorf("error creating category: %w", dbError(err))
+        }
+
+        created, err := categoryInTx(ctx, tx, cat.ID, false, "")
+        if err != nil {
+            return err
+        }
+        cat.Version = created.Version
+        return writeAudit(ctx, tx, models.AuditCategory, cat.ID, models.AuditCreate, nil, created)
+    })
 }
 
-func (r *CategoryRepository) Update(cat *models.Category) error {
-    query := `UPDATE categories SET name = $1, description = $2, updated_at = $3 WHERE id = $4`
-    result, err := r.db.Exec(query, cat.Name, cat.Description, time.Now(), cat.ID)
-    if err != nil {
-        return fmt.Errorf("error updating category: %w", err)
-    }
+// Update stores the fields of cat and increments its version, refusing the
+// update like ItemRepository.Update when the stored category is at another
+// version
+func (r *CategoryRepository) Update(ctx context.Context, cat *models.Category) error {
+    query := `
+        UPDATE categories SET name = $1, description = $2, depreciation_method = $3, useful_life_months = $4, depreciation_rate = $5,
+            salvage_value = $6, salvage_percent = $7, tax_group = $8, tax_method = $9, expense_account = $10, accumulated_account = $11,
+            updated_at = $12, version = version + 1
+        WHERE id = $13 AND deleted_at IS NULL AND version = $14
+    `
+    return r.withTx(ctx, func(tx *sql.Tx) error {
+        before, err := categoryInTx(ctx, tx, cat.ID, false, "")
+        if err != nil {
+            return err
+        }
+        if err := checkVersion("category", cat.ID, cat.Version, before.Version); err != nil {
+            return err
+        }
 
-    rows, err := result.RowsAffected()
-    if err != nil {
-        return fmt.Errorf("error getting rows affected: %w", err)
+        result, err := tx.ExecContext(ctx, query, cat.Name, cat.Description, cat.Method, cat.UsefulLifeMonths, cat.RatePercent,
+            cat.SalvageValue, cat.SalvagePercent, cat.TaxGroup, cat.TaxMethod, cat.ExpenseAccount, cat.AccumulatedAccount, time.Now(), cat.ID, before.Version)
+        if err != nil {
+            if isUniqueViolation(err) {
+                return fmt.Errorf("error updating category: %w", &apperrors.DuplicateNameError{Entity: "category", Name: cat.Name})
+            }
+            return fmt.Errorf("error updating category: %w", dbError(err))
+        }
+
+        rows, err := result.RowsAffected()
+        if err != nil {
+            return fmt.Errorf("error getting rows affected: %w", err)
+        }
+        if rows == 0 {
+            // changed or deleted by a concurrent transaction since it was read above
+            current, err := categoryInTx(ctx, tx, cat.ID, false, "")
+            if err != nil {
+                return err
+            }
+            return &apperrors.VersionConflictError{Entity: "category", ID: cat.ID, Expected: before.Version, Actual: current.Version}
+        }
+
+        after, err := categoryInTx(ctx, tx, cat.ID, false, "")
+        if err != nil {
+            return err
+        }
+        cat.Version = after.Version
+        return writeAudit(ctx, tx, models.AuditCategory, cat.ID, models.AuditUpdate, before, after)
+    })
+}
+
+// Delete moves a category to the trash. Like ON DELETE RESTRICT, a category
+// is kept while items use it, including the items in the trash, so that an
+// item can always be restored into its category.
+func (r *CategoryRepository) Delete(ctx context.Context, id int) error {
+    query := `
+        UPDATE categories SET deleted_at = $1
+        WHERE id = $2 AND deleted_at IS NULL AND NOT EXISTS (SELECT 1 FROM items WHERE category_id = $2)
+    `
+    return r.withTx(ctx, func(tx *sql.Tx) error {
+        // waits for the units of work that read the category FOR SHARE, so
+        // that the check for its items below sees the items they created
+        before, err := categoryInTx(ctx, tx, id, false, r.forUpdate())
+        if err != nil {
+            return err
+        }
+
+        result, err := tx.ExecContext(ctx, query, deletedAt(), id)
+        if err != nil {
+            return fmt.Errorf("error deleting category: %w", dbError(err))
+        }
+
+        rows, err := result.RowsAffected()
+        if err != nil {
+            return fmt.Errorf("error getting rows affected: %w", err)
+        }
+        if rows == 0 {
+            return deleteBlocked(ctx, tx, id)
+        }
+
+        return writeAudit(ctx, tx, models.AuditCategory, id, models.AuditDelete, before, nil)
+    })
+}
+
+// deleteBlocked tells why Delete did not delete category id: it is missing
+// or in the trash already, or items still use it
+func deleteBlocked(ctx context.Context, tx *sql.Tx, id int) error {
+    query := `
+        SELECT
+            (SELECT COUNT(*) FROM categories WHERE id = $1 AND deleted_at IS NULL),
+            (SELECT COUNT(*) FROM items WHERE category_id = $1 AND deleted_at IS NULL),
+            (SELECT COUNT(*) FROM items WHERE category_id = $1)
+    `
+    var found, liveItems, allItems int
+    if err := tx.QueryRowContext(ctx, query, id).Scan(&found, &liveItems, &allItems); err != nil {
+        return fmt.Errorf("error deleting category: %w", dbError(err))
     }
-    if rows == 0 {
-        return fmt.Errorf("category with ID %d not found", cat.ID)
+    if found == 0 || allItems == 0 {
+        return &apperrors.NotFoundError{Entity: "category", ID: id}
     }
+    return fmt.Errorf("error deleting category: %w", &apperrors.CategoryInUseError{ID: id, InTrash: liveItems == 0})
+}
 
-    return nil
+// GetDeleted returns the categories in the trash, oldest deletion first
+func (r *CategoryRepository) GetDeleted(ctx context.Context) ([]models.TrashEntry, error) {
+    query := `SELECT id, name, deleted_at FROM categories WHERE deleted_at IS NOT NULL ORDER BY deleted_at, id`
+    return queryTrash(ctx, r.dbtx(), query, models.TrashCategory)
 }
 
-func (r *CategoryRepository) Delete(id int) error {
-    query := `DELETE FROM categories WHERE id = $1`
-    result, err := r.db.Exec(query, id)
-    if err != nil {
-        return fmt.Errorf("error deleting category: %w", err)
-    }
+// Restore takes a category out of the trash
+func (r *CategoryRepository) Restore(ctx context.Context, id int) error {
+    query := `UPDATE categories SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL`
+    return r.withTx(ctx, func(tx *sql.Tx) error {
+        result, err := tx.ExecContext(ctx, query, id)
+        if err != nil {
+            return fmt.Errorf("error restoring category: %w", dbError(err))
+        }
+
+        rows, err := result.RowsAffected()
+        if err != nil {
+            return fmt.Errorf("error getting rows affected: %w", err)
+        }
+        if rows == 0 {
+            return &apperrors.NotFoundError{Entity: "deleted category", ID: id}
+        }
+
+        after, err := categoryInTx(ctx, tx, id, false, "")
+        if err != nil {
+            return err
+        }
+        return writeAudit(ctx, tx, models.AuditCategory, id, models.AuditRestore, nil, after)
+    })
+}
 
-    rows, err := result.RowsAffected()
+// Purge permanently deletes the categories moved to the trash before before
+// and returns how many there were
+func (r *CategoryRepository) Purge(ctx context.Context, before time.Time) (int, error) {
+    query := `SELECT ` + categoryColumns + ` FROM categories WHERE deleted_at IS NOT NULL AND deleted_at < $1 ORDER BY id`
+    var purged []models.Category
+    err := r.withTx(ctx, func(tx *sql.Tx) error {
+        rows, err := tx.QueryContext(ctx, query, before.UTC())
+        if err != nil {
+            return fmt.Errorf("error purging categories: %w", dbError(err))
+        }
+        for rows.Next() {
+            var cat models.Category
+            if err := scanCategory(rows, &cat); err != nil {
+                rows.Close()
+                return fmt.Errorf("error scanning category: %w", err)
+            }
+            purged = append(purged, cat)
+        }
+        rows.Close()
+        if err := rows.Err(); err != nil {
+            return fmt.Errorf("error purging categories: %w", dbError(err))
+        }
+
+        for _, cat := range purged {
+            if _, err := tx.ExecContext(ctx, `DELETE FROM categories WHERE id = $1`, cat.ID); err != nil {
+                if isForeignKeyViolation(err) {
+                    return fmt.Errorf("error purging categories: %w", &apperrors.CategoryInUseError{ID: cat.ID, InTrash: true})
+                }
+                return fmt.Errorf("error purging categories: %w", dbError(err))
+            }
+            if err := writeAudit(ctx, tx, models.AuditCategory, cat.ID, models.AuditPurge, cat, nil); err != nil {
+                return err
+            }
+        }
+        return nil
+    })
     if err != nil {
-        return fmt.Errorf("error getting rows affected: %w", err)
-    }
-    if rows == 0 {
-        return fmt.Errorf("category with ID %d not found", id)
+        return 0, err
     }
-
-    return nil
+    return len(purged), nil
 }
 
-func (r *CategoryRepository) CheckNameExists(name string, excludeID int) (bool, error) {
+// CheckNameExists also counts categories in the trash, which keep their name
+// under the unique constraint until they are purged
+func (r *CategoryRepository) CheckNameExists(ctx context.Context, name string, excludeID int) (bool, error) {
     query := `SELECT COUNT(*) FROM categories WHERE name = $1 AND id != $2`
     var count int
-    err := r.db.QueryRow(query, name, excludeID).Scan(&count)
+    err := r.dbtx().QueryRowContext(ctx, query, name, excludeID).Scan(&count)
     if err != nil {
-        return false, fmt.Errorf("error checking category name: %w", err)
+        return false, fmt.Errorf("error checking category name: %w", dbError(err))
     }
     return count > 0, nil
 }
\ No newline at end of file
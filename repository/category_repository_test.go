@@ -1,12 +1,17 @@
 package repository
 
 import (
+    "context"
     "database/sql"
+    "errors"
     "testing"
     "time"
 
     "github.com/DATA-DOG/go-sqlmock"
+    "github.com/lib/pq"
+    "mini_project3/apperrors"
     "mini_project3/models"
+    "mini_project3/money"
 )
 
 func TestCategoryRepository_GetAll(t *testing.T) {
@@ -18,14 +23,14 @@ func TestCategoryRepository_GetAll(t *testing.T) {
 
     repo := NewCategoryRepository(db)
 
-    rows := sqlmock.NewRows([]string{"id", "name", "description", "created_at", "updated_at"}).
-        AddRow(1, "Elektronik", "Peralatan elektronik", time.Now(), time.Now()).
-        AddRow(2, "Furniture", "Mebel kantor", time.Now(), time.Now())
+    rows := sqlmock.NewRows([]string{"id", "name", "description", "depreciation_method", "useful_life_months", "depreciation_rate", "salvage_value", "salvage_percent", "tax_group", "tax_method", "expense_account", "accumulated_account", "created_at", "updated_at", "version"}).
+        AddRow(1, "Elektronik", "Peralatan elektronik", "", 0, "0", "0", "0", "", "", "", "", time.Now(), time.Now(), 1).
+        AddRow(2, "Furniture", "Mebel kantor", "", 0, "0", "0", "0", "", "", "", "", time.Now(), time.Now(), 1)
 
-    mock.ExpectQuery("SELECT id, name, description, created_at, updated_at FROM categories ORDER BY id").
+    mock.ExpectQuery("SELECT id, name, description, depreciation_method, useful_life_months, depreciation_rate, salvage_value, salvage_percent, tax_group, tax_method, expense_account, accumulated_account, created_at, updated_at, version FROM categories WHERE deleted_at IS NULL ORDER BY id").
         WillReturnRows(rows)
 
-    categories, err := repo.GetAll()
+    categories, err := repo.GetAll(context.Background())
     if err != nil {
         t.Errorf("error was not expected: %s", err)
     }
@@ -52,14 +57,14 @@ func TestCategoryRepository_GetByID(t *testing.T) {
 
     repo := NewCategoryRepository(db)
 
-    rows := sqlmock.NewRows([]string{"id", "name", "description", "created_at", "updated_at"}).
-        AddRow(1, "Elektronik", "Peralatan elektronik", time.Now(), time.Now())
+    rows := sqlmock.NewRows([]string{"id", "name", "description", "depreciation_method", "useful_life_months", "depreciation_rate", "salvage_value", "salvage_percent", "tax_group", "tax_method", "expense_account", "accumulated_account", "created_at", "updated_at", "version"}).
+        AddRow(1, "Elektronik", "Peralatan elektronik", "", 0, "0", "0", "0", "", "", "", "", time.Now(), time.Now(), 1)
 
-    mock.ExpectQuery("SELECT id, name, description, created_at, updated_at FROM categories WHERE id = \\$1").
+    mock.ExpectQuery("SELECT id, name, description, depreciation_method, useful_life_months, depreciation_rate, salvage_value, salvage_percent, tax_group, tax_method, expense_account, accumulated_account, created_at, updated_at, version FROM categories WHERE id = \\$1 AND deleted_at IS NULL").
         WithArgs(1).
         WillReturnRows(rows)
 
-    category, err := repo.GetByID(1)
+    category, err := repo.GetByID(context.Background(), 1)
     if err != nil {
         t.Errorf("error was not expected: %s", err)
     }
@@ -82,14 +87,17 @@ func TestCategoryRepository_GetByID_NotFound(t *testing.T) {
 
     repo := NewCategoryRepository(db)
 
-    mock.ExpectQuery("SELECT id, name, description, created_at, updated_at FROM categories WHERE id = \\$1").
+    mock.ExpectQuery("SELECT id, name, description, depreciation_method, useful_life_months, depreciation_rate, salvage_value, salvage_percent, tax_group, tax_method, expense_account, accumulated_account, created_at, updated_at, version FROM categories WHERE id = \\$1 AND deleted_at IS NULL").
         WithArgs(999).
         WillReturnError(sql.ErrNoRows)
 
-    _, err = repo.GetByID(999)
+    _, err = repo.GetByID(context.Background(), 999)
     if err == nil {
         t.Error("expected error, got nil")
     }
+    if !errors.Is(err, apperrors.ErrNotFound) {
+        t.Errorf("expected ErrNotFound, got %v", err)
+    }
 
     if err := mock.ExpectationsWereMet(); err != nil {
         t.Errorf("there were unfulfilled expectations: %s", err)
@@ -108,16 +116,23 @@ func TestCategoryRepository_Create(t *testing.T) {
     cat := &models.Category{
         Name:        "Test Category",
         Description: "Test Description",
+        DepreciationPolicy: models.DepreciationPolicy{Method: "declining-balance", RatePercent: money.MustParseRate("25"), SalvagePercent: money.MustParseRate("10"),
+            TaxGroup: "kelompok-2", TaxMethod: "declining-balance"},
+        JournalAccounts: models.JournalAccounts{ExpenseAccount: "6-1100", AccumulatedAccount: "1-2190"},
     }
 
     rows := sqlmock.NewRows([]string{"id", "created_at"}).
         AddRow(1, time.Now())
 
-    mock.ExpectQuery("INSERT INTO categories \\(name, description, updated_at\\) VALUES \\(\\$1, \\$2, \\$3\\) RETURNING id, created_at").
-        WithArgs(cat.Name, cat.Description, sqlmock.AnyArg()).
+    mock.ExpectBegin()
+    mock.ExpectQuery("INSERT INTO categories \\(name, description, depreciation_method, useful_life_months, depreciation_rate, salvage_value, salvage_percent, tax_group, tax_method, expense_account, accumulated_account, updated_at\\) VALUES \\(\\$1, \\$2, \\$3, \\$4, \\$5, \\$6, \\$7, \\$8, \\$9, \\$10, \\$11, \\$12\\) RETURNING id, created_at").
+        WithArgs(cat.Name, cat.Description, "declining-balance", 0, cat.RatePercent, money.Zero, cat.SalvagePercent, "kelompok-2", "declining-balance", "6-1100", "1-2190", sqlmock.AnyArg()).
         WillReturnRows(rows)
+    expectCategoryRow(mock, 1, cat.Name)
+    expectAudit(mock, models.AuditCategory, 1, models.AuditCreate)
+    mock.ExpectCommit()
 
-    err = repo.Create(cat)
+    err = repo.Create(context.Background(), cat)
     if err != nil {
         t.Errorf("error was not expected: %s", err)
     }
@@ -146,11 +161,16 @@ func TestCategoryRepository_Update(t *testing.T) {
         Description: "Updated Description",
     }
 
-    mock.ExpectExec("UPDATE categories SET name = \\$1, description = \\$2, updated_at = \\$3 WHERE id = \\$4").
-        WithArgs(cat.Name, cat.Description, sqlmock.AnyArg(), cat.ID).
+    mock.ExpectBegin()
+    expectCategoryRow(mock, 1, "Elektronik")
+    mock.ExpectExec("UPDATE categories SET name = \\$1, description = \\$2, depreciation_method = \\$3, useful_life_months = \\$4, depreciation_rate = \\$5, salvage_value = \\$6, salvage_percent = \\$7, tax_group = \\$8, tax_method = \\$9, expense_account = \\$10, accumulated_account = \\$11, updated_at = \\$12, version = version \\+ 1 WHERE id = \\$13 AND deleted_at IS NULL AND version = \\$14").
+        WithArgs(cat.Name, cat.Description, "", 0, cat.RatePercent, money.Zero, money.Rate{}, "", "", "", "", sqlmock.AnyArg(), cat.ID, 1).
         WillReturnResult(sqlmock.NewResult(0, 1))
+    expectCategoryRow(mock, 1, cat.Name)
+    expectAudit(mock, models.AuditCategory, 1, models.AuditUpdate)
+    mock.ExpectCommit()
 
-    err = repo.Update(cat)
+    err = repo.Update(context.Background(), cat)
     if err != nil {
         t.Errorf("error was not expected: %s", err)
     }
@@ -160,7 +180,7 @@ func TestCategoryRepository_Update(t *testing.T) {
     }
 }
 
-func TestCategoryRepository_Delete(t *testing.T) {
+func TestCategoryRepository_Update_VersionConflict(t *testing.T) {
     db, mock, err := sqlmock.New()
     if err != nil {
         t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
@@ -169,11 +189,56 @@ func TestCategoryRepository_Delete(t *testing.T) {
 
     repo := NewCategoryRepository(db)
 
-    mock.ExpectExec("DELETE FROM categories WHERE id = \\$1").
+    // read at version 1, but a concurrent update commits before the UPDATE runs
+    mock.ExpectBegin()
+    expectCategoryRow(mock, 1, "Elektronik")
+    mock.ExpectExec("UPDATE categories SET name").
+        WithArgs("Gadget", "", "", 0, money.Rate{}, money.Zero, money.Rate{}, "", "", "", "", sqlmock.AnyArg(), 1, 1).
+        WillReturnResult(sqlmock.NewResult(0, 0))
+    mock.ExpectQuery("SELECT id, name, description").
         WithArgs(1).
+        WillReturnRows(sqlmock.NewRows(categoryColumnNames).AddRow(1, "Perangkat", "", "", 0, "0", "0", "0", "", "", "", "", time.Now(), time.Now(), 2))
+    mock.ExpectRollback()
+
+    err = repo.Update(context.Background(), &models.Category{ID: 1, Name: "Gadget"})
+    var conflict *apperrors.VersionConflictError
+    if !errors.As(err, &conflict) || conflict.Expected != 1 || conflict.Actual != 2 {
+        t.Errorf("expected a version conflict from 1 to 2, got %v", err)
+    }
+
+    // the caller read version 3, which is no longer the stored version 1
+    mock.ExpectBegin()
+    expectCategoryRow(mock, 1, "Elektronik")
+    mock.ExpectRollback()
+
+    err = repo.Update(context.Background(), &models.Category{ID: 1, Name: "Gadget", Version: 3})
+    if !errors.As(err, &conflict) || conflict.Expected != 3 || conflict.Actual != 1 {
+        t.Errorf("expected a version conflict from 3 to 1, got %v", err)
+    }
+
+    if err := mock.ExpectationsWereMet(); err != nil {
+        t.Errorf("there were unfulfilled expectations: %s", err)
+    }
+}
+
+func TestCategoryRepository_Delete(t *testing.T) {
+    db, mock, err := sqlmock.New()
+    if err != nil {
+        t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+    }
+    defer db.Close()
+
+    repo := NewCategoryRepository(db)
+
+    mock.ExpectBegin()
+    expectCategoryRow(mock, 1, "Elektronik")
+    mock.ExpectExec("UPDATE categories SET deleted_at = \\$1 WHERE id = \\$2 AND deleted_at IS NULL AND NOT EXISTS \\(SELECT 1 FROM items WHERE category_id = \\$2\\)").
+        WithArgs(sqlmock.AnyArg(), 1).
         WillReturnResult(sqlmock.NewResult(0, 1))
+    expectAudit(mock, models.AuditCategory, 1, models.AuditDelete)
+    mock.ExpectCommit()
 
-    err = repo.Delete(1)
+    err = repo.Delete(context.Background(), 1)
     if err != nil {
         t.Errorf("error was not expected: %s", err)
     }
@@ -183,6 +248,82 @@ func TestCategoryRepository_Delete(t *testing.T) {
     }
 }
 
+func TestCategoryRepository_Delete_InUse(t *testing.T) {
+    db, mock, err := sqlmock.New()
+    if err != nil {
+        t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+    }
+    defer db.Close()
+
+    repo := NewCategoryRepository(db)
+
+    mock.ExpectBegin()
+    expectCategoryRow(mock, 1, "Elektronik")
+    mock.ExpectExec("UPDATE categories SET deleted_at").
+        WithArgs(sqlmock.AnyArg(), 1).
+        WillReturnResult(sqlmock.NewResult(0, 0))
+    mock.ExpectQuery("SELECT \\(SELECT COUNT\\(\\*\\) FROM categories").
+        WithArgs(1).
+        WillReturnRows(sqlmock.NewRows([]string{"found", "live_items", "all_items"}).AddRow(1, 0, 2))
+    mock.ExpectRollback()
+
+    err = repo.Delete(context.Background(), 1)
+    var inUse *apperrors.CategoryInUseError
+    if !errors.As(err, &inUse) || inUse.ID != 1 || !inUse.InTrash {
+        t.Errorf("expected CategoryInUseError for ID 1 with its items in the trash, got %v", err)
+    }
+
+    if err := mock.ExpectationsWereMet(); err != nil {
+        t.Errorf("there were unfulfilled expectations: %s", err)
+    }
+}
+
+func TestCategoryRepository_Create_Duplicate(t *testing.T) {
+    db, mock, err := sqlmock.New()
+    if err != nil {
+        t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+    }
+    defer db.Close()
+
+    repo := NewCategoryRepository(db)
+
+    mock.ExpectBegin()
+    mock.ExpectQuery("INSERT INTO categories").
+        WillReturnError(&pq.Error{Code: "23505", Message: "duplicate key value violates unique constraint"})
+    mock.ExpectRollback()
+
+    err = repo.Create(context.Background(), &models.Category{Name: "Elektronik"})
+    if !errors.Is(err, apperrors.ErrDuplicateName) {
+        t.Errorf("expected ErrDuplicateName, got %v", err)
+    }
+
+    if err := mock.ExpectationsWereMet(); err != nil {
+        t.Errorf("there were unfulfilled expectations: %s", err)
+    }
+}
+
+func TestCategoryRepository_GetAll_DatabaseUnavailable(t *testing.T) {
+    db, mock, err := sqlmock.New()
+    if err != nil {
+        t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+    }
+    defer db.Close()
+
+    repo := NewCategoryRepository(db)
+
+    mock.ExpectQuery("SELECT id, name, description, depreciation_method, useful_life_months, depreciation_rate, salvage_value, salvage_percent, tax_group, tax_method, expense_account, accumulated_account, created_at, updated_at, version FROM categories WHERE deleted_at IS NULL ORDER BY id").
+        WillReturnError(&pq.Error{Code: "57P01", Message: "terminating connection due to administrator command"})
+
+    _, err = repo.GetAll(context.Background())
+    if !errors.Is(err, apperrors.ErrDatabaseUnavailable) {
+        t.Errorf("expected ErrDatabaseUnavailable, got %v", err)
+    }
+
+    if err := mock.ExpectationsWereMet(); err != nil {
+        t.Errorf("there were unfulfilled expectations: %s", err)
+    }
+}
+
 func TestCategoryRepository_CheckNameExists(t *testing.T) {
     db, mock, err := sqlmock.New()
     if err != nil {
@@ -194,11 +335,11 @@ func TestCategoryRepository_CheckNameExists(t *testing.T) {
 
     rows := sqlmock.NewRows([]string{"count"}).AddRow(1)
 
-    mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM categories WHERE name = \\$1 AND id != \\$2").
+    mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM categories WHERE name = \\$1 AND id != \\$2$").
         WithArgs("Elektronik", 0).
         WillReturnRows(rows)
 
-    exists, err := repo.CheckNameExists("Elektronik", 0)
+    exists, err := repo.CheckNameExists(context.Background(), "Elektronik", 0)
     if err != nil {
         t.Errorf("error was not expected: %s", err)
     }
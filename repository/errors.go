@@ -0,0 +1,79 @@
+package repository
+
+import (
+    "database/sql"
+    "database/sql/driver"
+    "errors"
+    "net"
+    "strings"
+
+    "mini_project3/apperrors"
+
+    "github.com/lib/pq"
+    "modernc.org/sqlite"
+    sqlite3 "modernc.org/sqlite/lib"
+)
+
+// PostgreSQL error codes, see https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+    pqUniqueViolation     = "23505"
+    pqForeignKeyViolation = "23503"
+)
+
+// isUniqueViolation reports whether err is a unique or primary key constraint violation on either backend
+func isUniqueViolation(err error) bool {
+    var pqErr *pq.Error
+    if errors.As(err, &pqErr) {
+        return pqErr.Code == pqUniqueViolation
+    }
+    var sqliteErr *sqlite.Error
+    if errors.As(err, &sqliteErr) {
+        return sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE || sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY
+    }
+    return false
+}
+
+// isForeignKeyViolation reports whether err is a foreign key violation on either backend.
+// SQLite reports ON DELETE RESTRICT with a different extended code than a
+// failed insert, so its primary code and message are checked instead.
+func isForeignKeyViolation(err error) bool {
+    var pqErr *pq.Error
+    if errors.As(err, &pqErr) {
+        return pqErr.Code == pqForeignKeyViolation
+    }
+    var sqliteErr *sqlite.Error
+    if errors.As(err, &sqliteErr) {
+        return sqliteErr.Code()&0xff == sqlite3.SQLITE_CONSTRAINT && strings.Contains(sqliteErr.Error(), "FOREIGN KEY")
+    }
+    return false
+}
+
+// isConnectionError reports whether err means the database could not be reached
+// rather than that the statement itself failed
+func isConnectionError(err error) bool {
+    if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) {
+        return true
+    }
+    var netErr net.Error
+    if errors.As(err, &netErr) {
+        return true
+    }
+    var pqErr *pq.Error
+    if errors.As(err, &pqErr) {
+        // Class 08 connection exception, 57P01-57P03 server shutting down or starting up
+        return pqErr.Code.Class() == "08" || pqErr.Code == "57P01" || pqErr.Code == "57P02" || pqErr.Code == "57P03"
+    }
+    var sqliteErr *sqlite.Error
+    if errors.As(err, &sqliteErr) {
+        return sqliteErr.Code()&0xff == sqlite3.SQLITE_CANTOPEN
+    }
+    return false
+}
+
+// dbError marks connection failures with apperrors.ErrDatabaseUnavailable and returns other errors unchanged
+func dbError(err error) error {
+    if isConnectionError(err) {
+        return &apperrors.DatabaseUnavailableError{Err: err}
+    }
+    return err
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+    "time"
+
+    "mini_project3/apperrors"
+    "mini_project3/config"
+    "mini_project3/models"
+)
+
+type ExchangeRateRepository struct {
+    conn
+}
+
+func NewExchangeRateRepository(db *sql.DB) *ExchangeRateRepository {
+    return &ExchangeRateRepository{conn{db: db, driver: config.DriverPostgres}}
+}
+
+// NewExchangeRateRepositoryWithDriver creates ExchangeRateRepository for the given backend (config.DriverPostgres or config.DriverSQLite)
+func NewExchangeRateRepositoryWithDriver(db *sql.DB, driver string) *ExchangeRateRepository {
+    return &ExchangeRateRepository{conn{db: db, driver: driver}}
+}
+
+// rateDate returns the SQL expression for rate_date as a YYYY-MM-DD day;
+// SQLite stores dates as text with a time part
+func (r *ExchangeRateRepository) rateDate() string {
+    if r.driver == config.DriverSQLite {
+        return `date(rate_date)`
+    }
+    return `rate_date`
+}
+
+// Set stores the rate of a currency on a date, replacing an existing rate for the same day
+func (r *ExchangeRateRepository) Set(ctx context.Context, rate *models.ExchangeRate) error {
+    query := `
+        INSERT INTO exchange_rates (currency, rate_date, rate, created_at) VALUES ($1, $2, $3, $4)
+        ON CONFLICT (currency, rate_date) DO UPDATE SET rate = excluded.rate, created_at = excluded.created_at
+    `
+    rate.CreatedAt = time.Now()
+    if _, err := r.dbtx().ExecContext(ctx, query, rate.Currency, rate.Date, rate.Rate, rate.CreatedAt); err != nil {
+        return fmt.Errorf("error setting exchange rate: %w", dbError(err))
+    }
+    return nil
+}
+
+// GetAll returns the rates of currency, or of every currency when it is empty, ordered by currency and date
+func (r *ExchangeRateRepository) GetAll(ctx context.Context, currency string) ([]models.ExchangeRate, error) {
+    query := `SELECT currency, rate_date, rate, created_at FROM exchange_rates`
+    var args []interface{}
+    if currency != "" {
+        query += ` WHERE currency = $1`
+        args = append(args, currency)
+    }
+    query += ` ORDER BY currency, rate_date`
+
+    rows, err := r.dbtx().QueryContext(ctx, query, args...)
+    if err != nil {
+        return nil, fmt.Errorf("error querying exchange rates: %w", dbError(err))
+    }
+    defer rows.Close()
+
+    var rates []models.ExchangeRate
+    for rows.Next() {
+        var rate models.ExchangeRate
+        if err := rows.Scan(&rate.Currency, &rate.Date, &rate.Rate, &rate.CreatedAt); err != nil {
+            return nil, fmt.Errorf("error scanning exchange rate: %w", err)
+        }
+        rates = append(rates, rate)
+    }
+
+    return rates, nil
+}
+
+// GetOnOrBefore returns the latest rate of currency dated on or before date
+func (r *ExchangeRateRepository) GetOnOrBefore(ctx context.Context, currency string, date time.Time) (*models.ExchangeRate, error) {
+    query := `
+        SELECT currency, rate_date, rate, created_at
+        FROM exchange_rates
+        WHERE currency = $1 AND ` + r.rateDate() + ` <= $2
+        ORDER BY rate_date DESC
+        LIMIT 1
+    `
+    var rate models.ExchangeRate
+    err := r.dbtx().QueryRowContext(ctx, query, currency, date.Format("2006-01-02")).Scan(&rate.Currency, &rate.Date, &rate.Rate, &rate.CreatedAt)
+    if err != nil {
+        if err == sql.ErrNoRows {
+            return nil, &apperrors.RateNotFoundError{Currency: currency, Date: date}
+        }
+        return nil, fmt.Errorf("error querying exchange rate: %w", dbError(err))
+    }
+    return &rate, nil
+}
\ No newline at end of file
@@ -0,0 +1,92 @@
+package repository
+
+import (
+    "context"
+    "errors"
+    "regexp"
+    "testing"
+    "time"
+
+    "github.com/DATA-DOG/go-sqlmock"
+    "mini_project3/apperrors"
+    "mini_project3/config"
+    "mini_project3/models"
+    "mini_project3/money"
+)
+
+func TestExchangeRateRepository_Set(t *testing.T) {
+    db, mock, err := sqlmock.New()
+    if err != nil {
+        t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+    }
+    defer db.Close()
+
+    repo := NewExchangeRateRepository(db)
+    rate := &models.ExchangeRate{Currency: "USD", Date: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Rate: money.MustParseRate("15400.5")}
+
+    mock.ExpectExec("INSERT INTO exchange_rates .* ON CONFLICT \\(currency, rate_date\\) DO UPDATE").
+        WithArgs("USD", rate.Date, "15400.5", sqlmock.AnyArg()).
+        WillReturnResult(sqlmock.NewResult(0, 1))
+
+    if err := repo.Set(context.Background(), rate); err != nil {
+        t.Errorf("error was not expected: %s", err)
+    }
+
+    if err := mock.ExpectationsWereMet(); err != nil {
+        t.Errorf("there were unfulfilled expectations: %s", err)
+    }
+}
+
+func TestExchangeRateRepository_GetAll_FilterByCurrency(t *testing.T) {
+    db, mock, err := sqlmock.New()
+    if err != nil {
+        t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+    }
+    defer db.Close()
+
+    repo := NewExchangeRateRepository(db)
+
+    rows := sqlmock.NewRows([]string{"currency", "rate_date", "rate", "created_at"}).
+        AddRow("USD", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), "15400.000000", time.Now()).
+        AddRow("USD", time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), "16350.000000", time.Now())
+
+    mock.ExpectQuery(regexp.QuoteMeta("SELECT currency, rate_date, rate, created_at FROM exchange_rates WHERE currency = $1 ORDER BY currency, rate_date")).
+        WithArgs("USD").
+        WillReturnRows(rows)
+
+    rates, err := repo.GetAll(context.Background(), "USD")
+    if err != nil {
+        t.Errorf("error was not expected: %s", err)
+    }
+
+    if len(rates) != 2 || rates[1].Rate != money.MustParseRate("16350") {
+        t.Errorf("unexpected rates %+v", rates)
+    }
+
+    if err := mock.ExpectationsWereMet(); err != nil {
+        t.Errorf("there were unfulfilled expectations: %s", err)
+    }
+}
+
+func TestExchangeRateRepository_GetOnOrBefore_NotFound(t *testing.T) {
+    db, mock, err := sqlmock.New()
+    if err != nil {
+        t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+    }
+    defer db.Close()
+
+    repo := NewExchangeRateRepositoryWithDriver(db, config.DriverSQLite)
+
+    mock.ExpectQuery("SELECT currency, rate_date, rate, created_at FROM exchange_rates WHERE currency = \\$1 AND date\\(rate_date\\) <= \\$2").
+        WithArgs("USD", "2023-12-31").
+        WillReturnRows(sqlmock.NewRows([]string{"currency", "rate_date", "rate", "created_at"}))
+
+    _, err = repo.GetOnOrBefore(context.Background(), "USD", time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC))
+    if !errors.Is(err, apperrors.ErrNotFound) {
+        t.Errorf("expected not found error, got %v", err)
+    }
+
+    if err := mock.ExpectationsWereMet(); err != nil {
+        t.Errorf("there were unfulfilled expectations: %s", err)
+    }
+}
@@ -1,39 +1,102 @@
 package repository
 
 import (
+    "context"
     "database/sql"
     "fmt"
     "strings"
     "time"
 
+    "mini_project3/apperrors"
+    "mini_project3/config"
     "mini_project3/models"
 )
 
+// ItemRepository stores items; every change is appended to audit_log in the
+// same transaction
 type ItemRepository struct {
-    db *sql.DB
+    conn
 }
 
 func NewItemRepository(db *sql.DB) *ItemRepository {
-    return &ItemRepository{db: db}
+    return &ItemRepository{conn{db: db, driver: config.DriverPostgres}}
 }
 
-func (r *ItemRepository) GetAll() ([]models.Item, error) {
+// NewItemRepositoryWithDriver creates ItemRepository for the given backend (config.DriverPostgres or config.DriverSQLite)
+func NewItemRepositoryWithDriver(db *sql.DB, driver string) *ItemRepository {
+    return &ItemRepository{conn{db: db, driver: driver}}
+}
+
+// daysSincePurchase returns the SQL expression for whole days between purchase_date
+// and the date bound to param as YYYY-MM-DD
+func (r *ItemRepository) daysSincePurchase(param string) string {
+    if r.driver == config.DriverSQLite {
+        return `CAST(julianday(date(` + param + `)) - julianday(date(i.purchase_date)) AS INTEGER)`
+    }
+    return param + `::date - i.purchase_date`
+}
+
+// inUseOn returns the SQL condition that an item was not disposed on or
+// before the date bound to param as YYYY-MM-DD
+func (r *ItemRepository) inUseOn(param string) string {
+    if r.driver == config.DriverSQLite {
+        return `(i.disposed_at IS NULL OR date(i.disposed_at) > date(` + param + `))`
+    }
+    return `(i.disposed_at IS NULL OR i.disposed_at > ` + param + `::date)`
+}
+
+// itemColumns is the select list of an item joined with its category name, read by scanItem
+const itemColumns = `i.id, i.name, i.category_id, c.name, i.price, i.currency, i.purchase_date,
+        i.depreciation_method, i.useful_life_months, i.depreciation_rate, i.salvage_value, i.salvage_percent,
+        i.tax_group, i.tax_method, i.disposed_at, i.disposal_method, i.disposal_proceeds, i.created_at, i.updated_at, i.version`
+
+// scanItem reads one row selected with itemColumns
+func scanItem(row interface{ Scan(...interface{}) error }, item *models.Item) error {
+    return row.Scan(&item.ID, &item.Name, &item.CategoryID, &item.CategoryName, &item.Price, &item.Currency, &item.PurchaseDate,
+        &item.Method, &item.UsefulLifeMonths, &item.RatePercent, &item.SalvageValue, &item.SalvagePercent,
+        &item.TaxGroup, &item.TaxMethod, &item.DisposedAt, &item.DisposalMethod, &item.DisposalProceeds, &item.CreatedAt, &item.UpdatedAt, &item.Version)
+}
+
+// itemInTx reads item id within tx for the audit log, from the trash when trashed is set
+func itemInTx(ctx context.Context, tx *sql.Tx, id int, trashed bool) (*models.Item, error) {
+    condition, entity := `i.deleted_at IS NULL`, "item"
+    if trashed {
+        condition, entity = `i.deleted_at IS NOT NULL`, "deleted item"
+    }
+    query := `
+        SELECT ` + itemColumns + `
+        FROM items i
+        JOIN categories c ON i.category_id = c.id
+        WHERE i.id = $1 AND ` + condition
+    var item models.Item
+    err := scanItem(tx.QueryRowContext(ctx, query, id), &item)
+    if err != nil {
+        if err == sql.ErrNoRows {
+            return nil, &apperrors.NotFoundError{Entity: entity, ID: id}
+        }
+        return nil, fmt.Errorf("error querying item: %w", dbError(err))
+    }
+    return &item, nil
+}
+
+func (r *ItemRepository) GetAll(ctx context.Context) ([]models.Item, error) {
     query := `
-        SELECT i.id, i.name, i.category_id, c.name, i.price, i.purchase_date, i.created_at, i.updated_at
+        SELECT ` + itemColumns + `
         FROM items i
         JOIN categories c ON i.category_id = c.id
+        WHERE i.deleted_at IS NULL
         ORDER BY i.id
     `
-    rows, err := r.db.Query(query)
+    rows, err := r.dbtx().QueryContext(ctx, query)
     if err != nil {
-        return nil, fmt.Errorf("error querying items: %w", err)
+        return nil, fmt.Errorf("error querying items: %w", dbError(err))
     }
     defer rows.Close()
 
     var items []models.Item
     for rows.Next() {
         var item models.Item
-        if err := rows.Scan(&item.ID, &item.Name, &item.CategoryID, &item.CategoryName, &item.Price, &item.PurchaseDate, &item.CreatedAt, &item.UpdatedAt); err != nil {
+        if err := scanItem(rows, &item); err != nil {
             return nil, fmt.Errorf("error scanning item: %w", err)
         }
         items = append(items, item)
@@ -42,88 +105,256 @@ func (r *ItemRepository) GetAll() ([]models.Item, error) {
     return items, nil
 }
 
-func (r *ItemRepository) GetByID(id int) (*models.Item, error) {
+func (r *ItemRepository) GetByID(ctx context.Context, id int) (*models.Item, error) {
     query := `
-        SELECT i.id, i.name, i.category_id, c.name, i.price, i.purchase_date, i.created_at, i.updated_at
+        SELECT ` + itemColumns + `
         FROM items i
         JOIN categories c ON i.category_id = c.id
-        WHERE i.id = $1
+        WHERE i.id = $1 AND i.deleted_at IS NULL
     `
     var item models.Item
-    err := r.db.QueryRow(query, id).Scan(&item.ID, &item.Name, &item.CategoryID, &item.CategoryName, &item.Price, &item.PurchaseDate, &item.CreatedAt, &item.UpdatedAt)
+    err := scanItem(r.dbtx().QueryRowContext(ctx, query, id), &item)
     if err != nil {
         if err == sql.ErrNoRows {
-            return nil, fmt.Errorf("item with ID %d not found", id)
+            return nil, &apperrors.NotFoundError{Entity: "item", ID: id}
         }
-        return nil, fmt.Errorf("error querying item: %w", err)
+        return nil, fmt.Errorf("error querying item: %w", dbError(err))
     }
     return &item, nil
 }
 
-func (r *ItemRepository) Create(item *models.Item) error {
-    query := `INSERT INTO items (name, category_id, price, purchase_date, updated_at) VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at`
-    err := r.db.QueryRow(query, item.Name, item.CategoryID, item.Price, item.PurchaseDate, time.Now()).Scan(&item.ID, &item.CreatedAt)
-    if err != nil {
-        return fmt.Errorf("error creating item: %w", err)
-    }
-    return nil
+func (r *ItemRepository) Create(ctx context.Context, item *models.Item) error {
+    query := `
+        INSERT INTO items (name, category_id, price, currency, purchase_date, depreciation_method, useful_life_months, depreciation_rate,
+            salvage_value, salvage_percent, tax_group, tax_method, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) RETURNING id, created_at
+    `
+    return r.withTx(ctx, func(tx *sql.Tx) error {
+        err := tx.QueryRowContext(ctx, query, item.Name, item.CategoryID, item.Price, item.Currency, item.PurchaseDate,
+            item.Method, item.UsefulLifeMonths, item.RatePercent, item.SalvageValue, item.SalvagePercent,
+            item.TaxGroup, item.TaxMethod, time.Now()).Scan(&item.ID, &item.CreatedAt)
+        if err != nil {
+            if isForeignKeyViolation(err) {
+                return fmt.Errorf("error creating item: %w", &apperrors.NotFoundError{Entity: "category", ID: item.CategoryID})
+            }
+            return fmt.Errorf("error creating item: %w", dbError(err))
+        }
+
+        created, err := itemInTx(ctx, tx, item.ID, false)
+        if err != nil {
+            return err
+        }
+        item.Version = created.Version
+        return writeAudit(ctx, tx, models.AuditItem, item.ID, models.AuditCreate, nil, created)
+    })
 }
 
-func (r *ItemRepository) Update(item *models.Item) error {
-    query := `UPDATE items SET name = $1, category_id = $2, price = $3, purchase_date = $4, updated_at = $5 WHERE id = $6`
-    result, err := r.db.Exec(query, item.Name, item.CategoryID, item.Price, item.PurchaseDate, time.Now(), item.ID)
-    if err != nil {
-        return fmt.Errorf("error updating item: %w", err)
-    }
+// Update stores the fields of item and increments its version. When
+// item.Version is set the stored item must still be at that version; either
+// way the update fails with a VersionConflictError when the item changes
+// between reading it for the audit log and writing it.
+func (r *ItemRepository) Update(ctx context.Context, item *models.Item) error {
+    query := `
+        UPDATE items SET name = $1, category_id = $2, price = $3, currency = $4, purchase_date = $5,
+            depreciation_method = $6, useful_life_months = $7, depreciation_rate = $8, salvage_value = $9, salvage_percent = $10,
+            tax_group = $11, tax_method = $12, updated_at = $13, version = version + 1
+        WHERE id = $14 AND deleted_at IS NULL AND version = $15
+    `
+    return r.withTx(ctx, func(tx *sql.Tx) error {
+        before, err := itemInTx(ctx, tx, item.ID, false)
+        if err != nil {
+            return err
+        }
+        if err := checkVersion("item", item.ID, item.Version, before.Version); err != nil {
+            return err
+        }
 
-    rows, err := result.RowsAffected()
-    if err != nil {
-        return fmt.Errorf("error getting rows affected: %w", err)
-    }
-    if rows == 0 {
-        return fmt.Errorf("item with ID %d not found", item.ID)
-    }
+        result, err := tx.ExecContext(ctx, query, item.Name, item.CategoryID, item.Price, item.Currency, item.PurchaseDate,
+            item.Method, item.UsefulLifeMonths, item.RatePercent, item.SalvageValue, item.SalvagePercent,
+            item.TaxGroup, item.TaxMethod, time.Now(), item.ID, before.Version)
+        if err != nil {
+            if isForeignKeyViolation(err) {
+                return fmt.Errorf("error updating item: %w", &apperrors.NotFoundError{Entity: "category", ID: item.CategoryID})
+            }
+            return fmt.Errorf("error updating item: %w", dbError(err))
+        }
+
+        rows, err := result.RowsAffected()
+        if err != nil {
+            return fmt.Errorf("error getting rows affected: %w", err)
+        }
+        if rows == 0 {
+            // changed or deleted by a concurrent transaction since it was read above
+            current, err := itemInTx(ctx, tx, item.ID, false)
+            if err != nil {
+                return err
+            }
+            return &apperrors.VersionConflictError{Entity: "item", ID: item.ID, Expected: before.Version, Actual: current.Version}
+        }
+
+        after, err := itemInTx(ctx, tx, item.ID, false)
+        if err != nil {
+            return err
+        }
+        item.Version = after.Version
+        return writeAudit(ctx, tx, models.AuditItem, item.ID, models.AuditUpdate, before, after)
+    })
+}
+
+// Dispose records the disposal of an item; its other fields are left as they are
+func (r *ItemRepository) Dispose(ctx context.Context, id int, disposal models.Disposal) error {
+    query := `
+        UPDATE items SET disposed_at = $1, disposal_method = $2, disposal_proceeds = $3, updated_at = $4, version = version + 1
+        WHERE id = $5 AND deleted_at IS NULL
+    `
+    return r.withTx(ctx, func(tx *sql.Tx) error {
+        before, err := itemInTx(ctx, tx, id, false)
+        if err != nil {
+            return err
+        }
+
+        result, err := tx.ExecContext(ctx, query, disposal.DisposedAt, disposal.DisposalMethod, disposal.DisposalProceeds, time.Now(), id)
+        if err != nil {
+            return fmt.Errorf("error disposing item: %w", dbError(err))
+        }
+
+        rows, err := result.RowsAffected()
+        if err != nil {
+            return fmt.Errorf("error getting rows affected: %w", err)
+        }
+        if rows == 0 {
+            return &apperrors.NotFoundError{Entity: "item", ID: id}
+        }
 
-    return nil
+        after, err := itemInTx(ctx, tx, id, false)
+        if err != nil {
+            return err
+        }
+        return writeAudit(ctx, tx, models.AuditItem, id, models.AuditDispose, before, after)
+    })
 }
 
-func (r *ItemRepository) Delete(id int) error {
-    query := `DELETE FROM items WHERE id = $1`
-    result, err := r.db.Exec(query, id)
-    if err != nil {
-        return fmt.Errorf("error deleting item: %w", err)
-    }
+// Delete moves an item to the trash
+func (r *ItemRepository) Delete(ctx context.Context, id int) error {
+    query := `UPDATE items SET deleted_at = $1 WHERE id = $2 AND deleted_at IS NULL`
+    return r.withTx(ctx, func(tx *sql.Tx) error {
+        before, err := itemInTx(ctx, tx, id, false)
+        if err != nil {
+            return err
+        }
+
+        result, err := tx.ExecContext(ctx, query, deletedAt(), id)
+        if err != nil {
+            return fmt.Errorf("error deleting item: %w", dbError(err))
+        }
+
+        rows, err := result.RowsAffected()
+        if err != nil {
+            return fmt.Errorf("error getting rows affected: %w", err)
+        }
+        if rows == 0 {
+            return &apperrors.NotFoundError{Entity: "item", ID: id}
+        }
+
+        return writeAudit(ctx, tx, models.AuditItem, id, models.AuditDelete, before, nil)
+    })
+}
 
-    rows, err := result.RowsAffected()
+// GetDeleted returns the items in the trash, oldest deletion first
+func (r *ItemRepository) GetDeleted(ctx context.Context) ([]models.TrashEntry, error) {
+    query := `SELECT id, name, deleted_at FROM items WHERE deleted_at IS NOT NULL ORDER BY deleted_at, id`
+    return queryTrash(ctx, r.dbtx(), query, models.TrashItem)
+}
+
+// Restore takes an item out of the trash
+func (r *ItemRepository) Restore(ctx context.Context, id int) error {
+    query := `UPDATE items SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL`
+    return r.withTx(ctx, func(tx *sql.Tx) error {
+        result, err := tx.ExecContext(ctx, query, id)
+        if err != nil {
+            return fmt.Errorf("error restoring item: %w", dbError(err))
+        }
+
+        rows, err := result.RowsAffected()
+        if err != nil {
+            return fmt.Errorf("error getting rows affected: %w", err)
+        }
+        if rows == 0 {
+            return &apperrors.NotFoundError{Entity: "deleted item", ID: id}
+        }
+
+        after, err := itemInTx(ctx, tx, id, false)
+        if err != nil {
+            return err
+        }
+        return writeAudit(ctx, tx, models.AuditItem, id, models.AuditRestore, nil, after)
+    })
+}
+
+// Purge permanently deletes the items moved to the trash before before and
+// returns how many there were
+func (r *ItemRepository) Purge(ctx context.Context, before time.Time) (int, error) {
+    query := `
+        SELECT ` + itemColumns + `
+        FROM items i
+        JOIN categories c ON i.category_id = c.id
+        WHERE i.deleted_at IS NOT NULL AND i.deleted_at < $1
+        ORDER BY i.id
+    `
+    var purged []models.Item
+    err := r.withTx(ctx, func(tx *sql.Tx) error {
+        rows, err := tx.QueryContext(ctx, query, before.UTC())
+        if err != nil {
+            return fmt.Errorf("error purging items: %w", dbError(err))
+        }
+        for rows.Next() {
+            var item models.Item
+            if err := scanItem(rows, &item); err != nil {
+                rows.Close()
+                return fmt.Errorf("error scanning item: %w", err)
+            }
+            purged = append(purged, item)
+        }
+        rows.Close()
+        if err := rows.Err(); err != nil {
+            return fmt.Errorf("error purging items: %w", dbError(err))
+        }
+
+        for _, item := range purged {
+            if _, err := tx.ExecContext(ctx, `DELETE FROM items WHERE id = $1`, item.ID); err != nil {
+                return fmt.Errorf("error purging items: %w", dbError(err))
+            }
+            if err := writeAudit(ctx, tx, models.AuditItem, item.ID, models.AuditPurge, item, nil); err != nil {
+                return err
+            }
+        }
+        return nil
+    })
     if err != nil {
-        return fmt.Errorf("error getting rows affected: %w", err)
+        return 0, err
     }
-    if rows == 0 {
-        return fmt.Errorf("item with ID %d not found", id)
-    }
-
-    return nil
+    return len(purged), nil
 }
 
-func (r *ItemRepository) Search(keyword string) ([]models.Item, error) {
+func (r *ItemRepository) Search(ctx context.Context, keyword string) ([]models.Item, error) {
     query := `
-        SELECT i.id, i.name, i.category_id, c.name, i.price, i.purchase_date, i.created_at, i.updated_at
+        SELECT ` + itemColumns + `
         FROM items i
         JOIN categories c ON i.category_id = c.id
-        WHERE LOWER(i.name) LIKE LOWER($1)
+        WHERE LOWER(i.name) LIKE LOWER($1) AND i.deleted_at IS NULL
         ORDER BY i.id
     `
     keyword = "%" + strings.ToLower(keyword) + "%"
-    rows, err := r.db.Query(query, keyword)
+    rows, err := r.dbtx().QueryContext(ctx, query, keyword)
     if err != nil {
-        return nil, fmt.Errorf("error searching items: %w", err)
+        return nil, fmt.Errorf("error searching items: %w", dbError(err))
     }
     defer rows.Close()
 
     var items []models.Item
     for rows.Next() {
         var item models.Item
-        if err := rows.Scan(&item.ID, &item.Name, &item.CategoryID, &item.CategoryName, &item.Price, &item.PurchaseDate, &item.CreatedAt, &item.UpdatedAt); err != nil {
+        if err := scanItem(rows, &item); err != nil {
             return nil, fmt.Errorf("error scanning item: %w", err)
         }
         items = append(items, item)
@@ -132,24 +363,26 @@ func (r *ItemRepository) Search(keyword string) ([]models.Item, error) {
     return items, nil
 }
 
-func (r *ItemRepository) GetItemsNeedReplacement(days int) ([]models.Item, error) {
+// GetItemsNeedReplacement returns the items used more than days days on asOf
+// and not disposed by then, oldest first
+func (r *ItemRepository) GetItemsNeedReplacement(ctx context.Context, days int, asOf time.Time) ([]models.Item, error) {
     query := `
-        SELECT i.id, i.name, i.category_id, c.name, i.price, i.purchase_date, i.created_at, i.updated_at
+        SELECT ` + itemColumns + `
         FROM items i
         JOIN categories c ON i.category_id = c.id
-        WHERE CURRENT_DATE - i.purchase_date > $1
+        WHERE ` + r.daysSincePurchase("$2") + ` > $1 AND ` + r.inUseOn("$2") + ` AND i.deleted_at IS NULL
         ORDER BY i.purchase_date ASC
     `
-    rows, err := r.db.Query(query, days)
+    rows, err := r.dbtx().QueryContext(ctx, query, days, asOf.Format("2006-01-02"))
     if err != nil {
-        return nil, fmt.Errorf("error querying items need replacement: %w", err)
+        return nil, fmt.Errorf("error querying items need replacement: %w", dbError(err))
     }
     defer rows.Close()
 
     var items []models.Item
     for rows.Next() {
         var item models.Item
-        if err := rows.Scan(&item.ID, &item.Name, &item.CategoryID, &item.CategoryName, &item.Price, &item.PurchaseDate, &item.CreatedAt, &item.UpdatedAt); err != nil {
+        if err := scanItem(rows, &item); err != nil {
             return nil, fmt.Errorf("error scanning item: %w", err)
         }
         items = append(items, item)
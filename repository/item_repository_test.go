@@ -1,11 +1,14 @@
 package repository
 
 import (
+    "context"
     "testing"
     "time"
 
     "github.com/DATA-DOG/go-sqlmock"
+	"mini_project3/config"
 	"mini_project3/models"
+	"mini_project3/money"
 )
 
 func TestItemRepository_GetAll(t *testing.T) {
@@ -17,14 +20,14 @@ func TestItemRepository_GetAll(t *testing.T) {
 
     repo := NewItemRepository(db)
 
-    rows := sqlmock.NewRows([]string{"id", "name", "category_id", "category_name", "price", "purchase_date", "created_at", "updated_at"}).
-        AddRow(1, "Laptop", 1, "Elektronik", 15000000.00, time.Now(), time.Now(), time.Now()).
-        AddRow(2, "Meja", 2, "Furniture", 1500000.00, time.Now(), time.Now(), time.Now())
+    rows := sqlmock.NewRows([]string{"id", "name", "category_id", "category_name", "price", "currency", "purchase_date", "depreciation_method", "useful_life_months", "depreciation_rate", "salvage_value", "salvage_percent", "tax_group", "tax_method", "disposed_at", "disposal_method", "disposal_proceeds", "created_at", "updated_at", "version"}).
+        AddRow(1, "Laptop", 1, "Elektronik", 15000000.00, "IDR", time.Now(), "", 0, "0", "0", "0", "", "", nil, "", "0", time.Now(), time.Now(), 1).
+        AddRow(2, "Meja", 2, "Furniture", 1500000.00, "IDR", time.Now(), "", 0, "0", "0", "0", "", "", nil, "", "0", time.Now(), time.Now(), 1)
 
-    mock.ExpectQuery("SELECT i.id, i.name, i.category_id, c.name, i.price, i.purchase_date, i.created_at, i.updated_at FROM items i JOIN categories c").
+    mock.ExpectQuery("SELECT i.id, i.name, i.category_id, c.name, i.price, i.currency, i.purchase_date, i.depreciation_method, i.useful_life_months, i.depreciation_rate, i.salvage_value, i.salvage_percent, i.tax_group, i.tax_method, i.disposed_at, i.disposal_method, i.disposal_proceeds, i.created_at, i.updated_at, i.version FROM items i JOIN categories c").
         WillReturnRows(rows)
 
-    items, err := repo.GetAll()
+    items, err := repo.GetAll(context.Background())
     if err != nil {
         t.Errorf("error was not expected: %s", err)
     }
@@ -51,14 +54,14 @@ func TestItemRepository_GetByID(t *testing.T) {
 
     repo := NewItemRepository(db)
 
-    rows := sqlmock.NewRows([]string{"id", "name", "category_id", "category_name", "price", "purchase_date", "created_at", "updated_at"}).
-        AddRow(1, "Laptop", 1, "Elektronik", 15000000.00, time.Now(), time.Now(), time.Now())
+    rows := sqlmock.NewRows([]string{"id", "name", "category_id", "category_name", "price", "currency", "purchase_date", "depreciation_method", "useful_life_months", "depreciation_rate", "salvage_value", "salvage_percent", "tax_group", "tax_method", "disposed_at", "disposal_method", "disposal_proceeds", "created_at", "updated_at", "version"}).
+        AddRow(1, "Laptop", 1, "Elektronik", 15000000.00, "IDR", time.Now(), "", 0, "0", "0", "0", "", "", nil, "", "0", time.Now(), time.Now(), 1)
 
-    mock.ExpectQuery("SELECT i.id, i.name, i.category_id, c.name, i.price, i.purchase_date, i.created_at, i.updated_at FROM items i JOIN categories c").
+    mock.ExpectQuery("SELECT i.id, i.name, i.category_id, c.name, i.price, i.currency, i.purchase_date, i.depreciation_method, i.useful_life_months, i.depreciation_rate, i.salvage_value, i.salvage_percent, i.tax_group, i.tax_method, i.disposed_at, i.disposal_method, i.disposal_proceeds, i.created_at, i.updated_at, i.version FROM items i JOIN categories c").
         WithArgs(1).
         WillReturnRows(rows)
 
-    item, err := repo.GetByID(1)
+    item, err := repo.GetByID(context.Background(), 1)
     if err != nil {
         t.Errorf("error was not expected: %s", err)
     }
@@ -89,18 +92,27 @@ func TestItemRepository_Create(t *testing.T) {
     rows := sqlmock.NewRows([]string{"id", "created_at"}).
         AddRow(1, time.Now())
 
-    mock.ExpectQuery("INSERT INTO items \\(name, category_id, price, purchase_date, updated_at\\) VALUES").
-        WithArgs("Laptop", 1, 15000000.00, purchaseDate, sqlmock.AnyArg()).
+    mock.ExpectBegin()
+    mock.ExpectQuery("INSERT INTO items \\(name, category_id, price, currency, purchase_date, depreciation_method, useful_life_months, depreciation_rate, salvage_value, salvage_percent, tax_group, tax_method, updated_at\\) VALUES").
+        WithArgs("Laptop", 1, money.FromInt(15000000), "IDR", purchaseDate, "straight-line", 48, money.Rate{}, money.FromInt(1000000), money.Rate{}, "kelompok-1", "", sqlmock.AnyArg()).
         WillReturnRows(rows)
+    mock.ExpectQuery("SELECT i.id, .* FROM items i JOIN categories c ON i.category_id = c.id WHERE i.id = \\$1 AND i.deleted_at IS NULL").
+        WithArgs(1).
+        WillReturnRows(sqlmock.NewRows([]string{"id", "name", "category_id", "category_name", "price", "currency", "purchase_date", "depreciation_method", "useful_life_months", "depreciation_rate", "salvage_value", "salvage_percent", "tax_group", "tax_method", "disposed_at", "disposal_method", "disposal_proceeds", "created_at", "updated_at", "version"}).
+            AddRow(1, "Laptop", 1, "Elektronik", "15000000.00", "IDR", purchaseDate, "straight-line", 48, "0", "1000000.00", "0", "kelompok-1", "", nil, "", "0", time.Now(), time.Now(), 1))
+    expectAudit(mock, models.AuditItem, 1, models.AuditCreate)
+    mock.ExpectCommit()
 
     item := &models.Item{
         Name:         "Laptop",
         CategoryID:   1,
-        Price:        15000000.00,
+        Price:        money.FromInt(15000000),
+        Currency:     "IDR",
         PurchaseDate: purchaseDate,
+        DepreciationPolicy: models.DepreciationPolicy{Method: "straight-line", UsefulLifeMonths: 48, SalvageValue: money.FromInt(1000000), TaxGroup: "kelompok-1"},
     }
 
-    err = repo.Create(item)
+    err = repo.Create(context.Background(), item)
     if err != nil {
         t.Errorf("error was not expected: %s", err)
     }
@@ -123,15 +135,15 @@ func TestItemRepository_Search(t *testing.T) {
 
     repo := NewItemRepository(db)
 
-    rows := sqlmock.NewRows([]string{"id", "name", "category_id", "category_name", "price", "purchase_date", "created_at", "updated_at"}).
-        AddRow(1, "Laptop Dell", 1, "Elektronik", 15000000.00, time.Now(), time.Now(), time.Now()).
-        AddRow(2, "Laptop HP", 1, "Elektronik", 12000000.00, time.Now(), time.Now(), time.Now())
+    rows := sqlmock.NewRows([]string{"id", "name", "category_id", "category_name", "price", "currency", "purchase_date", "depreciation_method", "useful_life_months", "depreciation_rate", "salvage_value", "salvage_percent", "tax_group", "tax_method", "disposed_at", "disposal_method", "disposal_proceeds", "created_at", "updated_at", "version"}).
+        AddRow(1, "Laptop Dell", 1, "Elektronik", 15000000.00, "IDR", time.Now(), "", 0, "0", "0", "0", "", "", nil, "", "0", time.Now(), time.Now(), 1).
+        AddRow(2, "Laptop HP", 1, "Elektronik", 12000000.00, "IDR", time.Now(), "", 0, "0", "0", "0", "", "", nil, "", "0", time.Now(), time.Now(), 1)
 
-    mock.ExpectQuery("SELECT i.id, i.name, i.category_id, c.name, i.price, i.purchase_date, i.created_at, i.updated_at FROM items i JOIN categories c").
+    mock.ExpectQuery("SELECT i.id, i.name, i.category_id, c.name, i.price, i.currency, i.purchase_date, i.depreciation_method, i.useful_life_months, i.depreciation_rate, i.salvage_value, i.salvage_percent, i.tax_group, i.tax_method, i.disposed_at, i.disposal_method, i.disposal_proceeds, i.created_at, i.updated_at, i.version FROM items i JOIN categories c").
         WithArgs("%laptop%").
         WillReturnRows(rows)
 
-    items, err := repo.Search("laptop")
+    items, err := repo.Search(context.Background(), "laptop")
     if err != nil {
         t.Errorf("error was not expected: %s", err)
     }
@@ -155,14 +167,14 @@ func TestItemRepository_GetItemsNeedReplacement(t *testing.T) {
     repo := NewItemRepository(db)
 
     oldDate := time.Now().AddDate(0, 0, -150)
-    rows := sqlmock.NewRows([]string{"id", "name", "category_id", "category_name", "price", "purchase_date", "created_at", "updated_at"}).
-        AddRow(1, "Old Laptop", 1, "Elektronik", 15000000.00, oldDate, time.Now(), time.Now())
+    rows := sqlmock.NewRows([]string{"id", "name", "category_id", "category_name", "price", "currency", "purchase_date", "depreciation_method", "useful_life_months", "depreciation_rate", "salvage_value", "salvage_percent", "tax_group", "tax_method", "disposed_at", "disposal_method", "disposal_proceeds", "created_at", "updated_at", "version"}).
+        AddRow(1, "Old Laptop", 1, "Elektronik", 15000000.00, "IDR", oldDate, "", 0, "0", "0", "0", "", "", nil, "", "0", time.Now(), time.Now(), 1)
 
-    mock.ExpectQuery("SELECT i.id, i.name, i.category_id, c.name, i.price, i.purchase_date, i.created_at, i.updated_at FROM items i JOIN categories c").
-        WithArgs(100).
+    mock.ExpectQuery("SELECT i.id, i.name, i.category_id, c.name, i.price, i.currency, i.purchase_date, i.depreciation_method, i.useful_life_months, i.depreciation_rate, i.salvage_value, i.salvage_percent, i.tax_group, i.tax_method, i.disposed_at, i.disposal_method, i.disposal_proceeds, i.created_at, i.updated_at, i.version FROM items i JOIN categories c").
+        WithArgs(100, "2026-01-15").
         WillReturnRows(rows)
 
-    items, err := repo.GetItemsNeedReplacement(100)
+    items, err := repo.GetItemsNeedReplacement(context.Background(), 100, time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC))
     if err != nil {
         t.Errorf("error was not expected: %s", err)
     }
@@ -174,4 +186,28 @@ func TestItemRepository_GetItemsNeedReplacement(t *testing.T) {
     if err := mock.ExpectationsWereMet(); err != nil {
         t.Errorf("there were unfulfilled expectations: %s", err)
     }
-}
\ No newline at end of file
+}
+func TestItemRepository_GetItemsNeedReplacement_SQLite(t *testing.T) {
+    db, mock, err := sqlmock.New()
+    if err != nil {
+        t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+    }
+    defer db.Close()
+
+    repo := NewItemRepositoryWithDriver(db, config.DriverSQLite)
+
+    rows := sqlmock.NewRows([]string{"id", "name", "category_id", "category_name", "price", "currency", "purchase_date", "depreciation_method", "useful_life_months", "depreciation_rate", "salvage_value", "salvage_percent", "tax_group", "tax_method", "disposed_at", "disposal_method", "disposal_proceeds", "created_at", "updated_at", "version"})
+
+    mock.ExpectQuery("WHERE CAST\\(julianday\\(date\\(\\$2\\)\\) - julianday\\(date\\(i.purchase_date\\)\\) AS INTEGER\\) > \\$1 " +
+        "AND \\(i.disposed_at IS NULL OR date\\(i.disposed_at\\) > date\\(\\$2\\)\\) AND i.deleted_at IS NULL").
+        WithArgs(100, "2026-01-15").
+        WillReturnRows(rows)
+
+    if _, err := repo.GetItemsNeedReplacement(context.Background(), 100, time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)); err != nil {
+        t.Errorf("error was not expected: %s", err)
+    }
+
+    if err := mock.ExpectationsWereMet(); err != nil {
+        t.Errorf("there were unfulfilled expectations: %s", err)
+    }
+}
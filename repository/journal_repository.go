@@ -0,0 +1,93 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+
+    "mini_project3/apperrors"
+    "mini_project3/models"
+)
+
+type JournalRepository struct {
+    db *sql.DB
+}
+
+func NewJournalRepository(db *sql.DB) *JournalRepository {
+    return &JournalRepository{db: db}
+}
+
+// Get returns the posted journal of month with its lines in order, or nil when month is not posted
+func (r *JournalRepository) Get(ctx context.Context, month string) (*models.Journal, error) {
+    journal := &models.Journal{Month: month, Posted: true, Lines: []models.JournalLine{}}
+    var postedAt sql.NullTime
+    err := r.db.QueryRowContext(ctx, `SELECT currency, posted_at FROM journal_periods WHERE month = $1`, month).
+        Scan(&journal.Currency, &postedAt)
+    if err == sql.ErrNoRows {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("error querying journal period: %w", dbError(err))
+    }
+    journal.PostedAt = &postedAt.Time
+
+    query := `
+        SELECT category_id, category_name, account, description, debit, credit
+        FROM journal_lines
+        WHERE month = $1
+        ORDER BY line_no
+    `
+    rows, err := r.db.QueryContext(ctx, query, month)
+    if err != nil {
+        return nil, fmt.Errorf("error querying journal lines: %w", dbError(err))
+    }
+    defer rows.Close()
+
+    for rows.Next() {
+        line := models.JournalLine{Month: month}
+        if err := rows.Scan(&line.CategoryID, &line.CategoryName, &line.Account, &line.Description, &line.Debit, &line.Credit); err != nil {
+            return nil, fmt.Errorf("error scanning journal line: %w", err)
+        }
+        journal.Lines = append(journal.Lines, line)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("error querying journal lines: %w", dbError(err))
+    }
+
+    return journal, nil
+}
+
+// Post stores journal and locks its month in one transaction; a month that
+// is already posted fails with apperrors.PeriodLockedError
+func (r *JournalRepository) Post(ctx context.Context, journal *models.Journal) error {
+    tx, err := r.db.BeginTx(ctx, nil)
+    if err != nil {
+        return fmt.Errorf("error starting transaction: %w", dbError(err))
+    }
+    defer tx.Rollback()
+
+    _, err = tx.ExecContext(ctx, `INSERT INTO journal_periods (month, currency, posted_at) VALUES ($1, $2, $3)`,
+        journal.Month, journal.Currency, *journal.PostedAt)
+    if err != nil {
+        if isUniqueViolation(err) {
+            return fmt.Errorf("error posting journal: %w", &apperrors.PeriodLockedError{Month: journal.Month})
+        }
+        return fmt.Errorf("error posting journal: %w", dbError(err))
+    }
+
+    query := `
+        INSERT INTO journal_lines (month, line_no, category_id, category_name, account, description, debit, credit)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+    `
+    for i, line := range journal.Lines {
+        _, err := tx.ExecContext(ctx, query, journal.Month, i+1, line.CategoryID, line.CategoryName, line.Account, line.Description, line.Debit, line.Credit)
+        if err != nil {
+            return fmt.Errorf("error posting journal line: %w", dbError(err))
+        }
+    }
+
+    if err := tx.Commit(); err != nil {
+        return fmt.Errorf("error committing journal: %w", dbError(err))
+    }
+    return nil
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "testing"
+    "time"
+
+    "github.com/DATA-DOG/go-sqlmock"
+    "mini_project3/models"
+    "mini_project3/money"
+)
+
+func TestJournalRepository_Get_NotPosted(t *testing.T) {
+    db, mock, err := sqlmock.New()
+    if err != nil {
+        t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+    }
+    defer db.Close()
+
+    repo := NewJournalRepository(db)
+
+    mock.ExpectQuery("SELECT currency, posted_at FROM journal_periods WHERE month = \\$1").
+        WithArgs("2025-11").
+        WillReturnError(sql.ErrNoRows)
+
+    journal, err := repo.Get(context.Background(), "2025-11")
+    if journal != nil || err != nil {
+        t.Errorf("expected no journal for a month that is not posted, got %+v (%v)", journal, err)
+    }
+
+    if err := mock.ExpectationsWereMet(); err != nil {
+        t.Errorf("there were unfulfilled expectations: %s", err)
+    }
+}
+
+func TestJournalRepository_Post_RollsBackOnError(t *testing.T) {
+    db, mock, err := sqlmock.New()
+    if err != nil {
+        t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+    }
+    defer db.Close()
+
+    repo := NewJournalRepository(db)
+    postedAt := time.Date(2025, 12, 2, 8, 0, 0, 0, time.UTC)
+    journal := &models.Journal{Month: "2025-11", Currency: "IDR", PostedAt: &postedAt, Lines: []models.JournalLine{
+        {CategoryID: 1, CategoryName: "Elektronik", Account: "6-1100", Description: "Penyusutan Elektronik 2025-11", Debit: money.FromInt(250000)},
+        {CategoryID: 1, CategoryName: "Elektronik", Account: "1-2900", Description: "Penyusutan Elektronik 2025-11", Credit: money.FromInt(250000)},
+    }}
+
+    mock.ExpectBegin()
+    mock.ExpectExec("INSERT INTO journal_periods").
+        WithArgs("2025-11", "IDR", postedAt).
+        WillReturnResult(sqlmock.NewResult(0, 1))
+    mock.ExpectExec("INSERT INTO journal_lines").
+        WithArgs("2025-11", 1, 1, "Elektronik", "6-1100", "Penyusutan Elektronik 2025-11", "250000.00", "0.00").
+        WillReturnResult(sqlmock.NewResult(0, 1))
+    mock.ExpectExec("INSERT INTO journal_lines").
+        WithArgs("2025-11", 2, 1, "Elektronik", "1-2900", "Penyusutan Elektronik 2025-11", "0.00", "250000.00").
+        WillReturnError(errors.New("disk full"))
+    mock.ExpectRollback()
+
+    if err := repo.Post(context.Background(), journal); err == nil {
+        t.Error("expected error when a journal line fails")
+    }
+
+    if err := mock.ExpectationsWereMet(); err != nil {
+        t.Errorf("there were unfulfilled expectations: %s", err)
+    }
+}
@@ -0,0 +1,835 @@
+package repository
+
+import (
+    "context"
+    "fmt"
+    "maps"
+    "regexp"
+    "sort"
+    "strings"
+    "sync"
+    "time"
+
+    "mini_project3/apperrors"
+    "mini_project3/models"
+)
+
+// MemoryStore holds the categories, items, exchange_rates and journal tables shared by
+// the memory repositories. It mirrors the
+// PostgreSQL schema: category names are unique, items must reference an
+// existing category and a category cannot be deleted while items use it.
+// Deleted rows stay in their table with their deleted_at time in
+// deletedCategories or deletedItems until they are purged. Every change of
+// a category or item is appended to audit under the same lock.
+// Operations never block, so the repositories only check the context on entry.
+type MemoryStore struct {
+    mu                sync.RWMutex
+    txMu              sync.Mutex // held by the unit of work running, see MemoryTxManager
+    categories        map[int]models.Category
+    items             map[int]models.Item
+    deletedCategories map[int]time.Time
+    deletedItems      map[int]time.Time
+    exchangeRates     map[rateKey]models.ExchangeRate
+    journals          map[string]models.Journal
+    audit             []models.AuditEntry
+    nextCategoryID    int
+    nextItemID        int
+}
+
+func NewMemoryStore() *MemoryStore {
+    return &MemoryStore{
+        categories:        map[int]models.Category{},
+        items:             map[int]models.Item{},
+        deletedCategories: map[int]time.Time{},
+        deletedItems:      map[int]time.Time{},
+        exchangeRates:     map[rateKey]models.ExchangeRate{},
+        journals:          map[string]models.Journal{},
+        nextCategoryID:    1,
+        nextItemID:        1,
+    }
+}
+
+// withCategoryName fills CategoryName like the JOIN in ItemRepository; callers hold the lock
+func (s *MemoryStore) withCategoryName(item models.Item) models.Item {
+    item.CategoryName = s.categories[item.CategoryID].Name
+    return item
+}
+
+// liveCategory returns the category with id unless it is missing or deleted; callers hold the lock
+func (s *MemoryStore) liveCategory(id int) (models.Category, bool) {
+    cat, ok := s.categories[id]
+    if _, deleted := s.deletedCategories[id]; deleted {
+        return models.Category{}, false
+    }
+    return cat, ok
+}
+
+// liveItem returns the item with id unless it is missing or deleted; callers hold the lock
+func (s *MemoryStore) liveItem(id int) (models.Item, bool) {
+    item, ok := s.items[id]
+    if _, deleted := s.deletedItems[id]; deleted {
+        return models.Item{}, false
+    }
+    return item, ok
+}
+
+// trash returns the deleted rows of entity, oldest deletion first; callers hold the lock
+func trash(deleted map[int]time.Time, entity string, name func(id int) string) []models.TrashEntry {
+    var entries []models.TrashEntry
+    for id, deletedAt := range deleted {
+        entries = append(entries, models.TrashEntry{Entity: entity, ID: id, Name: name(id), DeletedAt: deletedAt.Local()})
+    }
+    sort.Slice(entries, func(i, j int) bool {
+        if entries[i].DeletedAt.Equal(entries[j].DeletedAt) {
+            return entries[i].ID < entries[j].ID
+        }
+        return entries[i].DeletedAt.Before(entries[j].DeletedAt)
+    })
+    return entries
+}
+
+// record appends the change of entity id made in ctx to the audit log, see
+// newAuditEntry; callers hold the write lock and apply the change only when it succeeds
+func (s *MemoryStore) record(ctx context.Context, entity string, id int, action string, before, after interface{}) error {
+    entry, err := newAuditEntry(ctx, entity, id, action, before, after)
+    if err != nil {
+        return err
+    }
+    entry.ID = len(s.audit) + 1
+    s.audit = append(s.audit, entry)
+    return nil
+}
+
+// sortedItems returns the live items matching keep, joined with their category and ordered by less
+func (s *MemoryStore) sortedItems(keep func(models.Item) bool, less func(a, b models.Item) bool) []models.Item {
+    var items []models.Item
+    for _, item := range s.items {
+        if _, deleted := s.deletedItems[item.ID]; !deleted && keep(item) {
+            items = append(items, s.withCategoryName(item))
+        }
+    }
+    sort.Slice(items, func(i, j int) bool { return less(items[i], items[j]) })
+    return items
+}
+
+func byItemID(a, b models.Item) bool {
+    return a.ID < b.ID
+}
+
+// ==================== CATEGORIES ====================
+
+type MemoryCategoryRepository struct {
+    store *MemoryStore
+}
+
+func NewMemoryCategoryRepository(store *MemoryStore) *MemoryCategoryRepository {
+    return &MemoryCategoryRepository{store: store}
+}
+
+func (r *MemoryCategoryRepository) GetAll(ctx context.Context) ([]models.Category, error) {
+    if err := ctx.Err(); err != nil {
+        return nil, err
+    }
+
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    var categories []models.Category
+    for _, cat := range r.store.categories {
+        if _, deleted := r.store.deletedCategories[cat.ID]; !deleted {
+            categories = append(categories, cat)
+        }
+    }
+    sort.Slice(categories, func(i, j int) bool { return categories[i].ID < categories[j].ID })
+    return categories, nil
+}
+
+func (r *MemoryCategoryRepository) GetByID(ctx context.Context, id int) (*models.Category, error) {
+    if err := ctx.Err(); err != nil {
+        return nil, err
+    }
+
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    cat, ok := r.store.liveCategory(id)
+    if !ok {
+        return nil, &apperrors.NotFoundError{Entity: "category", ID: id}
+    }
+    return &cat, nil
+}
+
+// nameTaken reports whether another category already uses name. Like the
+// UNIQUE constraint, categories in the trash count. Callers hold the lock.
+func (r *MemoryCategoryRepository) nameTaken(name string, excludeID int) bool {
+    for _, cat := range r.store.categories {
+        if cat.Name == name && cat.ID != excludeID {
+            return true
+        }
+    }
+    return false
+}
+
+func (r *MemoryCategoryRepository) Create(ctx context.Context, cat *models.Category) error {
+    if err := ctx.Err(); err != nil {
+        return err
+    }
+
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    if r.nameTaken(cat.Name, 0) {
+        return fmt.Errorf("error creating category: %w", &apperrors.DuplicateNameError{Entity: "category", Name: cat.Name})
+    }
+
+    now := time.Now()
+    created := *cat
+    created.ID = r.store.nextCategoryID
+    created.CreatedAt = now
+    created.UpdatedAt = now
+    created.Version = 1
+    if err := r.store.record(ctx, models.AuditCategory, created.ID, models.AuditCreate, nil, created); err != nil {
+        return err
+    }
+
+    *cat = created
+    r.store.nextCategoryID++
+    r.store.categories[cat.ID] = created
+    return nil
+}
+
+func (r *MemoryCategoryRepository) Update(ctx context.Context, cat *models.Category) error {
+    if err := ctx.Err(); err != nil {
+        return err
+    }
+
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    existing, ok := r.store.liveCategory(cat.ID)
+    if !ok {
+        return &apperrors.NotFoundError{Entity: "category", ID: cat.ID}
+    }
+    if err := checkVersion("category", cat.ID, cat.Version, existing.Version); err != nil {
+        return err
+    }
+    if r.nameTaken(cat.Name, cat.ID) {
+        return fmt.Errorf("error updating category: %w", &apperrors.DuplicateNameError{Entity: "category", Name: cat.Name})
+    }
+
+    updated := existing
+    updated.Name = cat.Name
+    updated.Description = cat.Description
+    updated.DepreciationPolicy = cat.DepreciationPolicy
+    updated.JournalAccounts = cat.JournalAccounts
+    updated.UpdatedAt = time.Now()
+    updated.Version++
+    if err := r.store.record(ctx, models.AuditCategory, cat.ID, models.AuditUpdate, existing, updated); err != nil {
+        return err
+    }
+    cat.Version = updated.Version
+    r.store.categories[cat.ID] = updated
+    return nil
+}
+
+func (r *MemoryCategoryRepository) Delete(ctx context.Context, id int) error {
+    if err := ctx.Err(); err != nil {
+        return err
+    }
+
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    existing, ok := r.store.liveCategory(id)
+    if !ok {
+        return &apperrors.NotFoundError{Entity: "category", ID: id}
+    }
+    if err := r.inUse(id); err != nil {
+        return fmt.Errorf("error deleting category: %w", err)
+    }
+
+    if err := r.store.record(ctx, models.AuditCategory, id, models.AuditDelete, existing, nil); err != nil {
+        return err
+    }
+    r.store.deletedCategories[id] = deletedAt()
+    return nil
+}
+
+// inUse mirrors ON DELETE RESTRICT, counting the items in the trash too; callers hold the lock
+func (r *MemoryCategoryRepository) inUse(id int) error {
+    used, inTrash := false, true
+    for _, item := range r.store.items {
+        if item.CategoryID == id {
+            used = true
+            if _, deleted := r.store.deletedItems[item.ID]; !deleted {
+                inTrash = false
+            }
+        }
+    }
+    if used {
+        return &apperrors.CategoryInUseError{ID: id, InTrash: inTrash}
+    }
+    return nil
+}
+
+func (r *MemoryCategoryRepository) GetDeleted(ctx context.Context) ([]models.TrashEntry, error) {
+    if err := ctx.Err(); err != nil {
+        return nil, err
+    }
+
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    name := func(id int) string { return r.store.categories[id].Name }
+    return trash(r.store.deletedCategories, models.TrashCategory, name), nil
+}
+
+func (r *MemoryCategoryRepository) Restore(ctx context.Context, id int) error {
+    if err := ctx.Err(); err != nil {
+        return err
+    }
+
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    if _, ok := r.store.deletedCategories[id]; !ok {
+        return &apperrors.NotFoundError{Entity: "deleted category", ID: id}
+    }
+    if err := r.store.record(ctx, models.AuditCategory, id, models.AuditRestore, nil, r.store.categories[id]); err != nil {
+        return err
+    }
+    delete(r.store.deletedCategories, id)
+    return nil
+}
+
+func (r *MemoryCategoryRepository) Purge(ctx context.Context, before time.Time) (int, error) {
+    if err := ctx.Err(); err != nil {
+        return 0, err
+    }
+
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    var ids []int
+    for id, deletedAt := range r.store.deletedCategories {
+        if deletedAt.Before(before) {
+            if err := r.inUse(id); err != nil {
+                return 0, fmt.Errorf("error purging categories: %w", err)
+            }
+            ids = append(ids, id)
+        }
+    }
+    sort.Ints(ids)
+    for _, id := range ids {
+        if err := r.store.record(ctx, models.AuditCategory, id, models.AuditPurge, r.store.categories[id], nil); err != nil {
+            return 0, err
+        }
+        delete(r.store.categories, id)
+        delete(r.store.deletedCategories, id)
+    }
+    return len(ids), nil
+}
+
+func (r *MemoryCategoryRepository) CheckNameExists(ctx context.Context, name string, excludeID int) (bool, error) {
+    if err := ctx.Err(); err != nil {
+        return false, err
+    }
+
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    return r.nameTaken(name, excludeID), nil
+}
+
+// ==================== ITEMS ====================
+
+type MemoryItemRepository struct {
+    store *MemoryStore
+}
+
+func NewMemoryItemRepository(store *MemoryStore) *MemoryItemRepository {
+    return &MemoryItemRepository{store: store}
+}
+
+func (r *MemoryItemRepository) GetAll(ctx context.Context) ([]models.Item, error) {
+    if err := ctx.Err(); err != nil {
+        return nil, err
+    }
+
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    keepAll := func(models.Item) bool { return true }
+    return r.store.sortedItems(keepAll, byItemID), nil
+}
+
+func (r *MemoryItemRepository) GetByID(ctx context.Context, id int) (*models.Item, error) {
+    if err := ctx.Err(); err != nil {
+        return nil, err
+    }
+
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    item, ok := r.store.liveItem(id)
+    if !ok {
+        return nil, &apperrors.NotFoundError{Entity: "item", ID: id}
+    }
+    item = r.store.withCategoryName(item)
+    return &item, nil
+}
+
+func (r *MemoryItemRepository) Create(ctx context.Context, item *models.Item) error {
+    if err := ctx.Err(); err != nil {
+        return err
+    }
+
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    if _, ok := r.store.categories[item.CategoryID]; !ok {
+        return fmt.Errorf("error creating item: %w", &apperrors.NotFoundError{Entity: "category", ID: item.CategoryID})
+    }
+
+    now := time.Now()
+    stored := *item
+    stored.ID = r.store.nextItemID
+    stored.CreatedAt = now
+    stored.UpdatedAt = now
+    stored.Version = 1
+    stored.CategoryName = ""
+    stored.Disposal = models.Disposal{}
+    if err := r.store.record(ctx, models.AuditItem, stored.ID, models.AuditCreate, nil, r.store.withCategoryName(stored)); err != nil {
+        return err
+    }
+
+    item.ID, item.CreatedAt, item.UpdatedAt, item.Version = stored.ID, now, now, stored.Version
+    r.store.nextItemID++
+    r.store.items[item.ID] = stored
+    return nil
+}
+
+func (r *MemoryItemRepository) Update(ctx context.Context, item *models.Item) error {
+    if err := ctx.Err(); err != nil {
+        return err
+    }
+
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    existing, ok := r.store.liveItem(item.ID)
+    if !ok {
+        return &apperrors.NotFoundError{Entity: "item", ID: item.ID}
+    }
+    if err := checkVersion("item", item.ID, item.Version, existing.Version); err != nil {
+        return err
+    }
+    if _, ok := r.store.categories[item.CategoryID]; !ok {
+        return fmt.Errorf("error updating item: %w", &apperrors.NotFoundError{Entity: "category", ID: item.CategoryID})
+    }
+
+    updated := existing
+    updated.Name = item.Name
+    updated.CategoryID = item.CategoryID
+    updated.Price = item.Price
+    updated.Currency = item.Currency
+    updated.PurchaseDate = item.PurchaseDate
+    updated.DepreciationPolicy = item.DepreciationPolicy
+    updated.UpdatedAt = time.Now()
+    updated.Version++
+    if err := r.store.record(ctx, models.AuditItem, item.ID, models.AuditUpdate, r.store.withCategoryName(existing), r.store.withCategoryName(updated)); err != nil {
+        return err
+    }
+    item.Version = updated.Version
+    r.store.items[item.ID] = updated
+    return nil
+}
+
+func (r *MemoryItemRepository) Dispose(ctx context.Context, id int, disposal models.Disposal) error {
+    if err := ctx.Err(); err != nil {
+        return err
+    }
+
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    existing, ok := r.store.liveItem(id)
+    if !ok {
+        return &apperrors.NotFoundError{Entity: "item", ID: id}
+    }
+    disposedAt := *disposal.DisposedAt
+    disposal.DisposedAt = &disposedAt
+    updated := existing
+    updated.Disposal = disposal
+    updated.UpdatedAt = time.Now()
+    updated.Version++
+    if err := r.store.record(ctx, models.AuditItem, id, models.AuditDispose, r.store.withCategoryName(existing), r.store.withCategoryName(updated)); err != nil {
+        return err
+    }
+    r.store.items[id] = updated
+    return nil
+}
+
+func (r *MemoryItemRepository) Delete(ctx context.Context, id int) error {
+    if err := ctx.Err(); err != nil {
+        return err
+    }
+
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    existing, ok := r.store.liveItem(id)
+    if !ok {
+        return &apperrors.NotFoundError{Entity: "item", ID: id}
+    }
+    if err := r.store.record(ctx, models.AuditItem, id, models.AuditDelete, r.store.withCategoryName(existing), nil); err != nil {
+        return err
+    }
+    r.store.deletedItems[id] = deletedAt()
+    return nil
+}
+
+func (r *MemoryItemRepository) GetDeleted(ctx context.Context) ([]models.TrashEntry, error) {
+    if err := ctx.Err(); err != nil {
+        return nil, err
+    }
+
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    name := func(id int) string { return r.store.items[id].Name }
+    return trash(r.store.deletedItems, models.TrashItem, name), nil
+}
+
+func (r *MemoryItemRepository) Restore(ctx context.Context, id int) error {
+    if err := ctx.Err(); err != nil {
+        return err
+    }
+
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    if _, ok := r.store.deletedItems[id]; !ok {
+        return &apperrors.NotFoundError{Entity: "deleted item", ID: id}
+    }
+    if err := r.store.record(ctx, models.AuditItem, id, models.AuditRestore, nil, r.store.withCategoryName(r.store.items[id])); err != nil {
+        return err
+    }
+    delete(r.store.deletedItems, id)
+    return nil
+}
+
+func (r *MemoryItemRepository) Purge(ctx context.Context, before time.Time) (int, error) {
+    if err := ctx.Err(); err != nil {
+        return 0, err
+    }
+
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    var ids []int
+    for id, deletedAt := range r.store.deletedItems {
+        if deletedAt.Before(before) {
+            ids = append(ids, id)
+        }
+    }
+    sort.Ints(ids)
+    for _, id := range ids {
+        if err := r.store.record(ctx, models.AuditItem, id, models.AuditPurge, r.store.withCategoryName(r.store.items[id]), nil); err != nil {
+            return 0, err
+        }
+        delete(r.store.items, id)
+        delete(r.store.deletedItems, id)
+    }
+    return len(ids), nil
+}
+
+// likePattern compiles a SQL LIKE pattern (% and _ wildcards) into a case-insensitive regexp
+func likePattern(pattern string) *regexp.Regexp {
+    var sb strings.Builder
+    sb.WriteString("(?is)^")
+    for _, ch := range pattern {
+        switch ch {
+        case '%':
+            sb.WriteString(".*")
+        case '_':
+            sb.WriteString(".")
+        default:
+            sb.WriteString(regexp.QuoteMeta(string(ch)))
+        }
+    }
+    sb.WriteString("$")
+    return regexp.MustCompile(sb.String())
+}
+
+func (r *MemoryItemRepository) Search(ctx context.Context, keyword string) ([]models.Item, error) {
+    if err := ctx.Err(); err != nil {
+        return nil, err
+    }
+
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    re := likePattern("%" + strings.ToLower(keyword) + "%")
+    matches := func(item models.Item) bool { return re.MatchString(strings.ToLower(item.Name)) }
+    return r.store.sortedItems(matches, byItemID), nil
+}
+
+// daysBetween counts whole calendar days from a to b, like PostgreSQL's date - date
+func daysBetween(a, b time.Time) int {
+    da := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+    db := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+    return int(db.Sub(da).Hours() / 24)
+}
+
+func (r *MemoryItemRepository) GetItemsNeedReplacement(ctx context.Context, days int, asOf time.Time) ([]models.Item, error) {
+    if err := ctx.Err(); err != nil {
+        return nil, err
+    }
+
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    old := func(item models.Item) bool {
+        return daysBetween(item.PurchaseDate, asOf) > days && !item.DisposedBy(asOf)
+    }
+    byPurchaseDate := func(a, b models.Item) bool {
+        if a.PurchaseDate.Equal(b.PurchaseDate) {
+            return a.ID < b.ID
+        }
+        return a.PurchaseDate.Before(b.PurchaseDate)
+    }
+    return r.store.sortedItems(old, byPurchaseDate), nil
+}
+
+
+// ==================== EXCHANGE RATES ====================
+
+// rateKey is the primary key of exchange_rates: one rate per currency per day
+type rateKey struct {
+    currency string
+    day      string
+}
+
+func newRateKey(currency string, date time.Time) rateKey {
+    return rateKey{currency: currency, day: date.Format("2006-01-02")}
+}
+
+type MemoryExchangeRateRepository struct {
+    store *MemoryStore
+}
+
+func NewMemoryExchangeRateRepository(store *MemoryStore) *MemoryExchangeRateRepository {
+    return &MemoryExchangeRateRepository{store: store}
+}
+
+func (r *MemoryExchangeRateRepository) Set(ctx context.Context, rate *models.ExchangeRate) error {
+    if err := ctx.Err(); err != nil {
+        return err
+    }
+
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    rate.CreatedAt = time.Now()
+    r.store.exchangeRates[newRateKey(rate.Currency, rate.Date)] = *rate
+    return nil
+}
+
+func (r *MemoryExchangeRateRepository) GetAll(ctx context.Context, currency string) ([]models.ExchangeRate, error) {
+    if err := ctx.Err(); err != nil {
+        return nil, err
+    }
+
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    var rates []models.ExchangeRate
+    for key, rate := range r.store.exchangeRates {
+        if currency == "" || key.currency == currency {
+            rates = append(rates, rate)
+        }
+    }
+    sort.Slice(rates, func(i, j int) bool {
+        if rates[i].Currency != rates[j].Currency {
+            return rates[i].Currency < rates[j].Currency
+        }
+        return rates[i].Date.Before(rates[j].Date)
+    })
+    return rates, nil
+}
+
+func (r *MemoryExchangeRateRepository) GetOnOrBefore(ctx context.Context, currency string, date time.Time) (*models.ExchangeRate, error) {
+    if err := ctx.Err(); err != nil {
+        return nil, err
+    }
+
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    day := date.Format("2006-01-02")
+    var latest *models.ExchangeRate
+    for key, rate := range r.store.exchangeRates {
+        if key.currency != currency || key.day > day {
+            continue
+        }
+        if latest == nil || key.day > latest.Date.Format("2006-01-02") {
+            rate := rate
+            latest = &rate
+        }
+    }
+    if latest == nil {
+        return nil, &apperrors.RateNotFoundError{Currency: currency, Date: date}
+    }
+    return latest, nil
+}
+
+// ==================== AUDIT LOG ====================
+
+type MemoryAuditRepository struct {
+    store *MemoryStore
+}
+
+func NewMemoryAuditRepository(store *MemoryStore) *MemoryAuditRepository {
+    return &MemoryAuditRepository{store: store}
+}
+
+func (r *MemoryAuditRepository) List(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, error) {
+    if err := ctx.Err(); err != nil {
+        return nil, err
+    }
+
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    var entries []models.AuditEntry
+    for _, entry := range r.store.audit {
+        if (filter.Entity != "" && entry.Entity != filter.Entity) || (filter.EntityID != 0 && entry.EntityID != filter.EntityID) || entry.At.Before(filter.Since) {
+            continue
+        }
+        entry.At = entry.At.Local()
+        entries = append(entries, entry)
+    }
+    return entries, nil
+}
+
+// ==================== JOURNALS ====================
+
+type MemoryJournalRepository struct {
+    store *MemoryStore
+}
+
+func NewMemoryJournalRepository(store *MemoryStore) *MemoryJournalRepository {
+    return &MemoryJournalRepository{store: store}
+}
+
+// copyJournal keeps callers from sharing the lines slice of the store
+func copyJournal(journal models.Journal) *models.Journal {
+    journal.Lines = append([]models.JournalLine(nil), journal.Lines...)
+    return &journal
+}
+
+func (r *MemoryJournalRepository) Get(ctx context.Context, month string) (*models.Journal, error) {
+    if err := ctx.Err(); err != nil {
+        return nil, err
+    }
+
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    journal, ok := r.store.journals[month]
+    if !ok {
+        return nil, nil
+    }
+    return copyJournal(journal), nil
+}
+
+func (r *MemoryJournalRepository) Post(ctx context.Context, journal *models.Journal) error {
+    if err := ctx.Err(); err != nil {
+        return err
+    }
+
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    if posted, ok := r.store.journals[journal.Month]; ok {
+        return fmt.Errorf("error posting journal: %w", &apperrors.PeriodLockedError{Month: journal.Month, PostedAt: *posted.PostedAt})
+    }
+    // keep only what journal_periods and journal_lines store
+    postedAt := *journal.PostedAt
+    stored := models.Journal{Month: journal.Month, Currency: journal.Currency, Posted: true, PostedAt: &postedAt, Lines: []models.JournalLine{}}
+    for _, line := range journal.Lines {
+        stored.Lines = append(stored.Lines, models.JournalLine{Month: journal.Month, CategoryID: line.CategoryID, CategoryName: line.CategoryName,
+            Account: line.Account, Description: line.Description, Debit: line.Debit, Credit: line.Credit})
+    }
+    r.store.journals[journal.Month] = stored
+    return nil
+}
+
+// ==================== UNITS OF WORK ====================
+
+// memorySnapshot is the content of a MemoryStore that a unit of work restores when it rolls back
+type memorySnapshot struct {
+    categories        map[int]models.Category
+    items             map[int]models.Item
+    deletedCategories map[int]time.Time
+    deletedItems      map[int]time.Time
+    exchangeRates     map[rateKey]models.ExchangeRate
+    journals          map[string]models.Journal
+    audit             []models.AuditEntry
+    nextCategoryID    int
+    nextItemID        int
+}
+
+// snapshot copies the tables of the store; rows are values, so copying the maps is enough
+func (s *MemoryStore) snapshot() memorySnapshot {
+    s.mu.RLock()
+    defer s.mu.RUnlock()
+
+    return memorySnapshot{
+        categories:        maps.Clone(s.categories),
+        items:             maps.Clone(s.items),
+        deletedCategories: maps.Clone(s.deletedCategories),
+        deletedItems:      maps.Clone(s.deletedItems),
+        exchangeRates:     maps.Clone(s.exchangeRates),
+        journals:          maps.Clone(s.journals),
+        audit:             s.audit[:len(s.audit):len(s.audit)],
+        nextCategoryID:    s.nextCategoryID,
+        nextItemID:        s.nextItemID,
+    }
+}
+
+func (s *MemoryStore) restore(snap memorySnapshot) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    s.categories, s.items = snap.categories, snap.items
+    s.deletedCategories, s.deletedItems = snap.deletedCategories, snap.deletedItems
+    s.exchangeRates, s.journals, s.audit = snap.exchangeRates, snap.journals, snap.audit
+    s.nextCategoryID, s.nextItemID = snap.nextCategoryID, snap.nextItemID
+}
+
+// MemoryTxManager runs units of work on a MemoryStore like TxManager does on
+// a database. Units of work run one at a time and an error restores the store
+// as it was when the unit began. Calls of the repositories outside a unit of
+// work are not isolated from it and are undone with it.
+type MemoryTxManager struct {
+    store *MemoryStore
+}
+
+func NewMemoryTxManager(store *MemoryStore) *MemoryTxManager {
+    return &MemoryTxManager{store: store}
+}
+
+// WithTx runs fn with the repositories of the store, see MemoryTxManager
+func (m *MemoryTxManager) WithTx(ctx context.Context, fn func(categories *MemoryCategoryRepository, items *MemoryItemRepository, rates *MemoryExchangeRateRepository) error) error {
+    m.store.txMu.Lock()
+    defer m.store.txMu.Unlock()
+
+    snap := m.store.snapshot()
+    if err := fn(NewMemoryCategoryRepository(m.store), NewMemoryItemRepository(m.store), NewMemoryExchangeRateRepository(m.store)); err != nil {
+        m.store.restore(snap)
+        return err
+    }
+    return nil
+}
\ No newline at end of file
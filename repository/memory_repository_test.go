@@ -0,0 +1,81 @@
+package repository
+
+import (
+    "context"
+    "fmt"
+    "sync"
+    "testing"
+    "time"
+
+    "mini_project3/models"
+    "mini_project3/money"
+)
+
+func TestMemoryRepository_ConcurrentCreate(t *testing.T) {
+    store := NewMemoryStore()
+    catRepo := NewMemoryCategoryRepository(store)
+    itemRepo := NewMemoryItemRepository(store)
+
+    cat := &models.Category{Name: "Elektronik"}
+    if err := catRepo.Create(context.Background(), cat); err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+
+    var wg sync.WaitGroup
+    for i := 0; i < 50; i++ {
+        wg.Add(1)
+        go func(i int) {
+            defer wg.Done()
+            item := &models.Item{Name: fmt.Sprintf("Item %d", i), CategoryID: cat.ID, Price: money.FromInt(1000), PurchaseDate: time.Now()}
+            if err := itemRepo.Create(context.Background(), item); err != nil {
+                t.Errorf("unexpected error: %s", err)
+            }
+        }(i)
+    }
+    wg.Wait()
+
+    items, _ := itemRepo.GetAll(context.Background())
+    if len(items) != 50 {
+        t.Fatalf("expected 50 items, got %d", len(items))
+    }
+    for i, item := range items {
+        if item.ID != i+1 {
+            t.Errorf("expected sequential IDs, got %d at position %d", item.ID, i)
+        }
+    }
+}
+
+func TestMemoryRepository_ReturnsCopies(t *testing.T) {
+    store := NewMemoryStore()
+    catRepo := NewMemoryCategoryRepository(store)
+
+    cat := &models.Category{Name: "Elektronik"}
+    catRepo.Create(context.Background(), cat)
+    cat.Name = "Changed"
+
+    got, _ := catRepo.GetByID(context.Background(), cat.ID)
+    if got.Name != "Elektronik" {
+        t.Errorf("expected stored category to be unaffected, got '%s'", got.Name)
+    }
+}
+
+func TestMemoryRepository_SearchWildcards(t *testing.T) {
+    store := NewMemoryStore()
+    catRepo := NewMemoryCategoryRepository(store)
+    itemRepo := NewMemoryItemRepository(store)
+
+    cat := &models.Category{Name: "Elektronik"}
+    catRepo.Create(context.Background(), cat)
+    itemRepo.Create(context.Background(), &models.Item{Name: "Laptop Dell", CategoryID: cat.ID, Price: money.FromInt(1), PurchaseDate: time.Now()})
+    itemRepo.Create(context.Background(), &models.Item{Name: "Lamp (x.y)", CategoryID: cat.ID, Price: money.FromInt(1), PurchaseDate: time.Now()})
+
+    items, _ := itemRepo.Search(context.Background(), "la_p")
+    if len(items) != 1 || items[0].Name != "Lamp (x.y)" {
+        t.Errorf("expected _ to match a single character, got %+v", items)
+    }
+
+    items, _ = itemRepo.Search(context.Background(), "(x.y)")
+    if len(items) != 1 {
+        t.Errorf("expected regexp metacharacters to match literally, got %+v", items)
+    }
+}
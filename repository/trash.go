@@ -0,0 +1,38 @@
+package repository
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    "mini_project3/models"
+)
+
+// deletedAt is the time a record is moved to the trash. It is stored in UTC
+// and compared with purge cutoffs in UTC, so that SQLite, which compares the
+// timestamps as text, orders them like PostgreSQL; trash entries return it
+// in local time.
+func deletedAt() time.Time {
+    return time.Now().UTC()
+}
+
+// queryTrash reads the id, name and deleted_at rows selected by query as trash entries of entity
+func queryTrash(ctx context.Context, db dbtx, query, entity string) ([]models.TrashEntry, error) {
+    rows, err := db.QueryContext(ctx, query)
+    if err != nil {
+        return nil, fmt.Errorf("error querying deleted %s: %w", entity, dbError(err))
+    }
+    defer rows.Close()
+
+    var entries []models.TrashEntry
+    for rows.Next() {
+        entry := models.TrashEntry{Entity: entity}
+        if err := rows.Scan(&entry.ID, &entry.Name, &entry.DeletedAt); err != nil {
+            return nil, fmt.Errorf("error scanning deleted %s: %w", entity, err)
+        }
+        entry.DeletedAt = entry.DeletedAt.Local()
+        entries = append(entries, entry)
+    }
+
+    return entries, nil
+}
\ No newline at end of file
@@ -0,0 +1,100 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+
+    "mini_project3/config"
+)
+
+// dbtx runs the statements of a repository: the database, or the
+// transaction of the unit of work the repository is bound to
+type dbtx interface {
+    ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+    QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+    QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// conn is the connection of a SQL repository. Repositories made by the
+// constructors run every call on db, each change in a transaction of its
+// own; TxManager binds them to tx instead.
+type conn struct {
+    db     *sql.DB
+    tx     *sql.Tx
+    driver string
+}
+
+func (c conn) dbtx() dbtx {
+    if c.tx != nil {
+        return c.tx
+    }
+    return c.db
+}
+
+// withTx runs fn in the transaction of the unit of work, or else in a
+// transaction of its own, see withTx
+func (c conn) withTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+    if c.tx != nil {
+        return fn(c.tx)
+    }
+    return withTx(ctx, c.db, fn)
+}
+
+// forShare locks the rows a unit of work reads on PostgreSQL until it
+// commits, so a check such as "the category exists" still holds when the
+// unit writes. SQLite needs no lock: its transactions take the database
+// write lock when they begin, see config.sqliteDSN.
+func (c conn) forShare() string {
+    if c.tx == nil || c.driver == config.DriverSQLite {
+        return ""
+    }
+    return ` FOR SHARE`
+}
+
+// forUpdate locks the rows a change reads on PostgreSQL until it commits
+func (c conn) forUpdate() string {
+    if c.driver == config.DriverSQLite {
+        return ""
+    }
+    return ` FOR UPDATE`
+}
+
+// withTx runs fn in a transaction that is committed when fn succeeds and rolled back otherwise
+func withTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+    tx, err := db.BeginTx(ctx, nil)
+    if err != nil {
+        return fmt.Errorf("error starting transaction: %w", dbError(err))
+    }
+    defer tx.Rollback()
+
+    if err := fn(tx); err != nil {
+        return err
+    }
+    if err := tx.Commit(); err != nil {
+        return fmt.Errorf("error committing transaction: %w", dbError(err))
+    }
+    return nil
+}
+
+// TxManager runs units of work: calls of the category, item and exchange
+// rate repositories that are committed together or not at all
+type TxManager struct {
+    db     *sql.DB
+    driver string
+}
+
+// NewTxManager creates TxManager for the given backend (config.DriverPostgres or config.DriverSQLite)
+func NewTxManager(db *sql.DB, driver string) *TxManager {
+    return &TxManager{db: db, driver: driver}
+}
+
+// WithTx runs fn with repositories bound to one transaction, committed when
+// fn returns nil and rolled back when it returns an error. The audit log
+// entries of their changes are part of the same transaction.
+func (m *TxManager) WithTx(ctx context.Context, fn func(categories *CategoryRepository, items *ItemRepository, rates *ExchangeRateRepository) error) error {
+    return withTx(ctx, m.db, func(tx *sql.Tx) error {
+        c := conn{db: m.db, tx: tx, driver: m.driver}
+        return fn(&CategoryRepository{conn: c}, &ItemRepository{conn: c}, &ExchangeRateRepository{conn: c})
+    })
+}
\ No newline at end of file
@@ -0,0 +1,82 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "testing"
+    "time"
+
+    "github.com/DATA-DOG/go-sqlmock"
+    "mini_project3/apperrors"
+    "mini_project3/config"
+    "mini_project3/models"
+    "mini_project3/money"
+)
+
+func TestTxManager_WithTx_RollsBackOnError(t *testing.T) {
+    db, mock, err := sqlmock.New()
+    if err != nil {
+        t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+    }
+    defer db.Close()
+
+    manager := NewTxManager(db, config.DriverPostgres)
+
+    // the category is read FOR SHARE, so it cannot be deleted before the insert
+    mock.ExpectBegin()
+    mock.ExpectQuery("SELECT id, name, description, .* FROM categories WHERE id = \\$1 AND deleted_at IS NULL FOR SHARE").
+        WithArgs(1).
+        WillReturnRows(sqlmock.NewRows(categoryColumnNames).AddRow(1, "Elektronik", "", "", 0, "0", "0", "0", "", "", "", "", time.Now(), time.Now(), 1))
+    mock.ExpectQuery("INSERT INTO items").
+        WillReturnError(errors.New("connection reset"))
+    mock.ExpectRollback()
+
+    err = manager.WithTx(context.Background(), func(categories *CategoryRepository, items *ItemRepository, rates *ExchangeRateRepository) error {
+        cat, err := categories.GetByID(context.Background(), 1)
+        if err != nil {
+            return err
+        }
+        return items.Create(context.Background(), &models.Item{Name: "Laptop", CategoryID: cat.ID, Price: money.FromInt(1000)})
+    })
+    if err == nil {
+        t.Error("expected the error of the failed insert")
+    }
+
+    if err := mock.ExpectationsWereMet(); err != nil {
+        t.Errorf("there were unfulfilled expectations: %s", err)
+    }
+}
+
+func TestTxManager_WithTx_CommitsOnce(t *testing.T) {
+    db, mock, err := sqlmock.New()
+    if err != nil {
+        t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+    }
+    defer db.Close()
+
+    manager := NewTxManager(db, config.DriverSQLite)
+
+    // SQLite needs no FOR SHARE, and the repositories begin no transaction of their own
+    mock.ExpectBegin()
+    mock.ExpectQuery("SELECT id, name, description, .* FROM categories WHERE id = \\$1 AND deleted_at IS NULL$").
+        WithArgs(1).
+        WillReturnError(sql.ErrNoRows)
+    mock.ExpectExec("INSERT INTO exchange_rates").
+        WillReturnResult(sqlmock.NewResult(0, 1))
+    mock.ExpectCommit()
+
+    err = manager.WithTx(context.Background(), func(categories *CategoryRepository, items *ItemRepository, rates *ExchangeRateRepository) error {
+        if _, err := categories.GetByID(context.Background(), 1); !errors.Is(err, apperrors.ErrNotFound) {
+            t.Errorf("expected ErrNotFound, got %v", err)
+        }
+        return rates.Set(context.Background(), &models.ExchangeRate{Currency: "USD", Rate: money.MustParseRate("16000")})
+    })
+    if err != nil {
+        t.Errorf("error was not expected: %s", err)
+    }
+
+    if err := mock.ExpectationsWereMet(); err != nil {
+        t.Errorf("there were unfulfilled expectations: %s", err)
+    }
+}
\ No newline at end of file
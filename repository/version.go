@@ -0,0 +1,12 @@
+package repository
+
+import "mini_project3/apperrors"
+
+// checkVersion refuses an update of entity id that expects an older version
+// than the stored one; expected 0 applies the update to any version
+func checkVersion(entity string, id, expected, actual int) error {
+    if expected != 0 && expected != actual {
+        return &apperrors.VersionConflictError{Entity: entity, ID: id, Expected: expected, Actual: actual}
+    }
+    return nil
+}
\ No newline at end of file
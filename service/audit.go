@@ -0,0 +1,146 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"mini_project3/apperrors"
+	"mini_project3/models"
+	"mini_project3/utils"
+)
+
+// AuditRepositoryInterface defines the contract for audit log repository
+type AuditRepositoryInterface interface {
+	// List returns the entries matching filter, oldest first
+	List(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, error)
+}
+
+// unauditedFields change with every update and are left out of the changes
+var unauditedFields = map[string]bool{"updated_at": true, "version": true}
+
+// AuditService reads the audit log the item and category repositories
+// append to with every change
+type AuditService struct {
+	repo AuditRepositoryInterface
+	now  func() time.Time
+}
+
+func NewAuditService(repo AuditRepositoryInterface) *AuditService {
+	return &AuditService{repo: repo, now: time.Now}
+}
+
+// SetClock replaces time.Now as the current time ages such as --since 7d count back from
+func (s *AuditService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// parseSince reads a date in YYYY-MM-DD format, from local midnight, or an
+// age such as 7d or 12h before now. Empty means since the first entry.
+func (s *AuditService) parseSince(since string) (time.Time, error) {
+	since = strings.TrimSpace(since)
+	if since == "" {
+		return time.Time{}, nil
+	}
+	if day, err := time.ParseInLocation("2006-01-02", since, time.Local); err == nil {
+		return day, nil
+	}
+	age, err := parseAge(since)
+	if err != nil {
+		return time.Time{}, apperrors.NewValidationError("since", fmt.Sprintf("must be a date in YYYY-MM-DD format or an age like 7d or 12h, got '%s'", since))
+	}
+	return s.now().Add(-age), nil
+}
+
+// List returns the changes of entity, item or category or empty for both,
+// with ID id, 0 for all of them, made since since, oldest first
+func (s *AuditService) List(ctx context.Context, entity string, id int, since string) ([]models.AuditEntry, error) {
+	entity = strings.ToLower(strings.TrimSpace(entity))
+	if entity != "" && entity != models.AuditItem && entity != models.AuditCategory {
+		return nil, apperrors.NewValidationError("entity", fmt.Sprintf("must be %s or %s, got '%s'", models.AuditItem, models.AuditCategory, entity))
+	}
+	if id < 0 {
+		return nil, apperrors.NewValidationError("ID", "must not be negative")
+	}
+	if id > 0 && entity == "" {
+		return nil, apperrors.NewValidationError("entity", "is required with an ID")
+	}
+	start, err := s.parseSince(since)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := s.repo.List(ctx, models.AuditFilter{Entity: entity, EntityID: id, Since: start})
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		if entries[i].Changes, err = diff(entries[i].Before, entries[i].After); err != nil {
+			return nil, fmt.Errorf("error reading audit entry %d: %w", entries[i].ID, err)
+		}
+	}
+	return entries, nil
+}
+
+// History returns every recorded change of item id, including those made
+// before it was deleted or purged
+func (s *AuditService) History(ctx context.Context, id int) ([]models.AuditEntry, error) {
+	if err := utils.ValidateID(id); err != nil {
+		return nil, err
+	}
+	return s.List(ctx, models.AuditItem, id, "")
+}
+
+// diff lists the fields whose values differ between the before and after
+// records, by field name. A create, delete, restore or purge, which lacks
+// one of them, has no changes.
+func diff(before, after json.RawMessage) ([]models.FieldChange, error) {
+	changes := []models.FieldChange{}
+	if before == nil || after == nil {
+		return changes, nil
+	}
+
+	var old, updated map[string]json.RawMessage
+	if err := json.Unmarshal(before, &old); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(after, &updated); err != nil {
+		return nil, err
+	}
+
+	fields := map[string]bool{}
+	for field := range old {
+		fields[field] = true
+	}
+	for field := range updated {
+		fields[field] = true
+	}
+	for field := range fields {
+		if unauditedFields[field] {
+			continue
+		}
+		from, to := jsonValue(old[field]), jsonValue(updated[field])
+		if from != to {
+			changes = append(changes, models.FieldChange{Field: field, Before: from, After: to})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes, nil
+}
+
+// jsonValue formats a JSON value for display: strings without quotes, null and missing as empty
+func jsonValue(value json.RawMessage) string {
+	var text string
+	if err := json.Unmarshal(value, &text); err == nil {
+		return text
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, value); err != nil || compact.String() == "null" {
+		return ""
+	}
+	return compact.String()
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "testing"
+    "time"
+
+    "mini_project3/apperrors"
+    "mini_project3/models"
+)
+
+type MockAuditRepository struct {
+    entries []models.AuditEntry
+    filter  models.AuditFilter
+}
+
+func (m *MockAuditRepository) List(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, error) {
+    m.filter = filter
+    return m.entries, nil
+}
+
+func TestAuditService_List_Changes(t *testing.T) {
+    mockRepo := &MockAuditRepository{entries: []models.AuditEntry{
+        {ID: 1, Entity: models.AuditItem, EntityID: 3, Action: models.AuditCreate, After: json.RawMessage(`{"id":3,"name":"Laptop","price":1500000.50}`)},
+        {ID: 2, Entity: models.AuditItem, EntityID: 3, Action: models.AuditUpdate,
+            Before: json.RawMessage(`{"id":3,"name":"Laptop","price":1500000.50,"disposed_at":null,"updated_at":"2026-01-01T00:00:00Z"}`),
+            After:  json.RawMessage(`{"id": 3, "name": "Laptop Dell", "price": 1200000.00, "disposed_at": "2026-01-15T00:00:00Z", "updated_at": "2026-01-15T00:00:00Z"}`)},
+    }}
+
+    entries, err := NewAuditService(mockRepo).List(context.Background(), " Item ", 3, "")
+    if err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+    if mockRepo.filter.Entity != models.AuditItem || mockRepo.filter.EntityID != 3 || !mockRepo.filter.Since.IsZero() {
+        t.Errorf("unexpected filter %+v", mockRepo.filter)
+    }
+    if len(entries[0].Changes) != 0 {
+        t.Errorf("expected no changes for a create, got %+v", entries[0].Changes)
+    }
+
+    expected := []models.FieldChange{
+        {Field: "disposed_at", Before: "", After: "2026-01-15T00:00:00Z"},
+        {Field: "name", Before: "Laptop", After: "Laptop Dell"},
+        {Field: "price", Before: "1500000.50", After: "1200000.00"},
+    }
+    changes := entries[1].Changes
+    if len(changes) != len(expected) {
+        t.Fatalf("expected changes %+v, got %+v", expected, changes)
+    }
+    for i := range expected {
+        if changes[i] != expected[i] {
+            t.Errorf("expected change %+v, got %+v", expected[i], changes[i])
+        }
+    }
+}
+
+func TestAuditService_List_Since(t *testing.T) {
+    now := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+    mockRepo := &MockAuditRepository{}
+    service := NewAuditService(mockRepo)
+    service.SetClock(func() time.Time { return now })
+
+    tests := []struct {
+        since    string
+        expected time.Time
+    }{
+        {"7d", now.AddDate(0, 0, -7)},
+        {"12h", now.Add(-12 * time.Hour)},
+        {"2026-01-01", time.Date(2026, 1, 1, 0, 0, 0, 0, time.Local)},
+    }
+    for _, tt := range tests {
+        if _, err := service.List(context.Background(), "", 0, tt.since); err != nil {
+            t.Fatalf("%s: unexpected error: %s", tt.since, err)
+        }
+        if !mockRepo.filter.Since.Equal(tt.expected) {
+            t.Errorf("%s: expected since %s, got %s", tt.since, tt.expected, mockRepo.filter.Since)
+        }
+    }
+}
+
+func TestAuditService_List_Validation(t *testing.T) {
+    service := NewAuditService(&MockAuditRepository{})
+
+    tests := []struct {
+        name   string
+        entity string
+        id     int
+        since  string
+        field  string
+    }{
+        {"unknown entity", "exchange-rate", 0, "", "entity"},
+        {"negative id", "item", -1, "", "ID"},
+        {"id without entity", "", 3, "", "entity"},
+        {"bad since", "item", 3, "last week", "since"},
+    }
+    for _, tt := range tests {
+        _, err := service.List(context.Background(), tt.entity, tt.id, tt.since)
+        var validationErr *apperrors.ValidationError
+        if !errors.As(err, &validationErr) || validationErr.Field != tt.field {
+            t.Errorf("%s: expected validation error on %s, got %v", tt.name, tt.field, err)
+        }
+    }
+
+    if _, err := service.History(context.Background(), 0); !errors.Is(err, apperrors.ErrValidation) {
+        t.Errorf("expected validation error for item history of ID 0, got %v", err)
+    }
+}
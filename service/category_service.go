@@ -1,9 +1,11 @@
 package service
 
 import (
-	"fmt"
+	"context"
 	"strings"
+	"time"
 
+	"mini_project3/apperrors"
 	"mini_project3/models"
 	"mini_project3/repository"
 	"mini_project3/utils"
@@ -11,96 +13,247 @@ import (
 
 // CategoryRepositoryInterface defines the contract for category repository
 type CategoryRepositoryInterface interface {
-	GetAll() ([]models.Category, error)
-	GetByID(id int) (*models.Category, error)
-	Create(cat *models.Category) error
-	Update(cat *models.Category) error
-	Delete(id int) error
-	CheckNameExists(name string, excludeID int) (bool, error)
+	GetAll(ctx context.Context) ([]models.Category, error)
+	GetByID(ctx context.Context, id int) (*models.Category, error)
+	Create(ctx context.Context, cat *models.Category) error
+	Update(ctx context.Context, cat *models.Category) error
+	Delete(ctx context.Context, id int) error
+	CheckNameExists(ctx context.Context, name string, excludeID int) (bool, error)
+	GetDeleted(ctx context.Context) ([]models.TrashEntry, error)
+	Restore(ctx context.Context, id int) error
+	Purge(ctx context.Context, before time.Time) (int, error)
 }
 
 type CategoryService struct {
 	repo CategoryRepositoryInterface
+	tx   TxManager
 }
 
 func NewCategoryService(repo CategoryRepositoryInterface) *CategoryService {
-	return &CategoryService{repo: repo}
+	return &CategoryService{repo: repo, tx: noTx{Repos{Categories: repo}}}
 }
 
 // NewCategoryServiceWithRepo creates CategoryService with concrete repository (for production)
 func NewCategoryServiceWithRepo(repo *repository.CategoryRepository) *CategoryService {
-	return &CategoryService{repo: repo}
+	return &CategoryService{repo: repo, tx: noTx{Repos{Categories: repo}}}
 }
 
-func (s *CategoryService) GetAll() ([]models.Category, error) {
-	return s.repo.GetAll()
+// SetTxManager makes the changes of the service run as units of work of tx, see ItemService.SetTxManager
+func (s *CategoryService) SetTxManager(tx TxManager) {
+	s.tx = tx
 }
 
-func (s *CategoryService) GetByID(id int) (*models.Category, error) {
+func (s *CategoryService) GetAll(ctx context.Context) ([]models.Category, error) {
+	return s.repo.GetAll(ctx)
+}
+
+func (s *CategoryService) GetByID(ctx context.Context, id int) (*models.Category, error) {
 	if err := utils.ValidateID(id); err != nil {
 		return nil, err
 	}
-	return s.repo.GetByID(id)
+	return s.repo.GetByID(ctx, id)
 }
 
-func (s *CategoryService) Create(name, description string) (*models.Category, error) {
+// newCategory checks and normalizes the fields of a category
+func newCategory(name, description string, policy models.DepreciationPolicy, accounts models.JournalAccounts) (*models.Category, error) {
 	name = strings.TrimSpace(name)
 	if err := utils.ValidateNotEmpty(name, "Category name"); err != nil {
 		return nil, err
 	}
-
-	// Check for duplicate
-	exists, err := s.repo.CheckNameExists(name, 0)
+	if err := checkCategoryPolicy(policy); err != nil {
+		return nil, err
+	}
+	accounts, err := checkAccounts(accounts)
 	if err != nil {
 		return nil, err
 	}
-	if exists {
-		return nil, fmt.Errorf("category with name '%s' already exists", name)
+
+	return &models.Category{
+		Name:               name,
+		Description:        strings.TrimSpace(description),
+		DepreciationPolicy: policy,
+		JournalAccounts:    accounts,
+	}, nil
+}
+
+// checkName fails with a DuplicateNameError when another category than
+// excludeID uses the name of cat, naming the category when it is in the trash
+func checkName(ctx context.Context, repos Repos, cat models.Category, excludeID int) error {
+	exists, err := repos.Categories.CheckNameExists(ctx, cat.Name, excludeID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return nil
 	}
 
-	cat := &models.Category{
-		Name:        name,
-		Description: strings.TrimSpace(description),
+	trashedID, err := trashedCategory(ctx, repos.Categories, cat.Name)
+	if err != nil {
+		return err
 	}
+	return &apperrors.DuplicateNameError{Entity: "category", Name: cat.Name, TrashedID: trashedID}
+}
+
+// trashedCategory returns the ID of the category in the trash named name, or 0
+func trashedCategory(ctx context.Context, repo CategoryRepositoryInterface, name string) (int, error) {
+	deleted, err := repo.GetDeleted(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, entry := range deleted {
+		if entry.Name == name {
+			return entry.ID, nil
+		}
+	}
+	return 0, nil
+}
 
-	if err := s.repo.Create(cat); err != nil {
+func (s *CategoryService) Create(ctx context.Context, name, description string, policy models.DepreciationPolicy, accounts models.JournalAccounts) (*models.Category, error) {
+	cat, err := newCategory(name, description, policy, accounts)
+	if err != nil {
 		return nil, err
 	}
 
+	err = s.tx.WithTx(ctx, func(repos Repos) error {
+		if err := checkName(ctx, repos, *cat, 0); err != nil {
+			return err
+		}
+		return repos.Categories.Create(ctx, cat)
+	})
+	if err != nil {
+		return nil, err
+	}
 	return cat, nil
 }
 
-func (s *CategoryService) Update(id int, name, description string) error {
+// Update replaces the fields of category id, failing with a
+// VersionConflictError when pre does not hold for the stored category
+func (s *CategoryService) Update(ctx context.Context, id int, name, description string, policy models.DepreciationPolicy, accounts models.JournalAccounts, pre Precondition) error {
 	if err := utils.ValidateID(id); err != nil {
 		return err
 	}
-
-	name = strings.TrimSpace(name)
-	if err := utils.ValidateNotEmpty(name, "Category name"); err != nil {
+	if err := checkPrecondition(pre); err != nil {
 		return err
 	}
 
-	// Check for duplicate (excluding current ID)
-	exists, err := s.repo.CheckNameExists(name, id)
+	cat, err := newCategory(name, description, policy, accounts)
 	if err != nil {
 		return err
 	}
-	if exists {
-		return fmt.Errorf("category with name '%s' already exists", name)
+	cat.ID, cat.Version = id, pre.Version
+
+	return s.tx.WithTx(ctx, func(repos Repos) error {
+		if err := checkName(ctx, repos, *cat, id); err != nil {
+			return err
+		}
+		if !pre.UpdatedAt.IsZero() {
+			existing, err := repos.Categories.GetByID(ctx, id)
+			if err != nil {
+				return err
+			}
+			if cat.Version, err = expectedVersion("category", id, pre, existing.Version, existing.UpdatedAt); err != nil {
+				return err
+			}
+		}
+		return repos.Categories.Update(ctx, cat)
+	})
+}
+
+// Patch changes the fields set in patch and keeps the others of category
+// id, failing like ItemService.Patch when it was changed by someone else
+func (s *CategoryService) Patch(ctx context.Context, id int, patch CategoryPatch, pre Precondition) error {
+	if err := utils.ValidateID(id); err != nil {
+		return err
 	}
+	if err := checkPrecondition(pre); err != nil {
+		return err
+	}
+	if patch == (CategoryPatch{}) {
+		return apperrors.NewValidationError("update", "must set at least one field")
+	}
+
+	return s.tx.WithTx(ctx, func(repos Repos) error {
+		existing, err := repos.Categories.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		version, err := expectedVersion("category", id, pre, existing.Version, existing.UpdatedAt)
+		if err != nil {
+			return err
+		}
+
+		merged := *existing
+		if patch.Name != nil {
+			merged.Name = *patch.Name
+		}
+		if patch.Description != nil {
+			merged.Description = *patch.Description
+		}
+		if patch.ExpenseAccount != nil {
+			merged.ExpenseAccount = *patch.ExpenseAccount
+		}
+		if patch.AccumulatedAccount != nil {
+			merged.AccumulatedAccount = *patch.AccumulatedAccount
+		}
+		cat, err := newCategory(merged.Name, merged.Description, patch.Policy.apply(merged.DepreciationPolicy), merged.JournalAccounts)
+		if err != nil {
+			return err
+		}
+		cat.ID, cat.Version = id, version
+
+		if err := checkName(ctx, repos, *cat, id); err != nil {
+			return err
+		}
+		return repos.Categories.Update(ctx, cat)
+	})
+}
 
-	cat := &models.Category{
-		ID:          id,
-		Name:        name,
-		Description: strings.TrimSpace(description),
+// checkAccounts trims the journal accounts of a category and checks they fit their columns
+func checkAccounts(accounts models.JournalAccounts) (models.JournalAccounts, error) {
+	accounts.ExpenseAccount = strings.TrimSpace(accounts.ExpenseAccount)
+	accounts.AccumulatedAccount = strings.TrimSpace(accounts.AccumulatedAccount)
+	if len(accounts.ExpenseAccount) > 30 {
+		return accounts, apperrors.NewValidationError("expense account", "must be at most 30 characters")
 	}
+	if len(accounts.AccumulatedAccount) > 30 {
+		return accounts, apperrors.NewValidationError("accumulated account", "must be at most 30 characters")
+	}
+	return accounts, nil
+}
+
+// checkCategoryPolicy checks a category policy; a category that selects a
+// method must also give the parameters the method needs, so its items can
+// inherit it as is
+func checkCategoryPolicy(policy models.DepreciationPolicy) error {
+	if err := validatePolicy(policy); err != nil {
+		return err
+	}
+	if _, err := NewTaxMethod(policy); err != nil {
+		return err
+	}
+	if policy.Method == "" {
+		return nil
+	}
+	_, err := NewDepreciationMethod(EffectivePolicy(policy, DefaultDepreciationPolicy))
+	return err
+}
 
-	return s.repo.Update(cat)
+// Delete moves a category without items to the trash, see TrashService
+func (s *CategoryService) Delete(ctx context.Context, id int) error {
+	if err := utils.ValidateID(id); err != nil {
+		return err
+	}
+	return s.tx.WithTx(ctx, func(repos Repos) error {
+		return repos.Categories.Delete(ctx, id)
+	})
 }
 
-func (s *CategoryService) Delete(id int) error {
+// Restore takes a category out of the trash
+func (s *CategoryService) Restore(ctx context.Context, id int) error {
 	if err := utils.ValidateID(id); err != nil {
 		return err
 	}
-	return s.repo.Delete(id)
+	return s.tx.WithTx(ctx, func(repos Repos) error {
+		return repos.Categories.Restore(ctx, id)
+	})
 }
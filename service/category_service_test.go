@@ -1,29 +1,33 @@
 package service
 
 import (
+    "context"
     "errors"
     "testing"
     "time"
 
+    "mini_project3/apperrors"
     "mini_project3/models"
+    "mini_project3/money"
 )
 
 // Mock Repository
 type MockCategoryRepository struct {
     categories     []models.Category
+    trash          []models.TrashEntry
     shouldError    bool
     checkNameError bool
     nameExists     bool
 }
 
-func (m *MockCategoryRepository) GetAll() ([]models.Category, error) {
+func (m *MockCategoryRepository) GetAll(ctx context.Context) ([]models.Category, error) {
     if m.shouldError {
         return nil, errors.New("mock error")
     }
     return m.categories, nil
 }
 
-func (m *MockCategoryRepository) GetByID(id int) (*models.Category, error) {
+func (m *MockCategoryRepository) GetByID(ctx context.Context, id int) (*models.Category, error) {
     if m.shouldError {
         return nil, errors.New("mock error")
     }
@@ -35,7 +39,7 @@ func (m *MockCategoryRepository) GetByID(id int) (*models.Category, error) {
     return nil, errors.New("category not found")
 }
 
-func (m *MockCategoryRepository) Create(cat *models.Category) error {
+func (m *MockCategoryRepository) Create(ctx context.Context, cat *models.Category) error {
     if m.shouldError {
         return errors.New("mock error")
     }
@@ -45,27 +49,53 @@ func (m *MockCategoryRepository) Create(cat *models.Category) error {
     return nil
 }
 
-func (m *MockCategoryRepository) Update(cat *models.Category) error {
+func (m *MockCategoryRepository) Update(ctx context.Context, cat *models.Category) error {
     if m.shouldError {
         return errors.New("mock error")
     }
+    for i := range m.categories {
+        if m.categories[i].ID == cat.ID {
+            m.categories[i] = *cat
+        }
+    }
     return nil
 }
 
-func (m *MockCategoryRepository) Delete(id int) error {
+func (m *MockCategoryRepository) Delete(ctx context.Context, id int) error {
     if m.shouldError {
         return errors.New("mock error")
     }
     return nil
 }
 
-func (m *MockCategoryRepository) CheckNameExists(name string, excludeID int) (bool, error) {
+func (m *MockCategoryRepository) CheckNameExists(ctx context.Context, name string, excludeID int) (bool, error) {
     if m.checkNameError {
         return false, errors.New("mock error")
     }
     return m.nameExists, nil
 }
 
+func (m *MockCategoryRepository) GetDeleted(ctx context.Context) ([]models.TrashEntry, error) {
+    if m.shouldError {
+        return nil, errors.New("mock error")
+    }
+    return m.trash, nil
+}
+
+func (m *MockCategoryRepository) Restore(ctx context.Context, id int) error {
+    if m.shouldError {
+        return errors.New("mock error")
+    }
+    return nil
+}
+
+func (m *MockCategoryRepository) Purge(ctx context.Context, before time.Time) (int, error) {
+    if m.shouldError {
+        return 0, errors.New("mock error")
+    }
+    return len(m.trash), nil
+}
+
 func TestCategoryService_GetAll(t *testing.T) {
     mockRepo := &MockCategoryRepository{
         categories: []models.Category{
@@ -75,7 +105,7 @@ func TestCategoryService_GetAll(t *testing.T) {
     }
 
     service := NewCategoryService(mockRepo)
-    categories, err := service.GetAll()
+    categories, err := service.GetAll(context.Background())
 
     if err != nil {
         t.Errorf("unexpected error: %s", err)
@@ -94,7 +124,7 @@ func TestCategoryService_GetByID(t *testing.T) {
     }
 
     service := NewCategoryService(mockRepo)
-    category, err := service.GetByID(1)
+    category, err := service.GetByID(context.Background(), 1)
 
     if err != nil {
         t.Errorf("unexpected error: %s", err)
@@ -109,12 +139,12 @@ func TestCategoryService_GetByID_InvalidID(t *testing.T) {
     mockRepo := &MockCategoryRepository{}
     service := NewCategoryService(mockRepo)
 
-    _, err := service.GetByID(0)
+    _, err := service.GetByID(context.Background(), 0)
     if err == nil {
         t.Error("expected error for invalid ID")
     }
 
-    _, err = service.GetByID(-1)
+    _, err = service.GetByID(context.Background(), -1)
     if err == nil {
         t.Error("expected error for negative ID")
     }
@@ -126,7 +156,7 @@ func TestCategoryService_Create(t *testing.T) {
     }
 
     service := NewCategoryService(mockRepo)
-    cat, err := service.Create("Test Category", "Test Description")
+    cat, err := service.Create(context.Background(), "Test Category", "Test Description", models.DepreciationPolicy{}, models.JournalAccounts{})
 
     if err != nil {
         t.Errorf("unexpected error: %s", err)
@@ -141,12 +171,12 @@ func TestCategoryService_Create_EmptyName(t *testing.T) {
     mockRepo := &MockCategoryRepository{}
     service := NewCategoryService(mockRepo)
 
-    _, err := service.Create("", "Description")
+    _, err := service.Create(context.Background(), "", "Description", models.DepreciationPolicy{}, models.JournalAccounts{})
     if err == nil {
         t.Error("expected error for empty name")
     }
 
-    _, err = service.Create("   ", "Description")
+    _, err = service.Create(context.Background(), "   ", "Description", models.DepreciationPolicy{}, models.JournalAccounts{})
     if err == nil {
         t.Error("expected error for whitespace name")
     }
@@ -158,11 +188,32 @@ func TestCategoryService_Create_DuplicateName(t *testing.T) {
     }
 
     service := NewCategoryService(mockRepo)
-    _, err := service.Create("Existing Category", "Description")
+    _, err := service.Create(context.Background(), "Existing Category", "Description", models.DepreciationPolicy{}, models.JournalAccounts{})
 
     if err == nil {
         t.Error("expected error for duplicate name")
     }
+    if !errors.Is(err, apperrors.ErrDuplicateName) {
+        t.Errorf("expected ErrDuplicateName, got %v", err)
+    }
+}
+
+func TestCategoryService_Create_NameInTrash(t *testing.T) {
+    mockRepo := &MockCategoryRepository{
+        trash:      []models.TrashEntry{{Entity: models.TrashCategory, ID: 7, Name: "Furniture"}},
+        nameExists: true,
+    }
+
+    service := NewCategoryService(mockRepo)
+    _, err := service.Create(context.Background(), "Furniture", "", models.DepreciationPolicy{}, models.JournalAccounts{})
+
+    var duplicate *apperrors.DuplicateNameError
+    if !errors.As(err, &duplicate) || duplicate.TrashedID != 7 {
+        t.Fatalf("expected a DuplicateNameError for the category in the trash, got %v", err)
+    }
+    if expected := "category with name 'Furniture' is in the trash: restore it with 'category restore --id 7' or purge it first"; err.Error() != expected {
+        t.Errorf("expected %q, got %q", expected, err.Error())
+    }
 }
 
 func TestCategoryService_Update(t *testing.T) {
@@ -174,13 +225,47 @@ func TestCategoryService_Update(t *testing.T) {
     }
 
     service := NewCategoryService(mockRepo)
-    err := service.Update(1, "New Name", "New Description")
+    err := service.Update(context.Background(), 1, "New Name", "New Description", models.DepreciationPolicy{}, models.JournalAccounts{}, Precondition{})
 
     if err != nil {
         t.Errorf("unexpected error: %s", err)
     }
 }
 
+func TestCategoryService_Patch(t *testing.T) {
+    mockRepo := &MockCategoryRepository{
+        categories: []models.Category{
+            {ID: 1, Name: "Furniture", Description: "Mebel kantor", Version: 2,
+                DepreciationPolicy: models.DepreciationPolicy{Method: MethodStraightLine, UsefulLifeMonths: 96, SalvagePercent: money.MustParseRate("10")},
+                JournalAccounts:    models.JournalAccounts{ExpenseAccount: "6-1200"}},
+        },
+    }
+    service := NewCategoryService(mockRepo)
+
+    // clearing the description and the salvage keeps every other field
+    empty, salvage := "", money.FromInt(50000)
+    err := service.Patch(context.Background(), 1, CategoryPatch{Description: &empty, Policy: PolicyPatch{SalvageValue: &salvage}}, Precondition{})
+    if err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+    got := mockRepo.categories[0]
+    expected := models.DepreciationPolicy{Method: MethodStraightLine, UsefulLifeMonths: 96, SalvageValue: salvage}
+    if got.Name != "Furniture" || got.Description != "" || got.DepreciationPolicy != expected || got.ExpenseAccount != "6-1200" {
+        t.Errorf("unexpected category after patch: %+v", got)
+    }
+    if got.Version != 2 {
+        t.Errorf("expected the update to expect the version read, got %d", got.Version)
+    }
+
+    if err := service.Patch(context.Background(), 1, CategoryPatch{}, Precondition{}); !errors.Is(err, apperrors.ErrValidation) {
+        t.Errorf("expected validation error for an empty patch, got %v", err)
+    }
+    name := "Mebel"
+    if err := service.Patch(context.Background(), 1, CategoryPatch{Name: &name}, Precondition{Version: 1}); !errors.Is(err, apperrors.ErrConflict) {
+        t.Errorf("expected conflict for an older version, got %v", err)
+    }
+}
+
 func TestCategoryService_Delete(t *testing.T) {
     mockRepo := &MockCategoryRepository{
         categories: []models.Category{
@@ -189,7 +274,7 @@ func TestCategoryService_Delete(t *testing.T) {
     }
 
     service := NewCategoryService(mockRepo)
-    err := service.Delete(1)
+    err := service.Delete(context.Background(), 1)
 
     if err != nil {
         t.Errorf("unexpected error: %s", err)
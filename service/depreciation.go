@@ -0,0 +1,374 @@
+package service
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"mini_project3/apperrors"
+	"mini_project3/models"
+	"mini_project3/money"
+)
+
+// DepreciationMethod computes the book value of an asset from its purchase
+// value. Items and categories select one of the built-in methods by name
+// through a models.DepreciationPolicy; see NewDepreciationMethod.
+type DepreciationMethod interface {
+	// Name is the key stored on items and categories, e.g. "straight-line"
+	Name() string
+	// Describe names the method with its parameters for the reports
+	Describe() string
+	// Formula shows how the book value is derived from the purchase value
+	Formula() string
+	// AnnualRate is the share of the purchase value written off in the first full year
+	AnnualRate() float64
+	// BookValue returns the value of cost after daysUsed days of use; it never
+	// drops below residual, which is at most cost
+	BookValue(cost, residual money.Money, daysUsed int) money.Money
+}
+
+// Names of the built-in depreciation methods
+const (
+	MethodDecliningBalance = "declining-balance"
+	MethodStraightLine     = "straight-line"
+	MethodDoubleDeclining  = "double-declining"
+	MethodSumOfYearsDigits = "sum-of-years-digits"
+)
+
+// DepreciationMethods lists the accepted method names, default first
+var DepreciationMethods = []string{MethodDecliningBalance, MethodStraightLine, MethodDoubleDeclining, MethodSumOfYearsDigits}
+
+// DefaultDepreciationPolicy fills whatever an item and its category leave empty
+var DefaultDepreciationPolicy = models.DepreciationPolicy{Method: MethodDecliningBalance, RatePercent: money.MustParseRate("20")}
+
+// A year of use is 365 days; useful lives are given in months of 365/12 days
+const daysPerYear = 365
+
+// EffectivePolicy fills the empty fields of the first policy from the next
+// ones in order, e.g. EffectivePolicy(item, category, DefaultDepreciationPolicy)
+func EffectivePolicy(policies ...models.DepreciationPolicy) models.DepreciationPolicy {
+	var effective models.DepreciationPolicy
+	for _, p := range policies {
+		if effective.Method == "" {
+			effective.Method = p.Method
+		}
+		if effective.UsefulLifeMonths == 0 {
+			effective.UsefulLifeMonths = p.UsefulLifeMonths
+		}
+		if effective.RatePercent.IsZero() {
+			effective.RatePercent = p.RatePercent
+		}
+		if !effective.HasSalvage() {
+			effective.SalvageValue, effective.SalvagePercent = p.SalvageValue, p.SalvagePercent
+		}
+		if effective.TaxGroup == "" {
+			effective.TaxGroup = p.TaxGroup
+		}
+		if effective.TaxMethod == "" {
+			effective.TaxMethod = p.TaxMethod
+		}
+	}
+	return effective
+}
+
+// validatePolicy checks the fields a policy sets; empty fields are inherited and always valid
+func validatePolicy(policy models.DepreciationPolicy) error {
+	if policy.Method != "" && !isDepreciationMethod(policy.Method) {
+		return apperrors.NewValidationError("method", fmt.Sprintf("must be one of %s, got '%s'", strings.Join(DepreciationMethods, ", "), policy.Method))
+	}
+	if policy.UsefulLifeMonths < 0 {
+		return apperrors.NewValidationError("useful life", "cannot be negative")
+	}
+	if policy.RatePercent.Rat().Cmp(big.NewRat(100, 1)) >= 0 {
+		return apperrors.NewValidationError("rate", fmt.Sprintf("must be below 100%%, got %s%%", policy.RatePercent))
+	}
+	if policy.SalvageValue.IsNegative() {
+		return apperrors.NewValidationError("salvage value", "cannot be negative")
+	}
+	if policy.SalvagePercent.Rat().Cmp(big.NewRat(100, 1)) >= 0 {
+		return apperrors.NewValidationError("salvage value", fmt.Sprintf("must be below 100%% of the price, got %s%%", policy.SalvagePercent))
+	}
+	if !policy.SalvageValue.IsZero() && !policy.SalvagePercent.IsZero() {
+		return apperrors.NewValidationError("salvage value", "set either an amount or a percentage, not both")
+	}
+	return validateTaxPolicy(policy)
+}
+
+// Residual returns the salvage value of policy for an asset bought at cost:
+// a percentage of cost, or the amount capped at cost
+func Residual(policy models.DepreciationPolicy, cost money.Money) money.Money {
+	if !policy.SalvagePercent.IsZero() {
+		return cost.Mul(new(big.Rat).Quo(policy.SalvagePercent.Rat(), big.NewRat(100, 1)))
+	}
+	if policy.SalvageValue.Cmp(cost) > 0 {
+		return cost
+	}
+	return policy.SalvageValue
+}
+
+func isDepreciationMethod(name string) bool {
+	for _, method := range DepreciationMethods {
+		if method == name {
+			return true
+		}
+	}
+	return false
+}
+
+// NewDepreciationMethod builds the method of a complete policy, usually the
+// result of EffectivePolicy. Straight-line, double-declining and
+// sum-of-years-digits need a useful life; declining balance uses the rate.
+func NewDepreciationMethod(policy models.DepreciationPolicy) (DepreciationMethod, error) {
+	if err := validatePolicy(policy); err != nil {
+		return nil, err
+	}
+
+	life := policy.UsefulLifeMonths
+	needLife := func() error {
+		if life == 0 {
+			return apperrors.NewValidationError("useful life", fmt.Sprintf("is required for %s", policy.Method))
+		}
+		return nil
+	}
+
+	switch policy.Method {
+	case MethodDecliningBalance:
+		if policy.RatePercent.IsZero() {
+			return nil, apperrors.NewValidationError("rate", fmt.Sprintf("is required for %s", policy.Method))
+		}
+		return DecliningBalance{Rate: new(big.Rat).Quo(policy.RatePercent.Rat(), big.NewRat(100, 1))}, nil
+	case MethodStraightLine:
+		if err := needLife(); err != nil {
+			return nil, err
+		}
+		return StraightLine{LifeMonths: life}, nil
+	case MethodDoubleDeclining:
+		if err := needLife(); err != nil {
+			return nil, err
+		}
+		return DoubleDeclining{LifeMonths: life}, nil
+	case MethodSumOfYearsDigits:
+		if err := needLife(); err != nil {
+			return nil, err
+		}
+		if life%12 != 0 {
+			return nil, apperrors.NewValidationError("useful life", fmt.Sprintf("must be whole years for %s, got %d months", policy.Method, life))
+		}
+		return SumOfYearsDigits{LifeMonths: life}, nil
+	}
+	return nil, apperrors.NewValidationError("method", "is required")
+}
+
+// decimal writes r with at least 2 and at most 6 decimal places, e.g. 0.20 or 0.125
+func decimal(r *big.Rat) string {
+	text := strings.TrimRight(r.FloatString(6), "0")
+	if whole, frac, _ := strings.Cut(text, "."); len(frac) < 2 {
+		return whole + "." + (frac + "00")[:2]
+	}
+	return text
+}
+
+// percent writes a fraction as a percentage without trailing zeros, e.g. 20 or 66.67
+func percent(r *big.Rat) string {
+	text := new(big.Rat).Mul(r, big.NewRat(100, 1)).FloatString(2)
+	return strings.TrimSuffix(strings.TrimRight(text, "0"), ".")
+}
+
+// years writes a useful life in years, e.g. 5 or 1.5
+func years(lifeMonths int) string {
+	text := big.NewRat(int64(lifeMonths), 12).FloatString(2)
+	return strings.TrimSuffix(strings.TrimRight(text, "0"), ".")
+}
+
+// ==================== DECLINING BALANCE ====================
+
+// DecliningBalance writes off Rate of the remaining book value every year and never reaches zero
+type DecliningBalance struct {
+	Rate *big.Rat
+}
+
+func (m DecliningBalance) Name() string { return MethodDecliningBalance }
+
+func (m DecliningBalance) Describe() string {
+	return fmt.Sprintf("Saldo Menurun %s%% per tahun", percent(m.Rate))
+}
+
+func (m DecliningBalance) Formula() string {
+	return fmt.Sprintf("Nilai Sekarang = Harga Awal × (1 - %s)^tahun, minimal Nilai Residu", decimal(m.Rate))
+}
+
+func (m DecliningBalance) AnnualRate() float64 {
+	rate, _ := m.Rate.Float64()
+	return rate
+}
+
+// BookValue applies Nilai Sekarang = Nilai Awal × (1 - Rate)^Tahun.
+// Each full year is one period whose depreciation is rounded half to even to
+// the cent before it is taken off the book value; the remaining days form a
+// last, partial period that writes off Rate × days / 365 of the book value at
+// its start, so the months of a year add up to its depreciation exactly.
+// Rounding per item per period means the depreciation
+// of all items sums exactly to the total report. The book value stops at
+// the residual once it would fall below it.
+func (m DecliningBalance) BookValue(cost, residual money.Money, daysUsed int) money.Money {
+	if daysUsed <= 0 {
+		return cost
+	}
+
+	book := cost
+	for year := 0; year < daysUsed/daysPerYear && book.Cmp(residual) > 0; year++ {
+		book = book.Sub(book.Mul(m.Rate))
+	}
+	if days := daysUsed % daysPerYear; days > 0 {
+		share := new(big.Rat).Mul(m.Rate, big.NewRat(int64(days), daysPerYear))
+		book = book.Sub(book.Mul(share))
+	}
+	if book.Cmp(residual) < 0 {
+		return residual
+	}
+	return book
+}
+
+// ==================== STRAIGHT LINE ====================
+
+// StraightLine writes off the same amount every day until the useful life ends
+type StraightLine struct {
+	LifeMonths int
+}
+
+func (m StraightLine) Name() string { return MethodStraightLine }
+
+func (m StraightLine) Describe() string {
+	return fmt.Sprintf("Garis Lurus, umur manfaat %d bulan", m.LifeMonths)
+}
+
+func (m StraightLine) Formula() string {
+	return fmt.Sprintf("Nilai Sekarang = Harga Awal - (Harga Awal - Nilai Residu) × tahun / %s", years(m.LifeMonths))
+}
+
+func (m StraightLine) AnnualRate() float64 {
+	return 12 / float64(m.LifeMonths)
+}
+
+// BookValue rounds the depreciation once, so the book value is exactly the
+// residual from the end of the useful life on
+func (m StraightLine) BookValue(cost, residual money.Money, daysUsed int) money.Money {
+	if daysUsed <= 0 {
+		return cost
+	}
+
+	used := big.NewRat(int64(daysUsed)*12, int64(m.LifeMonths)*daysPerYear)
+	if used.Cmp(big.NewRat(1, 1)) > 0 {
+		used.SetInt64(1)
+	}
+	return cost.Sub(cost.Sub(residual).Mul(used))
+}
+
+// ==================== DOUBLE DECLINING ====================
+
+// DoubleDeclining writes off twice the straight-line rate of the remaining
+// book value every year, switching to straight line over the remaining life
+// once that writes off more, so the book value reaches the residual at the
+// end of the useful life
+type DoubleDeclining struct {
+	LifeMonths int
+}
+
+func (m DoubleDeclining) Name() string { return MethodDoubleDeclining }
+
+func (m DoubleDeclining) rate() *big.Rat {
+	return big.NewRat(24, int64(m.LifeMonths))
+}
+
+func (m DoubleDeclining) Describe() string {
+	return fmt.Sprintf("Saldo Menurun Ganda %s%% per tahun, umur manfaat %d bulan", percent(m.rate()), m.LifeMonths)
+}
+
+func (m DoubleDeclining) Formula() string {
+	return fmt.Sprintf("Depresiasi per tahun = Nilai Buku × %s, beralih ke garis lurus bila lebih besar; mencapai Nilai Residu setelah %s tahun", decimal(m.rate()), years(m.LifeMonths))
+}
+
+func (m DoubleDeclining) AnnualRate() float64 {
+	rate, _ := m.rate().Float64()
+	return rate
+}
+
+// BookValue works year by year like DecliningBalance; a partial year
+// depreciates its share of the days, and each period is rounded to the cent.
+// The straight-line alternative spreads what is left above the residual.
+func (m DoubleDeclining) BookValue(cost, residual money.Money, daysUsed int) money.Money {
+	life := big.NewRat(int64(m.LifeMonths), 12)
+	book := cost
+	for start := 0; start < daysUsed && book.Cmp(residual) > 0; start += daysPerYear {
+		remaining := new(big.Rat).Sub(life, big.NewRat(int64(start/daysPerYear), 1))
+		if remaining.Sign() <= 0 {
+			return residual
+		}
+
+		period := big.NewRat(int64(min(daysPerYear, daysUsed-start)), daysPerYear)
+		charge := book.Mul(new(big.Rat).Mul(m.rate(), period))
+		slPeriod := period
+		if slPeriod.Cmp(remaining) > 0 {
+			slPeriod = remaining
+		}
+		left := book.Sub(residual)
+		if straight := left.Mul(new(big.Rat).Quo(slPeriod, remaining)); straight.Cmp(charge) > 0 {
+			charge = straight
+		}
+		if charge.Cmp(left) > 0 {
+			charge = left
+		}
+		book = book.Sub(charge)
+	}
+	return book
+}
+
+// ==================== SUM OF YEARS DIGITS ====================
+
+// SumOfYearsDigits writes off (N - n + 1) / (1 + 2 + ... + N) of the purchase
+// value less the residual in year n of an N-year useful life
+type SumOfYearsDigits struct {
+	LifeMonths int
+}
+
+func (m SumOfYearsDigits) Name() string { return MethodSumOfYearsDigits }
+
+func (m SumOfYearsDigits) lifeYears() int64 {
+	return int64(m.LifeMonths / 12)
+}
+
+func (m SumOfYearsDigits) digits() int64 {
+	n := m.lifeYears()
+	return n * (n + 1) / 2
+}
+
+func (m SumOfYearsDigits) Describe() string {
+	return fmt.Sprintf("Jumlah Angka Tahun, umur manfaat %d tahun", m.lifeYears())
+}
+
+func (m SumOfYearsDigits) Formula() string {
+	return fmt.Sprintf("Depresiasi tahun ke-n = (Harga Awal - Nilai Residu) × (%d - n + 1) / %d", m.lifeYears(), m.digits())
+}
+
+func (m SumOfYearsDigits) AnnualRate() float64 {
+	return float64(m.lifeYears()) / float64(m.digits())
+}
+
+// BookValue sums the digits of the full years used plus the share of the
+// days of the current year, then rounds the depreciation once
+func (m SumOfYearsDigits) BookValue(cost, residual money.Money, daysUsed int) money.Money {
+	if daysUsed <= 0 {
+		return cost
+	}
+
+	n := m.lifeYears()
+	full := int64(daysUsed / daysPerYear)
+	if full >= n {
+		return residual
+	}
+
+	used := big.NewRat(full*n-full*(full-1)/2, 1)
+	used.Add(used, big.NewRat((n-full)*int64(daysUsed%daysPerYear), daysPerYear))
+	used.Quo(used, big.NewRat(m.digits(), 1))
+	return cost.Sub(cost.Sub(residual).Mul(used))
+}
@@ -0,0 +1,259 @@
+package service
+
+import (
+    "context"
+    "errors"
+    "testing"
+    "time"
+
+    "mini_project3/apperrors"
+    "mini_project3/models"
+    "mini_project3/money"
+)
+
+// defaultMethod is the method of an item when neither it nor its category selects one
+func defaultMethod(t *testing.T) DepreciationMethod {
+    t.Helper()
+    method, err := NewDepreciationMethod(DefaultDepreciationPolicy)
+    if err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+    return method
+}
+
+func mustMethod(t *testing.T, policy models.DepreciationPolicy) DepreciationMethod {
+    t.Helper()
+    method, err := NewDepreciationMethod(policy)
+    if err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+    return method
+}
+
+func TestDepreciationMethods_BookValue(t *testing.T) {
+    tests := []struct {
+        name     string
+        policy   models.DepreciationPolicy
+        cost     money.Money
+        days     int
+        expected money.Money
+        residual money.Money
+    }{
+        {"declining 25% one year", models.DepreciationPolicy{Method: MethodDecliningBalance, RatePercent: money.MustParseRate("25")}, money.FromInt(1000000), 365, money.FromInt(750000), money.Zero},
+        {"declining 25% partial year pro rata", models.DepreciationPolicy{Method: MethodDecliningBalance, RatePercent: money.MustParseRate("25")}, money.FromInt(1000000), 438, money.FromInt(712500), money.Zero},
+        {"straight-line before purchase", models.DepreciationPolicy{Method: MethodStraightLine, UsefulLifeMonths: 60}, money.FromInt(12000000), 0, money.FromInt(12000000), money.Zero},
+        {"straight-line one year", models.DepreciationPolicy{Method: MethodStraightLine, UsefulLifeMonths: 60}, money.FromInt(12000000), 365, money.FromInt(9600000), money.Zero},
+        {"straight-line end of life", models.DepreciationPolicy{Method: MethodStraightLine, UsefulLifeMonths: 60}, money.FromInt(12000000), 1825, money.Zero, money.Zero},
+        {"straight-line after life", models.DepreciationPolicy{Method: MethodStraightLine, UsefulLifeMonths: 60}, money.FromInt(12000000), 3000, money.Zero, money.Zero},
+        // 10.000.000 × 0.4 × 182/365 = 1.994.520,5479 → 1.994.520,55
+        {"double-declining partial year", models.DepreciationPolicy{Method: MethodDoubleDeclining, UsefulLifeMonths: 60}, money.FromInt(10000000), 182, money.MustParse("8005479.45"), money.Zero},
+        {"double-declining three years", models.DepreciationPolicy{Method: MethodDoubleDeclining, UsefulLifeMonths: 60}, money.FromInt(10000000), 3 * 365, money.FromInt(2160000), money.Zero},
+        // year 4 switches to straight line: 2.160.000 / 2 remaining years
+        {"double-declining switches to straight line", models.DepreciationPolicy{Method: MethodDoubleDeclining, UsefulLifeMonths: 60}, money.FromInt(10000000), 4 * 365, money.FromInt(1080000), money.Zero},
+        {"double-declining end of life", models.DepreciationPolicy{Method: MethodDoubleDeclining, UsefulLifeMonths: 60}, money.FromInt(10000000), 5 * 365, money.Zero, money.Zero},
+        {"double-declining 18 months", models.DepreciationPolicy{Method: MethodDoubleDeclining, UsefulLifeMonths: 18}, money.FromInt(9000000), 2 * 365, money.Zero, money.Zero},
+        {"sum-of-years-digits one year", models.DepreciationPolicy{Method: MethodSumOfYearsDigits, UsefulLifeMonths: 60}, money.FromInt(15000000), 365, money.FromInt(10000000), money.Zero},
+        // 5/15 + 4/15 × 0.2 of the second year
+        {"sum-of-years-digits partial year", models.DepreciationPolicy{Method: MethodSumOfYearsDigits, UsefulLifeMonths: 60}, money.FromInt(15000000), 365 + 73, money.FromInt(9200000), money.Zero},
+        {"sum-of-years-digits end of life", models.DepreciationPolicy{Method: MethodSumOfYearsDigits, UsefulLifeMonths: 60}, money.FromInt(15000000), 5 * 365, money.Zero, money.Zero},
+        {"declining above residual", models.DepreciationPolicy{Method: MethodDecliningBalance, RatePercent: money.MustParseRate("25")}, money.FromInt(1000000), 365, money.FromInt(750000), money.FromInt(700000)},
+        {"declining stops at residual", models.DepreciationPolicy{Method: MethodDecliningBalance, RatePercent: money.MustParseRate("25")}, money.FromInt(1000000), 730, money.FromInt(700000), money.FromInt(700000)},
+        {"straight-line with residual", models.DepreciationPolicy{Method: MethodStraightLine, UsefulLifeMonths: 60}, money.FromInt(12000000), 365, money.FromInt(10000000), money.FromInt(2000000)},
+        {"straight-line after life with residual", models.DepreciationPolicy{Method: MethodStraightLine, UsefulLifeMonths: 60}, money.FromInt(12000000), 3000, money.FromInt(2000000), money.FromInt(2000000)},
+        // year 4: declining 864.000 beats straight line (2.160.000 - 1.000.000) / 2
+        {"double-declining with residual", models.DepreciationPolicy{Method: MethodDoubleDeclining, UsefulLifeMonths: 60}, money.FromInt(10000000), 4 * 365, money.FromInt(1296000), money.FromInt(1000000)},
+        {"double-declining end of life with residual", models.DepreciationPolicy{Method: MethodDoubleDeclining, UsefulLifeMonths: 60}, money.FromInt(10000000), 5 * 365, money.FromInt(1000000), money.FromInt(1000000)},
+        {"sum-of-years-digits with residual", models.DepreciationPolicy{Method: MethodSumOfYearsDigits, UsefulLifeMonths: 60}, money.FromInt(15000000), 365, money.FromInt(11000000), money.FromInt(3000000)},
+        {"sum-of-years-digits end of life with residual", models.DepreciationPolicy{Method: MethodSumOfYearsDigits, UsefulLifeMonths: 60}, money.FromInt(15000000), 6 * 365, money.FromInt(3000000), money.FromInt(3000000)},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := mustMethod(t, tt.policy).BookValue(tt.cost, tt.residual, tt.days); got != tt.expected {
+                t.Errorf("expected book value %s, got %s", tt.expected, got)
+            }
+        })
+    }
+}
+
+func TestDepreciationMethods_Describe(t *testing.T) {
+    tests := []struct {
+        policy            models.DepreciationPolicy
+        describe, formula string
+    }{
+        {DefaultDepreciationPolicy, "Saldo Menurun 20% per tahun", "Nilai Sekarang = Harga Awal × (1 - 0.20)^tahun, minimal Nilai Residu"},
+        {models.DepreciationPolicy{Method: MethodDecliningBalance, RatePercent: money.MustParseRate("12.5")}, "Saldo Menurun 12.5% per tahun", "Nilai Sekarang = Harga Awal × (1 - 0.125)^tahun, minimal Nilai Residu"},
+        {models.DepreciationPolicy{Method: MethodStraightLine, UsefulLifeMonths: 18}, "Garis Lurus, umur manfaat 18 bulan", "Nilai Sekarang = Harga Awal - (Harga Awal - Nilai Residu) × tahun / 1.5"},
+        {models.DepreciationPolicy{Method: MethodDoubleDeclining, UsefulLifeMonths: 48}, "Saldo Menurun Ganda 50% per tahun, umur manfaat 48 bulan", "Depresiasi per tahun = Nilai Buku × 0.50, beralih ke garis lurus bila lebih besar; mencapai Nilai Residu setelah 4 tahun"},
+        {models.DepreciationPolicy{Method: MethodSumOfYearsDigits, UsefulLifeMonths: 48}, "Jumlah Angka Tahun, umur manfaat 4 tahun", "Depresiasi tahun ke-n = (Harga Awal - Nilai Residu) × (4 - n + 1) / 10"},
+    }
+    for _, tt := range tests {
+        method := mustMethod(t, tt.policy)
+        if method.Describe() != tt.describe || method.Formula() != tt.formula {
+            t.Errorf("unexpected description %q / %q", method.Describe(), method.Formula())
+        }
+    }
+}
+
+func TestNewDepreciationMethod_Invalid(t *testing.T) {
+    tests := []struct {
+        policy models.DepreciationPolicy
+        field  string
+    }{
+        {models.DepreciationPolicy{Method: "units-of-production"}, "method"},
+        {models.DepreciationPolicy{Method: MethodStraightLine}, "useful life"},
+        {models.DepreciationPolicy{Method: MethodDoubleDeclining, UsefulLifeMonths: -12}, "useful life"},
+        {models.DepreciationPolicy{Method: MethodSumOfYearsDigits, UsefulLifeMonths: 18}, "useful life"},
+        {models.DepreciationPolicy{Method: MethodDecliningBalance}, "rate"},
+        {models.DepreciationPolicy{Method: MethodDecliningBalance, RatePercent: money.MustParseRate("100")}, "rate"},
+        {models.DepreciationPolicy{Method: MethodStraightLine, UsefulLifeMonths: 60, SalvageValue: money.FromInt(-1)}, "salvage value"},
+        {models.DepreciationPolicy{Method: MethodStraightLine, UsefulLifeMonths: 60, SalvagePercent: money.MustParseRate("100")}, "salvage value"},
+        {models.DepreciationPolicy{Method: MethodStraightLine, UsefulLifeMonths: 60, SalvageValue: money.FromInt(1), SalvagePercent: money.MustParseRate("10")}, "salvage value"},
+    }
+    for _, tt := range tests {
+        _, err := NewDepreciationMethod(tt.policy)
+        var validationErr *apperrors.ValidationError
+        if !errors.As(err, &validationErr) || validationErr.Field != tt.field {
+            t.Errorf("expected validation error on %s for %+v, got %v", tt.field, tt.policy, err)
+        }
+    }
+}
+
+func TestEffectivePolicy(t *testing.T) {
+    item := models.DepreciationPolicy{UsefulLifeMonths: 36}
+    category := models.DepreciationPolicy{Method: MethodStraightLine, UsefulLifeMonths: 60}
+
+    got := EffectivePolicy(item, category, DefaultDepreciationPolicy)
+    expected := models.DepreciationPolicy{Method: MethodStraightLine, UsefulLifeMonths: 36, RatePercent: money.MustParseRate("20")}
+    if got != expected {
+        t.Errorf("expected %+v, got %+v", expected, got)
+    }
+
+    // the salvage amount of the item replaces the salvage percentage of the category
+    item.SalvageValue = money.FromInt(500000)
+    category.SalvagePercent = money.MustParseRate("10")
+    got = EffectivePolicy(item, category, DefaultDepreciationPolicy)
+    if got.SalvageValue != item.SalvageValue || !got.SalvagePercent.IsZero() {
+        t.Errorf("expected salvage to be inherited as a whole, got %+v", got)
+    }
+    got = EffectivePolicy(models.DepreciationPolicy{}, category, DefaultDepreciationPolicy)
+    if got.SalvagePercent != category.SalvagePercent {
+        t.Errorf("expected the salvage percentage of the category, got %+v", got)
+    }
+}
+
+func TestResidual(t *testing.T) {
+    cost := money.MustParse("1000000.50")
+    tests := []struct {
+        policy   models.DepreciationPolicy
+        expected money.Money
+    }{
+        {models.DepreciationPolicy{}, money.Zero},
+        {models.DepreciationPolicy{SalvageValue: money.FromInt(250000)}, money.FromInt(250000)},
+        {models.DepreciationPolicy{SalvageValue: money.FromInt(2000000)}, cost},
+        // 1.000.000,50 × 10% = 100.000,05
+        {models.DepreciationPolicy{SalvagePercent: money.MustParseRate("10")}, money.MustParse("100000.05")},
+    }
+    for _, tt := range tests {
+        if got := Residual(tt.policy, cost); got != tt.expected {
+            t.Errorf("expected residual %s for %+v, got %s", tt.expected, tt.policy, got)
+        }
+    }
+}
+
+func TestItemService_GetInvestmentSummary_ByMethod(t *testing.T) {
+    now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+    mockCatRepo := &MockCategoryRepository{categories: []models.Category{
+        {ID: 1, Name: "Furniture", DepreciationPolicy: models.DepreciationPolicy{Method: MethodStraightLine, UsefulLifeMonths: 60}},
+        {ID: 2, Name: "Elektronik"},
+    }}
+    mockItemRepo := &MockItemRepository{items: []models.Item{
+        {ID: 1, CategoryID: 1, Price: money.FromInt(12000000), PurchaseDate: now.AddDate(0, 0, -365)},
+        {ID: 2, CategoryID: 2, Price: money.FromInt(1000000), PurchaseDate: now.AddDate(0, 0, -365)},
+        {ID: 3, CategoryID: 1, Price: money.FromInt(15000000), PurchaseDate: now.AddDate(0, 0, -365),
+            DepreciationPolicy: models.DepreciationPolicy{Method: MethodSumOfYearsDigits}},
+        {ID: 4, CategoryID: 1, Price: money.FromInt(3000000), PurchaseDate: now.AddDate(0, 0, -730)},
+    }}
+
+    service := NewItemService(mockItemRepo, mockCatRepo)
+    service.SetClock(func() time.Time { return now })
+    summary, err := service.GetInvestmentSummary(context.Background(), "IDR")
+    if err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+
+    // 9.600.000 + 1.800.000 straight-line, 800.000 declining, 10.000.000 sum-of-years-digits
+    if summary.TotalCurrent != money.FromInt(22200000) {
+        t.Errorf("expected total current 22200000, got %s", summary.TotalCurrent)
+    }
+    if len(summary.Methods) != 3 {
+        t.Fatalf("expected 3 methods, got %+v", summary.Methods)
+    }
+    straightLine := summary.Methods[0]
+    if straightLine.Method != MethodStraightLine || straightLine.Items != 2 || straightLine.TotalCurrent != money.FromInt(11400000) {
+        t.Errorf("unexpected straight-line subtotal %+v", straightLine)
+    }
+    if summary.Methods[1].Method != MethodDecliningBalance || summary.Methods[2].Method != MethodSumOfYearsDigits {
+        t.Errorf("expected methods in order of first use, got %+v", summary.Methods)
+    }
+}
+
+func TestItemService_Create_ValidatesPolicy(t *testing.T) {
+    mockCatRepo := &MockCategoryRepository{categories: []models.Category{
+        {ID: 1, Name: "Elektronik"},
+        {ID: 2, Name: "Furniture", DepreciationPolicy: models.DepreciationPolicy{UsefulLifeMonths: 96}},
+    }}
+    service := NewItemService(&MockItemRepository{}, mockCatRepo)
+    straightLine := models.DepreciationPolicy{Method: MethodStraightLine}
+
+    _, err := service.Create(context.Background(), "Laptop", 1, money.FromInt(1000), "IDR", time.Now(), straightLine)
+    if !errors.Is(err, apperrors.ErrValidation) {
+        t.Errorf("expected validation error for straight-line without a useful life, got %v", err)
+    }
+
+    item, err := service.Create(context.Background(), "Meja", 2, money.FromInt(1000), "IDR", time.Now(), straightLine)
+    if err != nil {
+        t.Fatalf("expected the useful life of the category to be inherited, got %v", err)
+    }
+    if item.Method != MethodStraightLine || item.UsefulLifeMonths != 0 {
+        t.Errorf("expected only the item's own policy to be stored, got %+v", item.DepreciationPolicy)
+    }
+
+    _, err = service.Create(context.Background(), "Kursi", 2, money.FromInt(1000), "IDR", time.Now(), models.DepreciationPolicy{SalvageValue: money.FromInt(1001)})
+    if !errors.Is(err, apperrors.ErrValidation) {
+        t.Errorf("expected validation error for a salvage value above the price, got %v", err)
+    }
+}
+
+func TestItemService_GetItemDepreciation_SalvageInItemCurrency(t *testing.T) {
+    now := time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
+    mockCatRepo := &MockCategoryRepository{categories: []models.Category{
+        {ID: 1, Name: "Elektronik", DepreciationPolicy: models.DepreciationPolicy{Method: MethodStraightLine, UsefulLifeMonths: 12, SalvageValue: money.FromInt(100)}},
+    }}
+    mockItemRepo := &MockItemRepository{items: []models.Item{
+        {ID: 1, CategoryID: 1, Price: money.FromInt(1000), Currency: "USD", PurchaseDate: now.AddDate(-2, 0, 0)},
+    }}
+
+    service := NewItemService(mockItemRepo, mockCatRepo)
+    service.SetClock(func() time.Time { return now })
+    service.SetConverter(newTestFXService(t, "USD 2020-01-01 15000"))
+    dep, err := service.GetItemDepreciation(context.Background(), 1, "IDR")
+    if err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+
+    // the 100 USD salvage value of the category is converted like the price
+    if dep.ResidualValue != money.FromInt(1500000) || dep.CurrentValue != money.FromInt(1500000) {
+        t.Errorf("expected the book value to stop at Rp 1.500.000, got residual %s, current %s", dep.ResidualValue, dep.CurrentValue)
+    }
+}
+
+func TestCategoryService_Create_ValidatesPolicy(t *testing.T) {
+    service := NewCategoryService(&MockCategoryRepository{})
+
+    if _, err := service.Create(context.Background(), "Furniture", "", models.DepreciationPolicy{Method: MethodStraightLine}, models.JournalAccounts{}); !errors.Is(err, apperrors.ErrValidation) {
+        t.Errorf("expected validation error for a method without its useful life, got %v", err)
+    }
+    if _, err := service.Create(context.Background(), "Furniture", "", models.DepreciationPolicy{UsefulLifeMonths: 96}, models.JournalAccounts{}); err != nil {
+        t.Errorf("expected a useful life without a method to be accepted, got %v", err)
+    }
+}
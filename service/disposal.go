@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"mini_project3/apperrors"
+	"mini_project3/models"
+	"mini_project3/money"
+	"mini_project3/utils"
+)
+
+// Ways an item can leave the inventory
+const (
+	DisposalSold     = "sold"
+	DisposalScrapped = "scrapped"
+	DisposalDonated  = "donated"
+)
+
+// DisposalMethods lists the accepted --method values of item dispose
+var DisposalMethods = []string{DisposalSold, DisposalScrapped, DisposalDonated}
+
+// Disposed reports whether item was disposed on or before the AsOf day;
+// active totals and replacements leave those items out
+func (s *ItemService) Disposed(item models.Item) bool {
+	return item.DisposedBy(s.AsOf())
+}
+
+// Dispose marks an item as sold, scrapped or donated on date for proceeds in
+// the currency of its price. Its depreciation stops on date; the returned
+// line holds the gain or loss against the book value in that currency.
+func (s *ItemService) Dispose(ctx context.Context, id int, date time.Time, method string, proceeds money.Money) (*models.DisposalLine, error) {
+	if err := utils.ValidateID(id); err != nil {
+		return nil, err
+	}
+
+	method = strings.ToLower(strings.TrimSpace(method))
+	valid := false
+	for _, name := range DisposalMethods {
+		valid = valid || name == method
+	}
+	if !valid {
+		return nil, apperrors.NewValidationError("disposal method", fmt.Sprintf("must be one of %s, got '%s'", strings.Join(DisposalMethods, ", "), method))
+	}
+	if proceeds.IsNegative() {
+		return nil, apperrors.NewValidationError("proceeds", "must not be negative")
+	}
+	if method == DisposalDonated && !proceeds.IsZero() {
+		return nil, apperrors.NewValidationError("proceeds", "must be 0 for a donated item")
+	}
+
+	var line models.DisposalLine
+	err := s.tx.WithTx(ctx, func(repos Repos) error {
+		item, err := repos.Items.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if item.DisposedAt != nil {
+			return &apperrors.AlreadyDisposedError{ID: item.ID, DisposedAt: *item.DisposedAt}
+		}
+		if date.Before(item.PurchaseDate) {
+			return apperrors.NewValidationError("disposal date", fmt.Sprintf("must not be before %s, the purchase date of item %d, got %s",
+				item.PurchaseDate.Format("2006-01-02"), item.ID, date.Format("2006-01-02")))
+		}
+		if date.After(s.AsOf()) {
+			return apperrors.NewValidationError("disposal date", fmt.Sprintf("must not be after %s, got %s", s.AsOf().Format("2006-01-02"), date.Format("2006-01-02")))
+		}
+
+		category, err := repos.Categories.GetByID(ctx, item.CategoryID)
+		if err != nil {
+			return err
+		}
+
+		item.Disposal = models.Disposal{DisposedAt: &date, DisposalMethod: method, DisposalProceeds: proceeds}
+		if line, err = s.disposalIn(ctx, *item, *category, currencyOf(*item)); err != nil {
+			return err
+		}
+		return repos.Items.Dispose(ctx, item.ID, item.Disposal)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &line, nil
+}
+
+// disposalIn reports a disposed item in currency: its book value frozen on
+// the disposal date, see bookIn, against the proceeds converted at the rate
+// of that date
+func (s *ItemService) disposalIn(ctx context.Context, item models.Item, category models.Category, currency string) (models.DisposalLine, error) {
+	converted, method, residual, err := s.bookIn(ctx, item, category, currency)
+	if err != nil {
+		return models.DisposalLine{}, err
+	}
+	bookValue := method.BookValue(converted.Price, residual, daysUsedOn(converted, *item.DisposedAt))
+
+	proceeds, err := s.converter.Convert(ctx, item.DisposalProceeds, currencyOf(item), currency, *item.DisposedAt)
+	if err != nil {
+		return models.DisposalLine{}, fmt.Errorf("error converting proceeds of item %d to %s: %w", item.ID, currency, err)
+	}
+
+	return models.DisposalLine{
+		ID:                      item.ID,
+		Name:                    item.Name,
+		CategoryName:            item.CategoryName,
+		PurchaseDate:            item.PurchaseDate,
+		DisposedAt:              *item.DisposedAt,
+		DisposalMethod:          item.DisposalMethod,
+		Currency:                currency,
+		PurchaseValue:           converted.Price,
+		AccumulatedDepreciation: converted.Price.Sub(bookValue),
+		BookValue:               bookValue,
+		Proceeds:                proceeds,
+		GainLoss:                proceeds.Sub(bookValue),
+	}, nil
+}
+
+// GetDisposalReport lists the items disposed by the AsOf day in currency,
+// in the order they were disposed
+func (s *ItemService) GetDisposalReport(ctx context.Context, currency string) (*models.DisposalReport, error) {
+	currency, err := NormalizeCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.itemRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := s.categoriesByID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.DisposalReport{Currency: currency, Items: []models.DisposalLine{}}
+	for _, item := range items {
+		if !s.Disposed(item) {
+			continue
+		}
+		line, err := s.disposalIn(ctx, item, categories[item.CategoryID], currency)
+		if err != nil {
+			return nil, err
+		}
+		report.Items = append(report.Items, line)
+		report.TotalPurchase = report.TotalPurchase.Add(line.PurchaseValue)
+		report.TotalBookValue = report.TotalBookValue.Add(line.BookValue)
+		report.TotalProceeds = report.TotalProceeds.Add(line.Proceeds)
+		report.TotalGainLoss = report.TotalGainLoss.Add(line.GainLoss)
+	}
+	sort.SliceStable(report.Items, func(i, j int) bool {
+		return report.Items[i].DisposedAt.Before(report.Items[j].DisposedAt)
+	})
+	return report, nil
+}
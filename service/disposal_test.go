@@ -0,0 +1,95 @@
+package service
+
+import (
+    "context"
+    "errors"
+    "testing"
+    "time"
+
+    "mini_project3/apperrors"
+    "mini_project3/models"
+    "mini_project3/money"
+)
+
+func TestItemService_Dispose_GainLoss(t *testing.T) {
+    purchased := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+    service, itemRepo := furnitureService(models.Item{ID: 1, Name: "Meja", CategoryID: 1, Price: money.FromInt(1460000), PurchaseDate: purchased})
+
+    // 365 of 1460 days used of 1.314.000 above the 10% salvage: book value 1.131.500
+    line, err := service.Dispose(context.Background(), 1, purchased.AddDate(0, 0, 365), " Sold ", money.FromInt(1200000))
+    if err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+    if line.BookValue != money.FromInt(1131500) || line.GainLoss != money.FromInt(68500) || line.DisposalMethod != DisposalSold {
+        t.Errorf("expected book value 1131500 and gain 68500, got %+v", line)
+    }
+
+    disposed := itemRepo.items[0]
+    if disposed.DisposedAt == nil || disposed.DisposalProceeds != money.FromInt(1200000) {
+        t.Fatalf("expected the disposal to be stored, got %+v", disposed.Disposal)
+    }
+    // depreciation is frozen on the disposal date
+    if days := service.DaysUsed(disposed); days != 365 {
+        t.Errorf("expected 365 days used after disposal, got %d", days)
+    }
+
+    summary, err := service.GetInvestmentSummary(context.Background(), "IDR")
+    if err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+    if !summary.TotalOriginal.IsZero() || len(summary.Methods) != 0 {
+        t.Errorf("expected disposed items to be left out of the totals, got %+v", summary)
+    }
+
+    report, err := service.GetDisposalReport(context.Background(), "IDR")
+    if err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+    if len(report.Items) != 1 || report.TotalGainLoss != money.FromInt(68500) {
+        t.Errorf("expected one disposal with a gain of 68500, got %+v", report)
+    }
+
+    // as of a day before the disposal the item is still in use
+    service.SetClock(func() time.Time { return purchased.AddDate(0, 0, 300) })
+    if report, _ := service.GetDisposalReport(context.Background(), "IDR"); len(report.Items) != 0 {
+        t.Errorf("expected no disposals before the disposal date, got %+v", report.Items)
+    }
+}
+
+func TestItemService_Dispose_Validation(t *testing.T) {
+    disposedAt := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+    service, _ := furnitureService(
+        models.Item{ID: 1, Name: "Meja", CategoryID: 1, Price: money.FromInt(1000000), PurchaseDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+        models.Item{ID: 2, Name: "Kursi", CategoryID: 1, Price: money.FromInt(500000), PurchaseDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+            Disposal: models.Disposal{DisposedAt: &disposedAt, DisposalMethod: DisposalScrapped}},
+    )
+    date := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+    tests := []struct {
+        name     string
+        id       int
+        date     time.Time
+        method   string
+        proceeds money.Money
+        field    string
+    }{
+        {"unknown method", 1, date, "lost", money.Zero, "disposal method"},
+        {"negative proceeds", 1, date, DisposalSold, money.FromInt(-1), "proceeds"},
+        {"donated with proceeds", 1, date, DisposalDonated, money.FromInt(1000), "proceeds"},
+        {"before purchase", 1, time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC), DisposalSold, money.Zero, "disposal date"},
+        {"after as-of date", 1, time.Date(2026, 1, 16, 0, 0, 0, 0, time.UTC), DisposalSold, money.Zero, "disposal date"},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            _, err := service.Dispose(context.Background(), tt.id, tt.date, tt.method, tt.proceeds)
+            var validationErr *apperrors.ValidationError
+            if !errors.As(err, &validationErr) || validationErr.Field != tt.field {
+                t.Errorf("expected validation error on %s, got %v", tt.field, err)
+            }
+        })
+    }
+
+    if _, err := service.Dispose(context.Background(), 2, date, DisposalSold, money.Zero); !errors.Is(err, apperrors.ErrConflict) {
+        t.Errorf("expected ErrConflict disposing an item twice, got %v", err)
+    }
+}
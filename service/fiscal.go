@@ -0,0 +1,165 @@
+package service
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"mini_project3/apperrors"
+	"mini_project3/models"
+	"mini_project3/money"
+)
+
+// TaxGroup is an asset group of the Indonesian fiscal book (UU PPh Pasal 11,
+// PMK 96/PMK.03/2009) with its prescribed useful life. The straight-line rate
+// is 1 / LifeYears and the declining-balance rate twice that.
+type TaxGroup struct {
+	Code      string
+	Name      string
+	LifeYears int
+	// Building groups may only be depreciated with straight line
+	Building bool
+}
+
+// TaxGroups lists the fiscal asset groups in the order of the regulation
+var TaxGroups = []TaxGroup{
+	{Code: "kelompok-1", Name: "Kelompok 1", LifeYears: 4},
+	{Code: "kelompok-2", Name: "Kelompok 2", LifeYears: 8},
+	{Code: "kelompok-3", Name: "Kelompok 3", LifeYears: 16},
+	{Code: "kelompok-4", Name: "Kelompok 4", LifeYears: 20},
+	{Code: "bangunan-permanen", Name: "Bangunan Permanen", LifeYears: 20, Building: true},
+	{Code: "bangunan-tidak-permanen", Name: "Bangunan Tidak Permanen", LifeYears: 10, Building: true},
+}
+
+// TaxMethods are the methods the fiscal book allows, default first
+var TaxMethods = []string{MethodStraightLine, MethodDecliningBalance}
+
+// LookupTaxGroup finds a tax group by its code
+func LookupTaxGroup(code string) (TaxGroup, bool) {
+	for _, group := range TaxGroups {
+		if group.Code == code {
+			return group, true
+		}
+	}
+	return TaxGroup{}, false
+}
+
+// TaxGroupCodes returns the codes of TaxGroups, e.g. for flag help
+func TaxGroupCodes() []string {
+	codes := make([]string, len(TaxGroups))
+	for i, group := range TaxGroups {
+		codes[i] = group.Code
+	}
+	return codes
+}
+
+// validateTaxPolicy checks the fiscal fields a policy sets
+func validateTaxPolicy(policy models.DepreciationPolicy) error {
+	if policy.TaxGroup != "" {
+		if _, ok := LookupTaxGroup(policy.TaxGroup); !ok {
+			return apperrors.NewValidationError("tax group", fmt.Sprintf("must be one of %s, got '%s'", strings.Join(TaxGroupCodes(), ", "), policy.TaxGroup))
+		}
+	}
+	if policy.TaxMethod != "" && policy.TaxMethod != MethodStraightLine && policy.TaxMethod != MethodDecliningBalance {
+		return apperrors.NewValidationError("tax method", fmt.Sprintf("must be one of %s, got '%s'", strings.Join(TaxMethods, ", "), policy.TaxMethod))
+	}
+	return nil
+}
+
+// NewTaxMethod builds the fiscal depreciation of a merged policy, or returns
+// nil when it has no tax group. The method defaults to straight line.
+func NewTaxMethod(policy models.DepreciationPolicy) (DepreciationMethod, error) {
+	if err := validateTaxPolicy(policy); err != nil {
+		return nil, err
+	}
+	if policy.TaxGroup == "" {
+		return nil, nil
+	}
+
+	group, _ := LookupTaxGroup(policy.TaxGroup)
+	switch policy.TaxMethod {
+	case "", MethodStraightLine:
+		return TaxStraightLine{Group: group}, nil
+	default:
+		if group.Building {
+			return nil, apperrors.NewValidationError("tax method", fmt.Sprintf("%s may only use %s", group.Name, MethodStraightLine))
+		}
+		return TaxDecliningBalance{Group: group}, nil
+	}
+}
+
+// ==================== TAX STRAIGHT LINE ====================
+
+// TaxStraightLine writes off the purchase value evenly over the life of its
+// tax group, without a residual value
+type TaxStraightLine struct {
+	Group TaxGroup
+}
+
+func (m TaxStraightLine) Name() string { return MethodStraightLine }
+
+func (m TaxStraightLine) rate() *big.Rat {
+	return big.NewRat(1, int64(m.Group.LifeYears))
+}
+
+func (m TaxStraightLine) Describe() string {
+	return fmt.Sprintf("%s, Garis Lurus %s%% per tahun, masa manfaat %d tahun", m.Group.Name, percent(m.rate()), m.Group.LifeYears)
+}
+
+func (m TaxStraightLine) Formula() string {
+	return fmt.Sprintf("Penyusutan per tahun = Harga Perolehan × %s", decimal(m.rate()))
+}
+
+func (m TaxStraightLine) AnnualRate() float64 {
+	rate, _ := m.rate().Float64()
+	return rate
+}
+
+// BookValue ignores residual: the fiscal book always depreciates to zero
+func (m TaxStraightLine) BookValue(cost, residual money.Money, daysUsed int) money.Money {
+	return StraightLine{LifeMonths: m.Group.LifeYears * 12}.BookValue(cost, money.Zero, daysUsed)
+}
+
+// ==================== TAX DECLINING BALANCE ====================
+
+// TaxDecliningBalance writes off twice the straight-line rate of the
+// remaining book value every year, and the whole remaining book value at
+// the end of the life of its tax group
+type TaxDecliningBalance struct {
+	Group TaxGroup
+}
+
+func (m TaxDecliningBalance) Name() string { return MethodDecliningBalance }
+
+func (m TaxDecliningBalance) rate() *big.Rat {
+	return big.NewRat(2, int64(m.Group.LifeYears))
+}
+
+func (m TaxDecliningBalance) Describe() string {
+	return fmt.Sprintf("%s, Saldo Menurun %s%% per tahun, masa manfaat %d tahun", m.Group.Name, percent(m.rate()), m.Group.LifeYears)
+}
+
+func (m TaxDecliningBalance) Formula() string {
+	return fmt.Sprintf("Penyusutan per tahun = Nilai Sisa Buku × %s; sisa buku disusutkan sekaligus setelah %d tahun", decimal(m.rate()), m.Group.LifeYears)
+}
+
+func (m TaxDecliningBalance) AnnualRate() float64 {
+	rate, _ := m.rate().Float64()
+	return rate
+}
+
+// BookValue charges the rate in proportion to the days of a partial year,
+// like the monthly pro rata of the first fiscal year, rounding each period
+// to the cent. Residual is ignored.
+func (m TaxDecliningBalance) BookValue(cost, residual money.Money, daysUsed int) money.Money {
+	if daysUsed >= m.Group.LifeYears*daysPerYear {
+		return money.Zero
+	}
+
+	book := cost
+	for start := 0; start < daysUsed; start += daysPerYear {
+		period := big.NewRat(int64(min(daysPerYear, daysUsed-start)), daysPerYear)
+		book = book.Sub(book.Mul(new(big.Rat).Mul(m.rate(), period)))
+	}
+	return book
+}
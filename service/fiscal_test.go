@@ -0,0 +1,119 @@
+package service
+
+import (
+    "context"
+    "errors"
+    "testing"
+    "time"
+
+    "mini_project3/apperrors"
+    "mini_project3/models"
+    "mini_project3/money"
+)
+
+func mustTaxMethod(t *testing.T, policy models.DepreciationPolicy) DepreciationMethod {
+    t.Helper()
+    method, err := NewTaxMethod(policy)
+    if err != nil || method == nil {
+        t.Fatalf("expected a tax method for %+v, got %v (%v)", policy, method, err)
+    }
+    return method
+}
+
+func TestTaxMethods_BookValue(t *testing.T) {
+    tests := []struct {
+        name     string
+        policy   models.DepreciationPolicy
+        cost     money.Money
+        days     int
+        expected money.Money
+    }{
+        {"kelompok 1 straight-line one year", models.DepreciationPolicy{TaxGroup: "kelompok-1"}, money.FromInt(12000000), 365, money.FromInt(9000000)},
+        {"kelompok 1 straight-line end of life", models.DepreciationPolicy{TaxGroup: "kelompok-1"}, money.FromInt(12000000), 4 * 365, money.Zero},
+        {"bangunan permanen straight-line one year", models.DepreciationPolicy{TaxGroup: "bangunan-permanen"}, money.FromInt(1000000000), 365, money.FromInt(950000000)},
+        {"kelompok 2 declining one year", models.DepreciationPolicy{TaxGroup: "kelompok-2", TaxMethod: MethodDecliningBalance}, money.FromInt(8000000), 365, money.FromInt(6000000)},
+        {"kelompok 2 declining two years", models.DepreciationPolicy{TaxGroup: "kelompok-2", TaxMethod: MethodDecliningBalance}, money.FromInt(8000000), 730, money.FromInt(4500000)},
+        // 10.000.000 × 0.5 × 182/365 = 2.493.150,6849 → 2.493.150,68
+        {"kelompok 1 declining partial year", models.DepreciationPolicy{TaxGroup: "kelompok-1", TaxMethod: MethodDecliningBalance}, money.FromInt(10000000), 182, money.MustParse("7506849.32")},
+        {"kelompok 2 declining written off at end of life", models.DepreciationPolicy{TaxGroup: "kelompok-2", TaxMethod: MethodDecliningBalance}, money.FromInt(8000000), 8 * 365, money.Zero},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := mustTaxMethod(t, tt.policy).BookValue(tt.cost, money.Zero, tt.days); got != tt.expected {
+                t.Errorf("expected book value %s, got %s", tt.expected, got)
+            }
+        })
+    }
+}
+
+func TestNewTaxMethod(t *testing.T) {
+    if method, err := NewTaxMethod(models.DepreciationPolicy{TaxMethod: MethodDecliningBalance}); method != nil || err != nil {
+        t.Errorf("expected no fiscal book without a tax group, got %v (%v)", method, err)
+    }
+
+    method := mustTaxMethod(t, models.DepreciationPolicy{TaxGroup: "kelompok-3", TaxMethod: MethodDecliningBalance})
+    if method.Describe() != "Kelompok 3, Saldo Menurun 12.5% per tahun, masa manfaat 16 tahun" {
+        t.Errorf("unexpected description %q", method.Describe())
+    }
+
+    tests := []struct {
+        policy models.DepreciationPolicy
+        field  string
+    }{
+        {models.DepreciationPolicy{TaxGroup: "kelompok-5"}, "tax group"},
+        {models.DepreciationPolicy{TaxGroup: "kelompok-1", TaxMethod: MethodDoubleDeclining}, "tax method"},
+        {models.DepreciationPolicy{TaxGroup: "bangunan-permanen", TaxMethod: MethodDecliningBalance}, "tax method"},
+    }
+    for _, tt := range tests {
+        _, err := NewTaxMethod(tt.policy)
+        var validationErr *apperrors.ValidationError
+        if !errors.As(err, &validationErr) || validationErr.Field != tt.field {
+            t.Errorf("expected validation error on %s for %+v, got %v", tt.field, tt.policy, err)
+        }
+    }
+}
+
+func TestItemService_GetFiscalReport(t *testing.T) {
+    now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+    mockCatRepo := &MockCategoryRepository{categories: []models.Category{
+        {ID: 1, Name: "Elektronik", DepreciationPolicy: models.DepreciationPolicy{TaxGroup: "kelompok-1"}},
+        {ID: 2, Name: "Furniture"},
+    }}
+    mockItemRepo := &MockItemRepository{items: []models.Item{
+        {ID: 1, Name: "Laptop", CategoryID: 1, Price: money.FromInt(12000000), PurchaseDate: now.AddDate(0, 0, -365)},
+        {ID: 2, Name: "Meja", CategoryID: 2, Price: money.FromInt(1000000), PurchaseDate: now.AddDate(0, 0, -365)},
+        {ID: 3, Name: "Lemari Besi", CategoryID: 2, Price: money.FromInt(8000000), PurchaseDate: now.AddDate(0, 0, -365),
+            DepreciationPolicy: models.DepreciationPolicy{TaxGroup: "kelompok-2", TaxMethod: MethodDecliningBalance}},
+    }}
+
+    service := NewItemService(mockItemRepo, mockCatRepo)
+    service.SetClock(func() time.Time { return now })
+    report, err := service.GetFiscalReport(context.Background(), "IDR")
+    if err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+
+    if len(report.Items) != 2 || report.UnmappedItems != 1 {
+        t.Fatalf("expected 2 mapped items and 1 unmapped, got %+v", report)
+    }
+    // commercial 20% declining: 9.600.000, fiscal kelompok 1 straight line: 9.000.000
+    laptop := report.Items[0]
+    if laptop.CommercialValue != money.FromInt(9600000) || laptop.FiscalValue != money.FromInt(9000000) || laptop.TimingDifference != money.FromInt(-600000) {
+        t.Errorf("unexpected laptop line %+v", laptop)
+    }
+    if report.TotalCommercial != money.FromInt(16000000) || report.TotalFiscal != money.FromInt(15000000) || report.TotalTimingDifference != money.FromInt(-1000000) {
+        t.Errorf("unexpected totals %+v", report)
+    }
+}
+
+func TestItemService_Create_ValidatesTaxGroup(t *testing.T) {
+    mockCatRepo := &MockCategoryRepository{categories: []models.Category{
+        {ID: 1, Name: "Gedung", DepreciationPolicy: models.DepreciationPolicy{TaxGroup: "bangunan-permanen"}},
+    }}
+    service := NewItemService(&MockItemRepository{}, mockCatRepo)
+
+    _, err := service.Create(context.Background(), "Gudang", 1, money.FromInt(1000), "IDR", time.Now(), models.DepreciationPolicy{TaxMethod: MethodDecliningBalance})
+    if !errors.Is(err, apperrors.ErrValidation) {
+        t.Errorf("expected validation error for a declining building, got %v", err)
+    }
+}
\ No newline at end of file
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+
+	"mini_project3/apperrors"
+	"mini_project3/models"
+	"mini_project3/money"
+	"mini_project3/spreadsheet"
+)
+
+// BaseCurrency is the currency every exchange rate is quoted in; its own rate is always 1
+const BaseCurrency = "IDR"
+
+var currencyCodeRe = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// NormalizeCurrency upper-cases an ISO 4217 currency code; an empty code means BaseCurrency
+func NormalizeCurrency(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return BaseCurrency, nil
+	}
+	if !currencyCodeRe.MatchString(code) {
+		return "", apperrors.NewValidationError("currency", fmt.Sprintf("must be a 3-letter ISO 4217 code such as USD, got '%s'", code))
+	}
+	return code, nil
+}
+
+// ExchangeRateRepositoryInterface defines the contract for exchange rate repository
+type ExchangeRateRepositoryInterface interface {
+	Set(ctx context.Context, rate *models.ExchangeRate) error
+	GetAll(ctx context.Context, currency string) ([]models.ExchangeRate, error)
+	GetOnOrBefore(ctx context.Context, currency string, date time.Time) (*models.ExchangeRate, error)
+}
+
+// CurrencyConverter converts an amount between currencies at the rate valid on a date
+type CurrencyConverter interface {
+	Convert(ctx context.Context, amount money.Money, from, to string, date time.Time) (money.Money, error)
+}
+
+// noRates is the converter of an ItemService without an exchange rate table:
+// amounts can only be reported in their own currency
+type noRates struct{}
+
+func (noRates) Convert(ctx context.Context, amount money.Money, from, to string, date time.Time) (money.Money, error) {
+	if from == to {
+		return amount, nil
+	}
+	return money.Zero, &apperrors.RateNotFoundError{Currency: from, Date: date}
+}
+
+type FXService struct {
+	repo ExchangeRateRepositoryInterface
+	tx   TxManager
+}
+
+func NewFXService(repo ExchangeRateRepositoryInterface) *FXService {
+	return &FXService{repo: repo, tx: noTx{Repos{ExchangeRates: repo}}}
+}
+
+// SetTxManager makes imports run as units of work of tx, see ItemService.SetTxManager
+func (s *FXService) SetTxManager(tx TxManager) {
+	s.tx = tx
+}
+
+// day drops the time of day so a rate is keyed by its calendar date
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func (s *FXService) validate(currency string, rate money.Rate) (string, error) {
+	currency, err := NormalizeCurrency(currency)
+	if err != nil {
+		return "", err
+	}
+	if currency == BaseCurrency {
+		return "", apperrors.NewValidationError("currency", fmt.Sprintf("%s is the base currency and always has rate 1", BaseCurrency))
+	}
+	if rate.IsZero() {
+		return "", apperrors.NewValidationError("rate", "must be greater than 0")
+	}
+	return currency, nil
+}
+
+// Set stores the value of one unit of currency in BaseCurrency from date on
+func (s *FXService) Set(ctx context.Context, currency string, date time.Time, rate money.Rate) (*models.ExchangeRate, error) {
+	currency, err := s.validate(currency, rate)
+	if err != nil {
+		return nil, err
+	}
+
+	exchangeRate := &models.ExchangeRate{Currency: currency, Date: day(date), Rate: rate}
+	if err := s.repo.Set(ctx, exchangeRate); err != nil {
+		return nil, err
+	}
+	return exchangeRate, nil
+}
+
+// GetAll returns the stored rates of currency, or of every currency when it is empty
+func (s *FXService) GetAll(ctx context.Context, currency string) ([]models.ExchangeRate, error) {
+	if strings.TrimSpace(currency) != "" {
+		var err error
+		if currency, err = NormalizeCurrency(currency); err != nil {
+			return nil, err
+		}
+	}
+	return s.repo.GetAll(ctx, currency)
+}
+
+// Import reads a CSV of daily rates with a date,currency,rate header (in any
+// column order, extra columns are ignored). Every row is validated before
+// the first rate is stored, so a file with an invalid row imports nothing,
+// and the rates are stored in one unit of work.
+func (s *FXService) Import(ctx context.Context, r io.Reader) ([]models.ExchangeRate, error) {
+	rows, err := spreadsheet.ReadCSV(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, apperrors.NewValidationError("file", "is empty, expected a date,currency,rate header")
+	}
+
+	columns := map[string]int{}
+	for i, name := range rows[0].Cells {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"date", "currency", "rate"} {
+		if _, ok := columns[name]; !ok {
+			return nil, apperrors.NewValidationError("file", fmt.Sprintf("has no '%s' column, expected a date,currency,rate header", name))
+		}
+	}
+
+	var rates []models.ExchangeRate
+	for _, row := range rows[1:] {
+		rate, err := s.parseRow(row.Cells, columns)
+		if err != nil {
+			return nil, apperrors.NewValidationError(fmt.Sprintf("line %d", row.Line), err.Error())
+		}
+		rates = append(rates, rate)
+	}
+
+	err = s.tx.WithTx(ctx, func(repos Repos) error {
+		for i := range rates {
+			if err := repos.ExchangeRates.Set(ctx, &rates[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rates, nil
+}
+
+func (s *FXService) parseRow(record []string, columns map[string]int) (models.ExchangeRate, error) {
+	field := func(name string) string {
+		if i := columns[name]; i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	date, err := time.Parse("2006-01-02", field("date"))
+	if err != nil {
+		return models.ExchangeRate{}, fmt.Errorf("date must be in YYYY-MM-DD format, got '%s'", field("date"))
+	}
+	rate, err := money.ParseRate(field("rate"))
+	if err != nil {
+		return models.ExchangeRate{}, fmt.Errorf("rate %s", err)
+	}
+	currency, err := s.validate(field("currency"), rate)
+	if err != nil {
+		return models.ExchangeRate{}, err
+	}
+	return models.ExchangeRate{Currency: currency, Date: date, Rate: rate}, nil
+}
+
+// Rate returns the rate of currency valid on date: the latest one on or before it
+func (s *FXService) Rate(ctx context.Context, currency string, date time.Time) (money.Rate, error) {
+	if currency == BaseCurrency {
+		return money.RateOne, nil
+	}
+	rate, err := s.repo.GetOnOrBefore(ctx, currency, day(date))
+	if err != nil {
+		return money.Rate{}, err
+	}
+	return rate.Rate, nil
+}
+
+// Convert converts amount from one currency to another through BaseCurrency
+// at the rates valid on date, rounding once, half to even, to the cent
+func (s *FXService) Convert(ctx context.Context, amount money.Money, from, to string, date time.Time) (money.Money, error) {
+	if from == to {
+		return amount, nil
+	}
+
+	fromRate, err := s.Rate(ctx, from, date)
+	if err != nil {
+		return money.Zero, err
+	}
+	toRate, err := s.Rate(ctx, to, date)
+	if err != nil {
+		return money.Zero, err
+	}
+	return amount.Mul(new(big.Rat).Quo(fromRate.Rat(), toRate.Rat())), nil
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+    "context"
+    "errors"
+    "strings"
+    "testing"
+    "time"
+
+    "mini_project3/apperrors"
+    "mini_project3/models"
+    "mini_project3/money"
+    "mini_project3/repository"
+)
+
+func newTestFXService(t *testing.T, rates ...string) *FXService {
+    t.Helper()
+    service := NewFXService(repository.NewMemoryExchangeRateRepository(repository.NewMemoryStore()))
+    for _, line := range rates {
+        fields := strings.Fields(line)
+        date, _ := time.Parse("2006-01-02", fields[1])
+        if _, err := service.Set(context.Background(), fields[0], date, money.MustParseRate(fields[2])); err != nil {
+            t.Fatalf("unexpected error: %s", err)
+        }
+    }
+    return service
+}
+
+func TestNormalizeCurrency(t *testing.T) {
+    tests := map[string]string{"": "IDR", "usd": "USD", " SGD ": "SGD"}
+    for input, expected := range tests {
+        if got, err := NormalizeCurrency(input); err != nil || got != expected {
+            t.Errorf("NormalizeCurrency(%q) = %q, %v; expected %q", input, got, err, expected)
+        }
+    }
+
+    for _, input := range []string{"US", "USDT", "U$D", "123"} {
+        if _, err := NormalizeCurrency(input); !errors.Is(err, apperrors.ErrValidation) {
+            t.Errorf("expected validation error for %q, got %v", input, err)
+        }
+    }
+}
+
+func TestFXService_Set_Invalid(t *testing.T) {
+    service := newTestFXService(t)
+    date := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+    if _, err := service.Set(context.Background(), "IDR", date, money.MustParseRate("1")); !errors.Is(err, apperrors.ErrValidation) {
+        t.Errorf("expected validation error for the base currency, got %v", err)
+    }
+    if _, err := service.Set(context.Background(), "USD", date, money.MustParseRate("0")); !errors.Is(err, apperrors.ErrValidation) {
+        t.Errorf("expected validation error for a zero rate, got %v", err)
+    }
+}
+
+func TestFXService_Import(t *testing.T) {
+    service := newTestFXService(t)
+    csv := "rate,currency,date,source\n15400,usd,2024-01-01,BI\n 11650.25 ,SGD,2024-01-01,BI\n"
+
+    rates, err := service.Import(context.Background(), strings.NewReader(csv))
+    if err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+    if len(rates) != 2 || rates[0].Currency != "USD" || rates[1].Rate != money.MustParseRate("11650.25") {
+        t.Errorf("unexpected rates %+v", rates)
+    }
+
+    stored, _ := service.GetAll(context.Background(), "")
+    if len(stored) != 2 {
+        t.Errorf("expected 2 stored rates, got %d", len(stored))
+    }
+}
+
+func TestFXService_Import_ByteOrderMark(t *testing.T) {
+    service := newTestFXService(t)
+    // Excel starts the files it saves as "CSV UTF-8" with a byte order mark
+    csv := "\ufeffdate,currency,rate\n2024-01-01,USD,15400\n"
+
+    rates, err := service.Import(context.Background(), strings.NewReader(csv))
+    if err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+    if len(rates) != 1 || rates[0].Currency != "USD" {
+        t.Errorf("unexpected rates %+v", rates)
+    }
+}
+
+func TestFXService_Import_Invalid(t *testing.T) {
+    tests := []struct {
+        name  string
+        csv   string
+        field string
+    }{
+        {"empty", "", "file"},
+        {"unterminated quote", "date,currency,rate\n2024-01-01,\"USD,15400\n", "line 2"},
+        {"missing column", "date,currency\n2024-01-01,USD\n", "file"},
+        {"bad date", "date,currency,rate\n2024-01-01,USD,15400\n01/07/2024,USD,16350\n", "line 3"},
+        {"bad rate", "date,currency,rate\n2024-01-01,USD,15400\n2024-07-01,USD,-1\n", "line 3"},
+        {"base currency", "date,currency,rate\n2024-01-01,IDR,1\n", "line 2"},
+        {"bad currency", "date,currency,rate\n2024-01-01,DOLLAR,15400\n", "line 2"},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            service := newTestFXService(t)
+            _, err := service.Import(context.Background(), strings.NewReader(tt.csv))
+
+            var validationErr *apperrors.ValidationError
+            if !errors.As(err, &validationErr) || validationErr.Field != tt.field {
+                t.Fatalf("expected validation error on %s, got %v", tt.field, err)
+            }
+            if stored, _ := service.GetAll(context.Background(), ""); len(stored) != 0 {
+                t.Errorf("expected nothing imported, got %d rates", len(stored))
+            }
+        })
+    }
+}
+
+func TestFXService_Convert(t *testing.T) {
+    service := newTestFXService(t,
+        "USD 2024-01-01 15400",
+        "USD 2024-07-01 16350",
+        "SGD 2024-01-01 11650",
+    )
+    ctx := context.Background()
+
+    tests := []struct {
+        amount   money.Money
+        from, to string
+        date     time.Time
+        expected money.Money
+    }{
+        // the latest rate on or before the date is used
+        {money.FromInt(100), "USD", "IDR", time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC), money.FromInt(1540000)},
+        {money.FromInt(100), "USD", "IDR", time.Date(2024, 7, 1, 15, 0, 0, 0, time.UTC), money.FromInt(1635000)},
+        {money.FromInt(1000000), "IDR", "USD", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), money.MustParse("64.94")},
+        // cross rates go through IDR and are rounded once
+        {money.FromInt(100), "USD", "SGD", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), money.MustParse("132.19")},
+        {money.FromInt(5), "IDR", "IDR", time.Time{}, money.FromInt(5)},
+    }
+    for _, tt := range tests {
+        got, err := service.Convert(ctx, tt.amount, tt.from, tt.to, tt.date)
+        if err != nil || got != tt.expected {
+            t.Errorf("Convert(%s %s → %s on %s) = %s, %v; expected %s", tt.amount, tt.from, tt.to, tt.date.Format("2006-01-02"), got, err, tt.expected)
+        }
+    }
+
+    _, err := service.Convert(ctx, money.FromInt(100), "USD", "IDR", time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC))
+    var rateErr *apperrors.RateNotFoundError
+    if !errors.As(err, &rateErr) || !errors.Is(err, apperrors.ErrNotFound) {
+        t.Errorf("expected RateNotFoundError before the first rate, got %v", err)
+    }
+}
+
+func TestItemService_GetTotalInvestment_ForeignCurrency(t *testing.T) {
+    now := time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
+    mockItemRepo := &MockItemRepository{
+        items: []models.Item{
+            {ID: 1, Price: money.FromInt(15400000), Currency: "IDR", PurchaseDate: now},
+            {ID: 2, Price: money.FromInt(1000), Currency: "USD", PurchaseDate: now},
+        },
+    }
+
+    itemService := NewItemService(mockItemRepo, &MockCategoryRepository{})
+    itemService.SetClock(func() time.Time { return now })
+    if _, _, err := itemService.GetTotalInvestment(context.Background(), "IDR"); !errors.Is(err, apperrors.ErrNotFound) {
+        t.Errorf("expected missing rate error without a converter, got %v", err)
+    }
+
+    itemService.SetConverter(newTestFXService(t, "USD 2025-01-01 15400"))
+    totalOriginal, _, err := itemService.GetTotalInvestment(context.Background(), "usd")
+    if err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+    if totalOriginal != money.FromInt(2000) {
+        t.Errorf("expected total original 2000 USD, got %s", totalOriginal)
+    }
+}
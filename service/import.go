@@ -0,0 +1,298 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"mini_project3/apperrors"
+	"mini_project3/models"
+	"mini_project3/money"
+	"mini_project3/spreadsheet"
+)
+
+// ImportFields are the item fields the columns of an import file fill, in
+// the order of the default header; ImportRequired must be present
+var (
+	ImportFields   = []string{"name", "category", "price", "currency", "purchase_date", "method", "life", "rate", "salvage", "tax_group", "tax_method"}
+	ImportRequired = []string{"name", "category", "price", "purchase_date"}
+)
+
+// ImportOptions control how ItemService.Import reads and stores a file
+type ImportOptions struct {
+	// Columns maps an item field to the header of the column holding it;
+	// other fields are read from the column named like the field
+	Columns map[string]string
+	// CreateCategories creates the categories the file names that do not exist yet
+	CreateCategories bool
+	// SkipInvalid imports the valid rows when some are invalid, instead of none
+	SkipInvalid bool
+	// DryRun validates every row without storing anything
+	DryRun bool
+}
+
+// importColumns maps every field of ImportFields found in header to its column
+type importColumns map[string]int
+
+func newImportColumns(header []string, mapping map[string]string) (importColumns, error) {
+	known := map[string]bool{}
+	for _, field := range ImportFields {
+		known[field] = true
+	}
+	for field := range mapping {
+		if !known[field] {
+			return nil, apperrors.NewValidationError("column mapping", fmt.Sprintf("has unknown field '%s', expected one of %s", field, strings.Join(ImportFields, ", ")))
+		}
+	}
+
+	headerOf := func(field string) string {
+		if mapped, ok := mapping[field]; ok {
+			return mapped
+		}
+		return field
+	}
+	// the first of columns with the same header wins
+	byHeader := map[string]int{}
+	for i := len(header) - 1; i >= 0; i-- {
+		byHeader[strings.ToLower(strings.TrimSpace(header[i]))] = i
+	}
+
+	columns := importColumns{}
+	for _, field := range ImportFields {
+		if i, ok := byHeader[strings.ToLower(strings.TrimSpace(headerOf(field)))]; ok {
+			columns[field] = i
+		}
+	}
+	for _, field := range ImportRequired {
+		if _, ok := columns[field]; !ok {
+			return nil, apperrors.NewValidationError("file", fmt.Sprintf("has no '%s' column for the %s of the items", headerOf(field), field))
+		}
+	}
+	return columns, nil
+}
+
+// get returns the trimmed cell of field in row, empty when the file has no such column
+func (c importColumns) get(row spreadsheet.Row, field string) string {
+	if i, ok := c[field]; ok && i < len(row.Cells) {
+		return strings.TrimSpace(row.Cells[i])
+	}
+	return ""
+}
+
+// importCategories resolves the category names of an import file, by exact
+// name first and then ignoring case, and plans the missing ones. Names of
+// categories in the trash are still taken, so they are neither used nor planned.
+type importCategories struct {
+	byName  map[string]*models.Category
+	byFold  map[string]*models.Category
+	trashed map[string]int
+	create  bool
+	planned map[*models.Category]bool
+}
+
+func newImportCategories(categories []models.Category, trash []models.TrashEntry, create bool) *importCategories {
+	c := &importCategories{byName: map[string]*models.Category{}, byFold: map[string]*models.Category{}, trashed: map[string]int{}, create: create, planned: map[*models.Category]bool{}}
+	for i := range categories {
+		cat := &categories[i]
+		c.byName[cat.Name] = cat
+		if _, ok := c.byFold[strings.ToLower(cat.Name)]; !ok {
+			c.byFold[strings.ToLower(cat.Name)] = cat
+		}
+	}
+	for _, entry := range trash {
+		c.trashed[strings.ToLower(entry.Name)] = entry.ID
+	}
+	return c
+}
+
+func (c *importCategories) resolve(name string) (*models.Category, error) {
+	if name == "" {
+		return nil, apperrors.NewValidationError("category", "cannot be empty")
+	}
+	if cat, ok := c.byName[name]; ok {
+		return cat, nil
+	}
+	if cat, ok := c.byFold[strings.ToLower(name)]; ok {
+		return cat, nil
+	}
+	if id, ok := c.trashed[strings.ToLower(name)]; ok {
+		return nil, apperrors.NewValidationError("category", fmt.Sprintf("'%s' is in the trash: restore it with 'category restore --id %d' or purge it first", name, id))
+	}
+	if !c.create {
+		return nil, apperrors.NewValidationError("category", fmt.Sprintf("'%s' does not exist", name))
+	}
+
+	cat, err := newCategory(name, "", models.DepreciationPolicy{}, models.JournalAccounts{})
+	if err != nil {
+		return nil, err
+	}
+	c.byName[name], c.byFold[strings.ToLower(name)] = cat, cat
+	c.planned[cat] = true
+	return cat, nil
+}
+
+// parseImportRow reads the item of row, checked like Create against its category
+func (s *ItemService) parseImportRow(row spreadsheet.Row, columns importColumns, categories *importCategories) (*models.Item, *models.Category, error) {
+	price, err := money.Parse(columns.get(row, "price"))
+	if err != nil {
+		return nil, nil, apperrors.NewValidationError("price", fmt.Sprintf("must be an amount with at most 2 decimal places, got '%s'", columns.get(row, "price")))
+	}
+	purchaseDate, err := time.Parse("2006-01-02", columns.get(row, "purchase_date"))
+	if err != nil {
+		return nil, nil, apperrors.NewValidationError("purchase_date", fmt.Sprintf("must be a date in YYYY-MM-DD format, got '%s'", columns.get(row, "purchase_date")))
+	}
+	policy, err := importPolicy(row, columns)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	item, err := itemFields(columns.get(row, "name"), price, columns.get(row, "currency"), purchaseDate, policy)
+	if err != nil {
+		return nil, nil, err
+	}
+	category, err := categories.resolve(columns.get(row, "category"))
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.checkPolicy(*item, *category); err != nil {
+		return nil, nil, err
+	}
+	return item, category, nil
+}
+
+// importPolicy reads the depreciation policy columns of row, written like the
+// flags of item create: life in months, rate in percent and salvage as an
+// amount or a percentage such as 10%
+func importPolicy(row spreadsheet.Row, columns importColumns) (models.DepreciationPolicy, error) {
+	policy := models.DepreciationPolicy{
+		Method:    strings.ToLower(columns.get(row, "method")),
+		TaxGroup:  strings.ToLower(columns.get(row, "tax_group")),
+		TaxMethod: strings.ToLower(columns.get(row, "tax_method")),
+	}
+	if life := columns.get(row, "life"); life != "" {
+		months, err := strconv.Atoi(life)
+		if err != nil {
+			return policy, apperrors.NewValidationError("life", fmt.Sprintf("must be a whole number of months, got '%s'", life))
+		}
+		policy.UsefulLifeMonths = months
+	}
+	if rate := columns.get(row, "rate"); rate != "" {
+		percent, err := money.ParseRate(strings.TrimSuffix(rate, "%"))
+		if err != nil {
+			return policy, apperrors.NewValidationError("rate", fmt.Sprintf("must be a percentage such as 25, got '%s'", rate))
+		}
+		policy.RatePercent = percent
+	}
+	if salvage := columns.get(row, "salvage"); strings.HasSuffix(salvage, "%") {
+		percent, err := money.ParseRate(strings.TrimSpace(strings.TrimSuffix(salvage, "%")))
+		if err != nil {
+			return policy, apperrors.NewValidationError("salvage", fmt.Sprintf("must be an amount or a percentage such as 10%%, got '%s'", salvage))
+		}
+		policy.SalvagePercent = percent
+	} else if salvage != "" {
+		amount, err := money.Parse(salvage)
+		if err != nil {
+			return policy, apperrors.NewValidationError("salvage", fmt.Sprintf("must be an amount or a percentage such as 10%%, got '%s'", salvage))
+		}
+		policy.SalvageValue = amount
+	}
+	return policy, nil
+}
+
+// Import creates an item from every row of a file whose first row is the
+// header, see ImportOptions, in one unit of work. Every row is checked like
+// Create. Without SkipInvalid a single invalid row imports nothing: Import
+// then returns the report of every row along with a ValidationError.
+func (s *ItemService) Import(ctx context.Context, rows []spreadsheet.Row, opts ImportOptions) (*models.ImportReport, error) {
+	if len(rows) == 0 {
+		return nil, apperrors.NewValidationError("file", "is empty, expected a header row")
+	}
+	columns, err := newImportColumns(rows[0].Cells, opts.Columns)
+	if err != nil {
+		return nil, err
+	}
+
+	var report *models.ImportReport
+	var invalidRows error
+	err = s.tx.WithTx(ctx, func(repos Repos) error {
+		all, err := repos.Categories.GetAll(ctx)
+		if err != nil {
+			return err
+		}
+		trash, err := repos.Categories.GetDeleted(ctx)
+		if err != nil {
+			return err
+		}
+		categories := newImportCategories(all, trash, opts.CreateCategories)
+
+		report = &models.ImportReport{DryRun: opts.DryRun, Rows: []models.ImportRow{}, NewCategories: []string{}}
+		type pendingItem struct {
+			row      int
+			item     *models.Item
+			category *models.Category
+		}
+		var pending []pendingItem
+		for _, row := range rows[1:] {
+			if row.Blank() {
+				continue
+			}
+			result := models.ImportRow{Line: row.Line, Name: columns.get(row, "name"), Category: columns.get(row, "category"), Status: models.ImportValid}
+			item, category, err := s.parseImportRow(row, columns, categories)
+			if err != nil {
+				result.Status, result.Error = models.ImportInvalid, err.Error()
+				if opts.SkipInvalid {
+					result.Status = models.ImportSkipped
+				}
+				report.Invalid++
+			} else {
+				pending = append(pending, pendingItem{row: len(report.Rows), item: item, category: category})
+			}
+			report.Rows = append(report.Rows, result)
+		}
+		if len(report.Rows) == 0 {
+			return apperrors.NewValidationError("file", "has no rows below the header")
+		}
+		if report.Invalid > 0 && !opts.SkipInvalid {
+			invalidRows = apperrors.NewValidationError("file", fmt.Sprintf("has %d invalid of %d rows, nothing was imported", report.Invalid, len(report.Rows)))
+			return invalidRows
+		}
+
+		// only the new categories of valid rows are created
+		for _, p := range pending {
+			if categories.planned[p.category] {
+				report.NewCategories = append(report.NewCategories, p.category.Name)
+				delete(categories.planned, p.category)
+				if !opts.DryRun {
+					if err := repos.Categories.Create(ctx, p.category); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		report.Imported = len(pending)
+		if opts.DryRun {
+			return nil
+		}
+
+		for _, p := range pending {
+			p.item.CategoryID = p.category.ID
+			if err := s.checkCategory(ctx, repos, *p.item); err != nil {
+				return fmt.Errorf("line %d: %w", report.Rows[p.row].Line, err)
+			}
+			if err := repos.Items.Create(ctx, p.item); err != nil {
+				return fmt.Errorf("line %d: %w", report.Rows[p.row].Line, err)
+			}
+			report.Rows[p.row].Status, report.Rows[p.row].ItemID = models.ImportImported, p.item.ID
+		}
+		return nil
+	})
+	if err != nil {
+		if err == invalidRows {
+			return report, err
+		}
+		return nil, err
+	}
+	return report, nil
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+    "context"
+    "errors"
+    "strings"
+    "testing"
+
+    "mini_project3/apperrors"
+    "mini_project3/models"
+    "mini_project3/repository"
+    "mini_project3/spreadsheet"
+)
+
+// newImportService returns an item service on a memory store holding the
+// category Elektronik, with units of work that roll back on the store
+func newImportService(t *testing.T) (*ItemService, *repository.MemoryStore) {
+    t.Helper()
+    store := repository.NewMemoryStore()
+    categories, items := repository.NewMemoryCategoryRepository(store), repository.NewMemoryItemRepository(store)
+    if err := categories.Create(context.Background(), &models.Category{Name: "Elektronik"}); err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+    service := NewItemService(items, categories)
+    service.SetTxManager(NewMemoryTxManager(repository.NewMemoryTxManager(store)))
+    return service, store
+}
+
+func importRows(t *testing.T, csv string) []spreadsheet.Row {
+    t.Helper()
+    rows, err := spreadsheet.ReadCSV(strings.NewReader(csv))
+    if err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+    return rows
+}
+
+func storedItems(t *testing.T, store *repository.MemoryStore) []models.Item {
+    t.Helper()
+    items, err := repository.NewMemoryItemRepository(store).GetAll(context.Background())
+    if err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+    return items
+}
+
+func TestItemService_Import(t *testing.T) {
+    service, store := newImportService(t)
+    rows := importRows(t, "Nama Barang,category,price,purchase_date,method,life,salvage\n"+
+        "Laptop,elektronik,15000000,2024-01-15,straight-line,48,10%\n"+
+        "\n"+
+        "Monitor,Elektronik,2500000.50,2024-02-01,,,\n")
+
+    report, err := service.Import(context.Background(), rows, ImportOptions{Columns: map[string]string{"name": "Nama Barang"}})
+    if err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+    if report.Imported != 2 || report.Invalid != 0 || len(report.Rows) != 2 {
+        t.Fatalf("expected 2 imported rows, got %+v", report)
+    }
+    if report.Rows[1].Line != 4 || report.Rows[1].Status != models.ImportImported || report.Rows[1].ItemID == 0 {
+        t.Errorf("expected line 4 to be imported, got %+v", report.Rows[1])
+    }
+
+    items := storedItems(t, store)
+    if len(items) != 2 {
+        t.Fatalf("expected 2 items, got %d", len(items))
+    }
+    laptop := items[0]
+    if laptop.Name != "Laptop" || laptop.CategoryID != 1 || laptop.Price.String() != "15000000.00" || laptop.PurchaseDate.Format("2006-01-02") != "2024-01-15" {
+        t.Errorf("unexpected item %+v", laptop)
+    }
+    if laptop.Method != "straight-line" || laptop.UsefulLifeMonths != 48 || laptop.SalvagePercent.String() != "10" {
+        t.Errorf("unexpected policy %+v", laptop.DepreciationPolicy)
+    }
+}
+
+func TestItemService_Import_AllOrNothing(t *testing.T) {
+    service, store := newImportService(t)
+    rows := importRows(t, "name,category,price,purchase_date\n"+
+        "Laptop,Elektronik,15000000,2024-01-15\n"+
+        ",Elektronik,1000,2024-01-15\n"+
+        "Meja,Furniture,1000,2024-01-15\n"+
+        "Kursi,Elektronik,-5,2024-01-15\n")
+
+    report, err := service.Import(context.Background(), rows, ImportOptions{})
+    if !errors.Is(err, apperrors.ErrValidation) {
+        t.Fatalf("expected a validation error, got %v", err)
+    }
+    if report == nil || report.Invalid != 3 || report.Imported != 0 {
+        t.Fatalf("expected the report of 3 invalid rows, got %+v", report)
+    }
+    for i, want := range []string{"", "Item name cannot be empty", "category 'Furniture' does not exist", "price must be greater than 0"} {
+        if got := report.Rows[i].Error; got != want {
+            t.Errorf("row %d: expected error %q, got %q", i, want, got)
+        }
+    }
+    if report.Rows[0].Status != models.ImportValid || report.Rows[1].Status != models.ImportInvalid {
+        t.Errorf("unexpected statuses %+v", report.Rows)
+    }
+    if items := storedItems(t, store); len(items) != 0 {
+        t.Errorf("expected no item, got %+v", items)
+    }
+}
+
+func TestItemService_Import_SkipInvalid(t *testing.T) {
+    service, store := newImportService(t)
+    rows := importRows(t, "name,category,price,purchase_date\n"+
+        "Laptop,Elektronik,15000000,2024-01-15\n"+
+        "Meja,Furniture,1000,2024-01-15\n"+
+        "Kursi,Furniture,abc,2024-01-15\n")
+
+    report, err := service.Import(context.Background(), rows, ImportOptions{SkipInvalid: true, CreateCategories: true})
+    if err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+    if report.Imported != 2 || report.Invalid != 1 || report.Rows[2].Status != models.ImportSkipped {
+        t.Fatalf("expected 2 imported and 1 skipped row, got %+v", report)
+    }
+    if len(report.NewCategories) != 1 || report.NewCategories[0] != "Furniture" {
+        t.Errorf("expected the new category Furniture, got %v", report.NewCategories)
+    }
+    if items := storedItems(t, store); len(items) != 2 || items[1].CategoryID != 2 {
+        t.Errorf("expected Meja in the new category, got %+v", items)
+    }
+}
+
+func TestItemService_Import_OnlyCreatesCategoriesOfValidRows(t *testing.T) {
+    service, store := newImportService(t)
+    rows := importRows(t, "name,category,price,purchase_date\n"+
+        "Meja,Furniture,abc,2024-01-15\n"+
+        "Laptop,Elektronik,15000000,2024-01-15\n")
+
+    report, err := service.Import(context.Background(), rows, ImportOptions{SkipInvalid: true, CreateCategories: true})
+    if err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+    if len(report.NewCategories) != 0 {
+        t.Errorf("expected no new category, got %v", report.NewCategories)
+    }
+    if all, _ := repository.NewMemoryCategoryRepository(store).GetAll(context.Background()); len(all) != 1 {
+        t.Errorf("expected only Elektronik, got %+v", all)
+    }
+}
+
+func TestItemService_Import_CategoryInTrash(t *testing.T) {
+    service, store := newImportService(t)
+    categories := repository.NewMemoryCategoryRepository(store)
+    furniture := &models.Category{Name: "Furniture"}
+    if err := categories.Create(context.Background(), furniture); err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+    if err := categories.Delete(context.Background(), furniture.ID); err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+    rows := importRows(t, "name,category,price,purchase_date\n"+
+        "Meja,furniture,1500000,2024-01-15\n"+
+        "Laptop,Elektronik,15000000,2024-01-15\n")
+
+    report, err := service.Import(context.Background(), rows, ImportOptions{SkipInvalid: true, CreateCategories: true})
+    if err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+    if report.Rows[0].Status != models.ImportSkipped || !strings.Contains(report.Rows[0].Error, "is in the trash: restore it with 'category restore --id") {
+        t.Errorf("expected the row of the trashed category to be skipped, got %+v", report.Rows[0])
+    }
+    if len(report.NewCategories) != 0 || len(storedItems(t, store)) != 1 {
+        t.Errorf("expected only the laptop and no new category, got %+v", report)
+    }
+}
+
+func TestItemService_Import_DryRun(t *testing.T) {
+    service, store := newImportService(t)
+    rows := importRows(t, "name,category,price,purchase_date\n"+
+        "Laptop,Elektronik,15000000,2024-01-15\n"+
+        "Meja,Furniture,1000,2024-01-15\n")
+
+    report, err := service.Import(context.Background(), rows, ImportOptions{DryRun: true, CreateCategories: true})
+    if err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+    if !report.DryRun || report.Imported != 2 || report.Rows[0].Status != models.ImportValid || report.Rows[0].ItemID != 0 {
+        t.Errorf("expected 2 valid rows, got %+v", report)
+    }
+    if items := storedItems(t, store); len(items) != 0 {
+        t.Errorf("expected no item, got %+v", items)
+    }
+    if all, _ := repository.NewMemoryCategoryRepository(store).GetAll(context.Background()); len(all) != 1 {
+        t.Errorf("expected no new category, got %+v", all)
+    }
+}
+
+func TestItemService_Import_Columns(t *testing.T) {
+    service, _ := newImportService(t)
+    tests := []struct {
+        name    string
+        csv     string
+        columns map[string]string
+        want    string
+    }{
+        {"unknown field", "name,category,price,purchase_date\n", map[string]string{"title": "Nama"}, "column mapping has unknown field 'title'"},
+        {"missing column", "name,category,harga,purchase_date\nLaptop,Elektronik,1000,2024-01-15\n", nil, "file has no 'price' column for the price of the items"},
+        {"missing mapped column", "name,category,price,purchase_date\n", map[string]string{"price": "Harga"}, "file has no 'Harga' column for the price of the items"},
+        {"no rows", "name,category,price,purchase_date\n\n", nil, "file has no rows below the header"},
+        {"empty file", "", nil, "file is empty"},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            report, err := service.Import(context.Background(), importRows(t, tt.csv), ImportOptions{Columns: tt.columns})
+            if !errors.Is(err, apperrors.ErrValidation) || !strings.Contains(err.Error(), tt.want) {
+                t.Fatalf("expected validation error %q, got %v", tt.want, err)
+            }
+            if report != nil {
+                t.Errorf("expected no report, got %+v", report)
+            }
+        })
+    }
+}
\ No newline at end of file
@@ -1,36 +1,48 @@
 package service
 
 import (
+	"context"
 	"fmt"
-	"math"
 	"strings"
 	"time"
 
+	"mini_project3/apperrors"
 	"mini_project3/models"
+	"mini_project3/money"
 	"mini_project3/repository"
 	"mini_project3/utils"
 )
 
 // ItemRepositoryInterface defines the contract for item repository
 type ItemRepositoryInterface interface {
-	GetAll() ([]models.Item, error)
-	GetByID(id int) (*models.Item, error)
-	Create(item *models.Item) error
-	Update(item *models.Item) error
-	Delete(id int) error
-	Search(keyword string) ([]models.Item, error)
-	GetItemsNeedReplacement(days int) ([]models.Item, error)
+	GetAll(ctx context.Context) ([]models.Item, error)
+	GetByID(ctx context.Context, id int) (*models.Item, error)
+	Create(ctx context.Context, item *models.Item) error
+	Update(ctx context.Context, item *models.Item) error
+	Delete(ctx context.Context, id int) error
+	Search(ctx context.Context, keyword string) ([]models.Item, error)
+	GetItemsNeedReplacement(ctx context.Context, days int, asOf time.Time) ([]models.Item, error)
+	Dispose(ctx context.Context, id int, disposal models.Disposal) error
+	GetDeleted(ctx context.Context) ([]models.TrashEntry, error)
+	Restore(ctx context.Context, id int) error
+	Purge(ctx context.Context, before time.Time) (int, error)
 }
 
 type ItemService struct {
 	itemRepo     ItemRepositoryInterface
 	categoryRepo CategoryRepositoryInterface
+	converter    CurrencyConverter
+	now          func() time.Time
+	tx           TxManager
 }
 
 func NewItemService(itemRepo ItemRepositoryInterface, categoryRepo CategoryRepositoryInterface) *ItemService {
 	return &ItemService{
 		itemRepo:     itemRepo,
 		categoryRepo: categoryRepo,
+		converter:    noRates{},
+		now:          time.Now,
+		tx:           noTx{Repos{Categories: categoryRepo, Items: itemRepo}},
 	}
 }
 
@@ -39,154 +51,535 @@ func NewItemServiceWithRepo(itemRepo *repository.ItemRepository, categoryRepo *r
 	return &ItemService{
 		itemRepo:     itemRepo,
 		categoryRepo: categoryRepo,
+		converter:    noRates{},
+		now:          time.Now,
+		tx:           noTx{Repos{Categories: categoryRepo, Items: itemRepo}},
 	}
 }
 
-func (s *ItemService) GetAll() ([]models.Item, error) {
-	return s.itemRepo.GetAll()
+// SetTxManager makes the changes of the service run as units of work of tx;
+// without it, the reads and writes of a change commit one by one
+func (s *ItemService) SetTxManager(tx TxManager) {
+	s.tx = tx
 }
 
-func (s *ItemService) GetByID(id int) (*models.Item, error) {
+// SetClock replaces time.Now as the current time used for days used and depreciation
+func (s *ItemService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// AsOf returns the day the service reports as of, the date of its clock
+func (s *ItemService) AsOf() time.Time {
+	now := s.now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Purchased reports whether item was bought on or before the AsOf day;
+// reports leave out the items bought later
+func (s *ItemService) Purchased(item models.Item) bool {
+	return !item.PurchaseDate.After(s.AsOf())
+}
+
+// SetConverter sets the exchange rates used to report items in another currency;
+// without it, reports can only use the currency of the items
+func (s *ItemService) SetConverter(converter CurrencyConverter) {
+	s.converter = converter
+}
+
+// DaysUsed counts the whole days between the purchase date and the service clock, see daysUsedOn
+func (s *ItemService) DaysUsed(item models.Item) int {
+	return daysUsedOn(item, s.now())
+}
+
+// daysUsedOn counts the whole days between the purchase date of item and
+// now, or its disposal date when it was disposed before now
+func daysUsedOn(item models.Item, now time.Time) int {
+	if item.DisposedAt != nil && now.After(*item.DisposedAt) {
+		now = *item.DisposedAt
+	}
+	return int(now.Sub(item.PurchaseDate).Hours() / 24)
+}
+
+func (s *ItemService) GetAll(ctx context.Context) ([]models.Item, error) {
+	return s.itemRepo.GetAll(ctx)
+}
+
+func (s *ItemService) GetByID(ctx context.Context, id int) (*models.Item, error) {
 	if err := utils.ValidateID(id); err != nil {
 		return nil, err
 	}
-	return s.itemRepo.GetByID(id)
+	return s.itemRepo.GetByID(ctx, id)
 }
 
-func (s *ItemService) Create(name string, categoryID int, price float64, purchaseDate time.Time) (*models.Item, error) {
-	name = strings.TrimSpace(name)
-	if err := utils.ValidateNotEmpty(name, "Item name"); err != nil {
+// newItem checks and normalizes the fields of an item
+func newItem(name string, categoryID int, price money.Money, currency string, purchaseDate time.Time, policy models.DepreciationPolicy) (*models.Item, error) {
+	item, err := itemFields(name, price, currency, purchaseDate, policy)
+	if err != nil {
 		return nil, err
 	}
-
 	if err := utils.ValidateID(categoryID); err != nil {
 		return nil, fmt.Errorf("invalid category ID: %w", err)
 	}
+	item.CategoryID = categoryID
+	return item, nil
+}
 
-	if price <= 0 {
-		return nil, fmt.Errorf("price must be greater than 0")
+// itemFields checks and normalizes the fields of an item other than its category
+func itemFields(name string, price money.Money, currency string, purchaseDate time.Time, policy models.DepreciationPolicy) (*models.Item, error) {
+	name = strings.TrimSpace(name)
+	if err := utils.ValidateNotEmpty(name, "Item name"); err != nil {
+		return nil, err
+	}
+
+	if price.Cmp(money.Zero) <= 0 {
+		return nil, apperrors.NewValidationError("price", "must be greater than 0")
 	}
 
-	// Check if category exists
-	_, err := s.categoryRepo.GetByID(categoryID)
+	currency, err := NormalizeCurrency(currency)
 	if err != nil {
-		return nil, fmt.Errorf("category not found: %w", err)
+		return nil, err
 	}
 
-	item := &models.Item{
-		Name:         name,
-		CategoryID:   categoryID,
-		Price:        price,
-		PurchaseDate: purchaseDate,
+	return &models.Item{
+		Name:               name,
+		Price:              price,
+		Currency:           currency,
+		PurchaseDate:       purchaseDate,
+		DepreciationPolicy: policy,
+	}, nil
+}
+
+// checkCategory checks within a unit of work that the category of item
+// exists and that the policy of item fits it
+func (s *ItemService) checkCategory(ctx context.Context, repos Repos, item models.Item) error {
+	category, err := repos.Categories.GetByID(ctx, item.CategoryID)
+	if err != nil {
+		return fmt.Errorf("category not found: %w", err)
 	}
+	return s.checkPolicy(item, *category)
+}
 
-	if err := s.itemRepo.Create(item); err != nil {
+// Create adds an item to an existing category; the category cannot be
+// deleted between the check and the insert
+func (s *ItemService) Create(ctx context.Context, name string, categoryID int, price money.Money, currency string, purchaseDate time.Time, policy models.DepreciationPolicy) (*models.Item, error) {
+	item, err := newItem(name, categoryID, price, currency, purchaseDate, policy)
+	if err != nil {
 		return nil, err
 	}
 
+	err = s.tx.WithTx(ctx, func(repos Repos) error {
+		if err := s.checkCategory(ctx, repos, *item); err != nil {
+			return err
+		}
+		return repos.Items.Create(ctx, item)
+	})
+	if err != nil {
+		return nil, err
+	}
 	return item, nil
 }
 
-func (s *ItemService) Update(id int, name string, categoryID int, price float64, purchaseDate time.Time) error {
+// Update replaces the fields of item id, failing with a VersionConflictError
+// when pre does not hold for the stored item
+func (s *ItemService) Update(ctx context.Context, id int, name string, categoryID int, price money.Money, currency string, purchaseDate time.Time, policy models.DepreciationPolicy, pre Precondition) error {
 	if err := utils.ValidateID(id); err != nil {
 		return err
 	}
-
-	name = strings.TrimSpace(name)
-	if err := utils.ValidateNotEmpty(name, "Item name"); err != nil {
+	if err := checkPrecondition(pre); err != nil {
 		return err
 	}
 
-	if err := utils.ValidateID(categoryID); err != nil {
-		return fmt.Errorf("invalid category ID: %w", err)
+	item, err := newItem(name, categoryID, price, currency, purchaseDate, policy)
+	if err != nil {
+		return err
 	}
+	item.ID, item.Version = id, pre.Version
+
+	return s.tx.WithTx(ctx, func(repos Repos) error {
+		if err := s.checkCategory(ctx, repos, *item); err != nil {
+			return err
+		}
+		if !pre.UpdatedAt.IsZero() {
+			existing, err := repos.Items.GetByID(ctx, id)
+			if err != nil {
+				return err
+			}
+			if item.Version, err = expectedVersion("item", id, pre, existing.Version, existing.UpdatedAt); err != nil {
+				return err
+			}
+		}
+		return repos.Items.Update(ctx, item)
+	})
+}
 
-	if price <= 0 {
-		return fmt.Errorf("price must be greater than 0")
+// Patch changes the fields set in patch and keeps the others of item id. It
+// fails with a VersionConflictError when pre does not hold, or when the item
+// changes between reading it here and writing it back.
+func (s *ItemService) Patch(ctx context.Context, id int, patch ItemPatch, pre Precondition) error {
+	if err := utils.ValidateID(id); err != nil {
+		return err
 	}
-
-	// Check if category exists
-	_, err := s.categoryRepo.GetByID(categoryID)
-	if err != nil {
-		return fmt.Errorf("category not found: %w", err)
+	if err := checkPrecondition(pre); err != nil {
+		return err
+	}
+	if patch == (ItemPatch{}) {
+		return apperrors.NewValidationError("update", "must set at least one field")
 	}
 
-	item := &models.Item{
-		ID:           id,
-		Name:         name,
-		CategoryID:   categoryID,
-		Price:        price,
-		PurchaseDate: purchaseDate,
+	return s.tx.WithTx(ctx, func(repos Repos) error {
+		existing, err := repos.Items.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		version, err := expectedVersion("item", id, pre, existing.Version, existing.UpdatedAt)
+		if err != nil {
+			return err
+		}
+
+		merged := *existing
+		if patch.Name != nil {
+			merged.Name = *patch.Name
+		}
+		if patch.CategoryID != nil {
+			merged.CategoryID = *patch.CategoryID
+		}
+		if patch.Price != nil {
+			merged.Price = *patch.Price
+		}
+		if patch.Currency != nil {
+			merged.Currency = *patch.Currency
+		}
+		if patch.PurchaseDate != nil {
+			merged.PurchaseDate = *patch.PurchaseDate
+		}
+		item, err := newItem(merged.Name, merged.CategoryID, merged.Price, merged.Currency, merged.PurchaseDate, patch.Policy.apply(merged.DepreciationPolicy))
+		if err != nil {
+			return err
+		}
+		item.ID, item.Version = id, version
+
+		if err := s.checkCategory(ctx, repos, *item); err != nil {
+			return err
+		}
+		return repos.Items.Update(ctx, item)
+	})
+}
+
+// checkPolicy checks the policy of item and that, merged with the policy
+// of its category, it selects a complete method
+func (s *ItemService) checkPolicy(item models.Item, category models.Category) error {
+	if err := validatePolicy(item.DepreciationPolicy); err != nil {
+		return err
+	}
+	if item.SalvageValue.Cmp(item.Price) > 0 {
+		return apperrors.NewValidationError("salvage value", fmt.Sprintf("cannot exceed the price %s, got %s", item.Price, item.SalvageValue))
 	}
+	if _, err := NewTaxMethod(policyFor(item, category)); err != nil {
+		return err
+	}
+	_, err := NewDepreciationMethod(policyFor(item, category))
+	return err
+}
 
-	return s.itemRepo.Update(item)
+// Delete moves an item to the trash, see TrashService
+func (s *ItemService) Delete(ctx context.Context, id int) error {
+	if err := utils.ValidateID(id); err != nil {
+		return err
+	}
+	return s.tx.WithTx(ctx, func(repos Repos) error {
+		return repos.Items.Delete(ctx, id)
+	})
 }
 
-func (s *ItemService) Delete(id int) error {
+// Restore takes an item out of the trash
+func (s *ItemService) Restore(ctx context.Context, id int) error {
 	if err := utils.ValidateID(id); err != nil {
 		return err
 	}
-	return s.itemRepo.Delete(id)
+	return s.tx.WithTx(ctx, func(repos Repos) error {
+		return repos.Items.Restore(ctx, id)
+	})
 }
 
-func (s *ItemService) Search(keyword string) ([]models.Item, error) {
+func (s *ItemService) Search(ctx context.Context, keyword string) ([]models.Item, error) {
 	keyword = strings.TrimSpace(keyword)
 	if keyword == "" {
-		return nil, fmt.Errorf("search keyword cannot be empty")
+		return nil, apperrors.NewValidationError("search keyword", "cannot be empty")
 	}
-	return s.itemRepo.Search(keyword)
+	return s.itemRepo.Search(ctx, keyword)
 }
 
-func (s *ItemService) GetItemsNeedReplacement() ([]models.Item, error) {
-	return s.itemRepo.GetItemsNeedReplacement(100)
+func (s *ItemService) GetItemsNeedReplacement(ctx context.Context) ([]models.Item, error) {
+	return s.itemRepo.GetItemsNeedReplacement(ctx, 100, s.AsOf())
 }
 
-// CalculateDepreciation menggunakan metode saldo menurun 20% per tahun
-func (s *ItemService) CalculateDepreciation(item models.Item) models.ItemDepreciation {
-	now := time.Now()
-	daysUsed := int(now.Sub(item.PurchaseDate).Hours() / 24)
-	yearsUsed := float64(daysUsed) / 365.0
-
-	// Metode saldo menurun: Nilai Sekarang = Nilai Awal × (1 - Tingkat Depresiasi)^Tahun
-	depreciationRate := 0.20
-	currentValue := item.Price * math.Pow(1-depreciationRate, yearsUsed)
-	depreciationValue := item.Price - currentValue
+// CalculateDepreciation depresiasi barang dengan method sampai nilai residu, dalam mata uang barang itu sendiri
+func (s *ItemService) CalculateDepreciation(item models.Item, method DepreciationMethod, residual money.Money) models.ItemDepreciation {
+	daysUsed := s.DaysUsed(item)
+	currentValue := method.BookValue(item.Price, residual, daysUsed)
 
 	return models.ItemDepreciation{
 		Item:              item,
 		DaysUsed:          daysUsed,
-		DepreciationRate:  depreciationRate,
+		Method:            method.Name(),
+		MethodDescription: method.Describe(),
+		Formula:           method.Formula(),
+		DepreciationRate:  method.AnnualRate(),
+		ReportCurrency:    currencyOf(item),
+		PurchaseValue:     item.Price,
+		ResidualValue:     residual,
 		CurrentValue:      currentValue,
-		DepreciationValue: depreciationValue,
+		DepreciationValue: item.Price.Sub(currentValue),
 	}
 }
 
-func (s *ItemService) GetTotalInvestment() (float64, float64, error) {
-	items, err := s.itemRepo.GetAll()
+// policyFor merges the policy of item with that of its category and DefaultDepreciationPolicy
+func policyFor(item models.Item, category models.Category) models.DepreciationPolicy {
+	return EffectivePolicy(item.DepreciationPolicy, category.DepreciationPolicy, DefaultDepreciationPolicy)
+}
+
+// MethodFor returns the depreciation method of item: its own policy, then
+// the policy of its category, then DefaultDepreciationPolicy
+func (s *ItemService) MethodFor(item models.Item, category models.Category) (DepreciationMethod, error) {
+	method, err := NewDepreciationMethod(policyFor(item, category))
+	if err != nil {
+		return nil, fmt.Errorf("invalid depreciation policy of item %d: %w", item.ID, err)
+	}
+	return method, nil
+}
+
+// TaxMethodFor returns the fiscal depreciation of item from the tax group of
+// the item or its category, or nil when neither sets one
+func (s *ItemService) TaxMethodFor(item models.Item, category models.Category) (DepreciationMethod, error) {
+	method, err := NewTaxMethod(policyFor(item, category))
 	if err != nil {
-		return 0, 0, err
+		return nil, fmt.Errorf("invalid tax group of item %d: %w", item.ID, err)
 	}
+	return method, nil
+}
 
-	var totalOriginal, totalCurrent float64
+// categoriesByID loads every category once for the reports that resolve many items
+func (s *ItemService) categoriesByID(ctx context.Context) (map[int]models.Category, error) {
+	categories, err := s.categoryRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]models.Category, len(categories))
+	for _, cat := range categories {
+		byID[cat.ID] = cat
+	}
+	return byID, nil
+}
+
+// currencyOf returns the currency of item; items stored before currencies existed are in BaseCurrency
+func currencyOf(item models.Item) string {
+	if item.Currency == "" {
+		return BaseCurrency
+	}
+	return item.Currency
+}
+
+// bookIn returns item with its price in currency, converted at the rate of
+// the purchase date so the book value is kept at historical cost in the
+// reporting currency, and the method and residual value it is depreciated
+// with. A salvage amount is in the currency of the item and is converted at
+// the same rate.
+func (s *ItemService) bookIn(ctx context.Context, item models.Item, category models.Category, currency string) (models.Item, DepreciationMethod, money.Money, error) {
+	method, err := s.MethodFor(item, category)
+	if err != nil {
+		return models.Item{}, nil, money.Zero, err
+	}
+
+	value, err := s.converter.Convert(ctx, item.Price, currencyOf(item), currency, item.PurchaseDate)
+	if err != nil {
+		return models.Item{}, nil, money.Zero, fmt.Errorf("error converting item %d to %s: %w", item.ID, currency, err)
+	}
+
+	policy := policyFor(item, category)
+	if !policy.SalvageValue.IsZero() {
+		policy.SalvageValue, err = s.converter.Convert(ctx, policy.SalvageValue, currencyOf(item), currency, item.PurchaseDate)
+		if err != nil {
+			return models.Item{}, nil, money.Zero, fmt.Errorf("error converting item %d to %s: %w", item.ID, currency, err)
+		}
+	}
+
+	converted := item
+	converted.Price = value
+	converted.Currency = currency
+	return converted, method, Residual(policy, value), nil
+}
+
+// depreciationIn reports item in currency, see bookIn. Items with a tax group
+// also get their fiscal book, depreciated from the same value.
+func (s *ItemService) depreciationIn(ctx context.Context, item models.Item, category models.Category, currency string) (models.ItemDepreciation, error) {
+	converted, method, residual, err := s.bookIn(ctx, item, category, currency)
+	if err != nil {
+		return models.ItemDepreciation{}, err
+	}
+	dep := s.CalculateDepreciation(converted, method, residual)
+	dep.Item = item
+
+	taxMethod, err := s.TaxMethodFor(item, category)
+	if err != nil {
+		return models.ItemDepreciation{}, err
+	}
+	if taxMethod != nil {
+		fiscal := s.CalculateDepreciation(converted, taxMethod, money.Zero)
+		group, _ := LookupTaxGroup(policyFor(item, category).TaxGroup)
+		dep.Fiscal = &models.FiscalDepreciation{
+			TaxGroup:          group.Code,
+			TaxGroupName:      group.Name,
+			Method:            fiscal.Method,
+			MethodDescription: fiscal.MethodDescription,
+			Formula:           fiscal.Formula,
+			CurrentValue:      fiscal.CurrentValue,
+			DepreciationValue: fiscal.DepreciationValue,
+			TimingDifference:  fiscal.CurrentValue.Sub(dep.CurrentValue),
+		}
+	}
+	return dep, nil
+}
+
+// GetTotalInvestment returns the total purchase and current value of all items in currency
+func (s *ItemService) GetTotalInvestment(ctx context.Context, currency string) (money.Money, money.Money, error) {
+	summary, err := s.GetInvestmentSummary(ctx, currency)
+	if err != nil {
+		return money.Zero, money.Zero, err
+	}
+	return summary.TotalOriginal, summary.TotalCurrent, nil
+}
+
+// GetInvestmentSummary totals the items in use in currency, with a subtotal
+// for every depreciation method in use in the order it is first used
+func (s *ItemService) GetInvestmentSummary(ctx context.Context, currency string) (*models.InvestmentSummary, error) {
+	currency, err := NormalizeCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.itemRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := s.categoriesByID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &models.InvestmentSummary{Currency: currency}
+	byMethod := map[string]int{}
 	for _, item := range items {
-		dep := s.CalculateDepreciation(item)
-		totalOriginal += item.Price
-		totalCurrent += dep.CurrentValue
+		if !s.Purchased(item) || s.Disposed(item) {
+			continue
+		}
+		dep, err := s.depreciationIn(ctx, item, categories[item.CategoryID], currency)
+		if err != nil {
+			return nil, err
+		}
+
+		i, ok := byMethod[dep.MethodDescription]
+		if !ok {
+			i = len(summary.Methods)
+			byMethod[dep.MethodDescription] = i
+			summary.Methods = append(summary.Methods, models.MethodSummary{
+				Method:      dep.Method,
+				Description: dep.MethodDescription,
+				Formula:     dep.Formula,
+			})
+		}
+		method := &summary.Methods[i]
+		method.Items++
+		method.TotalOriginal = method.TotalOriginal.Add(dep.PurchaseValue)
+		method.TotalCurrent = method.TotalCurrent.Add(dep.CurrentValue)
+		summary.TotalOriginal = summary.TotalOriginal.Add(dep.PurchaseValue)
+		summary.TotalCurrent = summary.TotalCurrent.Add(dep.CurrentValue)
+	}
+
+	summary.TotalDepreciation = summary.TotalOriginal.Sub(summary.TotalCurrent)
+	summary.DepreciationPercentage = summary.TotalDepreciation.Ratio(summary.TotalOriginal) * 100
+	for i := range summary.Methods {
+		summary.Methods[i].TotalDepreciation = summary.Methods[i].TotalOriginal.Sub(summary.Methods[i].TotalCurrent)
+	}
+	return summary, nil
+}
+
+// GetFiscalReport compares the commercial and fiscal book value of every item
+// in use with a tax group in currency
+func (s *ItemService) GetFiscalReport(ctx context.Context, currency string) (*models.FiscalReport, error) {
+	currency, err := NormalizeCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.itemRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := s.categoriesByID(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	return totalOriginal, totalCurrent, nil
+	report := &models.FiscalReport{Currency: currency, Items: []models.FiscalLine{}}
+	for _, item := range items {
+		if !s.Purchased(item) || s.Disposed(item) {
+			continue
+		}
+		dep, err := s.depreciationIn(ctx, item, categories[item.CategoryID], currency)
+		if err != nil {
+			return nil, err
+		}
+		if dep.Fiscal == nil {
+			report.UnmappedItems++
+			continue
+		}
+
+		report.Items = append(report.Items, models.FiscalLine{
+			ID:               item.ID,
+			Name:             item.Name,
+			CategoryName:     item.CategoryName,
+			TaxGroup:         dep.Fiscal.TaxGroup,
+			TaxMethod:        dep.Fiscal.Method,
+			PurchaseValue:    dep.PurchaseValue,
+			CommercialValue:  dep.CurrentValue,
+			FiscalValue:      dep.Fiscal.CurrentValue,
+			TimingDifference: dep.Fiscal.TimingDifference,
+		})
+		report.TotalPurchase = report.TotalPurchase.Add(dep.PurchaseValue)
+		report.TotalCommercial = report.TotalCommercial.Add(dep.CurrentValue)
+		report.TotalFiscal = report.TotalFiscal.Add(dep.Fiscal.CurrentValue)
+	}
+	report.TotalTimingDifference = report.TotalFiscal.Sub(report.TotalCommercial)
+	return report, nil
 }
 
-func (s *ItemService) GetItemDepreciation(id int) (*models.ItemDepreciation, error) {
+// GetItemDepreciation reports the depreciation of one item in currency
+func (s *ItemService) GetItemDepreciation(ctx context.Context, id int, currency string) (*models.ItemDepreciation, error) {
 	if err := utils.ValidateID(id); err != nil {
 		return nil, err
 	}
 
-	item, err := s.itemRepo.GetByID(id)
+	currency, err := NormalizeCurrency(currency)
 	if err != nil {
 		return nil, err
 	}
 
-	dep := s.CalculateDepreciation(*item)
+	item, err := s.itemRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !s.Purchased(*item) {
+		return nil, apperrors.NewValidationError("as-of date", fmt.Sprintf("must not be before %s, the purchase date of item %d, got %s",
+			item.PurchaseDate.Format("2006-01-02"), item.ID, s.AsOf().Format("2006-01-02")))
+	}
+	category, err := s.categoryRepo.GetByID(ctx, item.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	dep, err := s.depreciationIn(ctx, *item, *category, currency)
+	if err != nil {
+		return nil, err
+	}
 	return &dep, nil
 }
@@ -1,27 +1,34 @@
 package service
 
 import (
+    "context"
     "errors"
     "testing"
     "time"
 
+    "mini_project3/apperrors"
     "mini_project3/models"
+    "mini_project3/money"
+    "mini_project3/repository"
 )
 
 // Mock Item Repository
 type MockItemRepository struct {
-    items       []models.Item
-    shouldError bool
+    items        []models.Item
+    trash        []models.TrashEntry
+    shouldError  bool
+    asOf         time.Time // date passed to GetItemsNeedReplacement
+    purgedBefore time.Time // time passed to Purge
 }
 
-func (m *MockItemRepository) GetAll() ([]models.Item, error) {
+func (m *MockItemRepository) GetAll(ctx context.Context) ([]models.Item, error) {
     if m.shouldError {
         return nil, errors.New("mock error")
     }
     return m.items, nil
 }
 
-func (m *MockItemRepository) GetByID(id int) (*models.Item, error) {
+func (m *MockItemRepository) GetByID(ctx context.Context, id int) (*models.Item, error) {
     if m.shouldError {
         return nil, errors.New("mock error")
     }
@@ -33,7 +40,7 @@ func (m *MockItemRepository) GetByID(id int) (*models.Item, error) {
     return nil, errors.New("item not found")
 }
 
-func (m *MockItemRepository) Create(item *models.Item) error {
+func (m *MockItemRepository) Create(ctx context.Context, item *models.Item) error {
     if m.shouldError {
         return errors.New("mock error")
     }
@@ -43,34 +50,92 @@ func (m *MockItemRepository) Create(item *models.Item) error {
     return nil
 }
 
-func (m *MockItemRepository) Update(item *models.Item) error {
+func (m *MockItemRepository) Update(ctx context.Context, item *models.Item) error {
     if m.shouldError {
         return errors.New("mock error")
     }
+    for i := range m.items {
+        if m.items[i].ID == item.ID {
+            m.items[i] = *item
+        }
+    }
+    return nil
+}
+
+func (m *MockItemRepository) Dispose(ctx context.Context, id int, disposal models.Disposal) error {
+    if m.shouldError {
+        return errors.New("mock error")
+    }
+    for i := range m.items {
+        if m.items[i].ID == id {
+            m.items[i].Disposal = disposal
+        }
+    }
     return nil
 }
 
-func (m *MockItemRepository) Delete(id int) error {
+func (m *MockItemRepository) Delete(ctx context.Context, id int) error {
     if m.shouldError {
         return errors.New("mock error")
     }
     return nil
 }
 
-func (m *MockItemRepository) Search(keyword string) ([]models.Item, error) {
+func (m *MockItemRepository) GetDeleted(ctx context.Context) ([]models.TrashEntry, error) {
+    if m.shouldError {
+        return nil, errors.New("mock error")
+    }
+    return m.trash, nil
+}
+
+func (m *MockItemRepository) Restore(ctx context.Context, id int) error {
+    if m.shouldError {
+        return errors.New("mock error")
+    }
+    return nil
+}
+
+func (m *MockItemRepository) Purge(ctx context.Context, before time.Time) (int, error) {
+    m.purgedBefore = before
+    if m.shouldError {
+        return 0, errors.New("mock error")
+    }
+    return len(m.trash), nil
+}
+
+func (m *MockItemRepository) Search(ctx context.Context, keyword string) ([]models.Item, error) {
     if m.shouldError {
         return nil, errors.New("mock error")
     }
     return m.items, nil
 }
 
-func (m *MockItemRepository) GetItemsNeedReplacement(days int) ([]models.Item, error) {
+func (m *MockItemRepository) GetItemsNeedReplacement(ctx context.Context, days int, asOf time.Time) ([]models.Item, error) {
+    m.asOf = asOf
     if m.shouldError {
         return nil, errors.New("mock error")
     }
     return m.items, nil
 }
 
+// testNow is the clock of the services built by furnitureService
+var testNow = time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
+
+// furnitureService returns an item service on items, as of testNow, with
+// category 1 Furniture (straight line over 48 months, 10% salvage, its own
+// expense account) and category 2 Elektronik following the defaults
+func furnitureService(items ...models.Item) (*ItemService, *MockItemRepository) {
+    itemRepo := &MockItemRepository{items: items}
+    mockCatRepo := &MockCategoryRepository{categories: []models.Category{
+        {ID: 1, Name: "Furniture", DepreciationPolicy: models.DepreciationPolicy{Method: MethodStraightLine, UsefulLifeMonths: 48, SalvagePercent: money.MustParseRate("10")},
+            JournalAccounts: models.JournalAccounts{ExpenseAccount: "6-1200"}},
+        {ID: 2, Name: "Elektronik"},
+    }}
+    service := NewItemService(itemRepo, mockCatRepo)
+    service.SetClock(func() time.Time { return testNow })
+    return service, itemRepo
+}
+
 func TestItemService_Create(t *testing.T) {
     mockItemRepo := &MockItemRepository{}
     mockCatRepo := &MockCategoryRepository{
@@ -80,7 +145,7 @@ func TestItemService_Create(t *testing.T) {
     }
 
     service := NewItemService(mockItemRepo, mockCatRepo)
-    item, err := service.Create("Laptop", 1, 15000000, time.Now())
+    item, err := service.Create(context.Background(), "Laptop", 1, money.FromInt(15000000), "IDR", time.Now(), models.DepreciationPolicy{})
 
     if err != nil {
         t.Errorf("unexpected error: %s", err)
@@ -96,7 +161,7 @@ func TestItemService_Create_EmptyName(t *testing.T) {
     mockCatRepo := &MockCategoryRepository{}
 
     service := NewItemService(mockItemRepo, mockCatRepo)
-    _, err := service.Create("", 1, 15000000, time.Now())
+    _, err := service.Create(context.Background(), "", 1, money.FromInt(15000000), "IDR", time.Now(), models.DepreciationPolicy{})
 
     if err == nil {
         t.Error("expected error for empty name")
@@ -110,13 +175,17 @@ func TestItemService_Create_InvalidPrice(t *testing.T) {
     }
 
     service := NewItemService(mockItemRepo, mockCatRepo)
-    _, err := service.Create("Laptop", 1, 0, time.Now())
+    _, err := service.Create(context.Background(), "Laptop", 1, money.Zero, "IDR", time.Now(), models.DepreciationPolicy{})
 
     if err == nil {
         t.Error("expected error for invalid price")
     }
+    var validationErr *apperrors.ValidationError
+    if !errors.As(err, &validationErr) || validationErr.Field != "price" {
+        t.Errorf("expected ValidationError for field 'price', got %v", err)
+    }
 
-    _, err = service.Create("Laptop", 1, -100, time.Now())
+    _, err = service.Create(context.Background(), "Laptop", 1, money.FromInt(-100), "IDR", time.Now(), models.DepreciationPolicy{})
     if err == nil {
         t.Error("expected error for negative price")
     }
@@ -128,27 +197,48 @@ func TestItemService_CalculateDepreciation(t *testing.T) {
 
     service := NewItemService(mockItemRepo, mockCatRepo)
 
-    // Item berusia 1 tahun
-    purchaseDate := time.Now().AddDate(-1, 0, 0)
+    // Item berusia tepat 1 tahun (365 hari)
+    purchaseDate := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+    service.SetClock(func() time.Time { return purchaseDate.AddDate(0, 0, 365) })
     item := models.Item{
         ID:           1,
         Name:         "Laptop",
-        Price:        10000000,
+        Price:        money.FromInt(10000000),
         PurchaseDate: purchaseDate,
     }
 
-    dep := service.CalculateDepreciation(item)
+    dep := service.CalculateDepreciation(item, defaultMethod(t), money.Zero)
 
     // Setelah 1 tahun dengan depresiasi 20%, nilai = 10000000 * 0.8 = 8000000
-    expectedValue := 8000000.0
-    tolerance := 100000.0 // Toleransi untuk floating point
+    if dep.CurrentValue != money.FromInt(8000000) {
+        t.Errorf("expected current value 8000000.00, got %s", dep.CurrentValue)
+    }
 
-    if dep.CurrentValue < expectedValue-tolerance || dep.CurrentValue > expectedValue+tolerance {
-        t.Errorf("expected current value around %.2f, got %.2f", expectedValue, dep.CurrentValue)
+    if dep.DepreciationValue != money.FromInt(2000000) {
+        t.Errorf("expected depreciation value 2000000.00, got %s", dep.DepreciationValue)
     }
+}
 
-    if dep.DepreciationValue < 0 {
-        t.Error("depreciation value should not be negative")
+func TestItemService_CalculateDepreciation_RoundsPerPeriod(t *testing.T) {
+    service := NewItemService(&MockItemRepository{}, &MockCategoryRepository{})
+    purchaseDate := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+    service.SetClock(func() time.Time { return purchaseDate.AddDate(0, 0, 730) })
+
+    // Tahun 1: 0.20 × 1000.05 = 200.01 → 800.04; tahun 2: 0.20 × 800.04 = 160.008 → 160.01 → 640.03
+    dep := service.CalculateDepreciation(models.Item{Price: money.MustParse("1000.05"), PurchaseDate: purchaseDate}, defaultMethod(t), money.Zero)
+    if dep.CurrentValue != money.MustParse("640.03") {
+        t.Errorf("expected current value 640.03, got %s", dep.CurrentValue)
+    }
+}
+
+func TestItemService_CalculateDepreciation_FuturePurchase(t *testing.T) {
+    service := NewItemService(&MockItemRepository{}, &MockCategoryRepository{})
+    now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+    service.SetClock(func() time.Time { return now })
+
+    dep := service.CalculateDepreciation(models.Item{Price: money.FromInt(1000), PurchaseDate: now.AddDate(0, 1, 0)}, defaultMethod(t), money.Zero)
+    if dep.CurrentValue != money.FromInt(1000) || !dep.DepreciationValue.IsZero() {
+        t.Errorf("expected no depreciation before the purchase date, got %+v", dep)
     }
 }
 
@@ -162,7 +252,7 @@ func TestItemService_Search(t *testing.T) {
     mockCatRepo := &MockCategoryRepository{}
 
     service := NewItemService(mockItemRepo, mockCatRepo)
-    items, err := service.Search("laptop")
+    items, err := service.Search(context.Background(), "laptop")
 
     if err != nil {
         t.Errorf("unexpected error: %s", err)
@@ -178,7 +268,7 @@ func TestItemService_Search_EmptyKeyword(t *testing.T) {
     mockCatRepo := &MockCategoryRepository{}
 
     service := NewItemService(mockItemRepo, mockCatRepo)
-    _, err := service.Search("")
+    _, err := service.Search(context.Background(), "")
 
     if err == nil {
         t.Error("expected error for empty keyword")
@@ -189,28 +279,190 @@ func TestItemService_GetTotalInvestment(t *testing.T) {
     purchaseDate := time.Now().AddDate(-1, 0, 0)
     mockItemRepo := &MockItemRepository{
         items: []models.Item{
-            {ID: 1, Name: "Laptop", Price: 10000000, PurchaseDate: purchaseDate},
-            {ID: 2, Name: "Monitor", Price: 5000000, PurchaseDate: purchaseDate},
+            {ID: 1, Name: "Laptop", Price: money.FromInt(10000000), PurchaseDate: purchaseDate},
+            {ID: 2, Name: "Monitor", Price: money.FromInt(5000000), PurchaseDate: purchaseDate},
         },
     }
     mockCatRepo := &MockCategoryRepository{}
 
     service := NewItemService(mockItemRepo, mockCatRepo)
-    totalOriginal, totalCurrent, err := service.GetTotalInvestment()
+    totalOriginal, totalCurrent, err := service.GetTotalInvestment(context.Background(), "IDR")
 
     if err != nil {
         t.Errorf("unexpected error: %s", err)
     }
 
-    if totalOriginal != 15000000 {
-        t.Errorf("expected total original 15000000, got %.2f", totalOriginal)
+    if totalOriginal != money.FromInt(15000000) {
+        t.Errorf("expected total original 15000000, got %s", totalOriginal)
     }
 
-    if totalCurrent <= 0 {
+    if totalCurrent.Cmp(money.Zero) <= 0 {
         t.Error("total current should be greater than 0")
     }
 
-    if totalCurrent >= totalOriginal {
+    if totalCurrent.Cmp(totalOriginal) >= 0 {
         t.Error("total current should be less than total original due to depreciation")
     }
-}
\ No newline at end of file
+}
+
+func TestItemService_GetTotalInvestment_ReconcilesWithItems(t *testing.T) {
+    now := time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
+    mockItemRepo := &MockItemRepository{
+        items: []models.Item{
+            {ID: 1, Price: money.MustParse("15000000.01"), PurchaseDate: now.AddDate(-2, -3, -7)},
+            {ID: 2, Price: money.MustParse("2500000.75"), PurchaseDate: now.AddDate(0, 0, -26)},
+            {ID: 3, Price: money.MustParse("1333333.33"), PurchaseDate: now.AddDate(-1, -8, 0)},
+        },
+    }
+
+    service := NewItemService(mockItemRepo, &MockCategoryRepository{})
+    service.SetClock(func() time.Time { return now })
+    totalOriginal, totalCurrent, err := service.GetTotalInvestment(context.Background(), "IDR")
+    if err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+
+    sum := money.Zero
+    for _, item := range mockItemRepo.items {
+        sum = sum.Add(service.CalculateDepreciation(item, defaultMethod(t), money.Zero).DepreciationValue)
+    }
+    if sum != totalOriginal.Sub(totalCurrent) {
+        t.Errorf("sum of item depreciation %s does not match total depreciation %s", sum, totalOriginal.Sub(totalCurrent))
+    }
+}
+
+func TestItemService_AsOf(t *testing.T) {
+    asOf := time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)
+    mockItemRepo := &MockItemRepository{
+        items: []models.Item{
+            {ID: 1, Name: "Laptop", Price: money.FromInt(10000000), PurchaseDate: asOf.AddDate(-1, 0, 0)},
+            {ID: 2, Name: "Monitor", Price: money.FromInt(5000000), PurchaseDate: asOf},
+            {ID: 3, Name: "Printer", Price: money.FromInt(3000000), PurchaseDate: asOf.AddDate(0, 0, 1)},
+        },
+    }
+
+    service := NewItemService(mockItemRepo, &MockCategoryRepository{})
+    // the time of day of the clock does not move the reporting date
+    service.SetClock(func() time.Time { return asOf.Add(18 * time.Hour) })
+
+    if !service.AsOf().Equal(asOf) {
+        t.Errorf("expected as-of date %s, got %s", asOf, service.AsOf())
+    }
+    if _, err := service.GetItemsNeedReplacement(context.Background()); err != nil || !mockItemRepo.asOf.Equal(asOf) {
+        t.Errorf("expected replacement as of %s, got %s (%v)", asOf, mockItemRepo.asOf, err)
+    }
+
+    // 10.000.000 after a year at 20% plus the unused monitor; the printer is left out
+    totalOriginal, totalCurrent, err := service.GetTotalInvestment(context.Background(), "IDR")
+    if err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+    if totalOriginal != money.FromInt(15000000) || totalCurrent != money.FromInt(13000000) {
+        t.Errorf("expected totals 15000000 and 13000000, got %s and %s", totalOriginal, totalCurrent)
+    }
+
+    if _, err := service.GetItemDepreciation(context.Background(), 3, "IDR"); !errors.Is(err, apperrors.ErrValidation) {
+        t.Errorf("expected validation error for an item bought after the as-of date, got %v", err)
+    }
+}
+
+func TestItemService_WithMemoryRepository(t *testing.T) {
+    store := repository.NewMemoryStore()
+    catRepo := repository.NewMemoryCategoryRepository(store)
+    itemRepo := repository.NewMemoryItemRepository(store)
+
+    categoryService := NewCategoryService(catRepo)
+    itemService := NewItemService(itemRepo, catRepo)
+
+    cat, err := categoryService.Create(context.Background(), "Elektronik", "", models.DepreciationPolicy{}, models.JournalAccounts{})
+    if err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+
+    item, err := itemService.Create(context.Background(), "Laptop", cat.ID, money.FromInt(15000000), "IDR", time.Now(), models.DepreciationPolicy{})
+    if err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+
+    if _, err := itemService.Create(context.Background(), "Laptop", cat.ID+1, money.FromInt(15000000), "IDR", time.Now(), models.DepreciationPolicy{}); err == nil {
+        t.Error("expected error for missing category")
+    }
+
+    if err := categoryService.Delete(context.Background(), cat.ID); err == nil {
+        t.Error("expected error deleting a category that still has items")
+    }
+
+    if err := itemService.Delete(context.Background(), item.ID); err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+    // the deleted item keeps its category until it is purged from the trash
+    if err := categoryService.Delete(context.Background(), cat.ID); !errors.Is(err, apperrors.ErrCategoryInUse) {
+        t.Errorf("expected ErrCategoryInUse while the item is in the trash, got %v", err)
+    }
+    if _, err := NewTrashService(itemRepo, catRepo).Purge(context.Background(), "0d"); err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+    if err := categoryService.Delete(context.Background(), cat.ID); err != nil {
+        t.Errorf("unexpected error: %s", err)
+    }
+}
+
+func TestItemService_Update_Precondition(t *testing.T) {
+    updatedAt := time.Date(2026, 1, 15, 9, 30, 12, 345000000, time.UTC)
+    mockCatRepo := &MockCategoryRepository{categories: []models.Category{{ID: 1, Name: "Elektronik"}}}
+    mockItemRepo := &MockItemRepository{items: []models.Item{{ID: 1, Name: "Laptop", CategoryID: 1, UpdatedAt: updatedAt, Version: 4}}}
+    service := NewItemService(mockItemRepo, mockCatRepo)
+    update := func(pre Precondition) error {
+        return service.Update(context.Background(), 1, "Laptop Dell", 1, money.FromInt(15000000), "IDR", time.Now(), models.DepreciationPolicy{}, pre)
+    }
+
+    var conflict *apperrors.VersionConflictError
+    if err := update(Precondition{UpdatedAt: updatedAt.Add(-time.Second)}); !errors.As(err, &conflict) || conflict.Actual != 4 {
+        t.Errorf("expected a version conflict for an older update time, got %v", err)
+    }
+    if err := update(Precondition{Version: 3, UpdatedAt: updatedAt}); !errors.As(err, &conflict) || conflict.Expected != 3 {
+        t.Errorf("expected a version conflict for an older version, got %v", err)
+    }
+    if err := update(Precondition{Version: -1}); !errors.Is(err, apperrors.ErrValidation) {
+        t.Errorf("expected validation error for a negative version, got %v", err)
+    }
+
+    // the time printed by item get, without its fraction of a second
+    if err := update(Precondition{UpdatedAt: updatedAt.Truncate(time.Second)}); err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+    if got := mockItemRepo.items[0]; got.Name != "Laptop Dell" || got.Version != 4 {
+        t.Errorf("expected the update to expect version 4, got %+v", got)
+    }
+}
+
+func TestItemService_Patch(t *testing.T) {
+    purchaseDate := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+    mockCatRepo := &MockCategoryRepository{categories: []models.Category{{ID: 1, Name: "Elektronik"}}}
+    mockItemRepo := &MockItemRepository{items: []models.Item{
+        {ID: 1, Name: "Laptop", CategoryID: 1, Price: money.FromInt(15000000), Currency: "USD", PurchaseDate: purchaseDate, Version: 3,
+            DepreciationPolicy: models.DepreciationPolicy{Method: MethodStraightLine, UsefulLifeMonths: 48}},
+    }}
+    service := NewItemService(mockItemRepo, mockCatRepo)
+
+    price, life := money.FromInt(1200), 60
+    if err := service.Patch(context.Background(), 1, ItemPatch{Price: &price, Policy: PolicyPatch{UsefulLifeMonths: &life}}, Precondition{}); err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+    got := mockItemRepo.items[0]
+    if got.Name != "Laptop" || got.Price != price || got.Currency != "USD" || !got.PurchaseDate.Equal(purchaseDate) || got.Version != 3 {
+        t.Errorf("expected only the price to change, got %+v", got)
+    }
+    if got.DepreciationPolicy != (models.DepreciationPolicy{Method: MethodStraightLine, UsefulLifeMonths: 60}) {
+        t.Errorf("expected only the useful life to change, got %+v", got.DepreciationPolicy)
+    }
+
+    // the merged item is validated like a full update
+    zero := money.Zero
+    if err := service.Patch(context.Background(), 1, ItemPatch{Price: &zero}, Precondition{}); !errors.Is(err, apperrors.ErrValidation) {
+        t.Errorf("expected validation error for a zero price, got %v", err)
+    }
+    if err := service.Patch(context.Background(), 1, ItemPatch{}, Precondition{}); !errors.Is(err, apperrors.ErrValidation) {
+        t.Errorf("expected validation error for an empty patch, got %v", err)
+    }
+}
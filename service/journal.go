@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"mini_project3/apperrors"
+	"mini_project3/models"
+	"mini_project3/money"
+)
+
+// JournalRepositoryInterface defines the contract for journal repository
+type JournalRepositoryInterface interface {
+	// Get returns the posted journal of a month, or nil when it is not posted
+	Get(ctx context.Context, month string) (*models.Journal, error)
+	Post(ctx context.Context, journal *models.Journal) error
+}
+
+// DefaultJournalAccounts book the depreciation of categories that set no accounts of their own
+var DefaultJournalAccounts = models.JournalAccounts{ExpenseAccount: "6-1100", AccumulatedAccount: "1-2900"}
+
+// JournalService books the monthly depreciation of all items to the general
+// ledger, with the same book values as the reports of ItemService
+type JournalService struct {
+	items    *ItemService
+	journals JournalRepositoryInterface
+	now      func() time.Time
+}
+
+func NewJournalService(items *ItemService, journals JournalRepositoryInterface) *JournalService {
+	return &JournalService{items: items, journals: journals, now: time.Now}
+}
+
+// SetClock replaces time.Now as the time that decides which months have
+// ended and can be posted. Unlike the clock of the items it never follows
+// --as-of, so a report date cannot lock a month that is still open.
+func (s *JournalService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// parseMonth returns the first day of a YYYY-MM month
+func parseMonth(month string) (time.Time, error) {
+	start, err := time.Parse("2006-01", strings.TrimSpace(month))
+	if err != nil {
+		return time.Time{}, apperrors.NewValidationError("month", fmt.Sprintf("must be a month in YYYY-MM format, got '%s'", month))
+	}
+	return start, nil
+}
+
+// accountsFor fills the accounts category leaves empty from DefaultJournalAccounts
+func accountsFor(category models.Category) models.JournalAccounts {
+	accounts := category.JournalAccounts
+	if accounts.ExpenseAccount == "" {
+		accounts.ExpenseAccount = DefaultJournalAccounts.ExpenseAccount
+	}
+	if accounts.AccumulatedAccount == "" {
+		accounts.AccumulatedAccount = DefaultJournalAccounts.AccumulatedAccount
+	}
+	return accounts
+}
+
+// calculate books the depreciation of the month starting on start per
+// category in currency: the book value of every item at the end of the
+// previous month minus that at the end of this month
+func (s *JournalService) calculate(ctx context.Context, start time.Time, currency string) (*models.Journal, error) {
+	end := start.AddDate(0, 1, -1)
+	items, err := s.items.itemRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := s.items.categoriesByID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byCategory := map[int]money.Money{}
+	for _, item := range items {
+		if item.PurchaseDate.After(end) {
+			continue
+		}
+		converted, method, residual, err := s.items.bookIn(ctx, item, categories[item.CategoryID], currency)
+		if err != nil {
+			return nil, err
+		}
+		opening := method.BookValue(converted.Price, residual, daysUsedOn(converted, start.AddDate(0, 0, -1)))
+		closing := method.BookValue(converted.Price, residual, daysUsedOn(converted, end))
+		byCategory[item.CategoryID] = byCategory[item.CategoryID].Add(opening.Sub(closing))
+	}
+
+	ids := make([]int, 0, len(byCategory))
+	for id, amount := range byCategory {
+		if !amount.IsZero() {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+
+	month := start.Format("2006-01")
+	journal := &models.Journal{Month: month, Currency: currency, Lines: []models.JournalLine{}}
+	for _, id := range ids {
+		category, amount := categories[id], byCategory[id]
+		accounts := accountsFor(category)
+		description := fmt.Sprintf("Penyusutan %s %s", category.Name, month)
+		journal.Lines = append(journal.Lines,
+			models.JournalLine{Month: month, CategoryID: id, CategoryName: category.Name, Account: accounts.ExpenseAccount, Description: description, Debit: amount},
+			models.JournalLine{Month: month, CategoryID: id, CategoryName: category.Name, Account: accounts.AccumulatedAccount, Description: description, Credit: amount},
+		)
+	}
+	return journal, nil
+}
+
+// complete sets the dates and totals of journal, which are not stored
+func complete(journal *models.Journal, start time.Time) {
+	journal.Date = start.AddDate(0, 1, -1)
+	journal.TotalDebit, journal.TotalCredit = money.Zero, money.Zero
+	for i := range journal.Lines {
+		journal.Lines[i].Date = journal.Date
+		journal.TotalDebit = journal.TotalDebit.Add(journal.Lines[i].Debit)
+		journal.TotalCredit = journal.TotalCredit.Add(journal.Lines[i].Credit)
+	}
+}
+
+// GetJournal returns the depreciation journal of month in currency. A posted
+// month is read back as posted and not recalculated; its Difference shows
+// how much a recalculation would change it.
+func (s *JournalService) GetJournal(ctx context.Context, month, currency string) (*models.Journal, error) {
+	start, err := parseMonth(month)
+	if err != nil {
+		return nil, err
+	}
+	currency, err = NormalizeCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+
+	posted, err := s.journals.Get(ctx, start.Format("2006-01"))
+	if err != nil {
+		return nil, err
+	}
+	if posted != nil && posted.Currency != currency {
+		return nil, apperrors.NewValidationError("currency", fmt.Sprintf("must be %s, the currency %s was posted in, got %s", posted.Currency, posted.Month, currency))
+	}
+
+	journal, err := s.calculate(ctx, start, currency)
+	if err != nil {
+		return nil, err
+	}
+	complete(journal, start)
+	if posted == nil {
+		return journal, nil
+	}
+
+	complete(posted, start)
+	posted.Difference = journal.TotalDebit.Sub(posted.TotalDebit)
+	return posted, nil
+}
+
+// PostJournal calculates the journal of month in currency and locks it.
+// A month can be posted once, from the first day of the next month on the
+// clock of the service.
+func (s *JournalService) PostJournal(ctx context.Context, month, currency string) (*models.Journal, error) {
+	start, err := parseMonth(month)
+	if err != nil {
+		return nil, err
+	}
+	currency, err = NormalizeCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+
+	end := start.AddDate(0, 1, -1)
+	if !end.Before(day(s.now())) {
+		return nil, apperrors.NewValidationError("month", fmt.Sprintf("cannot be posted before %s, the day after it ends", end.AddDate(0, 0, 1).Format("2006-01-02")))
+	}
+
+	posted, err := s.journals.Get(ctx, start.Format("2006-01"))
+	if err != nil {
+		return nil, err
+	}
+	if posted != nil {
+		return nil, &apperrors.PeriodLockedError{Month: posted.Month, PostedAt: *posted.PostedAt}
+	}
+
+	journal, err := s.calculate(ctx, start, currency)
+	if err != nil {
+		return nil, err
+	}
+	postedAt := time.Now()
+	journal.Posted, journal.PostedAt = true, &postedAt
+	if err := s.journals.Post(ctx, journal); err != nil {
+		return nil, err
+	}
+	complete(journal, start)
+	return journal, nil
+}
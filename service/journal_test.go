@@ -0,0 +1,182 @@
+package service
+
+import (
+    "context"
+    "errors"
+    "testing"
+    "time"
+
+    "mini_project3/apperrors"
+    "mini_project3/models"
+    "mini_project3/money"
+)
+
+type MockJournalRepository struct {
+    journals map[string]models.Journal
+}
+
+func (m *MockJournalRepository) Get(ctx context.Context, month string) (*models.Journal, error) {
+    journal, ok := m.journals[month]
+    if !ok {
+        return nil, nil
+    }
+    journal.Lines = append([]models.JournalLine(nil), journal.Lines...)
+    return &journal, nil
+}
+
+func (m *MockJournalRepository) Post(ctx context.Context, journal *models.Journal) error {
+    if _, ok := m.journals[journal.Month]; ok {
+        return &apperrors.PeriodLockedError{Month: journal.Month}
+    }
+    m.journals[journal.Month] = *journal
+    return nil
+}
+
+func journalService(items ...models.Item) (*JournalService, *MockItemRepository) {
+    itemService, itemRepo := furnitureService(items...)
+    service := NewJournalService(itemService, &MockJournalRepository{journals: map[string]models.Journal{}})
+    service.SetClock(func() time.Time { return testNow })
+    return service, itemRepo
+}
+
+func TestJournalService_GetJournal_MonthsAddUpToYear(t *testing.T) {
+    // Elektronik follows the default declining balance of 20% a year
+    laptop := models.Item{ID: 1, Name: "Laptop", CategoryID: 2, Price: money.MustParse("15000000.01"), PurchaseDate: time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)}
+    service, _ := journalService(laptop)
+
+    total := money.Zero
+    for month := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC); month.Year() < 2025 || month.Month() <= 6; month = month.AddDate(0, 1, 0) {
+        journal, err := service.GetJournal(context.Background(), month.Format("2006-01"), "IDR")
+        if err != nil {
+            t.Fatalf("unexpected error: %s", err)
+        }
+        if month.Month() == 7 && journal.TotalDebit.Cmp(money.MustParse("254794.52")) != 0 {
+            t.Errorf("expected 31/365 of the annual depreciation in July, got %s", journal.TotalDebit)
+        }
+        total = total.Add(journal.TotalDebit)
+    }
+
+    if expected := money.MustParse("3000000.00"); total.Cmp(expected) != 0 {
+        t.Errorf("expected the months to add up to the annual depreciation %s, got %s", expected, total)
+    }
+}
+
+func TestJournalService_GetJournal_AgreesWithSchedule(t *testing.T) {
+    meja := models.Item{ID: 1, Name: "Meja", CategoryID: 1, Price: money.MustParse("1333333.33"), PurchaseDate: time.Date(2023, 5, 10, 0, 0, 0, 0, time.UTC)}
+    lemari := models.Item{ID: 2, Name: "Lemari", CategoryID: 1, Price: money.FromInt(2000000), PurchaseDate: time.Date(2025, 2, 3, 0, 0, 0, 0, time.UTC)}
+    service, _ := journalService(meja, lemari)
+
+    expected := money.Zero
+    for _, id := range []int{1, 2} {
+        schedule, err := service.items.GetDepreciationSchedule(context.Background(), id, "IDR", PeriodMonth)
+        if err != nil {
+            t.Fatalf("unexpected error: %s", err)
+        }
+        for _, row := range schedule.Rows {
+            if row.Period == "2025-06" {
+                expected = expected.Add(row.Depreciation)
+            }
+        }
+    }
+
+    journal, err := service.GetJournal(context.Background(), "2025-06", "idr")
+    if err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+    if len(journal.Lines) != 2 || journal.TotalDebit != expected || journal.TotalCredit != expected {
+        t.Fatalf("expected one debit and one credit of %s, got %+v", expected, journal)
+    }
+    debit, credit := journal.Lines[0], journal.Lines[1]
+    if debit.Account != "6-1200" || credit.Account != DefaultJournalAccounts.AccumulatedAccount {
+        t.Errorf("expected accounts 6-1200 and the default %s, got %s and %s", DefaultJournalAccounts.AccumulatedAccount, debit.Account, credit.Account)
+    }
+    if !journal.Date.Equal(time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)) || journal.Posted {
+        t.Errorf("expected an unposted journal dated 2025-06-30, got %+v", journal)
+    }
+}
+
+func TestJournalService_PostJournal_LocksMonth(t *testing.T) {
+    service, itemRepo := journalService(models.Item{ID: 1, Name: "Meja", CategoryID: 1, Price: money.FromInt(1000000), PurchaseDate: time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)})
+    ctx := context.Background()
+
+    posted, err := service.PostJournal(ctx, "2025-11", "IDR")
+    if err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+    if !posted.Posted || posted.PostedAt == nil {
+        t.Errorf("expected a posted journal, got %+v", posted)
+    }
+
+    _, err = service.PostJournal(ctx, "2025-11", "IDR")
+    var lockedErr *apperrors.PeriodLockedError
+    if !errors.As(err, &lockedErr) || !errors.Is(err, apperrors.ErrConflict) || lockedErr.Month != "2025-11" {
+        t.Errorf("expected PeriodLockedError for 2025-11, got %v", err)
+    }
+
+    // a backdated purchase changes the recalculation, not the posted journal
+    itemRepo.items = append(itemRepo.items, models.Item{ID: 2, Name: "Kursi", CategoryID: 1, Price: money.FromInt(500000), PurchaseDate: time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)})
+    journal, err := service.GetJournal(ctx, "2025-11", "IDR")
+    if err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+    if journal.TotalDebit != posted.TotalDebit || len(journal.Lines) != len(posted.Lines) {
+        t.Errorf("expected the posted journal %+v, got %+v", posted, journal)
+    }
+    if journal.Difference.Cmp(money.Zero) <= 0 {
+        t.Errorf("expected a positive difference after the backdated purchase, got %s", journal.Difference)
+    }
+
+    if _, err := service.GetJournal(ctx, "2025-11", "USD"); !errors.Is(err, apperrors.ErrValidation) {
+        t.Errorf("expected validation error for another currency than posted, got %v", err)
+    }
+}
+
+func TestJournalService_PostJournal_Validation(t *testing.T) {
+    service, _ := journalService()
+
+    tests := []struct {
+        month string
+        field string
+    }{
+        {"2025/12", "month"},
+        {"2025-13", "month"},
+        // the clock is 2026-01-15, January has not ended yet
+        {"2026-01", "month"},
+    }
+    for _, tt := range tests {
+        _, err := service.PostJournal(context.Background(), tt.month, "IDR")
+        var validationErr *apperrors.ValidationError
+        if !errors.As(err, &validationErr) || validationErr.Field != tt.field {
+            t.Errorf("expected validation error on %s for %s, got %v", tt.field, tt.month, err)
+        }
+    }
+}
+
+func TestJournalService_PostJournal_IgnoresAsOf(t *testing.T) {
+    service, _ := journalService()
+    // --as-of sets the clock of the items only; the journal clock stays at 2026-01-15
+    service.items.SetClock(func() time.Time { return time.Date(2030, 6, 1, 0, 0, 0, 0, time.UTC) })
+
+    _, err := service.PostJournal(context.Background(), "2030-05", "IDR")
+    if !errors.Is(err, apperrors.ErrValidation) {
+        t.Fatalf("expected validation error for a month after the real date, got %v", err)
+    }
+    if journal, _ := service.journals.Get(context.Background(), "2030-05"); journal != nil {
+        t.Errorf("expected 2030-05 not to be posted, got %+v", journal)
+    }
+}
+
+func TestJournalService_PostJournal_FromNextMonth(t *testing.T) {
+    service, _ := journalService()
+
+    // the last day of November is still open, even late in the day
+    service.SetClock(func() time.Time { return time.Date(2025, 11, 30, 23, 0, 0, 0, time.UTC) })
+    if _, err := service.PostJournal(context.Background(), "2025-11", "IDR"); !errors.Is(err, apperrors.ErrValidation) {
+        t.Errorf("expected validation error on the last day of the month, got %v", err)
+    }
+
+    service.SetClock(func() time.Time { return time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC) })
+    if _, err := service.PostJournal(context.Background(), "2025-11", "IDR"); err != nil {
+        t.Errorf("unexpected error on the first day of the next month: %s", err)
+    }
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"time"
+
+	"mini_project3/models"
+	"mini_project3/money"
+)
+
+// PolicyPatch holds the depreciation policy fields of an update; nil fields
+// keep their stored value and an empty value inherits again
+type PolicyPatch struct {
+	Method           *string
+	UsefulLifeMonths *int
+	RatePercent      *money.Rate
+	// SalvageValue and SalvagePercent replace the residual value together,
+	// since at most one of them is set
+	SalvageValue   *money.Money
+	SalvagePercent *money.Rate
+	TaxGroup       *string
+	TaxMethod      *string
+}
+
+// apply returns policy with the fields of p set
+func (p PolicyPatch) apply(policy models.DepreciationPolicy) models.DepreciationPolicy {
+	if p.Method != nil {
+		policy.Method = *p.Method
+	}
+	if p.UsefulLifeMonths != nil {
+		policy.UsefulLifeMonths = *p.UsefulLifeMonths
+	}
+	if p.RatePercent != nil {
+		policy.RatePercent = *p.RatePercent
+	}
+	if p.SalvageValue != nil || p.SalvagePercent != nil {
+		policy.SalvageValue, policy.SalvagePercent = money.Zero, money.Rate{}
+		if p.SalvageValue != nil {
+			policy.SalvageValue = *p.SalvageValue
+		}
+		if p.SalvagePercent != nil {
+			policy.SalvagePercent = *p.SalvagePercent
+		}
+	}
+	if p.TaxGroup != nil {
+		policy.TaxGroup = *p.TaxGroup
+	}
+	if p.TaxMethod != nil {
+		policy.TaxMethod = *p.TaxMethod
+	}
+	return policy
+}
+
+// ItemPatch holds the fields of an item update; nil fields keep their stored value
+type ItemPatch struct {
+	Name         *string
+	CategoryID   *int
+	Price        *money.Money
+	Currency     *string
+	PurchaseDate *time.Time
+	Policy       PolicyPatch
+}
+
+// CategoryPatch holds the fields of a category update; nil fields keep
+// their stored value, an empty Description clears it
+type CategoryPatch struct {
+	Name               *string
+	Description        *string
+	Policy             PolicyPatch
+	ExpenseAccount     *string
+	AccumulatedAccount *string
+}
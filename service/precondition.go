@@ -0,0 +1,39 @@
+package service
+
+import (
+	"time"
+
+	"mini_project3/apperrors"
+)
+
+// Precondition guards an update against changes made by someone else since
+// the caller read the item or category: the stored row must still be at
+// Version, and still have been last updated at UpdatedAt. UpdatedAt is
+// compared by its date and time of day to the second, as printed by the get
+// commands, since the database drivers read back timestamps without their
+// time zone. The zero value sets no condition.
+type Precondition struct {
+	Version   int
+	UpdatedAt time.Time
+}
+
+// expectedVersion checks pre against the stored version and update time of
+// entity id and returns the version its update must expect
+func expectedVersion(entity string, id int, pre Precondition, version int, updatedAt time.Time) (int, error) {
+	if pre.Version != 0 && pre.Version != version {
+		return 0, &apperrors.VersionConflictError{Entity: entity, ID: id, Expected: pre.Version, Actual: version}
+	}
+	const layout = "2006-01-02 15:04:05"
+	if !pre.UpdatedAt.IsZero() && updatedAt.Format(layout) != pre.UpdatedAt.Format(layout) {
+		return 0, &apperrors.VersionConflictError{Entity: entity, ID: id, Actual: version}
+	}
+	return version, nil
+}
+
+// checkPrecondition validates pre before anything is read
+func checkPrecondition(pre Precondition) error {
+	if pre.Version < 0 {
+		return apperrors.NewValidationError("version", "must not be negative")
+	}
+	return nil
+}
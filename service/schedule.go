@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"mini_project3/apperrors"
+	"mini_project3/models"
+	"mini_project3/utils"
+)
+
+// Periods of a depreciation schedule
+const (
+	PeriodMonth = "month"
+	PeriodYear  = "year"
+)
+
+// maxScheduleYears ends the schedule of a declining balance without a useful
+// life, whose book value may never reach its residual value
+const maxScheduleYears = 40
+
+// nextPeriod returns the first day of the calendar month or year after day
+func nextPeriod(day time.Time, period string) time.Time {
+	if period == PeriodMonth {
+		return time.Date(day.Year(), day.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return time.Date(day.Year()+1, 1, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// periodLabel names the month or year that starts on day, e.g. 2024-06 or 2024
+func periodLabel(day time.Time, period string) string {
+	if period == PeriodMonth {
+		return day.Format("2006-01")
+	}
+	return day.Format("2006")
+}
+
+// GetDepreciationSchedule lists the book value of an item in currency at the
+// end of every calendar month or year from its purchase date until it reaches
+// its residual value, the end of its useful life or the period it was
+// disposed in. Every closing value is what CalculateDepreciation reports with
+// the last day of the period as clock.
+func (s *ItemService) GetDepreciationSchedule(ctx context.Context, id int, currency, period string) (*models.DepreciationSchedule, error) {
+	if err := utils.ValidateID(id); err != nil {
+		return nil, err
+	}
+
+	period = strings.ToLower(strings.TrimSpace(period))
+	if period != PeriodMonth && period != PeriodYear {
+		return nil, apperrors.NewValidationError("period", fmt.Sprintf("must be %s or %s, got '%s'", PeriodMonth, PeriodYear, period))
+	}
+
+	currency, err := NormalizeCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := s.itemRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	category, err := s.categoryRepo.GetByID(ctx, item.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	converted, method, residual, err := s.bookIn(ctx, *item, *category, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule := &models.DepreciationSchedule{
+		ID:                item.ID,
+		Name:              item.Name,
+		Period:            period,
+		Method:            method.Name(),
+		MethodDescription: method.Describe(),
+		Currency:          currency,
+		PurchaseDate:      item.PurchaseDate,
+		PurchaseValue:     converted.Price,
+		ResidualValue:     residual,
+	}
+
+	// useful lives are months of 365/12 days, see daysPerYear
+	lifeDays := (policyFor(*item, *category).UsefulLifeMonths*daysPerYear + 11) / 12
+	horizon := item.PurchaseDate.AddDate(maxScheduleYears, 0, 0)
+	opening := converted.Price
+	for start := item.PurchaseDate; ; {
+		next := nextPeriod(start, period)
+		end := next.AddDate(0, 0, -1)
+		daysUsed := daysUsedOn(converted, end)
+		closing := method.BookValue(converted.Price, residual, daysUsed)
+
+		schedule.Rows = append(schedule.Rows, models.ScheduleRow{
+			Period:                  periodLabel(start, period),
+			Start:                   start,
+			End:                     end,
+			DaysUsed:                daysUsed,
+			OpeningValue:            opening,
+			Depreciation:            opening.Sub(closing),
+			AccumulatedDepreciation: converted.Price.Sub(closing),
+			ClosingValue:            closing,
+		})
+
+		if closing.Cmp(residual) <= 0 || (lifeDays > 0 && daysUsed >= lifeDays) {
+			break
+		}
+		if item.DisposedAt != nil && !end.Before(*item.DisposedAt) {
+			schedule.DisposedAt = item.DisposedAt
+			break
+		}
+		if !next.Before(horizon) {
+			schedule.Truncated = true
+			break
+		}
+		opening, start = closing, next
+	}
+	return schedule, nil
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+    "context"
+    "errors"
+    "testing"
+    "time"
+
+    "mini_project3/apperrors"
+    "mini_project3/models"
+    "mini_project3/money"
+)
+
+func TestItemService_GetDepreciationSchedule_AgreesWithCalculateDepreciation(t *testing.T) {
+    item := models.Item{ID: 1, Name: "Meja", CategoryID: 1, Price: money.MustParse("1333333.33"), PurchaseDate: time.Date(2023, 5, 10, 0, 0, 0, 0, time.UTC)}
+    service, _ := furnitureService(item)
+
+    for _, period := range []string{PeriodMonth, PeriodYear} {
+        schedule, err := service.GetDepreciationSchedule(context.Background(), 1, "IDR", period)
+        if err != nil {
+            t.Fatalf("unexpected error: %s", err)
+        }
+
+        residual := money.MustParse("133333.33")
+        last := schedule.Rows[len(schedule.Rows)-1]
+        if schedule.ResidualValue != residual || last.ClosingValue != residual || schedule.Truncated {
+            t.Errorf("%s: expected the schedule to end at the residual %s, got %+v", period, residual, last)
+        }
+        if last.AccumulatedDepreciation != item.Price.Sub(residual) {
+            t.Errorf("%s: expected accumulated depreciation %s, got %s", period, item.Price.Sub(residual), last.AccumulatedDepreciation)
+        }
+
+        method, _ := NewDepreciationMethod(models.DepreciationPolicy{Method: MethodStraightLine, UsefulLifeMonths: 48})
+        sum := money.Zero
+        for i, row := range schedule.Rows {
+            if i > 0 && row.OpeningValue != schedule.Rows[i-1].ClosingValue {
+                t.Errorf("%s %s: opening %s does not continue closing %s", period, row.Period, row.OpeningValue, schedule.Rows[i-1].ClosingValue)
+            }
+            end := row.End
+            service.SetClock(func() time.Time { return end })
+            if dep := service.CalculateDepreciation(item, method, residual); dep.CurrentValue != row.ClosingValue {
+                t.Errorf("%s %s: closing %s, CalculateDepreciation gives %s", period, row.Period, row.ClosingValue, dep.CurrentValue)
+            }
+            sum = sum.Add(row.Depreciation)
+        }
+        if sum != last.AccumulatedDepreciation {
+            t.Errorf("%s: depreciation per period adds up to %s, expected %s", period, sum, last.AccumulatedDepreciation)
+        }
+    }
+}
+
+func TestItemService_GetDepreciationSchedule_Periods(t *testing.T) {
+    service, _ := furnitureService(models.Item{ID: 1, Name: "Meja", CategoryID: 1, Price: money.FromInt(1000000), PurchaseDate: time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)})
+
+    schedule, err := service.GetDepreciationSchedule(context.Background(), 1, "IDR", " Month ")
+    if err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+    // 48 months of 365/12 days from 2024-01-31 end in January 2028
+    if len(schedule.Rows) != 49 {
+        t.Errorf("expected 49 monthly rows, got %d", len(schedule.Rows))
+    }
+    first, second := schedule.Rows[0], schedule.Rows[1]
+    if first.Period != "2024-01" || first.DaysUsed != 0 || !first.Depreciation.IsZero() {
+        t.Errorf("expected an empty first period on the purchase date, got %+v", first)
+    }
+    if second.Period != "2024-02" || !second.Start.Equal(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)) || second.DaysUsed != 29 {
+        t.Errorf("unexpected second period %+v", second)
+    }
+
+    if _, err := service.GetDepreciationSchedule(context.Background(), 1, "IDR", "week"); !errors.Is(err, apperrors.ErrValidation) {
+        t.Errorf("expected validation error for an unknown period, got %v", err)
+    }
+}
+
+func TestItemService_GetDepreciationSchedule_DecliningBalanceIsTruncated(t *testing.T) {
+    service, _ := furnitureService(models.Item{ID: 1, Name: "Laptop", CategoryID: 2, Price: money.FromInt(15000000), PurchaseDate: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)})
+
+    schedule, err := service.GetDepreciationSchedule(context.Background(), 1, "IDR", PeriodYear)
+    if err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+    if !schedule.Truncated || len(schedule.Rows) != maxScheduleYears+1 {
+        t.Errorf("expected a truncated schedule of %d years, got %d rows (truncated %v)", maxScheduleYears+1, len(schedule.Rows), schedule.Truncated)
+    }
+}
\ No newline at end of file
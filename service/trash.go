@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"mini_project3/apperrors"
+	"mini_project3/models"
+)
+
+// TrashService lists the deleted items and categories and deletes them
+// permanently once they have been in the trash long enough. Until then
+// ItemService.Restore and CategoryService.Restore bring them back.
+type TrashService struct {
+	items      ItemRepositoryInterface
+	categories CategoryRepositoryInterface
+	now        func() time.Time
+	tx         TxManager
+}
+
+func NewTrashService(items ItemRepositoryInterface, categories CategoryRepositoryInterface) *TrashService {
+	return &TrashService{items: items, categories: categories, now: time.Now, tx: noTx{Repos{Categories: categories, Items: items}}}
+}
+
+// SetTxManager makes purges run as units of work of tx, see ItemService.SetTxManager
+func (s *TrashService) SetTxManager(tx TxManager) {
+	s.tx = tx
+}
+
+// SetClock replaces time.Now as the current time purges count the age of the trash from
+func (s *TrashService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// List returns the items and categories in the trash, oldest deletion first
+func (s *TrashService) List(ctx context.Context) ([]models.TrashEntry, error) {
+	items, err := s.items.GetDeleted(ctx)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := s.categories.GetDeleted(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := append(items, categories...)
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].DeletedAt.Before(entries[j].DeletedAt) })
+	return entries, nil
+}
+
+// parseAge reads an age in days such as 90d, or a Go duration such as 12h
+func parseAge(age string) (time.Duration, error) {
+	age = strings.ToLower(strings.TrimSpace(age))
+	invalid := apperrors.NewValidationError("older than", fmt.Sprintf("must be a duration like 90d or 12h, got '%s'", age))
+
+	var duration time.Duration
+	if days, ok := strings.CutSuffix(age, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, invalid
+		}
+		duration = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if duration, err = time.ParseDuration(age); err != nil {
+			return 0, invalid
+		}
+	}
+	if duration < 0 {
+		return 0, invalid
+	}
+	return duration, nil
+}
+
+// Purge permanently deletes the items and categories that were moved to the
+// trash more than olderThan ago, e.g. 90d, all in one unit of work. Items
+// go first, as the foreign key of items requires.
+func (s *TrashService) Purge(ctx context.Context, olderThan string) (*models.PurgeResult, error) {
+	age, err := parseAge(olderThan)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.PurgeResult{Before: s.now().Add(-age)}
+	err = s.tx.WithTx(ctx, func(repos Repos) error {
+		var err error
+		if result.Items, err = repos.Items.Purge(ctx, result.Before); err != nil {
+			return err
+		}
+		result.Categories, err = repos.Categories.Purge(ctx, result.Before)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
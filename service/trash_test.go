@@ -0,0 +1,76 @@
+package service
+
+import (
+    "context"
+    "errors"
+    "testing"
+    "time"
+
+    "mini_project3/apperrors"
+    "mini_project3/models"
+)
+
+func TestTrashService_List(t *testing.T) {
+    day := func(d int) time.Time { return time.Date(2026, 1, d, 9, 0, 0, 0, time.UTC) }
+    mockItemRepo := &MockItemRepository{trash: []models.TrashEntry{
+        {Entity: models.TrashItem, ID: 4, Name: "Kursi", DeletedAt: day(3)},
+        {Entity: models.TrashItem, ID: 2, Name: "Meja", DeletedAt: day(10)},
+    }}
+    mockCatRepo := &MockCategoryRepository{trash: []models.TrashEntry{
+        {Entity: models.TrashCategory, ID: 3, Name: "Dapur", DeletedAt: day(5)},
+    }}
+
+    entries, err := NewTrashService(mockItemRepo, mockCatRepo).List(context.Background())
+    if err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+    if len(entries) != 3 || entries[0].Name != "Kursi" || entries[1].Name != "Dapur" || entries[2].Name != "Meja" {
+        t.Errorf("expected items and categories by deletion time, got %+v", entries)
+    }
+}
+
+func TestTrashService_Purge(t *testing.T) {
+    now := time.Date(2026, 4, 1, 12, 0, 0, 0, time.UTC)
+    mockItemRepo := &MockItemRepository{trash: []models.TrashEntry{{Entity: models.TrashItem, ID: 1}, {Entity: models.TrashItem, ID: 2}}}
+    mockCatRepo := &MockCategoryRepository{trash: []models.TrashEntry{{Entity: models.TrashCategory, ID: 1}}}
+    service := NewTrashService(mockItemRepo, mockCatRepo)
+    service.SetClock(func() time.Time { return now })
+
+    tests := []struct {
+        olderThan string
+        before    time.Time
+    }{
+        {"90d", time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)},
+        {" 0D ", now},
+        {"36h", now.Add(-36 * time.Hour)},
+    }
+    for _, tt := range tests {
+        result, err := service.Purge(context.Background(), tt.olderThan)
+        if err != nil {
+            t.Fatalf("%s: unexpected error: %s", tt.olderThan, err)
+        }
+        if !result.Before.Equal(tt.before) || !mockItemRepo.purgedBefore.Equal(tt.before) {
+            t.Errorf("%s: expected to purge before %s, got %s", tt.olderThan, tt.before, result.Before)
+        }
+        if result.Items != 2 || result.Categories != 1 {
+            t.Errorf("%s: unexpected counts %+v", tt.olderThan, result)
+        }
+    }
+
+    for _, olderThan := range []string{"", "90", "-1d", "d", "three months"} {
+        if _, err := service.Purge(context.Background(), olderThan); !errors.Is(err, apperrors.ErrValidation) {
+            t.Errorf("expected validation error for '%s', got %v", olderThan, err)
+        }
+    }
+}
+
+func TestTrashService_Restore_ValidatesID(t *testing.T) {
+    items := NewItemService(&MockItemRepository{}, &MockCategoryRepository{})
+    if err := items.Restore(context.Background(), 0); !errors.Is(err, apperrors.ErrValidation) {
+        t.Errorf("expected validation error restoring item 0, got %v", err)
+    }
+    categories := NewCategoryService(&MockCategoryRepository{})
+    if err := categories.Restore(context.Background(), -1); !errors.Is(err, apperrors.ErrValidation) {
+        t.Errorf("expected validation error restoring category -1, got %v", err)
+    }
+}
\ No newline at end of file
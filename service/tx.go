@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+
+	"mini_project3/repository"
+)
+
+// Repos are the repositories a unit of work reads and changes
+type Repos struct {
+	Categories    CategoryRepositoryInterface
+	Items         ItemRepositoryInterface
+	ExchangeRates ExchangeRateRepositoryInterface
+}
+
+// TxManager runs units of work: every change fn makes through repos is
+// committed when it returns nil and rolled back when it returns an error, so
+// a check such as "the category exists" still holds when fn writes
+type TxManager interface {
+	WithTx(ctx context.Context, fn func(repos Repos) error) error
+}
+
+// noTx is the TxManager of a service without one: fn runs on the
+// repositories of the service and every call commits on its own
+type noTx struct {
+	repos Repos
+}
+
+func (t noTx) WithTx(ctx context.Context, fn func(repos Repos) error) error {
+	return fn(t.repos)
+}
+
+type sqlTx struct {
+	manager *repository.TxManager
+}
+
+// NewSQLTxManager runs units of work in a database transaction
+func NewSQLTxManager(manager *repository.TxManager) TxManager {
+	return sqlTx{manager: manager}
+}
+
+func (t sqlTx) WithTx(ctx context.Context, fn func(repos Repos) error) error {
+	return t.manager.WithTx(ctx, func(categories *repository.CategoryRepository, items *repository.ItemRepository, rates *repository.ExchangeRateRepository) error {
+		return fn(Repos{Categories: categories, Items: items, ExchangeRates: rates})
+	})
+}
+
+type memoryTx struct {
+	manager *repository.MemoryTxManager
+}
+
+// NewMemoryTxManager runs units of work on the in-memory store, see repository.MemoryTxManager
+func NewMemoryTxManager(manager *repository.MemoryTxManager) TxManager {
+	return memoryTx{manager: manager}
+}
+
+func (t memoryTx) WithTx(ctx context.Context, fn func(repos Repos) error) error {
+	return t.manager.WithTx(ctx, func(categories *repository.MemoryCategoryRepository, items *repository.MemoryItemRepository, rates *repository.MemoryExchangeRateRepository) error {
+		return fn(Repos{Categories: categories, Items: items, ExchangeRates: rates})
+	})
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+    "context"
+    "errors"
+    "strings"
+    "testing"
+    "time"
+
+    "mini_project3/models"
+    "mini_project3/money"
+    "mini_project3/repository"
+)
+
+// failingTx runs units of work on the memory store with some repositories
+// replaced by failing ones, so that they fail halfway and must roll back
+type failingTx struct {
+    TxManager
+    replace func(repos *Repos)
+}
+
+func (t failingTx) WithTx(ctx context.Context, fn func(repos Repos) error) error {
+    return t.TxManager.WithTx(ctx, func(repos Repos) error {
+        t.replace(&repos)
+        return fn(repos)
+    })
+}
+
+// failingRates stores the first n rates and fails on the next one
+type failingRates struct {
+    ExchangeRateRepositoryInterface
+    n int
+}
+
+func (r *failingRates) Set(ctx context.Context, rate *models.ExchangeRate) error {
+    if r.n == 0 {
+        return errors.New("mock error")
+    }
+    r.n--
+    return r.ExchangeRateRepositoryInterface.Set(ctx, rate)
+}
+
+func TestItemService_Create_ChecksCategoryInUnitOfWork(t *testing.T) {
+    store := repository.NewMemoryStore()
+    categories, items := repository.NewMemoryCategoryRepository(store), repository.NewMemoryItemRepository(store)
+    cat := &models.Category{Name: "Elektronik"}
+    if err := categories.Create(context.Background(), cat); err != nil {
+        t.Fatalf("unexpected error: %s", err)
+    }
+
+    // the category the unit of work sees is gone, even though the repositories of the service still have it
+    service := NewItemService(items, categories)
+    service.SetTxManager(failingTx{NewMemoryTxManager(repository.NewMemoryTxManager(store)), func(repos *Repos) {
+        repos.Categories = &MockCategoryRepository{}
+    }})
+
+    _, err := service.Create(context.Background(), "Laptop", cat.ID, money.FromInt(15000000), "IDR", time.Now(), models.DepreciationPolicy{})
+    if err == nil || !strings.Contains(err.Error(), "category not found") {
+        t.Fatalf("expected the category not to be found, got %v", err)
+    }
+    if all, _ := items.GetAll(context.Background()); len(all) != 0 {
+        t.Errorf("expected no item, got %+v", all)
+    }
+}
+
+func TestTrashService_Purge_RollsBack(t *testing.T) {
+    store := repository.NewMemoryStore()
+    categories, items := repository.NewMemoryCategoryRepository(store), repository.NewMemoryItemRepository(store)
+    ctx := context.Background()
+    cat := &models.Category{Name: "Elektronik"}
+    categories.Create(ctx, cat)
+    item := &models.Item{Name: "Laptop", CategoryID: cat.ID, Price: money.FromInt(1000), PurchaseDate: time.Now()}
+    items.Create(ctx, item)
+    items.Delete(ctx, item.ID)
+
+    service := NewTrashService(items, categories)
+    service.SetClock(func() time.Time { return time.Now().Add(time.Hour) })
+    service.SetTxManager(failingTx{NewMemoryTxManager(repository.NewMemoryTxManager(store)), func(repos *Repos) {
+        repos.Categories = &MockCategoryRepository{shouldError: true}
+    }})
+
+    if _, err := service.Purge(ctx, "0d"); err == nil {
+        t.Fatal("expected the purge of the categories to fail")
+    }
+    if trash, _ := items.GetDeleted(ctx); len(trash) != 1 {
+        t.Errorf("expected the purge of the items to be rolled back, got trash %+v", trash)
+    }
+}
+
+func TestFXService_Import_RollsBack(t *testing.T) {
+    store := repository.NewMemoryStore()
+    rates := repository.NewMemoryExchangeRateRepository(store)
+    service := NewFXService(rates)
+    service.SetTxManager(failingTx{NewMemoryTxManager(repository.NewMemoryTxManager(store)), func(repos *Repos) {
+        repos.ExchangeRates = &failingRates{ExchangeRateRepositoryInterface: repos.ExchangeRates, n: 1}
+    }})
+
+    csv := "date,currency,rate\n2024-01-01,USD,15400\n2024-01-01,SGD,11650\n"
+    if _, err := service.Import(context.Background(), strings.NewReader(csv)); err == nil {
+        t.Fatal("expected the second rate to fail")
+    }
+    if stored, _ := rates.GetAll(context.Background(), "USD"); len(stored) != 0 {
+        t.Errorf("expected the first rate to be rolled back, got %+v", stored)
+    }
+}
\ No newline at end of file
@@ -0,0 +1,88 @@
+// Package spreadsheet reads the rows of the CSV and XLSX files that imports
+// accept. Cells are returned as text: numbers as stored, and XLSX cells with
+// a date format as YYYY-MM-DD like dates typed into a CSV file.
+package spreadsheet
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"mini_project3/apperrors"
+)
+
+// Extensions lists the file types Open reads
+var Extensions = []string{".csv", ".xlsx"}
+
+// Row is a row of a file with the line (CSV) or row number (XLSX) it was read from
+type Row struct {
+	Line  int
+	Cells []string
+}
+
+// Blank reports whether every cell of the row is empty
+func (r Row) Blank() bool {
+	for _, cell := range r.Cells {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// Open reads the rows of a .csv file, or of the first sheet of a .xlsx file
+func Open(path string) ([]Row, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext != ".csv" && ext != ".xlsx" {
+		return nil, apperrors.NewValidationError("file", fmt.Sprintf("must be one of %s, got '%s'", strings.Join(Extensions, ", "), filepath.Base(path)))
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if ext == ".csv" {
+		return ReadCSV(f)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return ReadXLSX(f, info.Size())
+}
+
+// ReadCSV reads the rows of a CSV file; rows may have different numbers of
+// cells. The byte order mark Excel writes before "CSV UTF-8" files is dropped.
+func ReadCSV(r io.Reader) ([]Row, error) {
+	buffered := bufio.NewReader(r)
+	if bom, _, err := buffered.ReadRune(); err == nil && bom != '\ufeff' {
+		buffered.UnreadRune()
+	}
+	reader := csv.NewReader(buffered)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	var rows []Row
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				return nil, apperrors.NewValidationError(fmt.Sprintf("line %d", parseErr.Line), parseErr.Err.Error())
+			}
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, Row{Line: line, Cells: record})
+	}
+}
@@ -0,0 +1,144 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"mini_project3/apperrors"
+)
+
+// buildXLSX zips parts into a minimal workbook
+func buildXLSX(t *testing.T, parts map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range parts {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+var workbookParts = map[string]string{
+	"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+		<sheets><sheet name="Aset" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+	"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+		<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`,
+	"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+		<si><t>name</t></si><si><t>price</t></si><si><t>purchase_date</t></si><si><r><t>Laptop </t></r><r><t>Dell</t></r></si></sst>`,
+	"xl/styles.xml": `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+		<numFmts count="1"><numFmt numFmtId="164" formatCode="dd/mm/yyyy;@"/></numFmts>
+		<cellXfs count="3"><xf numFmtId="0"/><xf numFmtId="14"/><xf numFmtId="164"/></cellXfs></styleSheet>`,
+	"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+		<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="s"><v>2</v></c></row>
+		<row r="2"><c r="A2" t="s"><v>3</v></c><c r="B2"><v>15000000.5</v></c><c r="C2" s="1"><v>45444</v></c></row>
+		<row r="4"><c r="A4" t="inlineStr"><is><t>Meja</t></is></c><c r="C4" s="2"><v>45200.75</v></c></row>
+	</sheetData></worksheet>`,
+}
+
+func TestReadXLSX(t *testing.T) {
+	data := buildXLSX(t, workbookParts)
+	rows, err := ReadXLSX(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := []Row{
+		{Line: 1, Cells: []string{"name", "price", "purchase_date"}},
+		{Line: 2, Cells: []string{"Laptop Dell", "15000000.5", "2024-06-01"}},
+		{Line: 4, Cells: []string{"Meja", "", "2023-10-01"}},
+	}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("expected %+v, got %+v", expected, rows)
+	}
+}
+
+func TestReadXLSX_Numbers(t *testing.T) {
+	parts := map[string]string{}
+	for name, content := range workbookParts {
+		parts[name] = content
+	}
+	// Excel stores 1234.56 with 17 significant digits and large numbers in exponent form
+	parts["xl/worksheets/sheet1.xml"] = `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+		<row r="1"><c r="A1" t="inlineStr"><is><t>Kursi</t></is></c><c r="B1"><v>1234.5599999999999</v></c><c r="C1" t="n"><v>1.5E+7</v></c></row>
+	</sheetData></worksheet>`
+
+	data := buildXLSX(t, parts)
+	rows, err := ReadXLSX(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if expected := []string{"Kursi", "1234.56", "15000000"}; len(rows) != 1 || !reflect.DeepEqual(rows[0].Cells, expected) {
+		t.Errorf("expected %q, got %+v", expected, rows)
+	}
+}
+
+func TestReadXLSX_Invalid(t *testing.T) {
+	if _, err := ReadXLSX(strings.NewReader("name,price"), 10); !errors.Is(err, apperrors.ErrValidation) {
+		t.Errorf("expected validation error for a file that is not a zip, got %v", err)
+	}
+
+	data := buildXLSX(t, map[string]string{"xl/workbook.xml": workbookParts["xl/workbook.xml"]})
+	if _, err := ReadXLSX(bytes.NewReader(data), int64(len(data))); !errors.Is(err, apperrors.ErrValidation) {
+		t.Errorf("expected validation error for a workbook without relationships, got %v", err)
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	rows, err := ReadCSV(strings.NewReader("name,price\n\"Meja, Jati\", 1500000\n\nKursi\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := []Row{
+		{Line: 1, Cells: []string{"name", "price"}},
+		{Line: 2, Cells: []string{"Meja, Jati", "1500000"}},
+		{Line: 4, Cells: []string{"Kursi"}},
+	}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("expected %+v, got %+v", expected, rows)
+	}
+
+	if _, err := ReadCSV(strings.NewReader("name\n\"Meja\n")); !errors.Is(err, apperrors.ErrValidation) {
+		t.Errorf("expected validation error for an unterminated quote, got %v", err)
+	}
+}
+
+func TestReadCSV_ByteOrderMark(t *testing.T) {
+	for _, header := range []string{"\ufeffname,price", "\ufeff\"name\",price"} {
+		rows, err := ReadCSV(strings.NewReader(header + "\nMeja,1500000\n"))
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if len(rows) != 2 || !reflect.DeepEqual(rows[0].Cells, []string{"name", "price"}) {
+			t.Errorf("expected the header name,price, got %q", rows)
+		}
+	}
+}
+
+func TestOpen(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "aset.XLSX")
+	data := buildXLSX(t, workbookParts)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if rows, err := Open(path); err != nil || len(rows) != 3 {
+		t.Errorf("expected 3 rows, got %d (%v)", len(rows), err)
+	}
+
+	if _, err := Open(filepath.Join(dir, "aset.ods")); !errors.Is(err, apperrors.ErrValidation) {
+		t.Errorf("expected validation error for an unsupported file type, got %v", err)
+	}
+}
@@ -0,0 +1,249 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"mini_project3/apperrors"
+)
+
+// An XLSX file is a zip of XML parts: the workbook lists the sheets, its
+// relationships name the part of each sheet, text cells point into the
+// shared strings and number formats are declared in the styles.
+
+type xlsxWorkbook struct {
+	Properties struct {
+		Date1904 bool `xml:"date1904,attr"`
+	} `xml:"workbookPr"`
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// xlsxText is a shared or inline string, either plain or in rich text runs
+type xlsxText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	if len(t.Runs) == 0 {
+		return t.T
+	}
+	var b strings.Builder
+	for _, run := range t.Runs {
+		b.WriteString(run.T)
+	}
+	return b.String()
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxText `xml:"si"`
+}
+
+type xlsxStyles struct {
+	NumFmts []struct {
+		ID   int    `xml:"numFmtId,attr"`
+		Code string `xml:"formatCode,attr"`
+	} `xml:"numFmts>numFmt"`
+	CellXfs []struct {
+		NumFmtID int `xml:"numFmtId,attr"`
+	} `xml:"cellXfs>xf"`
+}
+
+type xlsxSheet struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			Ref    string   `xml:"r,attr"`
+			Type   string   `xml:"t,attr"`
+			Style  int      `xml:"s,attr"`
+			Value  string   `xml:"v"`
+			Inline xlsxText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// ReadXLSX reads the rows of the first sheet of an XLSX file. Cells left out
+// of a row come back empty, so the others stay under their header.
+func ReadXLSX(r io.ReaderAt, size int64) ([]Row, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, apperrors.NewValidationError("file", "is not an XLSX workbook: "+err.Error())
+	}
+	parts := map[string]*zip.File{}
+	for _, f := range zr.File {
+		parts[strings.TrimPrefix(f.Name, "/")] = f
+	}
+
+	var workbook xlsxWorkbook
+	if err := decodePart(parts, "xl/workbook.xml", &workbook, true); err != nil {
+		return nil, err
+	}
+	if len(workbook.Sheets) == 0 {
+		return nil, apperrors.NewValidationError("file", "has no sheets")
+	}
+	var rels xlsxRelationships
+	if err := decodePart(parts, "xl/_rels/workbook.xml.rels", &rels, true); err != nil {
+		return nil, err
+	}
+	sheetPart := ""
+	for _, rel := range rels.Relationships {
+		if rel.ID == workbook.Sheets[0].RID {
+			sheetPart = rel.Target
+		}
+	}
+	if strings.HasPrefix(sheetPart, "/") {
+		sheetPart = strings.TrimPrefix(sheetPart, "/")
+	} else {
+		sheetPart = path.Join("xl", sheetPart)
+	}
+
+	var shared xlsxSharedStrings
+	if err := decodePart(parts, "xl/sharedStrings.xml", &shared, false); err != nil {
+		return nil, err
+	}
+	var styles xlsxStyles
+	if err := decodePart(parts, "xl/styles.xml", &styles, false); err != nil {
+		return nil, err
+	}
+	var sheet xlsxSheet
+	if err := decodePart(parts, sheetPart, &sheet, true); err != nil {
+		return nil, err
+	}
+
+	dateStyles := map[int]bool{}
+	customFormats := map[int]string{}
+	for _, numFmt := range styles.NumFmts {
+		customFormats[numFmt.ID] = numFmt.Code
+	}
+	for i, xf := range styles.CellXfs {
+		dateStyles[i] = isDateFormat(xf.NumFmtID, customFormats[xf.NumFmtID])
+	}
+
+	var rows []Row
+	for i, row := range sheet.Rows {
+		line := row.R
+		if line == 0 {
+			line = i + 1
+		}
+		var cells []string
+		for j, c := range row.Cells {
+			col := j
+			if c.Ref != "" {
+				if col, err = columnIndex(c.Ref); err != nil {
+					return nil, apperrors.NewValidationError(fmt.Sprintf("row %d", line), err.Error())
+				}
+			}
+			for len(cells) <= col {
+				cells = append(cells, "")
+			}
+
+			switch c.Type {
+			case "s":
+				n, err := strconv.Atoi(c.Value)
+				if err != nil || n < 0 || n >= len(shared.Items) {
+					return nil, apperrors.NewValidationError(fmt.Sprintf("row %d", line), fmt.Sprintf("refers to a missing shared string '%s'", c.Value))
+				}
+				cells[col] = shared.Items[n].String()
+			case "inlineStr":
+				cells[col] = c.Inline.String()
+			case "b":
+				cells[col] = map[string]string{"0": "FALSE", "1": "TRUE"}[c.Value]
+			case "", "n":
+				// numbers are stored with up to 17 digits, e.g. 1234.5599999999999;
+				// the shortest text that reads back as the same number is what Excel shows
+				cells[col] = c.Value
+				if serial, err := strconv.ParseFloat(c.Value, 64); err == nil {
+					cells[col] = strconv.FormatFloat(serial, 'f', -1, 64)
+					if dateStyles[c.Style] {
+						cells[col] = serialDate(serial, workbook.Properties.Date1904).Format("2006-01-02")
+					}
+				}
+			default: // str (formula text) and e (error) keep their stored value
+				cells[col] = c.Value
+			}
+		}
+		rows = append(rows, Row{Line: line, Cells: cells})
+	}
+	return rows, nil
+}
+
+// decodePart decodes the XML part name into v; a missing optional part leaves v empty
+func decodePart(parts map[string]*zip.File, name string, v interface{}, required bool) error {
+	f, ok := parts[name]
+	if !ok {
+		if required {
+			return apperrors.NewValidationError("file", fmt.Sprintf("is not an XLSX workbook, it has no %s", name))
+		}
+		return nil
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return apperrors.NewValidationError("file", fmt.Sprintf("cannot read %s: %s", name, err))
+	}
+	defer rc.Close()
+	if err := xml.NewDecoder(rc).Decode(v); err != nil {
+		return apperrors.NewValidationError("file", fmt.Sprintf("cannot read %s: %s", name, err))
+	}
+	return nil
+}
+
+var cellRefRe = regexp.MustCompile(`^([A-Z]{1,3})[0-9]+$`)
+
+// columnIndex returns the 0-based column of a cell reference such as B3
+func columnIndex(ref string) (int, error) {
+	m := cellRefRe.FindStringSubmatch(strings.ToUpper(ref))
+	if m == nil {
+		return 0, fmt.Errorf("has an invalid cell reference '%s'", ref)
+	}
+	col := 0
+	for _, letter := range m[1] {
+		col = col*26 + int(letter-'A') + 1
+	}
+	return col - 1, nil
+}
+
+// quotedRe matches the literal text and the [color] or [$-locale] sections of a number format
+var quotedRe = regexp.MustCompile(`"[^"]*"|\[[^\]]*\]|\\.`)
+
+// isDateFormat reports whether a number format shows a date: the built-in
+// date formats, or a custom format with day or year parts
+func isDateFormat(id int, code string) bool {
+	if (id >= 14 && id <= 17) || id == 22 {
+		return true
+	}
+	if code == "" {
+		return false
+	}
+	code = strings.ToLower(quotedRe.ReplaceAllString(code, ""))
+	return strings.ContainsAny(code, "dy")
+}
+
+// serialDate converts an Excel date serial, days since 1899-12-30 (or
+// 1904-01-01 in 1904 workbooks), to the day it falls on
+func serialDate(serial float64, date1904 bool) time.Time {
+	epoch := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	if date1904 {
+		epoch = time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return epoch.AddDate(0, 0, int(math.Floor(serial)))
+}
@@ -1,17 +1,17 @@
 package utils
 
-import "fmt"
+import "mini_project3/apperrors"
 
 func ValidateNotEmpty(value, fieldName string) error {
     if value == "" {
-        return fmt.Errorf("%s cannot be empty", fieldName)
+        return apperrors.NewValidationError(fieldName, "cannot be empty")
     }
     return nil
 }
 
 func ValidateID(id int) error {
     if id <= 0 {
-        return fmt.Errorf("ID must be greater than 0")
+        return apperrors.NewValidationError("ID", "must be greater than 0")
     }
     return nil
 }
\ No newline at end of file